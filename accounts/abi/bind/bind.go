@@ -22,6 +22,7 @@ package bind
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"go/format"
 	"regexp"
@@ -38,6 +39,8 @@ type Lang int
 
 const (
 	LangGo Lang = iota
+	LangTypeScript
+	LangPython
 )
 
 func isKeyWord(arg string) bool {
@@ -77,11 +80,73 @@ func isKeyWord(arg string) bool {
 	return true
 }
 
-// Bind generates a Go wrapper around a contract ABI. This wrapper isn't meant
-// to be used as is in client code, but rather as an intermediate struct which
-// enforces compile time type safety and naming convention opposed to having to
-// manually maintain hard coded strings that break on runtime.
+// isKeyWordTypeScript checks whether the given name is reserved in TypeScript
+// parameter lists, or by the generated TypeScript bindings themselves.
+func isKeyWordTypeScript(arg string) bool {
+	switch arg {
+	case "arguments", "await", "break", "case", "catch", "class", "const", "continue",
+		"debugger", "default", "delete", "do", "else", "enum", "eval", "export",
+		"extends", "false", "finally", "for", "function", "if", "implements",
+		"import", "in", "instanceof", "interface", "let", "new", "null", "package",
+		"private", "protected", "public", "return", "static", "super", "switch",
+		"this", "throw", "true", "try", "typeof", "var", "void", "while", "with",
+		"yield":
+		return true
+	case "overrides", "runner", "libraries":
+		return true
+	}
+	return false
+}
+
+// isKeyWordPython checks whether the given name is reserved in Python, or by
+// the generated Python bindings themselves.
+func isKeyWordPython(arg string) bool {
+	switch arg {
+	case "False", "None", "True", "and", "as", "assert", "async", "await", "break",
+		"class", "continue", "def", "del", "elif", "else", "except", "finally",
+		"for", "from", "global", "if", "import", "in", "is", "lambda", "nonlocal",
+		"not", "or", "pass", "raise", "return", "try", "while", "with", "yield":
+		return true
+	case "self", "cls", "w3", "tx", "block_identifier", "libraries":
+		return true
+	}
+	return false
+}
+
+// keyWord is a set of checks whether a name is reserved in the target language
+// and can't be used as a parameter name.
+var keyWord = map[Lang]func(string) bool{
+	LangGo:         isKeyWord,
+	LangTypeScript: isKeyWordTypeScript,
+	LangPython:     isKeyWordPython,
+}
+
+// Bind generates a Go, TypeScript or Python wrapper around a contract ABI. This
+// wrapper isn't meant to be used as is in client code, but rather as an
+// intermediate struct which enforces compile time type safety and naming
+// convention opposed to having to manually maintain hard coded strings that
+// break on runtime.
+//
+// The TypeScript bindings are built on ethers v6, the Python bindings on web3.py
+// v6.
 func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]string, pkg string, lang Lang, libs map[string]string, aliases map[string]string) (string, error) {
+	// The classic Go bindings predate custom errors and don't bind them
+	return generate(types, abis, bytecodes, fsigs, pkg, lang, libs, aliases, tmplSource[lang], lang != LangGo)
+}
+
+// BindV2 generates lightweight Go bindings around a contract ABI. Instead of a
+// contract wrapper bound to a backend, the bindings consist of pack and unpack
+// functions for each method, event and custom error of the contract. They are
+// used together with the generic helpers in package accounts/abi/bind/v2, which
+// work with any contract backend.
+func BindV2(types []string, abis []string, bytecodes []string, fsigs []map[string]string, pkg string, libs map[string]string, aliases map[string]string) (string, error) {
+	return generate(types, abis, bytecodes, fsigs, pkg, LangGo, libs, aliases, tmplSourceGoV2, true)
+}
+
+// generate renders the binding template for the given contracts. Custom errors
+// are only bound if requested, leaving the output of the templates not binding
+// them unaffected.
+func generate(types []string, abis []string, bytecodes []string, fsigs []map[string]string, pkg string, lang Lang, libs map[string]string, aliases map[string]string, source string, bindErrors bool) (string, error) {
 	var (
 		// contracts is the map of each individual contract requested binding
 		contracts = make(map[string]*tmplContract)
@@ -111,6 +176,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			calls     = make(map[string]*tmplMethod)
 			transacts = make(map[string]*tmplMethod)
 			events    = make(map[string]*tmplEvent)
+			errs      = make(map[string]*tmplError)
 			fallback  *tmplMethod
 			receive   *tmplMethod
 
@@ -121,6 +187,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			callIdentifiers     = make(map[string]bool)
 			transactIdentifiers = make(map[string]bool)
 			eventIdentifiers    = make(map[string]bool)
+			errorIdentifiers    = make(map[string]bool)
 		)
 
 		for _, input := range evmABI.Constructor.Inputs {
@@ -155,7 +222,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			normalized.Inputs = make([]abi.Argument, len(original.Inputs))
 			copy(normalized.Inputs, original.Inputs)
 			for j, input := range normalized.Inputs {
-				if input.Name == "" || isKeyWord(input.Name) {
+				if input.Name == "" || keyWord[lang](input.Name) {
 					normalized.Inputs[j].Name = fmt.Sprintf("arg%d", j)
 				}
 				if hasStruct(input.Type) {
@@ -207,7 +274,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			normalized.Inputs = make([]abi.Argument, len(original.Inputs))
 			copy(normalized.Inputs, original.Inputs)
 			for j, input := range normalized.Inputs {
-				if input.Name == "" || isKeyWord(input.Name) {
+				if input.Name == "" || keyWord[lang](input.Name) {
 					normalized.Inputs[j].Name = fmt.Sprintf("arg%d", j)
 				}
 				// Event is a bit special, we need to define event struct in binding,
@@ -226,6 +293,45 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			// Append the event to the accumulator list
 			events[original.Name] = &tmplEvent{Original: original, Normalized: normalized}
 		}
+		// Custom errors are only bound by the templates supporting them
+		if bindErrors {
+			for _, original := range evmABI.Errors {
+				// Normalize the error for capital cases and non-anonymous inputs
+				normalized := original
+
+				// Ensure there is no duplicated identifier. Errors and events are
+				// both bound to types, so they share the namespace.
+				normalizedName := methodNormalizer[lang](alias(aliases, original.Name))
+				if len(normalizedName) > 0 && unicode.IsDigit(rune(normalizedName[0])) {
+					normalizedName = fmt.Sprintf("E%s", normalizedName)
+				}
+				if errorIdentifiers[normalizedName] || eventIdentifiers[normalizedName] {
+					return "", fmt.Errorf("duplicated identifier \"%s\"(normalized \"%s\"), use --alias for renaming", original.Name, normalizedName)
+				}
+				errorIdentifiers[normalizedName] = true
+				normalized.Name = normalizedName
+
+				used := make(map[string]bool)
+				normalized.Inputs = make([]abi.Argument, len(original.Inputs))
+				copy(normalized.Inputs, original.Inputs)
+				for j, input := range normalized.Inputs {
+					if input.Name == "" || keyWord[lang](input.Name) {
+						normalized.Inputs[j].Name = fmt.Sprintf("arg%d", j)
+					}
+					for index := 0; ; index++ {
+						if !used[capitalise(normalized.Inputs[j].Name)] {
+							used[capitalise(normalized.Inputs[j].Name)] = true
+							break
+						}
+						normalized.Inputs[j].Name = fmt.Sprintf("%s%d", normalized.Inputs[j].Name, index)
+					}
+					if hasStruct(input.Type) {
+						bindStructType[lang](input.Type, structs)
+					}
+				}
+				errs[original.Name] = &tmplError{Original: original, Normalized: normalized}
+			}
+		}
 		// Add two special fallback functions if they exist
 		if evmABI.HasFallback() {
 			fallback = &tmplMethod{Original: evmABI.Fallback}
@@ -243,6 +349,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			Fallback:    fallback,
 			Receive:     receive,
 			Events:      events,
+			Errors:      errs,
 			Libraries:   make(map[string]string),
 		}
 		// Function 4-byte signatures are stored in the same sequence
@@ -285,8 +392,14 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 		"namedtype":     namedType[lang],
 		"capitalise":    capitalise,
 		"decapitalise":  decapitalise,
+		"pyname":        pythonName,
+		"dict":          dict,
+		"packv2":        packTypeGoV2,
+		"unpackv2":      unpackTypeGoV2,
+		"topictypev2":   bindTopicTypeGoV2,
+		"unpacktopicv2": unpackTopicTypeGoV2,
 	}
-	tmpl := template.Must(template.New("").Funcs(funcs).Parse(tmplSource[lang]))
+	tmpl := template.Must(template.New("").Funcs(funcs).Parse(source))
 	if err := tmpl.Execute(buffer, data); err != nil {
 		return "", err
 	}
@@ -305,7 +418,9 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 // bindType is a set of type binders that convert Solidity types to some supported
 // programming language types.
 var bindType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
-	LangGo: bindTypeGo,
+	LangGo:         bindTypeGo,
+	LangTypeScript: bindTypeTypeScript,
+	LangPython:     bindTypePython,
 }
 
 // bindBasicTypeGo converts basic solidity types(except array, slice and tuple) to Go ones.
@@ -348,10 +463,80 @@ func bindTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
 	}
 }
 
+// bindBasicTypeTypeScript converts basic solidity types(except array, slice and
+// tuple) to TypeScript ones. Integers of all sizes are bound to bigint, as that
+// is how ethers decodes them; byte arrays and addresses are hex strings.
+func bindBasicTypeTypeScript(kind abi.Type) string {
+	switch kind.T {
+	case abi.IntTy, abi.UintTy:
+		return "bigint"
+	case abi.BoolTy:
+		return "boolean"
+	default:
+		// address, string, bytes and function types
+		return "string"
+	}
+}
+
+// bindTypeTypeScript converts solidity types to TypeScript ones. Fixed size
+// arrays keep their length in the FixedArray type of the bindings, tuples are
+// bound to interfaces.
+func bindTypeTypeScript(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return structs[kind.TupleRawName+kind.String()].Name
+	case abi.ArrayTy:
+		return fmt.Sprintf("FixedArray<%s, %d>", bindTypeTypeScript(*kind.Elem, structs), kind.Size)
+	case abi.SliceTy:
+		return bindTypeTypeScript(*kind.Elem, structs) + "[]"
+	default:
+		return bindBasicTypeTypeScript(kind)
+	}
+}
+
+// bindBasicTypePython converts basic solidity types(except array, slice and
+// tuple) to Python ones.
+func bindBasicTypePython(kind abi.Type) string {
+	switch kind.T {
+	case abi.IntTy, abi.UintTy:
+		return "int"
+	case abi.BoolTy:
+		return "bool"
+	case abi.FixedBytesTy, abi.BytesTy, abi.FunctionTy:
+		return "bytes"
+	default:
+		// address and string types
+		return "str"
+	}
+}
+
+// bindTypePython converts solidity types to Python type hints. Fixed size arrays
+// are bound to tuples of their exact length, slices to lists and tuples to named
+// tuples.
+func bindTypePython(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return structs[kind.TupleRawName+kind.String()].Name
+	case abi.ArrayTy:
+		elem := bindTypePython(*kind.Elem, structs)
+		elems := make([]string, kind.Size)
+		for i := range elems {
+			elems[i] = elem
+		}
+		return "Tuple[" + strings.Join(elems, ", ") + "]"
+	case abi.SliceTy:
+		return "List[" + bindTypePython(*kind.Elem, structs) + "]"
+	default:
+		return bindBasicTypePython(kind)
+	}
+}
+
 // bindTopicType is a set of type binders that convert Solidity types to some
 // supported programming language topic types.
 var bindTopicType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
-	LangGo: bindTopicTypeGo,
+	LangGo:         bindTopicTypeGo,
+	LangTypeScript: bindTopicTypeTypeScript,
+	LangPython:     bindTopicTypePython,
 }
 
 // bindTopicTypeGo converts a Solidity topic type to a Go one. It is almost the same
@@ -371,10 +556,187 @@ func bindTopicTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
 	return bound
 }
 
+// isDynamicType returns whether values of the given type are encoded in the
+// tail of their enclosing tuple, referenced by an offset from its head.
+func isDynamicType(kind abi.Type) bool {
+	switch kind.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy:
+		return true
+	case abi.ArrayTy:
+		return isDynamicType(*kind.Elem)
+	case abi.TupleTy:
+		for _, elem := range kind.TupleElems {
+			if isDynamicType(*elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// packTypeGoV2 returns the Go expression encoding the value expr of the given
+// Solidity type with the _enc packer of the v2 bindings. Arrays are sliced, so
+// expr must be addressable.
+func packTypeGoV2(kind abi.Type, expr string, structs map[string]*tmplStruct) (string, error) {
+	switch kind.T {
+	case abi.UintTy, abi.IntTy:
+		signed := kind.T == abi.IntTy
+		if bindBasicTypeGo(kind) == "*big.Int" {
+			if signed {
+				return fmt.Sprintf("_enc.Int(%s, %d)", expr, kind.Size), nil
+			}
+			return fmt.Sprintf("_enc.Uint(%s, %d)", expr, kind.Size), nil
+		}
+		if signed {
+			return fmt.Sprintf("_enc.Int64(int64(%s))", expr), nil
+		}
+		return fmt.Sprintf("_enc.Uint64(uint64(%s))", expr), nil
+	case abi.BoolTy:
+		return fmt.Sprintf("_enc.Bool(%s)", expr), nil
+	case abi.AddressTy:
+		return fmt.Sprintf("_enc.Address(%s)", expr), nil
+	case abi.FixedBytesTy, abi.FunctionTy:
+		return fmt.Sprintf("_enc.FixedBytes(%s[:])", expr), nil
+	case abi.BytesTy:
+		return fmt.Sprintf("_enc.Bytes(%s)", expr), nil
+	case abi.StringTy:
+		return fmt.Sprintf("_enc.String(%s)", expr), nil
+	case abi.TupleTy:
+		fields := structs[kind.TupleRawName+kind.String()].Fields
+		packed := make([]string, len(fields))
+		for i, field := range fields {
+			enc, err := packTypeGoV2(*kind.TupleElems[i], expr+"."+field.Name, structs)
+			if err != nil {
+				return "", err
+			}
+			packed[i] = enc
+		}
+		return fmt.Sprintf("_enc.Tuple(%s)", strings.Join(packed, ", ")), nil
+	case abi.ArrayTy, abi.SliceTy:
+		elem, err := packTypeGoV2(*kind.Elem, "v", structs)
+		if err != nil {
+			return "", err
+		}
+		if kind.T == abi.ArrayTy {
+			return fmt.Sprintf("bind.PackArray(%s[:], func(v %s) bind.Encoded { return %s })", expr, bindTypeGo(*kind.Elem, structs), elem), nil
+		}
+		return fmt.Sprintf("bind.PackSlice(%s, func(v %s) bind.Encoded { return %s })", expr, bindTypeGo(*kind.Elem, structs), elem), nil
+	default:
+		return "", fmt.Errorf("unsupported type %v", kind)
+	}
+}
+
+// unpackTypeGoV2 returns the Go expression decoding a value of the given
+// Solidity type with the _dec decoder of the v2 bindings.
+func unpackTypeGoV2(kind abi.Type, structs map[string]*tmplStruct) (string, error) {
+	switch kind.T {
+	case abi.UintTy, abi.IntTy:
+		method := "Uint"
+		if kind.T == abi.IntTy {
+			method = "Int"
+		}
+		switch bound := bindBasicTypeGo(kind); bound {
+		case "*big.Int":
+			return fmt.Sprintf("_dec.%s(%d)", method, kind.Size), nil
+		case "uint64", "int64":
+			return fmt.Sprintf("_dec.%s64(%d)", method, kind.Size), nil
+		default:
+			return fmt.Sprintf("%s(_dec.%s64(%d))", bound, method, kind.Size), nil
+		}
+	case abi.BoolTy:
+		return "_dec.Bool()", nil
+	case abi.AddressTy:
+		return "_dec.Address()", nil
+	case abi.FixedBytesTy, abi.FunctionTy:
+		size := kind.Size
+		if kind.T == abi.FunctionTy {
+			size = 24
+		}
+		return fmt.Sprintf("func(b []byte) (v [%d]byte) { copy(v[:], b); return v }(_dec.FixedBytes(%d))", size, size), nil
+	case abi.BytesTy:
+		return "_dec.Bytes()", nil
+	case abi.StringTy:
+		return "_dec.String()", nil
+	case abi.TupleTy:
+		s := structs[kind.TupleRawName+kind.String()]
+		fields := make([]string, len(s.Fields))
+		for i, field := range s.Fields {
+			dec, err := unpackTypeGoV2(*kind.TupleElems[i], structs)
+			if err != nil {
+				return "", err
+			}
+			fields[i] = fmt.Sprintf("v.%s = %s", field.Name, dec)
+		}
+		return fmt.Sprintf("func(_dec *bind.Decoder) (v %s) { %s; return v }(_dec.Tuple(%t))", s.Name, strings.Join(fields, "; "), isDynamicType(kind)), nil
+	case abi.ArrayTy:
+		elem, err := unpackTypeGoV2(*kind.Elem, structs)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("func(_dec *bind.Decoder) (v %s) { for i := range v { v[i] = %s }; return v }(_dec.Tuple(%t))", bindTypeGo(kind, structs), elem, isDynamicType(kind)), nil
+	case abi.SliceTy:
+		elem, err := unpackTypeGoV2(*kind.Elem, structs)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("bind.UnpackSlice(_dec, func(_dec *bind.Decoder) %s { return %s })", bindTypeGo(*kind.Elem, structs), elem), nil
+	default:
+		return "", fmt.Errorf("unsupported type %v", kind)
+	}
+}
+
+// bindTopicTypeGoV2 converts a Solidity topic type to a Go one in the v2
+// bindings, where all hashed topics are bound to the hash.
+func bindTopicTypeGoV2(kind abi.Type, structs map[string]*tmplStruct) string {
+	if isHashedTopic(kind) {
+		return "common.Hash"
+	}
+	return bindTypeGo(kind, structs)
+}
+
+// unpackTopicTypeGoV2 returns the Go expression decoding an indexed event field
+// of the given Solidity type with the _dec topic decoder of the v2 bindings.
+func unpackTopicTypeGoV2(kind abi.Type, structs map[string]*tmplStruct) (string, error) {
+	if isHashedTopic(kind) {
+		return "_dec.Hash()", nil
+	}
+	return unpackTypeGoV2(kind, structs)
+}
+
+// isHashedTopic returns whether an indexed event parameter of the given type is
+// stored as the keccak256 hash of its encoding rather than its value.
+func isHashedTopic(kind abi.Type) bool {
+	switch kind.T {
+	case abi.StringTy, abi.BytesTy, abi.ArrayTy, abi.SliceTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+// bindTopicTypeTypeScript converts a Solidity topic type to a TypeScript one.
+// Hashed topics are decoded by ethers into Indexed values holding the hash.
+func bindTopicTypeTypeScript(kind abi.Type, structs map[string]*tmplStruct) string {
+	if isHashedTopic(kind) {
+		return "Indexed"
+	}
+	return bindTypeTypeScript(kind, structs)
+}
+
+// bindTopicTypePython converts a Solidity topic type to a Python one. Hashed
+// topics are decoded by web3 into the raw hash.
+func bindTopicTypePython(kind abi.Type, structs map[string]*tmplStruct) string {
+	if isHashedTopic(kind) {
+		return "bytes"
+	}
+	return bindTypePython(kind, structs)
+}
+
 // bindStructType is a set of type binders that convert Solidity tuple types to some supported
 // programming language struct definition.
 var bindStructType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
-	LangGo: bindStructTypeGo,
+	LangGo:         bindStructTypeGo,
+	LangTypeScript: bindStructTypeTypeScript,
+	LangPython:     bindStructTypePython,
 }
 
 // bindStructTypeGo converts a Solidity tuple type to a Go one and records the mapping
@@ -423,10 +785,98 @@ func bindStructTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
 	}
 }
 
+// bindStructTypeTypeScript converts a Solidity tuple type to a TypeScript
+// interface and records the mapping in the given map. The fields keep their raw
+// names, which are the keys of the results decoded by ethers.
+func bindStructTypeTypeScript(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		id := kind.TupleRawName + kind.String()
+		if s, exist := structs[id]; exist {
+			return s.Name
+		}
+		var (
+			names  = make(map[string]bool)
+			fields []*tmplField
+		)
+		for i, elem := range kind.TupleElems {
+			name := kind.TupleRawNames[i]
+			if name == "" {
+				name = fmt.Sprintf("arg%d", i)
+			}
+			name = abi.ResolveNameConflict(name, func(s string) bool { return names[s] })
+			names[name] = true
+			fields = append(fields, &tmplField{Type: bindStructTypeTypeScript(*elem, structs), Name: name, SolKind: *elem})
+		}
+		name := kind.TupleRawName
+		if name == "" {
+			name = fmt.Sprintf("Struct%d", len(structs))
+		}
+		name = capitalise(name)
+
+		structs[id] = &tmplStruct{
+			Name:   name,
+			Fields: fields,
+		}
+		return name
+	case abi.ArrayTy:
+		return fmt.Sprintf("FixedArray<%s, %d>", bindStructTypeTypeScript(*kind.Elem, structs), kind.Size)
+	case abi.SliceTy:
+		return bindStructTypeTypeScript(*kind.Elem, structs) + "[]"
+	default:
+		return bindBasicTypeTypeScript(kind)
+	}
+}
+
+// bindStructTypePython converts a Solidity tuple type to a Python named tuple
+// and records the mapping in the given map. Tuples are decoded positionally by
+// web3, so the fields are renamed to Python conventions.
+func bindStructTypePython(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		id := kind.TupleRawName + kind.String()
+		if s, exist := structs[id]; exist {
+			return s.Name
+		}
+		var (
+			names  = make(map[string]bool)
+			fields []*tmplField
+		)
+		for i, elem := range kind.TupleElems {
+			name := pythonName(kind.TupleRawNames[i])
+			if name == "" {
+				name = fmt.Sprintf("field%d", i)
+			}
+			name = abi.ResolveNameConflict(name, func(s string) bool { return names[s] })
+			names[name] = true
+			fields = append(fields, &tmplField{Type: bindStructTypePython(*elem, structs), Name: name, SolKind: *elem})
+		}
+		name := kind.TupleRawName
+		if name == "" {
+			name = fmt.Sprintf("Struct%d", len(structs))
+		}
+		name = capitalise(name)
+
+		structs[id] = &tmplStruct{
+			Name:   name,
+			Fields: fields,
+		}
+		return name
+	case abi.ArrayTy, abi.SliceTy:
+		// Element structs are recorded, the container is rebuilt by bindTypePython.
+		bindStructTypePython(*kind.Elem, structs)
+		return bindTypePython(kind, structs)
+	default:
+		return bindBasicTypePython(kind)
+	}
+}
+
 // namedType is a set of functions that transform language specific types to
 // named versions that may be used inside method names.
 var namedType = map[Lang]func(string, abi.Type) string{
-	LangGo: func(string, abi.Type) string { panic("this shouldn't be needed") },
+	LangGo:         func(string, abi.Type) string { panic("this shouldn't be needed") },
+	LangTypeScript: func(string, abi.Type) string { panic("this shouldn't be needed") },
+	LangPython:     func(string, abi.Type) string { panic("this shouldn't be needed") },
 }
 
 // alias returns an alias of the given string based on the aliasing rules
@@ -441,12 +891,31 @@ func alias(aliases map[string]string, n string) string {
 // methodNormalizer is a name transformer that modifies Solidity method names to
 // conform to target language naming conventions.
 var methodNormalizer = map[Lang]func(string) string{
-	LangGo: abi.ToCamelCase,
+	LangGo:         abi.ToCamelCase,
+	LangTypeScript: decapitalise,
+	LangPython:     pythonName,
 }
 
 // capitalise makes a camel-case string which starts with an upper case character.
 var capitalise = abi.ToCamelCase
 
+// dict creates a map from alternating keys and values, which is used to pass
+// multiple values to nested templates.
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("odd number of dict arguments")
+	}
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
 // decapitalise makes a camel-case string which starts with a lower case character.
 func decapitalise(input string) string {
 	if len(input) == 0 {
@@ -457,6 +926,32 @@ func decapitalise(input string) string {
 	return strings.ToLower(goForm[:1]) + goForm[1:]
 }
 
+// pythonName converts a camel-case Solidity identifier into a snake-case Python
+// one. Leading underscores are dropped, as they denote private names in Python,
+// and reserved names get an underscore appended.
+func pythonName(input string) string {
+	var (
+		runes = []rune(strings.TrimLeft(input, "_"))
+		name  strings.Builder
+	)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word after a lower case character or digit, and at the
+			// last capital of an acronym followed by a lower case word (URIList).
+			if i > 0 && runes[i-1] != '_' && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				name.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		name.WriteRune(r)
+	}
+	if isKeyWordPython(name.String()) {
+		name.WriteByte('_')
+	}
+	return name.String()
+}
+
 // structured checks whether a list of ABI data types has enough information to
 // operate through a proper Go struct or if flat returns are needed.
 func structured(args abi.Arguments) bool {
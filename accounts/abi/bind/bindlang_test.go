@@ -0,0 +1,197 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bindLangTests are the expected snippets of the TypeScript and Python bindings
+// generated for some of the contracts of bindTests.
+var bindLangTests = []struct {
+	name   string
+	lang   Lang
+	expect []string
+}{
+	{
+		`Token`, LangTypeScript,
+		[]string{
+			`async balanceOf(arg0: string, overrides: Overrides = {}): Promise<bigint> {`,
+			`return await this.contract.getFunction("balanceOf(address)").staticCall(arg0, overrides);`,
+			`async transfer(_to: string, _value: bigint, overrides: Overrides = {}): Promise<ContractTransactionResponse> {`,
+			`static async deploy(runner: ContractRunner, initialSupply: bigint, tokenName: string, decimalUnits: bigint, tokenSymbol: string, overrides: Overrides = {})`,
+			`export interface TokenTransfer {
+  from: string;
+  to: string;
+  value: bigint;
+  raw: EventLog;
+}`,
+		},
+	},
+	{
+		`Tuple`, LangTypeScript,
+		[]string{
+			`export interface TupleS {
+  a: bigint;
+  b: bigint[];
+  c: TupleT[];
+}`,
+			`async func1(a: TupleS, b: FixedArray<TupleT, 2>[], c: FixedArray<TupleT[], 2>, d: TupleS[], e: bigint[], overrides: Overrides = {})`,
+			`async func3(arg0: TupleQ[], overrides: Overrides = {}): Promise<void> {`,
+		},
+	},
+	{
+		`Eventer`, LangTypeScript,
+		[]string{
+			`export interface EventerDynamicEvent {
+  IndexedString: Indexed;
+  IndexedBytes: Indexed;
+  NonIndexedString: string;
+  NonIndexedBytes: string;
+  raw: EventLog;
+}`,
+			`await this.contract.on("SimpleEvent(address,bytes32,bool,uint256)", handler);`,
+		},
+	},
+	{
+		`NewErrors`, LangTypeScript,
+		[]string{
+			`export type NewErrorsError =
+  | NewErrorsMyError
+  | NewErrorsMyError1
+  | NewErrorsMyError2
+  | NewErrorsMyError3;`,
+			`      case "MyError3(uint256,uint256,uint256)":
+        return {
+          name: "MyError3",
+          args: {
+            a: parsed.args[0],
+            b: parsed.args[1],
+            c: parsed.args[2],
+          },
+        };`,
+		},
+	},
+	{
+		`Token`, LangPython,
+		[]string{
+			`def balance_of(self, arg0: str, block_identifier: BlockIdentifier = "latest") -> int:`,
+			`def transfer_from(self, _from: str, _to: str, _value: int, tx: Optional[TxParams] = None) -> HexBytes:`,
+			`class TokenTransfer(NamedTuple):
+    """Represents a Transfer event raised by the Token contract."""
+
+    arg0: str
+    to: str
+    value: int
+    raw: EventData`,
+			`_convert(args["from"], str),`,
+		},
+	},
+	{
+		`Tuple`, LangPython,
+		[]string{
+			`class TupleS(NamedTuple):
+    """TupleS is an auto generated binding around an user-defined struct."""
+
+    a: int
+    b: List[int]
+    c: List[TupleT]`,
+			`def func1(self, a: TupleS, b: List[Tuple[TupleT, TupleT]], c: Tuple[List[TupleT], List[TupleT]], d: List[TupleS], e: List[int], block_identifier: BlockIdentifier = "latest")`,
+		},
+	},
+	{
+		`Tupler`, LangPython,
+		[]string{
+			`class TuplerTupleOutput(NamedTuple):`,
+			`def tuple(self, block_identifier: BlockIdentifier = "latest") -> TuplerTupleOutput:`,
+			`return _convert(result, TuplerTupleOutput)`,
+		},
+	},
+	{
+		`NewErrors`, LangPython,
+		[]string{
+			`if selector == bytes.fromhex("921db340"):`,
+			`values = self.contract.w3.codec.decode(["uint256", "uint256", "uint256"], payload)`,
+		},
+	},
+}
+
+func TestLangBindings(t *testing.T) {
+	contracts := make(map[string]int)
+	for i, tt := range bindTests {
+		contracts[tt.name] = i
+	}
+	for _, tt := range bindLangTests {
+		contract := bindTests[contracts[tt.name]]
+		code, err := Bind([]string{tt.name}, contract.abi, contract.bytecode, contract.fsigs, "", tt.lang, contract.libs, contract.aliases)
+		if err != nil {
+			t.Fatalf("%s: failed to generate binding: %v", tt.name, err)
+		}
+		for _, want := range tt.expect {
+			if !strings.Contains(code, want) {
+				t.Errorf("%s: binding missing %q", tt.name, want)
+			}
+		}
+	}
+}
+
+// Tests that the Python bindings of all test contracts are valid Python code.
+func TestPythonBindingsCompile(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not found for testing")
+	}
+	dir := t.TempDir()
+	for _, tt := range bindTests {
+		code, err := Bind([]string{tt.name}, tt.abi, tt.bytecode, tt.fsigs, "", LangPython, tt.libs, tt.aliases)
+		if err != nil {
+			t.Fatalf("%s: failed to generate binding: %v", tt.name, err)
+		}
+		file := filepath.Join(dir, strings.ToLower(tt.name)+".py")
+		if err := os.WriteFile(file, []byte(code), 0600); err != nil {
+			t.Fatalf("%s: failed to write binding: %v", tt.name, err)
+		}
+		if out, err := exec.Command(python, "-m", "py_compile", file).CombinedOutput(); err != nil {
+			t.Errorf("%s: invalid Python binding: %v\n%s", tt.name, err, out)
+		}
+	}
+}
+
+func TestPythonName(t *testing.T) {
+	tests := map[string]string{
+		"balanceOf":    "balance_of",
+		"_value":       "value",
+		"totalSupply":  "total_supply",
+		"getURIList":   "get_uri_list",
+		"ERC20Name":    "erc20_name",
+		"snake_case":   "snake_case",
+		"MyError3":     "my_error3",
+		"from":         "from_",
+		"DOMAIN_HASH":  "domain_hash",
+		"UPPER":        "upper",
+		"__private__x": "private__x",
+	}
+	for input, want := range tests {
+		if have := pythonName(input); have != want {
+			t.Errorf("pythonName(%q) = %q, want %q", input, have, want)
+		}
+	}
+}
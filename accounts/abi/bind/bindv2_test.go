@@ -0,0 +1,387 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// bindV2Tests reuse the contracts of bindTests with testers for the v2 bindings.
+var bindV2Tests = []struct {
+	name    string
+	imports string
+	tester  string
+}{
+	// Test that calls, return values and events are packed and unpacked without
+	// a live contract.
+	{
+		`Token`,
+		`
+			"bytes"
+			"math/big"
+
+			"github.com/ethereum/go-ethereum/common"
+			"github.com/ethereum/go-ethereum/core/types"
+		`,
+		`
+			token := NewToken()
+
+			input, err := token.PackTransfer(common.Address{1}, big.NewInt(5))
+			if err != nil {
+				t.Fatal("failed to pack transfer:", err)
+			}
+			if !bytes.Equal(input[:4], common.FromHex("0xa9059cbb")) || len(input) != 68 {
+				t.Fatalf("wrong transfer calldata %x", input)
+			}
+			if _, err := token.PackTransfer(common.Address{1}, big.NewInt(-1)); err == nil {
+				t.Fatal("packed negative transfer value")
+			}
+			if _, err := token.PackTransfer(common.Address{1}, nil); err == nil {
+				t.Fatal("packed nil transfer value")
+			}
+			balance, err := token.UnpackBalanceOf(common.LeftPadBytes([]byte{42}, 32))
+			if err != nil || balance.Uint64() != 42 {
+				t.Fatalf("wrong balance %v, err %v", balance, err)
+			}
+			log := &types.Log{
+				Topics: []common.Hash{TokenTransferEventID, common.BytesToHash([]byte{1}), common.BytesToHash([]byte{2})},
+				Data:   common.LeftPadBytes([]byte{7}, 32),
+			}
+			ev, err := token.UnpackTransferEvent(log)
+			if err != nil {
+				t.Fatal("failed to unpack event:", err)
+			}
+			if ev.From != (common.Address{19: 1}) || ev.To != (common.Address{19: 2}) || ev.Value.Uint64() != 7 || ev.Raw != log {
+				t.Fatalf("wrong event %+v", ev)
+			}
+			log.Topics[0] = common.Hash{1}
+			if _, err := token.UnpackTransferEvent(log); err == nil {
+				t.Fatal("unpacked event with wrong signature")
+			}
+		`,
+	},
+	// Test that custom errors of reverted calls are decoded into typed errors.
+	{
+		`NewErrors`,
+		`
+			"math/big"
+
+			bindv1 "github.com/ethereum/go-ethereum/accounts/abi/bind"
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+			"github.com/ethereum/go-ethereum/common"
+			"github.com/ethereum/go-ethereum/core"
+			"github.com/ethereum/go-ethereum/crypto"
+			"github.com/ethereum/go-ethereum/eth/ethconfig"
+		`,
+		`
+			var (
+				key, _  = crypto.GenerateKey()
+				user, _ = bindv1.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+				sim     = backends.NewSimulatedBackend(core.GenesisAlloc{user.From: {Balance: big.NewInt(1000000000000000000)}}, ethconfig.Defaults.Miner.GasCeil)
+			)
+			defer sim.Close()
+
+			contract := NewNewErrors()
+			params, err := contract.PackConstructor()
+			if err != nil {
+				t.Fatal(err)
+			}
+			addr, tx, err := bind.DeployContract(user, common.FromHex(NewErrorsMetaData.Bin), sim, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sim.Commit()
+			if deployed, err := bindv1.WaitDeployed(nil, sim, tx); err != nil || deployed != addr {
+				t.Fatalf("deployment failed: %v, address %v != %v", err, deployed, addr)
+			}
+			input, err := contract.PackError()
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = contract.Instance(sim, addr).CallRaw(nil, input)
+			if err == nil {
+				t.Fatal("expected contract to throw error")
+			}
+			data, ok := bind.RevertData(err)
+			if !ok {
+				t.Fatalf("no revert data in error %v", err)
+			}
+			unpacked, err := contract.UnpackError(data)
+			if err != nil {
+				t.Fatal("failed to unpack error:", err)
+			}
+			myErr, ok := unpacked.(*NewErrorsMyError3)
+			if !ok {
+				t.Fatalf("wrong error type %T", unpacked)
+			}
+			if myErr.A.Uint64() != 1 || myErr.B.Uint64() != 2 || myErr.C.Uint64() != 3 {
+				t.Fatalf("wrong error values %v", myErr)
+			}
+			if _, err := contract.UnpackMyError2Error(data); err == nil {
+				t.Fatal("unpacked error with wrong selector")
+			}
+		`,
+	},
+	// Test transactions and event filtering through the generic helpers.
+	{
+		`Eventer`,
+		`
+			"math/big"
+
+			bindv1 "github.com/ethereum/go-ethereum/accounts/abi/bind"
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+			"github.com/ethereum/go-ethereum/common"
+			"github.com/ethereum/go-ethereum/core"
+			"github.com/ethereum/go-ethereum/crypto"
+		`,
+		`
+			key, _ := crypto.GenerateKey()
+			auth, _ := bindv1.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+
+			sim := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(10000000000000000)}}, 10000000)
+			defer sim.Close()
+
+			eventer := NewEventer()
+			params, err := eventer.PackConstructor()
+			if err != nil {
+				t.Fatal(err)
+			}
+			addr, _, err := bind.DeployContract(auth, common.FromHex(EventerMetaData.Bin), sim, params)
+			if err != nil {
+				t.Fatalf("Failed to deploy eventer contract: %v", err)
+			}
+			sim.Commit()
+
+			instance := eventer.Instance(sim, addr)
+			for i := 1; i <= 3; i++ {
+				input, err := eventer.PackRaiseSimpleEvent(common.Address{byte(i)}, [32]byte{byte(i)}, true, big.NewInt(int64(10*i)))
+				if err != nil {
+					t.Fatalf("event %d: failed to pack: %v", i, err)
+				}
+				if _, err := bind.Transact(instance, auth, input); err != nil {
+					t.Fatalf("event %d: raise failed: %v", i, err)
+				}
+			}
+			sim.Commit()
+
+			it, err := bind.FilterEvents(instance, nil, EventerSimpleEventEventID, eventer.UnpackSimpleEventEvent, []interface{}{common.Address{1}, common.Address{3}})
+			if err != nil {
+				t.Fatalf("failed to filter for simple events: %v", err)
+			}
+			defer it.Close()
+
+			var values []uint64
+			for it.Next() {
+				if !it.Value().Flag || it.Value().Raw == nil {
+					t.Fatalf("wrong event %+v", it.Value())
+				}
+				values = append(values, it.Value().Value.Uint64())
+			}
+			if err := it.Error(); err != nil {
+				t.Fatal(err)
+			}
+			if len(values) != 2 || values[0] != 10 || values[1] != 30 {
+				t.Fatalf("wrong filtered events %v", values)
+			}
+		`,
+	},
+	// Test that nested structs, arrays and slices are encoded exactly like the
+	// reflection based packer does, and decoded back from calls and events.
+	{
+		`Tuple`,
+		`
+			"bytes"
+			"math/big"
+			"reflect"
+
+			bindv1 "github.com/ethereum/go-ethereum/accounts/abi/bind"
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+			"github.com/ethereum/go-ethereum/common"
+			"github.com/ethereum/go-ethereum/core"
+			"github.com/ethereum/go-ethereum/crypto"
+		`,
+		`
+			key, _ := crypto.GenerateKey()
+			auth, _ := bindv1.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+
+			sim := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(10000000000000000)}}, 10000000)
+			defer sim.Close()
+
+			var (
+				a = TupleS{
+					A: big.NewInt(1),
+					B: []*big.Int{big.NewInt(2), big.NewInt(3)},
+					C: []TupleT{{X: big.NewInt(4), Y: big.NewInt(5)}, {X: big.NewInt(6), Y: big.NewInt(7)}},
+				}
+				b = [][2]TupleT{{{X: big.NewInt(8), Y: big.NewInt(9)}, {X: big.NewInt(10), Y: big.NewInt(11)}}}
+				c = [2][]TupleT{{{X: big.NewInt(12), Y: big.NewInt(13)}, {X: big.NewInt(14), Y: big.NewInt(15)}}, {{X: big.NewInt(16), Y: big.NewInt(17)}}}
+				d = []TupleS{a}
+				e = []*big.Int{big.NewInt(18), big.NewInt(19)}
+			)
+			tuple := NewTuple()
+			input, err := tuple.PackFunc1(a, b, c, d, e)
+			if err != nil {
+				t.Fatal("failed to pack call:", err)
+			}
+			parsed, _ := TupleMetaData.GetAbi()
+			if want, _ := parsed.Pack("func1", a, b, c, d, e); !bytes.Equal(input, want) {
+				t.Fatalf("calldata mismatch:\nhave %x\nwant %x", input, want)
+			}
+			params, err := tuple.PackConstructor()
+			if err != nil {
+				t.Fatal(err)
+			}
+			addr, _, err := bind.DeployContract(auth, common.FromHex(TupleMetaData.Bin), sim, params)
+			if err != nil {
+				t.Fatalf("deploy contract failed %v", err)
+			}
+			sim.Commit()
+
+			instance := tuple.Instance(sim, addr)
+			output, err := instance.CallRaw(nil, input)
+			if err != nil {
+				t.Fatalf("invoke contract failed, err %v", err)
+			}
+			ret1, ret2, ret3, ret4, ret5, err := tuple.UnpackFunc1(output)
+			if err != nil {
+				t.Fatal("failed to unpack return values:", err)
+			}
+			if !reflect.DeepEqual(ret1, a) || !reflect.DeepEqual(ret2, b) || !reflect.DeepEqual(ret3, c) || !reflect.DeepEqual(ret4, d) || !reflect.DeepEqual(ret5, e) {
+				t.Fatalf("return values mismatch: %v %v %v %v %v", ret1, ret2, ret3, ret4, ret5)
+			}
+			if _, _, _, _, _, err := tuple.UnpackFunc1(output[:len(output)-32]); err == nil {
+				t.Fatal("unpacked truncated return values")
+			}
+			input, err = tuple.PackFunc2(a, b, c, d, e)
+			if err != nil {
+				t.Fatal("failed to pack transaction:", err)
+			}
+			if _, err := bind.Transact(instance, auth, input); err != nil {
+				t.Fatalf("invoke contract failed, err %v", err)
+			}
+			sim.Commit()
+
+			it, err := bind.FilterEvents(instance, nil, TupleTupleEventEventID, tuple.UnpackTupleEventEvent)
+			if err != nil {
+				t.Fatalf("failed to create event filter, err %v", err)
+			}
+			defer it.Close()
+
+			if !it.Next() {
+				t.Fatalf("no event found: %v", it.Error())
+			}
+			ev := it.Value()
+			if !reflect.DeepEqual(ev.A, a) || !reflect.DeepEqual(ev.B, b) || !reflect.DeepEqual(ev.C, c) || !reflect.DeepEqual(ev.D, d) || !reflect.DeepEqual(ev.E, e) {
+				t.Fatalf("event mismatch: %+v", ev)
+			}
+		`,
+	},
+}
+
+func TestGolangBindingsV2(t *testing.T) {
+	// Skip the test if no Go command can be found
+	gocmd := runtime.GOROOT() + "/bin/go"
+	if !common.FileExist(gocmd) {
+		t.Skip("go sdk not found for testing")
+	}
+	// Create a temporary workspace for the test suite
+	pkg := filepath.Join(t.TempDir(), "bindtest")
+	if err := os.MkdirAll(pkg, 0700); err != nil {
+		t.Fatalf("failed to create package: %v", err)
+	}
+	// Generate the bindings of all the contracts, so the encoders and decoders
+	// of every type covered by the classic bindings are compiled too
+	for i, tt := range bindTests {
+		types := tt.types
+		if types == nil {
+			types = []string{tt.name}
+		}
+		bind, err := BindV2(types, tt.abi, tt.bytecode, tt.fsigs, "bindtest", tt.libs, tt.aliases)
+		if err != nil {
+			t.Fatalf("test %d: failed to generate binding: %v", i, err)
+		}
+		if err = os.WriteFile(filepath.Join(pkg, strings.ToLower(tt.name)+".go"), []byte(bind), 0600); err != nil {
+			t.Fatalf("test %d: failed to write binding: %v", i, err)
+		}
+	}
+	// Generate the test suite for the contracts with v2 testers
+	for _, tt := range bindV2Tests {
+		// Generate the test file with the injected test code
+		code := fmt.Sprintf(`
+			package bindtest
+
+			import (
+				"testing"
+				%s
+			)
+
+			func Test%s(t *testing.T) {
+				%s
+			}
+		`, tt.imports, tt.name, tt.tester)
+		if err := os.WriteFile(filepath.Join(pkg, strings.ToLower(tt.name)+"_test.go"), []byte(code), 0600); err != nil {
+			t.Fatalf("failed to write tests: %v", err)
+		}
+	}
+	// Convert the package to go modules and use the current source for go-ethereum
+	moder := exec.Command(gocmd, "mod", "init", "bindtest")
+	moder.Dir = pkg
+	if out, err := moder.CombinedOutput(); err != nil {
+		t.Fatalf("failed to convert binding test to modules: %v\n%s", err, out)
+	}
+	pwd, _ := os.Getwd()
+	replacer := exec.Command(gocmd, "mod", "edit", "-x", "-require", "github.com/ethereum/go-ethereum@v0.0.0", "-replace", "github.com/ethereum/go-ethereum="+filepath.Join(pwd, "..", "..", "..")) // Repo root
+	replacer.Dir = pkg
+	if out, err := replacer.CombinedOutput(); err != nil {
+		t.Fatalf("failed to replace binding test dependency to current source tree: %v\n%s", err, out)
+	}
+	tidier := exec.Command(gocmd, "mod", "tidy")
+	tidier.Dir = pkg
+	if out, err := tidier.CombinedOutput(); err != nil {
+		t.Fatalf("failed to tidy Go module file: %v\n%s", err, out)
+	}
+	// Test the entire package and report any failures
+	cmd := exec.Command(gocmd, "test", "-v", "-count", "1")
+	cmd.Dir = pkg
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to run binding test: %v\n%s", err, out)
+	}
+}
+
+// Tests that custom errors colliding with events are only rejected by the v2
+// bindings binding them, leaving the classic bindings unaffected.
+func TestBindV2IdentifierCollision(t *testing.T) {
+	abis := []string{`[{"type":"event","name":"Failed","inputs":[]},{"type":"error","name":"Failed","inputs":[]}]`}
+
+	if _, err := Bind([]string{"Collider"}, abis, []string{""}, nil, "bindtest", LangGo, nil, nil); err != nil {
+		t.Fatalf("classic binding failed: %v", err)
+	}
+	if _, err := BindV2([]string{"Collider"}, abis, []string{""}, nil, "bindtest", nil, nil); err == nil {
+		t.Fatal("v2 binding with colliding identifiers succeeded")
+	}
+}
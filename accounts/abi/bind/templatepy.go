@@ -0,0 +1,210 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+// tmplSourcePython is the Python source template that the generated Python
+// contract binding is based on. The bindings wrap a web3.py v6 contract.
+const tmplSourcePython = `# Code generated - DO NOT EDIT.
+# This file is a generated binding and any manual changes will be lost.
+
+from __future__ import annotations
+
+import json
+import typing
+from typing import Any, Dict, List, NamedTuple, Optional, Tuple
+
+from hexbytes import HexBytes
+from web3 import Web3
+from web3.types import BlockIdentifier, EventData, TxParams
+
+
+def _convert(value: Any, hint: Any) -> Any:
+    """Converts a value decoded by web3 into the given binding type."""
+    if isinstance(hint, type) and issubclass(hint, tuple) and hasattr(hint, "_fields"):
+        hints = typing.get_type_hints(hint)
+        return hint(*(_convert(v, hints[f]) for v, f in zip(value, hint._fields)))
+    origin = typing.get_origin(hint)
+    if origin is list:
+        return [_convert(v, typing.get_args(hint)[0]) for v in value]
+    if origin is tuple:
+        return tuple(_convert(v, h) for v, h in zip(value, typing.get_args(hint)))
+    if hint is bytes:
+        return bytes(value)
+    return value
+
+
+def link_bytecode(bytecode: str, libraries: Dict[str, str]) -> str:
+    """Replaces the library placeholders in the bytecode of a contract with the
+    addresses of the deployed libraries, keyed by link pattern."""
+    for pattern, address in libraries.items():
+        bytecode = bytecode.replace("__$" + pattern + "$__", address.lower().removeprefix("0x"))
+    if "__$" in bytecode:
+        raise ValueError("unlinked library placeholder in bytecode")
+    return bytecode
+{{- $structs := .Structs}}
+{{- range $structs}}
+
+
+class {{.Name}}(NamedTuple):
+    """{{.Name}} is an auto generated binding around an user-defined struct."""
+{{range $field := .Fields}}
+    {{$field.Name}}: {{$field.Type}}
+{{- end}}
+{{- end}}
+{{- range $contract := .Contracts}}
+
+
+{{.Type}}ABI: List[Dict[str, Any]] = json.loads("{{.InputABI}}")
+"""The input ABI used to generate the {{.Type}} binding from."""
+{{- if .InputBin}}
+
+{{.Type}}Bytecode = "0x{{.InputBin}}"
+"""The bytecode used for deploying new {{.Type}} contracts."""
+{{- end}}
+{{- if .Libraries}}
+
+{{.Type}}Libraries: Dict[str, str] = {
+{{- range $pattern, $name := .Libraries}}
+    "{{$pattern}}": "{{$name}}",
+{{- end}}
+}
+"""Maps the link placeholders in the bytecode of {{.Type}} to the names of the
+libraries which have to be deployed and linked first."""
+{{- end}}
+{{- range $method := .Calls}}
+{{- if $method.Structured}}
+
+
+class {{$contract.Type}}{{capitalise .Normalized.Name}}Output(NamedTuple):
+    """The return values of the {{.Original.Name}} method."""
+{{range .Normalized.Outputs}}
+    {{pyname .Name}}: {{bindtype .Type $structs}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- range .Events}}
+
+
+class {{$contract.Type}}{{capitalise .Normalized.Name}}(NamedTuple):
+    """Represents a {{.Original.Name}} event raised by the {{$contract.Type}} contract."""
+{{range .Normalized.Inputs}}
+    {{pyname .Name}}: {{if .Indexed}}{{bindtopictype .Type $structs}}{{else}}{{bindtype .Type $structs}}{{end}}
+{{- end}}
+    raw: EventData
+{{- end}}
+{{- range .Errors}}
+
+
+class {{$contract.Type}}{{capitalise .Normalized.Name}}(NamedTuple):
+    """Represents a {{.Original.Name}} error raised by the {{$contract.Type}} contract."""
+{{range .Normalized.Inputs}}
+    {{pyname .Name}}: {{bindtype .Type $structs}}
+{{- end}}
+{{- end}}
+
+
+class {{.Type}}:
+    """{{.Type}} is an auto generated binding around an Ethereum contract."""
+
+    def __init__(self, w3: Web3, address: str) -> None:
+        """Creates a new binding of a deployed {{.Type}} contract."""
+        self.contract = w3.eth.contract(address=address, abi={{.Type}}ABI)
+{{- if .InputBin}}
+
+    @classmethod
+    def deploy(cls, w3: Web3{{if .Libraries}}, libraries: Dict[str, str]{{end}}{{range .Constructor.Inputs}}, {{.Name}}: {{bindtype .Type $structs}}{{end}}, tx: Optional[TxParams] = None) -> Tuple[{{.Type}}, HexBytes]:
+        """Deploys a new {{.Type}} contract and waits for it to be mined,
+        returning a binding to it and the creation transaction hash.
+{{- if .Constructor.Sig}}
+
+        Solidity: {{.Constructor.String}}
+{{- end}}
+        """
+        factory = w3.eth.contract(abi={{.Type}}ABI, bytecode={{if .Libraries}}link_bytecode({{.Type}}Bytecode, libraries){{else}}{{.Type}}Bytecode{{end}})
+        tx_hash = factory.constructor({{range $i, $_ := .Constructor.Inputs}}{{if $i}}, {{end}}{{.Name}}{{end}}).transact(tx or {})
+        receipt = w3.eth.wait_for_transaction_receipt(tx_hash)
+        return cls(w3, receipt["contractAddress"]), tx_hash
+{{- end}}
+{{- range $method := .Calls}}
+
+    def {{.Normalized.Name}}(self, {{range .Normalized.Inputs}}{{.Name}}: {{bindtype .Type $structs}}, {{end}}block_identifier: BlockIdentifier = "latest") -> {{template "pyOutputs" (dict "Contract" $contract "Method" $method "Structs" $structs)}}:
+        """Calls the {{.Original.Name}} method 0x{{printf "%x" .Original.ID}}.
+
+        Solidity: {{.Original.String}}
+        """
+        {{if .Normalized.Outputs}}result = {{end}}self.contract.get_function_by_signature("{{.Original.Sig}}")({{range $i, $_ := .Normalized.Inputs}}{{if $i}}, {{end}}{{.Name}}{{end}}).call(block_identifier=block_identifier)
+        {{- if .Normalized.Outputs}}
+        return _convert(result, {{template "pyOutputs" (dict "Contract" $contract "Method" $method "Structs" $structs)}})
+        {{- end}}
+{{- end}}
+{{- range .Transacts}}
+
+    def {{.Normalized.Name}}(self, {{range .Normalized.Inputs}}{{.Name}}: {{bindtype .Type $structs}}, {{end}}tx: Optional[TxParams] = None) -> HexBytes:
+        """Sends a transaction invoking the {{.Original.Name}} method 0x{{printf "%x" .Original.ID}},
+        returning the transaction hash.
+
+        Solidity: {{.Original.String}}
+        """
+        return self.contract.get_function_by_signature("{{.Original.Sig}}")({{range $i, $_ := .Normalized.Inputs}}{{if $i}}, {{end}}{{.Name}}{{end}}).transact(tx or {})
+{{- end}}
+{{- range $event := .Events}}
+
+    @staticmethod
+    def parse_{{.Normalized.Name}}_event(log: EventData) -> {{$contract.Type}}{{capitalise .Normalized.Name}}:
+        """Decodes a {{.Original.Name}} event log.
+
+        Solidity: {{.Original.String}}
+        """
+        args = log["args"]
+        return {{$contract.Type}}{{capitalise .Normalized.Name}}(
+{{- range $i, $in := .Normalized.Inputs}}
+            _convert(args["{{(index $event.Original.Inputs $i).Name}}"], {{if .Indexed}}{{bindtopictype .Type $structs}}{{else}}{{bindtype .Type $structs}}{{end}}),
+{{- end}}
+            log,
+        )
+
+    def get_{{.Normalized.Name}}_events(self, from_block: BlockIdentifier = 0, to_block: BlockIdentifier = "latest") -> List[{{$contract.Type}}{{capitalise .Normalized.Name}}]:
+        """Retrieves the {{.Original.Name}} events raised in the given block range.
+
+        Solidity: {{.Original.String}}
+        """
+        logs = self.contract.events["{{.Original.Name}}"]().get_logs(fromBlock=from_block, toBlock=to_block)
+        return [self.parse_{{.Normalized.Name}}_event(log) for log in logs]
+{{- end}}
+{{- if .Errors}}
+
+    def decode_error(self, data: bytes) -> Optional[Any]:
+        """Decodes revert data into one of the custom errors of the {{.Type}}
+        contract, or returns None if the data matches none of them."""
+        selector, payload = bytes(data[:4]), bytes(data[4:])
+{{- range .Errors}}
+        if selector == bytes.fromhex("{{slice .Original.ID.Hex 2 10}}"):
+            values = self.contract.w3.codec.decode([{{range $i, $in := .Original.Inputs}}{{if $i}}, {{end}}"{{$in.Type.String}}"{{end}}], payload)
+            return _convert(values, {{$contract.Type}}{{capitalise .Normalized.Name}})
+{{- end}}
+        return None
+{{- end}}
+{{- end}}
+{{define "pyOutputs"}}
+{{- $contract := .Contract}}{{$method := .Method}}{{$structs := .Structs}}
+{{- if not $method.Normalized.Outputs}}None
+{{- else if $method.Structured}}{{$contract.Type}}{{capitalise $method.Normalized.Name}}Output
+{{- else if eq (len $method.Normalized.Outputs) 1}}{{bindtype (index $method.Normalized.Outputs 0).Type $structs}}
+{{- else}}Tuple[{{range $i, $out := $method.Normalized.Outputs}}{{if $i}}, {{end}}{{bindtype .Type $structs}}{{end}}]
+{{- end}}
+{{- end}}
+`
@@ -0,0 +1,240 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+// tmplSourceTypeScript is the TypeScript source template that the generated
+// TypeScript contract binding is based on. The bindings wrap an ethers v6
+// contract.
+const tmplSourceTypeScript = `// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+import {
+  BlockTag,
+  BytesLike,
+  Contract,
+  ContractEventPayload,
+  ContractFactory,
+  ContractRunner,
+  ContractTransactionResponse,
+  EventLog,
+  Indexed,
+  Interface,
+  Overrides,
+} from "ethers";
+
+/** FixedArray is an array of exactly N elements, the binding of a Solidity T[N]. */
+export type FixedArray<T, N extends number, R extends T[] = []> = R["length"] extends N ? R : FixedArray<T, N, [...R, T]>;
+
+/**
+ * linkBytecode replaces the library placeholders in the bytecode of a contract
+ * with the addresses of the deployed libraries, keyed by link pattern.
+ */
+export function linkBytecode(bytecode: string, libraries: Record<string, string>): string {
+  for (const [pattern, address] of Object.entries(libraries)) {
+    bytecode = bytecode.split("__$" + pattern + "$__").join(address.toLowerCase().replace(/^0x/, ""));
+  }
+  if (bytecode.includes("__$")) {
+    throw new Error("unlinked library placeholder in bytecode");
+  }
+  return bytecode;
+}
+{{- $structs := .Structs}}
+{{- range $structs}}
+
+/** {{.Name}} is an auto generated binding around an user-defined struct. */
+export interface {{.Name}} {
+{{- range $field := .Fields}}
+  {{$field.Name}}: {{$field.Type}};
+{{- end}}
+}
+{{- end}}
+{{- range $contract := .Contracts}}
+
+/** {{.Type}}ABI is the input ABI used to generate the binding from. */
+export const {{.Type}}ABI = JSON.parse("{{.InputABI}}");
+
+/** {{.Type}}Interface is the parsed ABI of the {{.Type}} contract. */
+export const {{.Type}}Interface = new Interface({{.Type}}ABI);
+{{- if .InputBin}}
+
+/** {{.Type}}Bytecode is the bytecode used for deploying new contracts. */
+export const {{.Type}}Bytecode = "0x{{.InputBin}}";
+{{- end}}
+{{- if .Libraries}}
+
+/**
+ * {{.Type}}Libraries maps the link placeholders in the bytecode of {{.Type}} to
+ * the names of the libraries which have to be deployed and linked first.
+ */
+export const {{.Type}}Libraries: Record<string, string> = {
+{{- range $pattern, $name := .Libraries}}
+  "{{$pattern}}": "{{$name}}",
+{{- end}}
+};
+{{- end}}
+{{- range .Events}}
+
+/** {{$contract.Type}}{{capitalise .Normalized.Name}} represents a {{.Original.Name}} event raised by the {{$contract.Type}} contract. */
+export interface {{$contract.Type}}{{capitalise .Normalized.Name}} {
+{{- range .Normalized.Inputs}}
+  {{.Name}}: {{if .Indexed}}{{bindtopictype .Type $structs}}{{else}}{{bindtype .Type $structs}}{{end}};
+{{- end}}
+  raw: EventLog;
+}
+{{- end}}
+{{- range .Errors}}
+
+/** {{$contract.Type}}{{capitalise .Normalized.Name}} represents a {{.Original.Name}} error raised by the {{$contract.Type}} contract. */
+export interface {{$contract.Type}}{{capitalise .Normalized.Name}} {
+  name: "{{.Original.Name}}";
+  args: {
+{{- range .Normalized.Inputs}}
+    {{.Name}}: {{bindtype .Type $structs}};
+{{- end}}
+  };
+}
+{{- end}}
+{{- if .Errors}}
+
+/** {{.Type}}Error is any of the custom errors of the {{.Type}} contract. */
+export type {{.Type}}Error ={{range .Errors}}
+  | {{$contract.Type}}{{capitalise .Normalized.Name}}{{end}};
+{{- end}}
+
+/** {{.Type}} is an auto generated binding around an Ethereum contract. */
+export class {{.Type}} {
+  /** The ethers contract the binding is backed by. */
+  readonly contract: Contract;
+
+  /** Creates a new binding of a deployed {{.Type}} contract. */
+  constructor(address: string, runner?: ContractRunner | null) {
+    this.contract = new Contract(address, {{.Type}}Interface, runner);
+  }
+{{- if .InputBin}}
+
+  /**
+   * Deploys a new {{.Type}} contract, returning a binding to it and the creation
+   * transaction.
+{{- if .Constructor.Sig}}
+   *
+   * Solidity: {{.Constructor.String}}
+{{- end}}
+   */
+  static async deploy(runner: ContractRunner{{if .Libraries}}, libraries: Record<string, string>{{end}}{{range .Constructor.Inputs}}, {{.Name}}: {{bindtype .Type $structs}}{{end}}, overrides: Overrides = {}): Promise<[{{.Type}}, ContractTransactionResponse]> {
+    const factory = new ContractFactory({{.Type}}Interface, {{if .Libraries}}linkBytecode({{.Type}}Bytecode, libraries){{else}}{{.Type}}Bytecode{{end}}, runner);
+    const contract = await factory.deploy({{range .Constructor.Inputs}}{{.Name}}, {{end}}overrides);
+    return [new {{.Type}}(await contract.getAddress(), runner), contract.deploymentTransaction()!];
+  }
+{{- end}}
+{{- range $method := .Calls}}
+
+  /**
+   * Calls the {{.Original.Name}} method 0x{{printf "%x" .Original.ID}}.
+   *
+   * Solidity: {{.Original.String}}
+   */
+  async {{.Normalized.Name}}({{range .Normalized.Inputs}}{{.Name}}: {{bindtype .Type $structs}}, {{end}}overrides: Overrides = {}): Promise<
+    {{- if not .Normalized.Outputs}}void
+    {{- else if eq (len .Normalized.Outputs) 1}}{{bindtype (index .Normalized.Outputs 0).Type $structs}}
+    {{- else}}[{{range $i, $out := .Normalized.Outputs}}{{if $i}}, {{end}}{{if $method.Structured}}{{.Name}}: {{end}}{{bindtype .Type $structs}}{{end}}]{{end}}> {
+    return await this.contract.getFunction("{{.Original.Sig}}").staticCall({{range .Normalized.Inputs}}{{.Name}}, {{end}}overrides);
+  }
+{{- end}}
+{{- range .Transacts}}
+
+  /**
+   * Sends a transaction invoking the {{.Original.Name}} method 0x{{printf "%x" .Original.ID}}.
+   *
+   * Solidity: {{.Original.String}}
+   */
+  async {{.Normalized.Name}}({{range .Normalized.Inputs}}{{.Name}}: {{bindtype .Type $structs}}, {{end}}overrides: Overrides = {}): Promise<ContractTransactionResponse> {
+    return await this.contract.getFunction("{{.Original.Sig}}").send({{range .Normalized.Inputs}}{{.Name}}, {{end}}overrides);
+  }
+{{- end}}
+{{- range .Events}}
+
+  /**
+   * Decodes a {{.Original.Name}} event log.
+   *
+   * Solidity: {{.Original.String}}
+   */
+  static parse{{capitalise .Normalized.Name}}Event(log: EventLog): {{$contract.Type}}{{capitalise .Normalized.Name}} {
+    return {
+{{- range $i, $_ := .Normalized.Inputs}}
+      {{.Name}}: log.args[{{$i}}],
+{{- end}}
+      raw: log,
+    };
+  }
+
+  /**
+   * Retrieves the {{.Original.Name}} events raised in the given block range.
+   *
+   * Solidity: {{.Original.String}}
+   */
+  async query{{capitalise .Normalized.Name}}Events(fromBlock?: BlockTag, toBlock?: BlockTag): Promise<{{$contract.Type}}{{capitalise .Normalized.Name}}[]> {
+    const logs = await this.contract.queryFilter("{{.Original.Sig}}", fromBlock, toBlock);
+    return logs.map((log) => {{$contract.Type}}.parse{{capitalise .Normalized.Name}}Event(log as EventLog));
+  }
+
+  /**
+   * Subscribes to future {{.Original.Name}} events, returning a function which
+   * cancels the subscription.
+   *
+   * Solidity: {{.Original.String}}
+   */
+  async watch{{capitalise .Normalized.Name}}Events(listener: (event: {{$contract.Type}}{{capitalise .Normalized.Name}}) => void): Promise<() => Promise<void>> {
+    const handler = (...args: unknown[]) => {
+      const payload = args[args.length - 1] as ContractEventPayload;
+      listener({{$contract.Type}}.parse{{capitalise .Normalized.Name}}Event(payload.log as EventLog));
+    };
+    await this.contract.on("{{.Original.Sig}}", handler);
+    return async () => {
+      await this.contract.off("{{.Original.Sig}}", handler);
+    };
+  }
+{{- end}}
+{{- if .Errors}}
+
+  /**
+   * Decodes revert data into one of the custom errors of the {{.Type}} contract,
+   * or returns null if the data matches none of them.
+   */
+  static decodeError(data: BytesLike): {{.Type}}Error | null {
+    const parsed = {{.Type}}Interface.parseError(data);
+    if (parsed === null) {
+      return null;
+    }
+    switch (parsed.signature) {
+{{- range .Errors}}
+      case "{{.Original.Sig}}":
+        return {
+          name: "{{.Original.Name}}",
+          args: {
+{{- range $i, $_ := .Normalized.Inputs}}
+            {{.Name}}: parsed.args[{{$i}}],
+{{- end}}
+          },
+        };
+{{- end}}
+    }
+    return null;
+  }
+{{- end}}
+}
+{{- end}}
+`
@@ -0,0 +1,245 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+// tmplSourceGoV2 is the Go source template of the lightweight contract bindings
+// generated by BindV2.
+const tmplSourceGoV2 = `
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package {{.Package}}
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	bind "github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = fmt.Sprintf
+	_ = big.NewInt
+	_ = bind.NewBoundContract
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.JSON
+)
+
+{{$structs := .Structs}}
+{{range $structs}}
+	// {{.Name}} is an auto generated low-level Go binding around an user-defined struct.
+	type {{.Name}} struct {
+	{{range $field := .Fields}}
+	{{$field.Name}} {{$field.Type}}{{end}}
+	}
+{{end}}
+
+{{range $contract := .Contracts}}
+	// {{.Type}}MetaData contains all meta data concerning the {{.Type}} contract.
+	var {{.Type}}MetaData = &bind.MetaData{
+		ABI: "{{.InputABI}}",
+		{{if $contract.FuncSigs -}}
+		Sigs: map[string]string{
+			{{range $strsig, $binsig := .FuncSigs}}"{{$binsig}}": "{{$strsig}}",
+			{{end}}
+		},
+		{{end -}}
+		{{if .InputBin -}}
+		Bin: "0x{{.InputBin}}",
+		{{end}}
+	}
+
+	{{if .Libraries}}
+		// {{.Type}}Libraries maps the link placeholders in the bytecode of {{.Type}} to
+		// the names of the libraries which have to be deployed and linked first.
+		var {{.Type}}Libraries = map[string]string{
+			{{range $pattern, $name := .Libraries}}"{{$pattern}}": "{{$name}}",
+			{{end}}
+		}
+	{{end}}
+
+	// {{.Type}} is an auto generated Go binding around an Ethereum contract. It
+	// packs and unpacks the calldata, return values, events and errors of the
+	// contract, and is independent of any backend.
+	type {{.Type}} struct {
+		abi abi.ABI
+	}
+
+	// New{{.Type}} creates a new binding of the {{.Type}} contract.
+	func New{{.Type}}() *{{.Type}} {
+		parsed, err := {{.Type}}MetaData.GetAbi()
+		if err != nil {
+			panic(errors.New("invalid ABI: " + err.Error()))
+		}
+		return &{{.Type}}{abi: *parsed}
+	}
+
+	// Instance binds the contract to a deployed instance at the given address,
+	// to be used with the call, transact and event helpers of the bind package.
+	func (_{{$contract.Type}} *{{$contract.Type}}) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+		return bind.NewBoundContract(addr, _{{$contract.Type}}.abi, backend)
+	}
+
+	{{if .InputBin}}
+		// PackConstructor packs the constructor arguments of {{.Type}}. The result is
+		// appended to the deployment bytecode.
+		//
+		// Solidity: {{.Constructor.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) PackConstructor({{range $i, $_ := .Constructor.Inputs}}{{if $i}}, {{end}}{{.Name}} {{bindtype .Type $structs}}{{end}}) ([]byte, error) {
+			var _enc bind.Packer
+			return _enc.Pack(nil{{range .Constructor.Inputs}}, {{packv2 .Type .Name $structs}}{{end}})
+		}
+	{{end}}
+
+	{{range $method := .Calls}}{{template "methodV2" (dict "Contract" $contract "Method" $method "Structs" $structs)}}{{end}}
+	{{range $method := .Transacts}}{{template "methodV2" (dict "Contract" $contract "Method" $method "Structs" $structs)}}{{end}}
+
+	{{range .Events}}
+		// {{$contract.Type}}{{.Normalized.Name}} represents a {{.Normalized.Name}} event raised by the {{$contract.Type}} contract.
+		type {{$contract.Type}}{{.Normalized.Name}} struct { {{range .Normalized.Inputs}}
+			{{capitalise .Name}} {{if .Indexed}}{{topictypev2 .Type $structs}}{{else}}{{bindtype .Type $structs}}{{end}}; {{end}}
+			Raw *types.Log // Blockchain specific contextual infos
+		}
+
+		// {{$contract.Type}}{{.Normalized.Name}}EventID is the topic of the {{.Normalized.Name}} event.
+		var {{$contract.Type}}{{.Normalized.Name}}EventID = common.HexToHash("{{.Original.ID.Hex}}")
+
+		// Unpack{{.Normalized.Name}}Event unpacks a {{.Normalized.Name}} event log.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Unpack{{.Normalized.Name}}Event(log *types.Log) (*{{$contract.Type}}{{.Normalized.Name}}, error) {
+			if len(log.Topics) == 0 || log.Topics[0] != {{$contract.Type}}{{.Normalized.Name}}EventID {
+				return nil, errors.New("event signature mismatch")
+			}
+			out := &{{$contract.Type}}{{.Normalized.Name}}{Raw: log}
+
+			_dec := bind.NewDecoder(log.Data)
+			{{range .Normalized.Inputs}}{{if not .Indexed}}out.{{capitalise .Name}} = {{unpackv2 .Type $structs}}
+			{{end}}{{end}}
+			if err := _dec.Err(); err != nil {
+				return nil, err
+			}
+			_dec = bind.NewTopicDecoder(log.Topics[1:])
+			{{range .Normalized.Inputs}}{{if .Indexed}}out.{{capitalise .Name}} = {{unpacktopicv2 .Type $structs}}
+			{{end}}{{end}}
+			if err := _dec.Err(); err != nil {
+				return nil, err
+			}
+			return out, nil
+		}
+	{{end}}
+
+	{{if .Errors}}
+		// UnpackError decodes revert data into one of the custom errors of the
+		// {{.Type}} contract. The returned value is a pointer to the matching error
+		// type.
+		func (_{{$contract.Type}} *{{$contract.Type}}) UnpackError(raw []byte) (any, error) {
+			{{range .Errors}}if bytes.HasPrefix(raw, {{$contract.Type}}{{.Normalized.Name}}ErrorID[:4]) {
+				return _{{$contract.Type}}.Unpack{{.Normalized.Name}}Error(raw)
+			}
+			{{end}}
+			return nil, errors.New("unknown error")
+		}
+	{{end}}
+
+	{{range .Errors}}
+		// {{$contract.Type}}{{.Normalized.Name}} represents a {{.Original.Name}} error raised by the {{$contract.Type}} contract.
+		type {{$contract.Type}}{{.Normalized.Name}} struct { {{range .Normalized.Inputs}}
+			{{capitalise .Name}} {{bindtype .Type $structs}}; {{end}}
+		}
+
+		// Error implements the error interface.
+		func (e *{{$contract.Type}}{{.Normalized.Name}}) Error() string {
+			return fmt.Sprintf("{{.Original.Name}}%+v", *e)
+		}
+
+		// {{$contract.Type}}{{.Normalized.Name}}ErrorID is the ID of the {{.Original.Name}} error, whose
+		// first four bytes are the selector of its revert data.
+		var {{$contract.Type}}{{.Normalized.Name}}ErrorID = common.HexToHash("{{.Original.ID.Hex}}")
+
+		// Unpack{{.Normalized.Name}}Error unpacks the revert data of a {{.Original.Name}} error.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Unpack{{.Normalized.Name}}Error(raw []byte) (*{{$contract.Type}}{{.Normalized.Name}}, error) {
+			if !bytes.HasPrefix(raw, {{$contract.Type}}{{.Normalized.Name}}ErrorID[:4]) {
+				return nil, errors.New("error selector mismatch")
+			}
+			out := new({{$contract.Type}}{{.Normalized.Name}})
+
+			_dec := bind.NewDecoder(raw[4:])
+			{{range .Normalized.Inputs}}out.{{capitalise .Name}} = {{unpackv2 .Type $structs}}
+			{{end}}
+			if err := _dec.Err(); err != nil {
+				return nil, err
+			}
+			return out, nil
+		}
+	{{end}}
+{{end}}
+
+{{define "methodV2"}}
+	{{$contract := .Contract}}{{$method := .Method}}{{$structs := .Structs}}
+	// Pack{{$method.Normalized.Name}} packs the calldata of a call to the {{$method.Original.Name}} method
+	// 0x{{printf "%x" $method.Original.ID}}.
+	//
+	// Solidity: {{$method.Original.String}}
+	func (_{{$contract.Type}} *{{$contract.Type}}) Pack{{$method.Normalized.Name}}({{range $i, $_ := $method.Normalized.Inputs}}{{if $i}}, {{end}}{{.Name}} {{bindtype .Type $structs}}{{end}}) ([]byte, error) {
+		var _enc bind.Packer
+		return _enc.Pack({{printf "%#v" $method.Original.ID}}{{range $method.Normalized.Inputs}}, {{packv2 .Type .Name $structs}}{{end}})
+	}
+	{{if $method.Normalized.Outputs}}
+		{{if $method.Structured}}
+			// {{$contract.Type}}{{$method.Normalized.Name}}Output is the return value of the {{$method.Original.Name}} method.
+			type {{$contract.Type}}{{$method.Normalized.Name}}Output struct { {{range $method.Normalized.Outputs}}
+				{{.Name}} {{bindtype .Type $structs}}; {{end}}
+			}
+		{{end}}
+
+		// Unpack{{$method.Normalized.Name}} unpacks the return data of the {{$method.Original.Name}} method.
+		//
+		// Solidity: {{$method.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Unpack{{$method.Normalized.Name}}(data []byte) ({{if $method.Structured}}*{{$contract.Type}}{{$method.Normalized.Name}}Output,{{else}}{{range $method.Normalized.Outputs}}{{bindtype .Type $structs}},{{end}}{{end}} error) {
+			_dec := bind.NewDecoder(data)
+			{{if $method.Structured}}
+				out := new({{$contract.Type}}{{$method.Normalized.Name}}Output)
+				{{range $method.Normalized.Outputs}}out.{{.Name}} = {{unpackv2 .Type $structs}}
+				{{end}}
+				if err := _dec.Err(); err != nil {
+					return nil, err
+				}
+				return out, nil
+			{{else}}
+				{{range $i, $_ := $method.Normalized.Outputs}}out{{$i}} := {{unpackv2 .Type $structs}}
+				{{end}}
+				if err := _dec.Err(); err != nil {
+					return {{range $method.Normalized.Outputs}}*new({{bindtype .Type $structs}}), {{end}}err
+				}
+				return {{range $i, $_ := $method.Normalized.Outputs}}out{{$i}}, {{end}}nil
+			{{- end}}
+		}
+	{{end}}
+{{end}}
+`
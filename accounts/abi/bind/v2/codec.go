@@ -0,0 +1,392 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// The generated bindings encode and decode contract data through the typed
+// primitives below instead of the reflection based abi.Arguments, the shape of
+// each value being known when the binding is generated.
+
+var (
+	errNilInteger       = errors.New("abi: nil integer")
+	errIntegerRange     = errors.New("abi: integer out of range")
+	errInsufficientData = errors.New("abi: insufficient data")
+	errInvalidOffset    = errors.New("abi: invalid offset")
+	errInvalidLength    = errors.New("abi: invalid length")
+	errInvalidBool      = errors.New("abi: invalid boolean")
+	errDirtyPadding     = errors.New("abi: non-zero padding")
+)
+
+// Encoded is the ABI encoding of a single value.
+type Encoded struct {
+	data    []byte
+	dynamic bool // Whether the value is placed in the tail of its enclosing tuple
+}
+
+// Packer encodes the arguments of a contract call, event or error. Encoding
+// failures are recorded and reported by Pack, so the encoders can be composed
+// into a single expression.
+type Packer struct {
+	err error
+}
+
+// fail records the first encoding failure.
+func (p *Packer) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// Pack encodes the given arguments as a tuple, prefixed with the selector of
+// the call (if any). It returns the first failure of any of the encoders.
+func (p *Packer) Pack(selector []byte, args ...Encoded) ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	tuple := encodeTuple(args)
+	return append(append(make([]byte, 0, len(selector)+len(tuple.data)), selector...), tuple.data...), nil
+}
+
+// Uint encodes an unsigned integer of the given bit size.
+func (p *Packer) Uint(v *big.Int, bits int) Encoded {
+	if v == nil {
+		p.fail(errNilInteger)
+		return Encoded{data: make([]byte, 32)}
+	}
+	if v.Sign() < 0 || v.BitLen() > bits {
+		p.fail(fmt.Errorf("%w: %v does not fit uint%d", errIntegerRange, v, bits))
+	}
+	return Encoded{data: math.U256Bytes(new(big.Int).Set(v))}
+}
+
+// Int encodes a signed integer of the given bit size in two's complement.
+func (p *Packer) Int(v *big.Int, bits int) Encoded {
+	if v == nil {
+		p.fail(errNilInteger)
+		return Encoded{data: make([]byte, 32)}
+	}
+	if !fitsInt(v, bits) {
+		p.fail(fmt.Errorf("%w: %v does not fit int%d", errIntegerRange, v, bits))
+	}
+	return Encoded{data: math.U256Bytes(new(big.Int).Set(v))}
+}
+
+// Uint64 encodes an unsigned integer bound to a native Go type.
+func (p *Packer) Uint64(v uint64) Encoded {
+	return Encoded{data: math.U256Bytes(new(big.Int).SetUint64(v))}
+}
+
+// Int64 encodes a signed integer bound to a native Go type.
+func (p *Packer) Int64(v int64) Encoded {
+	return Encoded{data: math.U256Bytes(big.NewInt(v))}
+}
+
+// Bool encodes a boolean.
+func (p *Packer) Bool(v bool) Encoded {
+	data := make([]byte, 32)
+	if v {
+		data[31] = 1
+	}
+	return Encoded{data: data}
+}
+
+// Address encodes an address.
+func (p *Packer) Address(v common.Address) Encoded {
+	return Encoded{data: common.LeftPadBytes(v[:], 32)}
+}
+
+// FixedBytes encodes a fixed size byte array (bytes1 to bytes32, function).
+func (p *Packer) FixedBytes(v []byte) Encoded {
+	return Encoded{data: common.RightPadBytes(v, 32)}
+}
+
+// Bytes encodes a dynamic byte array.
+func (p *Packer) Bytes(v []byte) Encoded {
+	data := make([]byte, 32+(len(v)+31)/32*32)
+	new(big.Int).SetUint64(uint64(len(v))).FillBytes(data[:32])
+	copy(data[32:], v)
+	return Encoded{data: data, dynamic: true}
+}
+
+// String encodes a string.
+func (p *Packer) String(v string) Encoded {
+	return p.Bytes([]byte(v))
+}
+
+// Tuple encodes a struct from the encodings of its fields.
+func (p *Packer) Tuple(fields ...Encoded) Encoded {
+	return encodeTuple(fields)
+}
+
+// PackArray encodes a fixed size array, using the given function to encode
+// the individual elements.
+func PackArray[T any](elems []T, pack func(T) Encoded) Encoded {
+	encoded := make([]Encoded, len(elems))
+	for i, elem := range elems {
+		encoded[i] = pack(elem)
+	}
+	return encodeTuple(encoded)
+}
+
+// PackSlice encodes a dynamic size array, using the given function to encode
+// the individual elements.
+func PackSlice[T any](elems []T, pack func(T) Encoded) Encoded {
+	tuple := PackArray(elems, pack)
+
+	data := make([]byte, 32+len(tuple.data))
+	new(big.Int).SetUint64(uint64(len(elems))).FillBytes(data[:32])
+	copy(data[32:], tuple.data)
+	return Encoded{data: data, dynamic: true}
+}
+
+// encodeTuple encodes a sequence of values, the static ones in place and the
+// dynamic ones referenced by their offset from the start of the tuple.
+func encodeTuple(elems []Encoded) Encoded {
+	var (
+		size    int
+		dynamic bool
+	)
+	for _, elem := range elems {
+		if elem.dynamic {
+			size += 32
+			dynamic = true
+		} else {
+			size += len(elem.data)
+		}
+	}
+	var head, tail []byte
+	for _, elem := range elems {
+		if !elem.dynamic {
+			head = append(head, elem.data...)
+			continue
+		}
+		head = append(head, common.LeftPadBytes(big.NewInt(int64(size+len(tail))).Bytes(), 32)...)
+		tail = append(tail, elem.data...)
+	}
+	return Encoded{data: append(head, tail...), dynamic: dynamic}
+}
+
+// fitsInt reports whether v is representable as a signed integer of the given
+// bit size.
+func fitsInt(v *big.Int, bits int) bool {
+	if v.Sign() >= 0 {
+		return v.BitLen() < bits
+	}
+	return new(big.Int).Not(v).BitLen() < bits
+}
+
+// Decoder decodes the consecutive values of an ABI encoded tuple. Decoding
+// failures are recorded and reported by Err, returning zero values for the
+// affected fields, so the decoders can be composed into a single expression.
+type Decoder struct {
+	data []byte // Encoding of the tuple, offsets are relative to its start
+	pos  int    // Position of the head of the next value
+	err  *error // First failure, shared with the nested decoders
+}
+
+// NewDecoder creates a decoder over the ABI encoding of a tuple.
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{data: data, err: new(error)}
+}
+
+// NewTopicDecoder creates a decoder over the indexed fields of an event. Each
+// topic holds a single word, which is either the value of a field or the hash
+// of its encoding.
+func NewTopicDecoder(topics []common.Hash) *Decoder {
+	data := make([]byte, 0, 32*len(topics))
+	for _, topic := range topics {
+		data = append(data, topic[:]...)
+	}
+	return NewDecoder(data)
+}
+
+// Err returns the first failure encountered while decoding.
+func (d *Decoder) Err() error {
+	return *d.err
+}
+
+// fail records the first decoding failure.
+func (d *Decoder) fail(err error) {
+	if *d.err == nil {
+		*d.err = err
+	}
+}
+
+// word returns the next 32 byte head slot, or a zero word if the data is
+// exhausted.
+func (d *Decoder) word() []byte {
+	if d.pos+32 > len(d.data) {
+		d.fail(errInsufficientData)
+		return make([]byte, 32)
+	}
+	word := d.data[d.pos : d.pos+32]
+	d.pos += 32
+	return word
+}
+
+// offset reads the next head slot as an offset into the tuple, validating that
+// at least size bytes are available behind it.
+func (d *Decoder) offset(size int) int {
+	word := new(big.Int).SetBytes(d.word())
+	if !word.IsUint64() || word.Uint64() > uint64(len(d.data)) || int(word.Uint64())+size > len(d.data) {
+		d.fail(errInvalidOffset)
+		return len(d.data)
+	}
+	return int(word.Uint64())
+}
+
+// Hash decodes a raw 32 byte word, such as the hash of a dynamic indexed field.
+func (d *Decoder) Hash() common.Hash {
+	return common.BytesToHash(d.word())
+}
+
+// Uint decodes an unsigned integer of the given bit size.
+func (d *Decoder) Uint(bits int) *big.Int {
+	v := new(big.Int).SetBytes(d.word())
+	if v.BitLen() > bits {
+		d.fail(fmt.Errorf("%w: %v does not fit uint%d", errIntegerRange, v, bits))
+		return new(big.Int)
+	}
+	return v
+}
+
+// Int decodes a signed integer of the given bit size.
+func (d *Decoder) Int(bits int) *big.Int {
+	v := math.S256(new(big.Int).SetBytes(d.word()))
+	if !fitsInt(v, bits) {
+		d.fail(fmt.Errorf("%w: %v does not fit int%d", errIntegerRange, v, bits))
+		return new(big.Int)
+	}
+	return v
+}
+
+// Uint64 decodes an unsigned integer bound to a native Go type.
+func (d *Decoder) Uint64(bits int) uint64 {
+	return d.Uint(bits).Uint64()
+}
+
+// Int64 decodes a signed integer bound to a native Go type.
+func (d *Decoder) Int64(bits int) int64 {
+	return d.Int(bits).Int64()
+}
+
+// Bool decodes a boolean.
+func (d *Decoder) Bool() bool {
+	word := d.word()
+	if !isZero(word[:31]) || word[31] > 1 {
+		d.fail(errInvalidBool)
+		return false
+	}
+	return word[31] == 1
+}
+
+// Address decodes an address.
+func (d *Decoder) Address() common.Address {
+	word := d.word()
+	if !isZero(word[:12]) {
+		d.fail(errDirtyPadding)
+		return common.Address{}
+	}
+	return common.BytesToAddress(word[12:])
+}
+
+// FixedBytes decodes a fixed size byte array of the given length, returning a
+// slice of exactly that length.
+func (d *Decoder) FixedBytes(size int) []byte {
+	word := d.word()
+	if !isZero(word[size:]) {
+		d.fail(errDirtyPadding)
+		return make([]byte, size)
+	}
+	return common.CopyBytes(word[:size])
+}
+
+// Bytes decodes a dynamic byte array.
+func (d *Decoder) Bytes() []byte {
+	offset := d.offset(32)
+	if offset == len(d.data) {
+		return []byte{}
+	}
+	length := new(big.Int).SetBytes(d.data[offset : offset+32])
+	if !length.IsUint64() || length.Uint64() > uint64(len(d.data)-offset-32) {
+		d.fail(errInvalidLength)
+		return []byte{}
+	}
+	return common.CopyBytes(d.data[offset+32 : offset+32+int(length.Uint64())])
+}
+
+// String decodes a string.
+func (d *Decoder) String() string {
+	return string(d.Bytes())
+}
+
+// Tuple returns a decoder for the fields of a nested struct or the elements of
+// a fixed size array, which is encoded the same way. Static tuples are encoded
+// in place, so their fields are read from this very decoder.
+func (d *Decoder) Tuple(dynamic bool) *Decoder {
+	if !dynamic {
+		return d
+	}
+	offset := d.offset(0)
+	return &Decoder{data: d.data[offset:], err: d.err}
+}
+
+// slice returns a decoder for the elements of a dynamic size array and the
+// number of its elements.
+func (d *Decoder) slice() (*Decoder, int) {
+	offset := d.offset(32)
+	if offset == len(d.data) {
+		return &Decoder{err: d.err}, 0
+	}
+	// Every element occupies at least one word of the encoding
+	rest := d.data[offset+32:]
+	length := new(big.Int).SetBytes(d.data[offset : offset+32])
+	if !length.IsUint64() || length.Uint64() > uint64(len(rest)/32) {
+		d.fail(errInvalidLength)
+		return &Decoder{err: d.err}, 0
+	}
+	return &Decoder{data: rest, err: d.err}, int(length.Uint64())
+}
+
+// UnpackSlice decodes a dynamic size array, using the given function to decode
+// the individual elements.
+func UnpackSlice[T any](d *Decoder, unpack func(*Decoder) T) []T {
+	elems, n := d.slice()
+	out := make([]T, n)
+	for i := range out {
+		out[i] = unpack(elems)
+	}
+	return out
+}
+
+// isZero reports whether all bytes of b are zero.
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests that the typed encoders produce the same output as the reflection based
+// packer of the abi package, and that the decoders reverse them.
+func TestCodecRoundtrip(t *testing.T) {
+	var args abi.Arguments
+	for _, typ := range []string{"int8", "int256", "uint24", "bool", "address", "bytes3", "bytes", "string", "string[]", "uint16[2]"} {
+		kind, err := abi.NewType(typ, "", nil)
+		if err != nil {
+			t.Fatalf("failed to create type %s: %v", typ, err)
+		}
+		args = append(args, abi.Argument{Type: kind})
+	}
+	var (
+		i8      = int8(-5)
+		i256    = big.NewInt(-1000)
+		u24     = big.NewInt(0xabcdef)
+		flag    = true
+		addr    = common.Address{0xde, 0xad}
+		fixed   = [3]byte{1, 2, 3}
+		blob    = []byte("a byte array spanning more than a single word")
+		str     = "hello"
+		strs    = []string{"a", "", "c"}
+		numbers = [2]uint16{7, 65535}
+	)
+	want, err := args.Pack(i8, i256, u24, flag, addr, fixed, blob, str, strs, numbers)
+	if err != nil {
+		t.Fatalf("failed to pack reference: %v", err)
+	}
+	var enc Packer
+	have, err := enc.Pack(nil,
+		enc.Int64(int64(i8)),
+		enc.Int(i256, 256),
+		enc.Uint(u24, 24),
+		enc.Bool(flag),
+		enc.Address(addr),
+		enc.FixedBytes(fixed[:]),
+		enc.Bytes(blob),
+		enc.String(str),
+		PackSlice(strs, func(v string) Encoded { return enc.String(v) }),
+		PackArray(numbers[:], func(v uint16) Encoded { return enc.Uint64(uint64(v)) }),
+	)
+	if err != nil {
+		t.Fatalf("failed to pack: %v", err)
+	}
+	if !bytes.Equal(have, want) {
+		t.Fatalf("encoding mismatch:\nhave %x\nwant %x", have, want)
+	}
+	dec := NewDecoder(have)
+	if v := int8(dec.Int64(8)); v != i8 {
+		t.Errorf("int8 mismatch: have %d, want %d", v, i8)
+	}
+	if v := dec.Int(256); v.Cmp(i256) != 0 {
+		t.Errorf("int256 mismatch: have %v, want %v", v, i256)
+	}
+	if v := dec.Uint(24); v.Cmp(u24) != 0 {
+		t.Errorf("uint24 mismatch: have %v, want %v", v, u24)
+	}
+	if v := dec.Bool(); v != flag {
+		t.Errorf("bool mismatch: have %v, want %v", v, flag)
+	}
+	if v := dec.Address(); v != addr {
+		t.Errorf("address mismatch: have %v, want %v", v, addr)
+	}
+	if v := dec.FixedBytes(3); !bytes.Equal(v, fixed[:]) {
+		t.Errorf("bytes3 mismatch: have %x, want %x", v, fixed)
+	}
+	if v := dec.Bytes(); !bytes.Equal(v, blob) {
+		t.Errorf("bytes mismatch: have %x, want %x", v, blob)
+	}
+	if v := dec.String(); v != str {
+		t.Errorf("string mismatch: have %q, want %q", v, str)
+	}
+	if v := UnpackSlice(dec, func(d *Decoder) string { return d.String() }); !reflect.DeepEqual(v, strs) {
+		t.Errorf("string[] mismatch: have %q, want %q", v, strs)
+	}
+	elems := dec.Tuple(false)
+	if v := [2]uint16{uint16(elems.Uint64(16)), uint16(elems.Uint64(16))}; v != numbers {
+		t.Errorf("uint16[2] mismatch: have %v, want %v", v, numbers)
+	}
+	if err := dec.Err(); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+}
+
+// Tests that values not fitting their ABI type are rejected by the encoders.
+func TestPackerRange(t *testing.T) {
+	tests := []func(p *Packer) Encoded{
+		func(p *Packer) Encoded { return p.Uint(nil, 256) },
+		func(p *Packer) Encoded { return p.Uint(big.NewInt(-1), 256) },
+		func(p *Packer) Encoded { return p.Uint(big.NewInt(256), 8) },
+		func(p *Packer) Encoded { return p.Uint(new(big.Int).Lsh(common.Big1, 256), 256) },
+		func(p *Packer) Encoded { return p.Int(big.NewInt(128), 8) },
+		func(p *Packer) Encoded { return p.Int(big.NewInt(-129), 8) },
+	}
+	for i, pack := range tests {
+		var p Packer
+		if _, err := p.Pack(nil, pack(&p)); err == nil {
+			t.Errorf("test %d: invalid value packed", i)
+		}
+	}
+	var p Packer
+	if _, err := p.Pack(nil, p.Int(big.NewInt(-128), 8), p.Int(big.NewInt(127), 8), p.Uint(big.NewInt(255), 8)); err != nil {
+		t.Errorf("boundary values rejected: %v", err)
+	}
+}
+
+// Tests that malformed encodings are reported by the decoders.
+func TestDecoderFailures(t *testing.T) {
+	word := func(b ...byte) []byte { return common.LeftPadBytes(b, 32) }
+	tests := []struct {
+		data   []byte
+		decode func(d *Decoder)
+		err    error
+	}{
+		{nil, func(d *Decoder) { d.Bool() }, errInsufficientData},
+		{word(2), func(d *Decoder) { d.Bool() }, errInvalidBool},
+		{word(1, 0), func(d *Decoder) { d.Uint64(8) }, errIntegerRange},
+		{common.RightPadBytes([]byte{1}, 32), func(d *Decoder) { d.Address() }, errDirtyPadding},
+		{word(1), func(d *Decoder) { d.FixedBytes(1) }, errDirtyPadding},
+		{word(64), func(d *Decoder) { d.Bytes() }, errInvalidOffset},
+		{append(word(32), word(33)...), func(d *Decoder) { d.Bytes() }, errInvalidLength},
+		{append(word(32), word(0xff, 0xff)...), func(d *Decoder) { UnpackSlice(d, func(d *Decoder) bool { return d.Bool() }) }, errInvalidLength},
+	}
+	for i, tt := range tests {
+		d := NewDecoder(tt.data)
+		tt.decode(d)
+		if err := d.Err(); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bind implements the runtime of the lightweight contract bindings
+// generated by abigen --v2.
+//
+// Generated bindings only pack and unpack contract data, using the typed
+// encoders and decoders of this package instead of reflection. Interacting with
+// a contract is done through the generic helpers of this package, which take
+// the packed input and the unpack function of the binding:
+//
+//	token := NewToken()
+//	instance := token.Instance(backend, address)
+//	input, err := token.PackBalanceOf(owner)
+//	if err != nil {
+//		return err
+//	}
+//	balance, err := bind.Call(instance, nil, input, token.UnpackBalanceOf)
+package bind
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	bind1 "github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+type (
+	// BoundContract is a deployed contract instance, used by the helpers to
+	// interact with the contract.
+	BoundContract = bind1.BoundContract
+
+	// ContractBackend is the backend required to interact with contracts.
+	ContractBackend = bind1.ContractBackend
+
+	// MetaData holds the ABI and bytecode of a contract.
+	MetaData = bind1.MetaData
+
+	CallOpts     = bind1.CallOpts
+	TransactOpts = bind1.TransactOpts
+	FilterOpts   = bind1.FilterOpts
+	WatchOpts    = bind1.WatchOpts
+)
+
+// NewBoundContract creates a contract instance at the given address.
+func NewBoundContract(address common.Address, abi abi.ABI, backend ContractBackend) *BoundContract {
+	return bind1.NewBoundContract(address, abi, backend, backend, backend)
+}
+
+// Call executes a contract call with the packed input, and decodes the output
+// with the given unpack function of a binding.
+func Call[T any](c *BoundContract, opts *CallOpts, packedInput []byte, unpack func([]byte) (T, error)) (T, error) {
+	output, err := c.CallRaw(opts, packedInput)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return unpack(output)
+}
+
+// Transact sends a transaction to the contract with the packed input.
+func Transact(c *BoundContract, opts *TransactOpts, packedInput []byte) (*types.Transaction, error) {
+	return c.RawTransact(opts, packedInput)
+}
+
+// DeployContract deploys a contract with the given bytecode and the packed
+// constructor arguments. It returns the address of the contract and the
+// creation transaction.
+func DeployContract(opts *TransactOpts, bytecode []byte, backend ContractBackend, packedParams []byte) (common.Address, *types.Transaction, error) {
+	c := bind1.NewBoundContract(common.Address{}, abi.ABI{}, backend, backend, backend)
+
+	input := make([]byte, 0, len(bytecode)+len(packedParams))
+	input = append(append(input, bytecode...), packedParams...)
+	tx, err := c.RawCreationTransact(opts, input)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	return crypto.CreateAddress(opts.From, tx.Nonce()), tx, nil
+}
+
+// LinkBytecode replaces the library placeholders in the hex encoded bytecode of
+// a contract with the addresses of the deployed libraries. The keys of the map
+// are the link patterns, as found in the Libraries map of a binding.
+func LinkBytecode(bytecode string, libraries map[string]common.Address) ([]byte, error) {
+	for pattern, addr := range libraries {
+		bytecode = strings.ReplaceAll(bytecode, "__$"+pattern+"$__", addr.String()[2:])
+	}
+	if i := strings.Index(bytecode, "__$"); i >= 0 {
+		return nil, fmt.Errorf("unlinked library placeholder at offset %d", i)
+	}
+	return hexutil.Decode(bytecode)
+}
+
+// RevertData returns the revert data carried by an error of a failed contract
+// call, transaction or gas estimation. The data can be decoded by the
+// UnpackError method of a binding.
+func RevertData(err error) ([]byte, bool) {
+	var dataErr interface {
+		ErrorData() interface{}
+	}
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	switch data := dataErr.ErrorData().(type) {
+	case string:
+		raw, err := hexutil.Decode(data)
+		return raw, err == nil
+	case []byte:
+		return data, true
+	}
+	return nil, false
+}
+
+// EventIterator iterates over the events of a log filter.
+type EventIterator[T any] struct {
+	current *T
+	unpack  func(*types.Log) (*T, error)
+
+	logs chan types.Log     // Log channel receiving the found contract events
+	sub  event.Subscription // Subscription for errors, completion and termination
+	done bool               // Whether the subscription completed delivering logs
+	fail error              // Occurred error to stop iteration
+}
+
+// Value returns the current event of the iterator.
+func (it *EventIterator[T]) Value() *T {
+	return it.current
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *EventIterator[T]) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			return it.next(&log)
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		return it.next(&log)
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+func (it *EventIterator[T]) next(log *types.Log) bool {
+	ev, err := it.unpack(log)
+	if err != nil {
+		it.fail = err
+		return false
+	}
+	it.current = ev
+	return true
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *EventIterator[T]) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *EventIterator[T]) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FilterEvents returns an iterator over the past events with the given ID,
+// decoded by the given unpack function of a binding. Topics filters on the
+// indexed event fields.
+func FilterEvents[T any](c *BoundContract, opts *FilterOpts, eventID common.Hash, unpack func(*types.Log) (*T, error), topics ...[]interface{}) (*EventIterator[T], error) {
+	logs, sub, err := c.FilterLogsByID(opts, eventID, topics...)
+	if err != nil {
+		return nil, err
+	}
+	return &EventIterator[T]{unpack: unpack, logs: logs, sub: sub}, nil
+}
+
+// WatchEvents subscribes to future events with the given ID, decoding them with
+// the given unpack function of a binding and delivering them to sink.
+func WatchEvents[T any](c *BoundContract, opts *WatchOpts, eventID common.Hash, unpack func(*types.Log) (*T, error), sink chan<- *T, topics ...[]interface{}) (event.Subscription, error) {
+	logs, sub, err := c.WatchLogsByID(opts, eventID, topics...)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				ev, err := unpack(&log)
+				if err != nil {
+					return err
+				}
+				select {
+				case sink <- ev:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
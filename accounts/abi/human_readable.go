@@ -0,0 +1,706 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package abi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// humanParam is a parameter of a human-readable signature, before struct
+// references are resolved.
+type humanParam struct {
+	name       string
+	typ        string       // elementary type or struct name, empty for inline tuples
+	components []humanParam // fields of an inline tuple
+	suffix     string       // array suffix, e.g. "[][2]"
+	indexed    bool
+}
+
+// humanParser is a recursive descent parser over the tokens of a single
+// human-readable signature.
+type humanParser struct {
+	tokens []string
+	pos    int
+}
+
+// tokenizeHuman splits a human-readable signature into identifiers, numbers
+// and punctuation.
+func tokenizeHuman(input string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte("()[]{},;", c) != -1:
+			tokens = append(tokens, string(c))
+			i++
+		case isAlpha(c) || isDigit(c) || isIdentifierSymbol(c):
+			start := i
+			for i < len(input) && (isAlpha(input[i]) || isDigit(input[i]) || isIdentifierSymbol(input[i])) {
+				i++
+			}
+			tokens = append(tokens, input[start:i])
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' at position %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+func (p *humanParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *humanParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *humanParser) next() string {
+	tok := p.peek()
+	if !p.done() {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *humanParser) expect(tok string) error {
+	if have := p.next(); have != tok {
+		if have == "" {
+			return fmt.Errorf("expected '%s', got end of input", tok)
+		}
+		return fmt.Errorf("expected '%s', got '%s'", tok, have)
+	}
+	return nil
+}
+
+// identifier consumes an identifier token, failing on punctuation, numbers
+// and the end of input.
+func (p *humanParser) identifier() (string, error) {
+	tok := p.next()
+	if tok == "" {
+		return "", errors.New("expected identifier, got end of input")
+	}
+	if !isAlpha(tok[0]) && !isIdentifierSymbol(tok[0]) {
+		return "", fmt.Errorf("expected identifier, got '%s'", tok)
+	}
+	return tok, nil
+}
+
+// parseParams parses a parenthesized, comma separated parameter list.
+func (p *humanParser) parseParams() ([]humanParam, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var params []humanParam
+	if p.peek() == ")" {
+		p.next()
+		return params, nil
+	}
+	for {
+		param, err := p.parseParam()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+
+		switch tok := p.next(); tok {
+		case ",":
+			continue
+		case ")":
+			if err := checkNames(params); err != nil {
+				return nil, err
+			}
+			return params, nil
+		case "":
+			return nil, errors.New("expected ')', got end of input")
+		default:
+			return nil, fmt.Errorf("expected ',' or ')', got '%s'", tok)
+		}
+	}
+}
+
+// parseParam parses a single parameter in the form of
+//
+//	type [indexed] [memory|calldata|storage] [name]
+//
+// where type is an elementary type, a struct name or a tuple, optionally
+// followed by array suffixes.
+func (p *humanParser) parseParam() (humanParam, error) {
+	var param humanParam
+	switch p.peek() {
+	case "tuple", "(":
+		if p.peek() == "tuple" {
+			p.next()
+		}
+		components, err := p.parseParams()
+		if err != nil {
+			return humanParam{}, err
+		}
+		if components == nil {
+			return humanParam{}, errors.New("empty tuple")
+		}
+		param.components = components
+	default:
+		typ, err := p.identifier()
+		if err != nil {
+			return humanParam{}, err
+		}
+		// Drop the payable modifier of addresses, it isn't part of the ABI
+		if typ == "address" && p.peek() == "payable" {
+			p.next()
+		}
+		param.typ = typ
+	}
+	for p.peek() == "[" {
+		p.next()
+		param.suffix += "["
+		if tok := p.peek(); tok != "" && isDigit(tok[0]) {
+			for i := 0; i < len(tok); i++ {
+				if !isDigit(tok[i]) {
+					return humanParam{}, fmt.Errorf("invalid array size '%s'", tok)
+				}
+			}
+			if strings.Trim(tok, "0") == "" {
+				return humanParam{}, fmt.Errorf("zero-length array '%s'", tok)
+			}
+			param.suffix += p.next()
+		}
+		if err := p.expect("]"); err != nil {
+			return humanParam{}, err
+		}
+		param.suffix += "]"
+	}
+	for tok := p.peek(); tok == "indexed" || tok == "memory" || tok == "calldata" || tok == "storage"; tok = p.peek() {
+		if tok == "indexed" {
+			param.indexed = true
+		}
+		p.next()
+	}
+	if tok := p.peek(); tok != "," && tok != ")" && tok != ";" && tok != "" {
+		name, err := p.identifier()
+		if err != nil {
+			return humanParam{}, err
+		}
+		param.name = name
+	}
+	return param, nil
+}
+
+// parseStruct parses the body of a struct declaration, after the struct
+// keyword and its name.
+func (p *humanParser) parseStruct() ([]humanParam, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var fields []humanParam
+	for p.peek() != "}" {
+		if p.done() {
+			return nil, errors.New("expected '}', got end of input")
+		}
+		field, err := p.parseParam()
+		if err != nil {
+			return nil, err
+		}
+		if field.name == "" {
+			return nil, fmt.Errorf("struct field %d has no name", len(fields))
+		}
+		if field.indexed {
+			return nil, errors.New("struct fields can't be indexed")
+		}
+		fields = append(fields, field)
+		if err := p.expect(";"); err != nil {
+			return nil, err
+		}
+	}
+	p.next()
+	if len(fields) == 0 {
+		return nil, errors.New("empty struct")
+	}
+	if err := checkNames(fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// checkNames ensures that the named parameters of a list are unique.
+func checkNames(params []humanParam) error {
+	seen := make(map[string]bool)
+	for _, param := range params {
+		if param.name == "" {
+			continue
+		}
+		if seen[param.name] {
+			return fmt.Errorf("duplicate parameter name '%s'", param.name)
+		}
+		seen[param.name] = true
+	}
+	return nil
+}
+
+// parseModifiers consumes the visibility and state mutability keywords of a
+// function, returning the mutability (nonpayable if none is specified).
+func (p *humanParser) parseModifiers() (string, error) {
+	var (
+		mutability string
+		seen       = make(map[string]bool)
+	)
+	for !p.done() && p.peek() != "returns" && p.peek() != ";" {
+		tok := p.next()
+		if seen[tok] {
+			return "", fmt.Errorf("repeated modifier '%s'", tok)
+		}
+		seen[tok] = true
+
+		switch tok {
+		case "external", "public", "internal", "private", "virtual", "override":
+		case "view", "pure", "payable", "nonpayable", "constant":
+			if mutability != "" {
+				return "", fmt.Errorf("conflicting modifiers '%s' and '%s'", mutability, tok)
+			}
+			mutability = tok
+		default:
+			return "", fmt.Errorf("unexpected modifier '%s'", tok)
+		}
+	}
+	switch mutability {
+	case "":
+		mutability = "nonpayable"
+	case "constant":
+		mutability = "view"
+	}
+	return mutability, nil
+}
+
+// humanResolver converts parsed parameters into ABI arguments, expanding the
+// references to declared structs.
+type humanResolver struct {
+	structs   map[string][]humanParam
+	resolving map[string]bool
+}
+
+func (r *humanResolver) marshal(param humanParam) (ArgumentMarshaling, error) {
+	arg := ArgumentMarshaling{Name: param.name, Indexed: param.indexed}
+
+	var components []humanParam
+	switch {
+	case param.components != nil:
+		arg.Type = "tuple" + param.suffix
+		components = param.components
+	case r.structs[param.typ] != nil:
+		if r.resolving[param.typ] {
+			return ArgumentMarshaling{}, fmt.Errorf("recursive struct '%s'", param.typ)
+		}
+		r.resolving[param.typ] = true
+		defer delete(r.resolving, param.typ)
+
+		arg.Type = "tuple" + param.suffix
+		arg.InternalType = "struct " + param.typ + param.suffix
+		components = r.structs[param.typ]
+	default:
+		// Expand the aliases of Solidity, which are not valid in the ABI
+		typ := param.typ
+		switch typ {
+		case "uint", "int":
+			typ += "256"
+		case "byte":
+			typ = "bytes1"
+		}
+		arg.Type = typ + param.suffix
+		arg.InternalType = arg.Type
+		return arg, nil
+	}
+	for i, component := range components {
+		if component.indexed {
+			return ArgumentMarshaling{}, errors.New("tuple components can't be indexed")
+		}
+		// Tuple components must be named, generate placeholder names
+		if component.name == "" {
+			component.name = fmt.Sprintf("arg%d", i)
+		}
+		c, err := r.marshal(component)
+		if err != nil {
+			return ArgumentMarshaling{}, err
+		}
+		arg.Components = append(arg.Components, c)
+	}
+	return arg, nil
+}
+
+func (r *humanResolver) arguments(params []humanParam, event bool) (Arguments, error) {
+	args := make(Arguments, 0, len(params))
+	for _, param := range params {
+		if param.indexed && !event {
+			return nil, errors.New("only event parameters can be indexed")
+		}
+		marshaled, err := r.marshal(param)
+		if err != nil {
+			return nil, err
+		}
+		typ, err := NewType(marshaled.Type, marshaled.InternalType, marshaled.Components)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, Argument{Name: marshaled.Name, Type: typ, Indexed: marshaled.Indexed})
+	}
+	return args, nil
+}
+
+// ParseHumanReadable builds an ABI from signatures in the Solidity
+// human-readable format, for example:
+//
+//	struct Point { uint256 x; uint256 y; }
+//	constructor(string name) payable
+//	function transfer(address to, uint256 amount) returns (bool)
+//	function origin() view returns (Point)
+//	event Transfer(address indexed from, address indexed to, uint256 value)
+//	error InsufficientBalance(uint256 available, uint256 required)
+//
+// Structs may be declared and referenced in any order, tuples can also be
+// written inline as tuple(uint256 x, uint256 y) or (uint256,uint256).
+func ParseHumanReadable(signatures []string) (ABI, error) {
+	abi := ABI{
+		Methods: make(map[string]Method),
+		Events:  make(map[string]Event),
+		Errors:  make(map[string]Error),
+	}
+	// Tokenize all signatures and gather the struct declarations first, so
+	// they can be referenced before being declared
+	var (
+		parsers  = make([]*humanParser, len(signatures))
+		resolver = &humanResolver{
+			structs:   make(map[string][]humanParam),
+			resolving: make(map[string]bool),
+		}
+	)
+	for i, sig := range signatures {
+		tokens, err := tokenizeHuman(sig)
+		if err != nil {
+			return ABI{}, fmt.Errorf("abi: failed to parse '%s': %v", sig, err)
+		}
+		parsers[i] = &humanParser{tokens: tokens}
+		if parsers[i].peek() != "struct" {
+			continue
+		}
+		p := parsers[i]
+		p.next()
+		name, err := p.identifier()
+		if err != nil {
+			return ABI{}, fmt.Errorf("abi: failed to parse '%s': %v", sig, err)
+		}
+		if resolver.structs[name] != nil {
+			return ABI{}, fmt.Errorf("abi: failed to parse '%s': struct '%s' already declared", sig, name)
+		}
+		fields, err := p.parseStruct()
+		if err != nil {
+			return ABI{}, fmt.Errorf("abi: failed to parse '%s': %v", sig, err)
+		}
+		resolver.structs[name] = fields
+	}
+	for i, sig := range signatures {
+		p := parsers[i]
+		if p.done() || p.tokens[0] == "struct" {
+			if err := p.finish(); err != nil {
+				return ABI{}, fmt.Errorf("abi: failed to parse '%s': %v", sig, err)
+			}
+			continue
+		}
+		if err := abi.parseHumanSignature(p, resolver); err != nil {
+			return ABI{}, fmt.Errorf("abi: failed to parse '%s': %v", sig, err)
+		}
+	}
+	return abi, nil
+}
+
+// finish consumes an optional trailing semicolon and ensures nothing else is
+// left to parse.
+func (p *humanParser) finish() error {
+	if p.peek() == ";" {
+		p.next()
+	}
+	if !p.done() {
+		return fmt.Errorf("unexpected '%s'", p.peek())
+	}
+	return nil
+}
+
+// parseHumanSignature parses a single non-struct signature and adds it to the
+// ABI.
+func (abi *ABI) parseHumanSignature(p *humanParser, r *humanResolver) error {
+	switch kind := p.next(); kind {
+	case "constructor":
+		params, err := p.parseParams()
+		if err != nil {
+			return err
+		}
+		mutability, err := p.parseModifiers()
+		if err != nil {
+			return err
+		}
+		inputs, err := r.arguments(params, false)
+		if err != nil {
+			return err
+		}
+		abi.Constructor = NewMethod("", "", Constructor, mutability, false, mutability == "payable", inputs, nil)
+
+	case "function":
+		rawName, err := p.identifier()
+		if err != nil {
+			return err
+		}
+		params, err := p.parseParams()
+		if err != nil {
+			return err
+		}
+		mutability, err := p.parseModifiers()
+		if err != nil {
+			return err
+		}
+		inputs, err := r.arguments(params, false)
+		if err != nil {
+			return err
+		}
+		var outputs Arguments
+		if p.peek() == "returns" {
+			p.next()
+			params, err := p.parseParams()
+			if err != nil {
+				return err
+			}
+			if outputs, err = r.arguments(params, false); err != nil {
+				return err
+			}
+		}
+		isConst := mutability == "view" || mutability == "pure"
+		name := ResolveNameConflict(rawName, func(s string) bool { _, ok := abi.Methods[s]; return ok })
+		abi.Methods[name] = NewMethod(name, rawName, Function, mutability, isConst, mutability == "payable", inputs, outputs)
+
+	case "fallback", "receive":
+		// The input and output of the fallback function are not part of
+		// the ABI, parse and discard them
+		if _, err := p.parseParams(); err != nil {
+			return err
+		}
+		mutability, err := p.parseModifiers()
+		if err != nil {
+			return err
+		}
+		if p.peek() == "returns" {
+			p.next()
+			if _, err := p.parseParams(); err != nil {
+				return err
+			}
+		}
+		if kind == "fallback" {
+			if abi.HasFallback() {
+				return errors.New("only single fallback is allowed")
+			}
+			abi.Fallback = NewMethod("", "", Fallback, mutability, false, mutability == "payable", nil, nil)
+		} else {
+			if abi.HasReceive() {
+				return errors.New("only single receive is allowed")
+			}
+			if mutability != "payable" {
+				return errors.New("the statemutability of receive can only be payable")
+			}
+			abi.Receive = NewMethod("", "", Receive, mutability, false, true, nil, nil)
+		}
+
+	case "event":
+		rawName, err := p.identifier()
+		if err != nil {
+			return err
+		}
+		params, err := p.parseParams()
+		if err != nil {
+			return err
+		}
+		anonymous := p.peek() == "anonymous"
+		if anonymous {
+			p.next()
+		}
+		// Anonymous events don't spend a topic on the signature, leaving
+		// room for an extra indexed parameter
+		limit, indexed := 3, 0
+		if anonymous {
+			limit = 4
+		}
+		for _, param := range params {
+			if param.indexed {
+				indexed++
+			}
+		}
+		if indexed > limit {
+			return fmt.Errorf("too many indexed parameters: have %d, max %d", indexed, limit)
+		}
+		inputs, err := r.arguments(params, true)
+		if err != nil {
+			return err
+		}
+		name := ResolveNameConflict(rawName, func(s string) bool { _, ok := abi.Events[s]; return ok })
+		abi.Events[name] = NewEvent(name, rawName, anonymous, inputs)
+
+	case "error":
+		name, err := p.identifier()
+		if err != nil {
+			return err
+		}
+		params, err := p.parseParams()
+		if err != nil {
+			return err
+		}
+		inputs, err := r.arguments(params, false)
+		if err != nil {
+			return err
+		}
+		abi.Errors[name] = NewError(name, inputs)
+
+	default:
+		return fmt.Errorf("unknown signature type '%s'", kind)
+	}
+	return p.finish()
+}
+
+// humanFormatter formats ABI types in the human-readable format, collecting
+// the declarations of the named structs it encounters.
+type humanFormatter struct {
+	structs map[string]string // struct name -> declaration
+	order   []string          // struct names in order of declaration
+}
+
+// formatType formats a type, referencing named structs by name. A named struct
+// is inlined as a tuple if another struct with the same name but different
+// fields was already declared.
+func (f *humanFormatter) formatType(t Type) string {
+	switch t.T {
+	case SliceTy:
+		return f.formatType(*t.Elem) + "[]"
+	case ArrayTy:
+		return fmt.Sprintf("%s[%d]", f.formatType(*t.Elem), t.Size)
+	case TupleTy:
+		fields := make([]string, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			fields[i] = f.formatType(*elem) + " " + t.TupleRawNames[i]
+		}
+		if t.TupleRawName != "" {
+			decl := fmt.Sprintf("struct %s { %s; }", t.TupleRawName, strings.Join(fields, "; "))
+			if have, ok := f.structs[t.TupleRawName]; !ok {
+				f.structs[t.TupleRawName] = decl
+				f.order = append(f.order, t.TupleRawName)
+				return t.TupleRawName
+			} else if have == decl {
+				return t.TupleRawName
+			}
+		}
+		return "tuple(" + strings.Join(fields, ", ") + ")"
+	default:
+		return t.String()
+	}
+}
+
+func (f *humanFormatter) formatArgs(args Arguments) string {
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		formatted[i] = f.formatType(arg.Type)
+		if arg.Indexed {
+			formatted[i] += " indexed"
+		}
+		if arg.Name != "" {
+			formatted[i] += " " + arg.Name
+		}
+	}
+	return "(" + strings.Join(formatted, ", ") + ")"
+}
+
+// formatMutability returns the state mutability keyword of a method, which is
+// empty for non-payable methods.
+func formatMutability(method Method) string {
+	switch {
+	case method.StateMutability == "view" || method.StateMutability == "pure" || method.StateMutability == "payable":
+		return " " + method.StateMutability
+	case method.Constant:
+		return " view"
+	case method.Payable:
+		return " payable"
+	}
+	return ""
+}
+
+// HumanReadable formats the ABI as signatures in the Solidity human-readable
+// format, which can be parsed back with ParseHumanReadable. The struct
+// declarations come first, followed by the constructor, the fallback and
+// receive functions, and the functions, events and errors sorted by name.
+func (abi ABI) HumanReadable() []string {
+	var (
+		f = &humanFormatter{structs: make(map[string]string)}
+		// The lines besides the struct declarations, which are only known
+		// once all the types have been formatted
+		lines []string
+	)
+	// The zero constructor has no state mutability, only output one that
+	// was explicitly declared
+	if c := abi.Constructor; c.StateMutability != "" || c.Payable || len(c.Inputs) > 0 {
+		lines = append(lines, "constructor"+f.formatArgs(c.Inputs)+formatMutability(c))
+	}
+	if abi.HasFallback() {
+		lines = append(lines, "fallback() external"+formatMutability(abi.Fallback))
+	}
+	if abi.HasReceive() {
+		lines = append(lines, "receive() external payable")
+	}
+	for _, name := range sortedKeys(abi.Methods) {
+		method := abi.Methods[name]
+		line := "function " + method.RawName + f.formatArgs(method.Inputs) + formatMutability(method)
+		if len(method.Outputs) > 0 {
+			line += " returns " + f.formatArgs(method.Outputs)
+		}
+		lines = append(lines, line)
+	}
+	for _, name := range sortedKeys(abi.Events) {
+		event := abi.Events[name]
+		line := "event " + event.RawName + f.formatArgs(event.Inputs)
+		if event.Anonymous {
+			line += " anonymous"
+		}
+		lines = append(lines, line)
+	}
+	for _, name := range sortedKeys(abi.Errors) {
+		abiErr := abi.Errors[name]
+		lines = append(lines, "error "+abiErr.Name+f.formatArgs(abiErr.Inputs))
+	}
+	signatures := make([]string, 0, len(f.order)+len(lines))
+	for _, name := range f.order {
+		signatures = append(signatures, f.structs[name])
+	}
+	return append(signatures, lines...)
+}
+
+// sortedKeys returns the keys of a map in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
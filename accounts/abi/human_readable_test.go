@@ -0,0 +1,241 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package abi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var humanReadableABI = []string{
+	"constructor(string name, uint8 decimals) payable",
+	"function transfer(address to, uint256 amount) returns (bool)",
+	"function transfer(address to, uint256 amount, bytes calldata data) external returns (bool success)",
+	"function balanceOf(address payable owner) view returns (uint)",
+	"function origin() external pure returns (Point memory)",
+	"function move(Point[] calldata points, Line[2] line) returns (tuple(uint256 x, uint256 y)[] moved, (int, bytes32))",
+	"struct Line { Point from; Point to; }",
+	"struct Point { uint256 x; uint256 y; }",
+	"event Transfer(address indexed from, address indexed to, uint256 value)",
+	"event Moved(Point indexed point, string indexed label, byte[] data) anonymous",
+	"error InsufficientBalance(uint256 available, uint256 required)",
+	"error Unauthorized()",
+	"fallback() external",
+	"receive() external payable;",
+}
+
+func TestParseHumanReadable(t *testing.T) {
+	abi, err := ParseHumanReadable(humanReadableABI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Check the canonical signatures, which determine the selectors
+	methods := map[string]string{
+		"transfer":  "transfer(address,uint256)",
+		"transfer0": "transfer(address,uint256,bytes)",
+		"balanceOf": "balanceOf(address)",
+		"origin":    "origin()",
+		"move":      "move((uint256,uint256)[],((uint256,uint256),(uint256,uint256))[2])",
+	}
+	if len(abi.Methods) != len(methods) {
+		t.Fatalf("wrong number of methods: have %d, want %d", len(abi.Methods), len(methods))
+	}
+	for name, sig := range methods {
+		if method, ok := abi.Methods[name]; !ok || method.Sig != sig {
+			t.Errorf("method %s: have signature %q, want %q", name, method.Sig, sig)
+		}
+	}
+	if id := abi.Methods["transfer"].ID; !reflect.DeepEqual(id, []byte{0xa9, 0x05, 0x9c, 0xbb}) {
+		t.Errorf("wrong transfer selector %x", id)
+	}
+	if m := abi.Methods["balanceOf"]; !m.IsConstant() || m.StateMutability != "view" || m.Outputs[0].Type.String() != "uint256" {
+		t.Errorf("wrong balanceOf method %v", m)
+	}
+	if m := abi.Methods["origin"]; m.StateMutability != "pure" || m.Outputs[0].Type.TupleRawName != "Point" {
+		t.Errorf("wrong origin method %v", m)
+	}
+	move := abi.Methods["move"]
+	if move.Inputs[1].Type.Elem.TupleRawName != "Line" || move.Inputs[1].Type.Elem.TupleRawNames[0] != "from" {
+		t.Errorf("struct Line not resolved: %+v", move.Inputs[1].Type.Elem)
+	}
+	if move.Outputs[0].Name != "moved" || move.Outputs[1].Type.TupleRawNames[1] != "arg1" {
+		t.Errorf("wrong move outputs %v", move.Outputs)
+	}
+	if c := abi.Constructor; !c.IsPayable() || len(c.Inputs) != 2 {
+		t.Errorf("wrong constructor %v", c)
+	}
+	if !abi.HasFallback() || abi.Fallback.IsPayable() || !abi.HasReceive() {
+		t.Error("fallback or receive function missing")
+	}
+	// Check the events and errors
+	transfer := abi.Events["Transfer"]
+	if transfer.Sig != "Transfer(address,address,uint256)" || !transfer.Inputs[0].Indexed || !transfer.Inputs[1].Indexed || transfer.Inputs[2].Indexed {
+		t.Errorf("wrong Transfer event %v", transfer)
+	}
+	if transfer.ID.Hex() != "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef" {
+		t.Errorf("wrong Transfer topic %x", transfer.ID)
+	}
+	if moved := abi.Events["Moved"]; !moved.Anonymous || moved.Sig != "Moved((uint256,uint256),string,bytes1[])" {
+		t.Errorf("wrong Moved event %v", moved)
+	}
+	if e := abi.Errors["InsufficientBalance"]; e.Sig != "InsufficientBalance(uint256,uint256)" {
+		t.Errorf("wrong error %v", e)
+	}
+	if e, ok := abi.Errors["Unauthorized"]; !ok || len(e.Inputs) != 0 {
+		t.Errorf("wrong error %v", e)
+	}
+}
+
+// Tests that the human-readable signatures produce the same ABI as the JSON
+// compiler output.
+func TestParseHumanReadableJSON(t *testing.T) {
+	human, err := ParseHumanReadable([]string{
+		"function send(uint256 amount) payable",
+		"function balance() view returns (uint256)",
+		"event received(address sender, uint256 amount, bytes memo)",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	json, err := JSON(strings.NewReader(`[
+		{"type":"function","name":"send","inputs":[{"name":"amount","type":"uint256"}],"stateMutability":"payable","payable":true},
+		{"type":"function","name":"balance","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","constant":true},
+		{"type":"event","name":"received","anonymous":false,"inputs":[{"indexed":false,"name":"sender","type":"address"},{"indexed":false,"name":"amount","type":"uint256"},{"indexed":false,"name":"memo","type":"bytes"}]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(human, json) {
+		t.Fatalf("ABI mismatch:\nhuman: %+v\njson:  %+v", human, json)
+	}
+}
+
+func TestHumanReadableRoundTrip(t *testing.T) {
+	abi, err := ParseHumanReadable(humanReadableABI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"struct Point { uint256 x; uint256 y; }",
+		"struct Line { Point from; Point to; }",
+		"constructor(string name, uint8 decimals) payable",
+		"fallback() external",
+		"receive() external payable",
+		"function balanceOf(address owner) view returns (uint256)",
+		"function move(Point[] points, Line[2] line) returns (tuple(uint256 x, uint256 y)[] moved, tuple(int256 arg0, bytes32 arg1))",
+		"function origin() pure returns (Point)",
+		"function transfer(address to, uint256 amount) returns (bool)",
+		"function transfer(address to, uint256 amount, bytes data) returns (bool success)",
+		"event Moved(Point indexed point, string indexed label, bytes1[] data) anonymous",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"error InsufficientBalance(uint256 available, uint256 required)",
+		"error Unauthorized()",
+	}
+	have := abi.HumanReadable()
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("formatted ABI mismatch:\nhave: %q\nwant: %q", have, want)
+	}
+	// Parsing the formatted ABI must produce it again
+	reparsed, err := ParseHumanReadable(have)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := reparsed.HumanReadable(); !reflect.DeepEqual(again, have) {
+		t.Fatalf("round trip mismatch:\nhave: %q\nwant: %q", again, have)
+	}
+	// Structs of the JSON ABI are referenced by their internal type names
+	json, err := JSON(strings.NewReader(`[
+		{"type":"function","name":"set","stateMutability":"nonpayable","inputs":[
+			{"name":"f","type":"tuple[]","internalType":"struct Lib.F[]","components":[
+				{"name":"a","type":"uint256","internalType":"uint256"},
+				{"name":"g","type":"tuple","internalType":"struct G","components":[{"name":"b","type":"bytes","internalType":"bytes"}]}
+			]}
+		],"outputs":[]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"struct G { bytes b; }",
+		"struct LibF { uint256 a; G g; }",
+		"function set(LibF[] f)",
+	}
+	if have := json.HumanReadable(); !reflect.DeepEqual(have, want) {
+		t.Fatalf("formatted JSON ABI mismatch:\nhave: %q\nwant: %q", have, want)
+	}
+	reparsed, err = ParseHumanReadable(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := reparsed.Methods["set"].Sig, json.Methods["set"].Sig; have != want {
+		t.Fatalf("round trip signature mismatch: have %q, want %q", have, want)
+	}
+}
+
+func TestParseHumanReadableErrors(t *testing.T) {
+	tests := []struct {
+		signature string
+		err       string
+	}{
+		{"function", "expected identifier, got end of input"},
+		{"function foo(uint256", "expected ')', got end of input"},
+		{"function foo(uint256 a b)", "expected ',' or ')', got 'b'"},
+		{"function foo() returns", "expected '(', got end of input"},
+		{"function foo() mutable", "unexpected modifier 'mutable'"},
+		{"function foo(uint256 indexed a)", "only event parameters can be indexed"},
+		{"function foo(uint256[x] a)", "expected ']', got 'x'"},
+		{"function foo(Unknown a)", "unsupported arg type: Unknown"},
+		{"function foo(Self a)", "recursive struct 'Self'"},
+		{"function foo() extra", "unexpected modifier 'extra'"},
+		{"event Foo() bar", "unexpected 'bar'"},
+		{"modifier onlyOwner()", "unknown signature type 'modifier'"},
+		{"function foo(uint256 #)", "unexpected character '#' at position 21"},
+		{"struct Empty {}", "empty struct"},
+		{"struct Point { uint256 x }", "expected ';', got '}'"},
+		{"struct Point { uint256; }", "struct field 0 has no name"},
+		{"function foo(())", "empty tuple"},
+		{"receive() external", "the statemutability of receive can only be payable"},
+		{"event Foo(uint8 indexed a, uint8 indexed b, uint8 indexed c, uint8 indexed d)", "too many indexed parameters: have 4, max 3"},
+		{"event Foo(uint8 indexed a, uint8 indexed b, uint8 indexed c, uint8 indexed d, uint8 indexed e) anonymous", "too many indexed parameters: have 5, max 4"},
+		{"function foo() view view", "repeated modifier 'view'"},
+		{"function foo() external external", "repeated modifier 'external'"},
+		{"function foo() view pure", "conflicting modifiers 'view' and 'pure'"},
+		{"function foo(uint256[0] a)", "zero-length array '0'"},
+		{"function foo(uint256[2][00] a)", "zero-length array '00'"},
+		{"function foo(uint256 a, bool a)", "duplicate parameter name 'a'"},
+		{"function foo() returns (uint256 a, uint256 a)", "duplicate parameter name 'a'"},
+		{"function foo((uint256 x, uint256 x) p)", "duplicate parameter name 'x'"},
+		{"struct Point { uint256 x; uint256 x; }", "duplicate parameter name 'x'"},
+	}
+	for _, tt := range tests {
+		_, err := ParseHumanReadable([]string{tt.signature, "struct Self { Self self; }"})
+		if err == nil {
+			t.Errorf("%q: expected error", tt.signature)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%q: have error %q, want %q", tt.signature, err, tt.err)
+		}
+	}
+	if _, err := ParseHumanReadable([]string{"struct A { uint256 a; }", "struct A { uint256 b; }"}); err == nil {
+		t.Error("duplicate struct declaration accepted")
+	}
+	if _, err := ParseHumanReadable([]string{"event Foo(uint8 indexed a, uint8 indexed b, uint8 indexed c, uint8 indexed d) anonymous"}); err != nil {
+		t.Errorf("anonymous event with four indexed parameters rejected: %v", err)
+	}
+}
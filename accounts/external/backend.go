@@ -218,6 +218,16 @@ func (api *ExternalSigner) SignTx(account accounts.Account, tx *types.Transactio
 	case types.DynamicFeeTxType:
 		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
 		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	case types.BlobTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		args.BlobFeeCap = (*hexutil.Big)(tx.BlobGasFeeCap())
+		args.BlobHashes = tx.BlobHashes()
+		if sidecar := tx.BlobTxSidecar(); sidecar != nil {
+			args.Blobs = sidecar.Blobs
+			args.Commitments = sidecar.Commitments
+			args.Proofs = sidecar.Proofs
+		}
 	default:
 		return nil, fmt.Errorf("unsupported tx type %d", tx.Type())
 	}
@@ -239,6 +249,14 @@ func (api *ExternalSigner) SignTx(account accounts.Account, tx *types.Transactio
 	if err := api.client.Call(&res, "account_signTransaction", args); err != nil {
 		return nil, err
 	}
+	// The JSON encoding drops the blob sidecar, decode the raw transaction instead
+	if tx.Type() == types.BlobTxType {
+		signed := new(types.Transaction)
+		if err := signed.UnmarshalBinary(res.Raw); err != nil {
+			return nil, err
+		}
+		return signed, nil
+	}
 	return res.Tx, nil
 }
 
@@ -0,0 +1,137 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hdwallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// hardenedOffset is the first child index of hardened derivation.
+const hardenedOffset = 0x80000000
+
+var (
+	// masterKeySalt is the HMAC key deriving the master key from a seed.
+	masterKeySalt = []byte("Bitcoin seed")
+
+	// errInvalidKey is returned if a derived key is outside the range of the
+	// curve order, which happens with a probability lower than 1 in 2^127.
+	errInvalidKey = errors.New("derived key invalid, use another path")
+)
+
+// extendedKey is a BIP-32 extended private key.
+type extendedKey struct {
+	key   []byte // 32 byte private key
+	chain []byte // 32 byte chain code
+}
+
+// newMasterKey derives the master extended key from a seed.
+func newMasterKey(seed []byte) (*extendedKey, error) {
+	mac := hmac.New(sha512.New, masterKeySalt)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key := &extendedKey{key: sum[:32], chain: sum[32:]}
+	if k := new(big.Int).SetBytes(key.key); k.Sign() == 0 || k.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, errInvalidKey
+	}
+	return key, nil
+}
+
+// child derives the child extended key at the given index. Indices from 2^31
+// up are derived hardened.
+func (k *extendedKey) child(index uint32) (*extendedKey, error) {
+	// Hardened children are derived from the private key, normal ones from
+	// the compressed public key
+	var data []byte
+	if index >= hardenedOffset {
+		data = append([]byte{0x00}, k.key...)
+	} else {
+		priv, err := crypto.ToECDSA(k.key)
+		if err != nil {
+			return nil, err
+		}
+		data = crypto.CompressPubkey(&priv.PublicKey)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chain)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	// The child key is the parent key tweaked by the first half of the HMAC
+	var (
+		n     = crypto.S256().Params().N
+		tweak = new(big.Int).SetBytes(sum[:32])
+	)
+	if tweak.Cmp(n) >= 0 {
+		return nil, errInvalidKey
+	}
+	key := tweak.Add(tweak, new(big.Int).SetBytes(k.key))
+	key.Mod(key, n)
+	if key.Sign() == 0 {
+		return nil, errInvalidKey
+	}
+	return &extendedKey{key: math.PaddedBigBytes(key, 32), chain: sum[32:]}, nil
+}
+
+// derive derives the extended key at the given path below the key.
+func (k *extendedKey) derive(path accounts.DerivationPath) (*extendedKey, error) {
+	key := k
+	for _, index := range path {
+		child, err := key.child(index)
+		if key != k {
+			key.zero()
+		}
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+// privateKey derives the private key at the given path below the key.
+func (k *extendedKey) privateKey(path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	key, err := k.derive(path)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := crypto.ToECDSA(key.key)
+	if key != k {
+		key.zero()
+	}
+	return priv, err
+}
+
+// zero overwrites the private key and chain code in memory.
+func (k *extendedKey) zero() {
+	for i := range k.key {
+		k.key[i] = 0
+	}
+	for i := range k.chain {
+		k.chain[i] = 0
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hdwallet
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// Tests key derivation against test vector 1 of BIP-32.
+func TestDeriveVectors(t *testing.T) {
+	master, err := newMasterKey(common.FromHex("000102030405060708090a0b0c0d0e0f"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{"m", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{"m/0'/1/2'/2", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	for _, tt := range tests {
+		var path accounts.DerivationPath
+		if tt.path != "m" {
+			if path, err = accounts.ParseDerivationPath(tt.path); err != nil {
+				t.Fatalf("%s: %v", tt.path, err)
+			}
+		}
+		key, err := master.privateKey(path)
+		if err != nil {
+			t.Fatalf("%s: derivation failed: %v", tt.path, err)
+		}
+		if have := common.Bytes2Hex(crypto.FromECDSA(key)); have != tt.key {
+			t.Errorf("%s: key mismatch: have %s, want %s", tt.path, have, tt.key)
+		}
+	}
+	// Derivation must not have touched the master key
+	if have := common.Bytes2Hex(master.key); have != tests[0].key {
+		t.Errorf("master key modified: have %s, want %s", have, tests[0].key)
+	}
+}
+
+// Tests that accounts derived from a mnemonic match other wallets.
+func TestDeriveMnemonic(t *testing.T) {
+	seed := bip39.NewSeed("test test test test test test test test test test test junk", "")
+	master, err := newMasterKey(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path    string
+		address common.Address
+	}{
+		{"m/44'/60'/0'/0/0", common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")},
+		{"m/44'/60'/0'/0/1", common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")},
+	}
+	for _, tt := range tests {
+		key, err := master.privateKey(mustParsePath(t, tt.path))
+		if err != nil {
+			t.Fatalf("%s: derivation failed: %v", tt.path, err)
+		}
+		if have := crypto.PubkeyToAddress(key.PublicKey); have != tt.address {
+			t.Errorf("%s: address mismatch: have %x, want %x", tt.path, have, tt.address)
+		}
+	}
+}
+
+func mustParsePath(t *testing.T, path string) accounts.DerivationPath {
+	t.Helper()
+	p, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
@@ -0,0 +1,239 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package hdwallet implements software hierarchical deterministic wallets.
+//
+// A wallet is created by importing a BIP-39 mnemonic. Its seed is stored in a
+// file encrypted with a passphrase, the same way keystore keys are, and accounts
+// are derived from it according to BIP-32.
+//
+// EIP-2335 keystores are not supported: they hold BLS12-381 validator keys of the
+// consensus layer, which can't sign execution layer transactions.
+package hdwallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/google/uuid"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// Scheme is the protocol scheme prefixing wallet and account URLs.
+const Scheme = "hd"
+
+// version is the version of the wallet file format.
+const version = 1
+
+// HubType is the reflect type of an HD wallet hub backend.
+var HubType = reflect.TypeOf(&Hub{})
+
+// ErrWalletExists is returned if a mnemonic is imported which is already present
+// in the hub.
+var ErrWalletExists = errors.New("wallet already exists")
+
+// walletJSON is the on-disk format of a wallet, holding the encrypted seed and
+// the accounts pinned to the wallet.
+type walletJSON struct {
+	Crypto   keystore.CryptoJSON `json:"crypto"`
+	Id       string              `json:"id"`
+	Version  int                 `json:"version"`
+	Accounts []pinnedJSON        `json:"accounts"`
+}
+
+// pinnedJSON is an account pinned to a wallet.
+type pinnedJSON struct {
+	Address common.Address `json:"address"`
+	Path    string         `json:"path"`
+}
+
+// Hub is an accounts.Backend managing the software HD wallets stored in a
+// directory, one wallet per file.
+type Hub struct {
+	dir     string // Directory of the wallet files
+	scryptN int    // Scrypt params of the wallet file encryption
+	scryptP int
+
+	wallets     []*wallet               // Wallets loaded from the directory, sorted by URL
+	updateFeed  event.Feed              // Event feed to notify wallet additions
+	updateScope event.SubscriptionScope // Subscription scope tracking current live listeners
+
+	lock sync.RWMutex
+}
+
+// NewHub creates a hub of the wallets stored in the given directory, using the
+// scrypt params to encrypt newly imported wallets. The directory is created on
+// the first import if it doesn't exist.
+func NewHub(dir string, scryptN, scryptP int) (*Hub, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	hub := &Hub{dir: dir, scryptN: scryptN, scryptP: scryptP}
+
+	files, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, file := range files {
+		// Skip hidden and temporary files, directories and special files
+		if strings.HasPrefix(file.Name(), ".") || !file.Type().IsRegular() {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		w, err := loadWallet(hub, path)
+		if err != nil {
+			log.Warn("Failed to load HD wallet", "path", path, "err", err)
+			continue
+		}
+		hub.wallets = append(hub.wallets, w)
+	}
+	sort.Slice(hub.wallets, func(i, j int) bool { return hub.wallets[i].url.Cmp(hub.wallets[j].url) < 0 })
+	return hub, nil
+}
+
+// Wallets implements accounts.Backend, returning all the wallets of the hub.
+func (hub *Hub) Wallets() []accounts.Wallet {
+	hub.lock.RLock()
+	defer hub.lock.RUnlock()
+
+	cpy := make([]accounts.Wallet, len(hub.wallets))
+	for i, w := range hub.wallets {
+		cpy[i] = w
+	}
+	return cpy
+}
+
+// Subscribe implements accounts.Backend, creating an async subscription to
+// receive notifications on the addition of wallets and their opening.
+func (hub *Hub) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return hub.updateScope.Track(hub.updateFeed.Subscribe(sink))
+}
+
+// ImportMnemonic creates a wallet from a BIP-39 mnemonic, storing its seed
+// encrypted with the passphrase. The mnemonicPassword is the optional BIP-39
+// passphrase extending the mnemonic, which is empty for most wallets.
+//
+// The first account along the default derivation path is pinned to the new
+// wallet, which is returned locked.
+func (hub *Hub) ImportMnemonic(mnemonic, mnemonicPassword, passphrase string) (accounts.Wallet, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, mnemonicPassword)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(seed)
+
+	// Derive the first account to name the wallet file after
+	master, err := newMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	defer master.zero()
+
+	path := accounts.DefaultBaseDerivationPath
+	key, err := master.privateKey(path)
+	if err != nil {
+		return nil, err
+	}
+	first := pinnedJSON{Address: crypto.PubkeyToAddress(key.PublicKey), Path: path.String()}
+	zeroKey(key)
+
+	// Encrypt the seed and store the wallet, unless it already exists
+	cryptoJSON, err := keystore.EncryptDataV3(seed, []byte(passphrase), hub.scryptN, hub.scryptP)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+
+	file := filepath.Join(hub.dir, fmt.Sprintf("%x.json", first.Address))
+	if _, err := os.Stat(file); err == nil {
+		return nil, ErrWalletExists
+	}
+	w := newWallet(hub, file, &walletJSON{
+		Crypto:   cryptoJSON,
+		Id:       id.String(),
+		Version:  version,
+		Accounts: []pinnedJSON{first},
+	})
+	if err := w.store(); err != nil {
+		return nil, err
+	}
+	hub.wallets = append(hub.wallets, w)
+	sort.Slice(hub.wallets, func(i, j int) bool { return hub.wallets[i].url.Cmp(hub.wallets[j].url) < 0 })
+
+	go hub.updateFeed.Send(accounts.WalletEvent{Wallet: w, Kind: accounts.WalletArrived})
+	return w, nil
+}
+
+// loadWallet loads a wallet from its file.
+func loadWallet(hub *Hub, file string) (*wallet, error) {
+	blob, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var data walletJSON
+	if err := json.Unmarshal(blob, &data); err != nil {
+		return nil, err
+	}
+	if data.Version != version {
+		return nil, fmt.Errorf("unsupported version %d", data.Version)
+	}
+	for _, pinned := range data.Accounts {
+		if _, err := accounts.ParseDerivationPath(pinned.Path); err != nil {
+			return nil, fmt.Errorf("invalid derivation path of account %x: %v", pinned.Address, err)
+		}
+	}
+	return newWallet(hub, file, &data), nil
+}
+
+// writeFile atomically writes a wallet file, only accessible by the user.
+func writeFile(file string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
@@ -0,0 +1,240 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hdwallet
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testMnemonic = "test test test test test test test test test test test junk"
+
+var (
+	testAccount0 = common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	testAccount1 = common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+)
+
+func tmpHub(t *testing.T, dir string) *Hub {
+	hub, err := NewHub(dir, keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hub
+}
+
+func TestImportMnemonic(t *testing.T) {
+	dir := t.TempDir()
+	hub := tmpHub(t, dir)
+
+	if _, err := hub.ImportMnemonic("test test test", "", "foo"); err == nil {
+		t.Fatal("imported invalid mnemonic")
+	}
+	w, err := hub.ImportMnemonic(testMnemonic, "", "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accs := w.Accounts(); len(accs) != 1 || accs[0].Address != testAccount0 {
+		t.Fatalf("wrong accounts after import: %v", accs)
+	}
+	if status, _ := w.Status(); status != "Locked" {
+		t.Fatalf("wrong status of imported wallet: %s", status)
+	}
+	if _, err := hub.ImportMnemonic(testMnemonic, "", "bar"); err != ErrWalletExists {
+		t.Fatalf("wrong error for duplicate import: have %v, want %v", err, ErrWalletExists)
+	}
+	// A different mnemonic password yields a different wallet
+	if _, err := hub.ImportMnemonic(testMnemonic, "pass", "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(hub.Wallets()); n != 2 {
+		t.Fatalf("wrong number of wallets: have %d, want 2", n)
+	}
+	// Wallets are loaded back from disk
+	reloaded := tmpHub(t, dir).Wallets()
+	if len(reloaded) != 2 {
+		t.Fatalf("wrong number of reloaded wallets: have %d, want 2", len(reloaded))
+	}
+	for _, rw := range reloaded {
+		if rw.URL() == w.URL() && !rw.Contains(accounts.Account{Address: testAccount0}) {
+			t.Fatal("reloaded wallet misses pinned account")
+		}
+	}
+}
+
+func TestWalletSigning(t *testing.T) {
+	hub := tmpHub(t, t.TempDir())
+	w, err := hub.ImportMnemonic(testMnemonic, "", "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := w.Accounts()[0]
+
+	// Locked wallets only sign with the passphrase
+	var authErr *accounts.AuthNeededError
+	if _, err := w.SignText(account, []byte("hello")); !errors.As(err, &authErr) {
+		t.Fatalf("wrong error signing with locked wallet: %v", err)
+	}
+	if _, err := w.SignTextWithPassphrase(account, "bar", []byte("hello")); err != keystore.ErrDecrypt {
+		t.Fatalf("wrong error signing with bad passphrase: have %v, want %v", err, keystore.ErrDecrypt)
+	}
+	sig, err := w.SignTextWithPassphrase(account, "foo", []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte("hello")), sig)
+	if err != nil || crypto.PubkeyToAddress(*pub) != testAccount0 {
+		t.Fatalf("signature by wrong account: %v", err)
+	}
+	if _, err := w.Derive(accounts.DefaultBaseDerivationPath, false); err != accounts.ErrWalletClosed {
+		t.Fatalf("wrong error deriving from locked wallet: have %v, want %v", err, accounts.ErrWalletClosed)
+	}
+	// Open wallets sign without passphrase
+	if err := w.Open("bar"); err != keystore.ErrDecrypt {
+		t.Fatalf("wrong error opening with bad passphrase: have %v, want %v", err, keystore.ErrDecrypt)
+	}
+	if err := w.Open("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Open("foo"); err != accounts.ErrWalletAlreadyOpen {
+		t.Fatalf("wrong error reopening wallet: have %v, want %v", err, accounts.ErrWalletAlreadyOpen)
+	}
+	chainID := big.NewInt(1337)
+	tx, err := w.SignTx(account, types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil), chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(chainID), tx); err != nil || from != testAccount0 {
+		t.Fatalf("transaction signed by wrong account: have %x, want %x (%v)", from, testAccount0, err)
+	}
+	if _, err := w.SignData(accounts.Account{Address: testAccount1}, accounts.MimetypeTypedData, nil); err != accounts.ErrUnknownAccount {
+		t.Fatalf("wrong error signing with unknown account: have %v, want %v", err, accounts.ErrUnknownAccount)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := w.Status(); status != "Locked" {
+		t.Fatalf("wrong status of closed wallet: %s", status)
+	}
+}
+
+func TestWalletDerivePin(t *testing.T) {
+	dir := t.TempDir()
+	hub := tmpHub(t, dir)
+	w, err := hub.ImportMnemonic(testMnemonic, "", "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Open("foo"); err != nil {
+		t.Fatal(err)
+	}
+	path := mustParsePath(t, "m/44'/60'/0'/0/1")
+
+	// Unpinned derivations are not tracked
+	account, err := w.Derive(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Address != testAccount1 || w.Contains(account) {
+		t.Fatalf("wrong unpinned derivation: %v", account)
+	}
+	if _, err := w.Derive(path, true); err != nil {
+		t.Fatal(err)
+	}
+	if !w.Contains(account) {
+		t.Fatal("pinned account not tracked")
+	}
+	// Pinned accounts survive closing and reloading the wallet
+	w.Close()
+	if !w.Contains(account) {
+		t.Fatal("pinned account dropped on close")
+	}
+	reloaded := tmpHub(t, dir).Wallets()[0]
+	if accs := reloaded.Accounts(); len(accs) != 2 || accs[1].Address != testAccount1 {
+		t.Fatalf("wrong accounts after reload: %v", accs)
+	}
+	if _, err := reloaded.SignTextWithPassphrase(account, "foo", []byte("hello")); err != nil {
+		t.Fatal("failed to sign with pinned account:", err)
+	}
+}
+
+// testChain is a chain state reader reporting nonces of a fixed set of accounts.
+type testChain struct {
+	nonces map[common.Address]uint64
+}
+
+func (c *testChain) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return new(big.Int), nil
+}
+
+func (c *testChain) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (c *testChain) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (c *testChain) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return c.nonces[account], nil
+}
+
+func TestWalletSelfDerive(t *testing.T) {
+	hub := tmpHub(t, t.TempDir())
+	w, err := hub.ImportMnemonic(testMnemonic, "", "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Open("foo"); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// Accounts 0 and 1 are used, account 2 is the first empty one
+	chain := &testChain{nonces: map[common.Address]uint64{testAccount0: 1, testAccount1: 3}}
+	w.SelfDerive([]accounts.DerivationPath{accounts.DefaultBaseDerivationPath}, chain)
+
+	// Derivation runs in the background, poll until it's done
+	var accs []accounts.Account
+	for i := 0; i < 50; i++ {
+		if accs = w.Accounts(); len(accs) == 3 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(accs) != 3 {
+		t.Fatalf("wrong number of self-derived accounts: have %d, want 3", len(accs))
+	}
+	if accs[0].Address != testAccount0 || accs[1].Address != testAccount1 {
+		t.Fatalf("wrong self-derived accounts: %v", accs)
+	}
+	// Self-derived accounts are dropped on close
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if accs := w.Accounts(); len(accs) != 1 {
+		t.Fatalf("wrong number of accounts after close: have %d, want 1", len(accs))
+	}
+}
@@ -0,0 +1,493 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hdwallet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// selfDeriveThrottling is the minimum time between account discoveries, to
+// avoid hammering the chain with requests.
+const selfDeriveThrottling = time.Second
+
+// errLocked is returned when signing with a locked wallet.
+var errLocked = accounts.NewAuthNeededError("password or open")
+
+// wallet is a software HD wallet, deriving accounts from a seed encrypted on
+// disk.
+type wallet struct {
+	hub  *Hub         // Hub the wallet belongs to
+	url  accounts.URL // Wallet URL, the path of its file
+	data *walletJSON  // On-disk contents of the wallet
+
+	accounts []accounts.Account                         // Pinned and self-derived accounts
+	paths    map[common.Address]accounts.DerivationPath // Derivation paths of the accounts
+	pinned   map[common.Address]bool                    // Accounts stored in the wallet file
+
+	master *extendedKey // Master key of the wallet, nil if locked
+
+	deriveNextPaths []accounts.DerivationPath // Next derivation paths for account auto-discovery (multiple bases supported)
+	deriveNextAddrs []common.Address          // Next derived account addresses for auto-discovery (multiple bases supported)
+	deriveChain     ethereum.ChainStateReader // Blockchain state reader to discover used account with
+	deriveReq       chan chan struct{}        // Channel to request a self-derivation on
+	deriveQuit      chan chan error           // Channel to terminate the self-deriver with
+
+	stateLock sync.RWMutex // Protects read and write access to the wallet struct fields
+	log       log.Logger   // Contextual logger to tag the wallet with its URL
+}
+
+// newWallet creates a locked wallet from its on-disk contents.
+func newWallet(hub *Hub, file string, data *walletJSON) *wallet {
+	w := &wallet{
+		hub:    hub,
+		url:    accounts.URL{Scheme: Scheme, Path: file},
+		data:   data,
+		paths:  make(map[common.Address]accounts.DerivationPath),
+		pinned: make(map[common.Address]bool),
+	}
+	w.log = log.New("url", w.url)
+	for _, pinned := range data.Accounts {
+		path, _ := accounts.ParseDerivationPath(pinned.Path) // Validated on load
+		w.track(pinned.Address, path)
+		w.pinned[pinned.Address] = true
+	}
+	return w
+}
+
+// track adds an account to the list of accounts of the wallet. The caller must
+// hold the state lock.
+func (w *wallet) track(addr common.Address, path accounts.DerivationPath) {
+	if _, ok := w.paths[addr]; ok {
+		return
+	}
+	w.accounts = append(w.accounts, accounts.Account{
+		Address: addr,
+		URL:     accounts.URL{Scheme: w.url.Scheme, Path: fmt.Sprintf("%s/%s", w.url.Path, path)},
+	})
+	w.paths[addr] = path
+}
+
+// store writes the wallet file, with the currently pinned accounts.
+func (w *wallet) store() error {
+	data := *w.data
+	data.Accounts = nil
+	for _, account := range w.accounts {
+		if w.pinned[account.Address] {
+			data.Accounts = append(data.Accounts, pinnedJSON{Address: account.Address, Path: w.paths[account.Address].String()})
+		}
+	}
+	content, err := json.Marshal(&data)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(w.url.Path, content); err != nil {
+		return err
+	}
+	w.data = &data
+	return nil
+}
+
+// URL implements accounts.Wallet, returning the URL of the wallet file.
+func (w *wallet) URL() accounts.URL {
+	return w.url
+}
+
+// Status implements accounts.Wallet, returning whether the wallet is locked.
+func (w *wallet) Status() (string, error) {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+
+	if w.master == nil {
+		return "Locked", nil
+	}
+	return "Unlocked", nil
+}
+
+// unlock decrypts the seed of the wallet and derives its master key.
+func (w *wallet) unlock(passphrase string) (*extendedKey, error) {
+	seed, err := keystore.DecryptDataV3(w.data.Crypto, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(seed)
+
+	return newMasterKey(seed)
+}
+
+// Open implements accounts.Wallet, decrypting the seed of the wallet with the
+// passphrase. Accounts are derived and signed with until the wallet is closed.
+func (w *wallet) Open(passphrase string) error {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+
+	if w.master != nil {
+		return accounts.ErrWalletAlreadyOpen
+	}
+	master, err := w.unlock(passphrase)
+	if err != nil {
+		return err
+	}
+	w.master = master
+
+	w.deriveReq = make(chan chan struct{})
+	w.deriveQuit = make(chan chan error)
+	go w.selfDerive()
+
+	// Notify anyone listening for wallet events that the wallet is accessible
+	go w.hub.updateFeed.Send(accounts.WalletEvent{Wallet: w, Kind: accounts.WalletOpened})
+
+	return nil
+}
+
+// Close implements accounts.Wallet, stopping the account discovery and wiping
+// the master key of the wallet from memory. Self-derived accounts are dropped,
+// pinned ones are retained.
+func (w *wallet) Close() error {
+	w.stateLock.RLock()
+	dQuit := w.deriveQuit
+	w.stateLock.RUnlock()
+
+	// Terminate the self-derivations
+	var derr error
+	if dQuit != nil {
+		errc := make(chan error)
+		dQuit <- errc
+		derr = <-errc
+	}
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+
+	w.deriveQuit = nil
+	w.deriveReq = nil
+
+	if w.master != nil {
+		w.master.zero()
+		w.master = nil
+	}
+	accs := w.accounts[:0]
+	for _, account := range w.accounts {
+		if w.pinned[account.Address] {
+			accs = append(accs, account)
+		} else {
+			delete(w.paths, account.Address)
+		}
+	}
+	w.accounts = accs
+	return derr
+}
+
+// Accounts implements accounts.Wallet, returning the list of accounts pinned to
+// the wallet. If self-derivation was enabled, the account list is periodically
+// expanded based on current chain state.
+func (w *wallet) Accounts() []accounts.Account {
+	// Attempt self-derivation if it's running
+	reqc := make(chan struct{}, 1)
+	select {
+	case w.deriveReq <- reqc:
+		// Self-derivation request accepted, wait for it
+		<-reqc
+	default:
+		// Self-derivation offline, throttled or busy, skip
+	}
+	// Return whatever account list we ended up with
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+
+	cpy := make([]accounts.Account, len(w.accounts))
+	copy(cpy, w.accounts)
+	return cpy
+}
+
+// selfDerive is an account derivation loop that upon request attempts to find
+// new non-zero accounts.
+func (w *wallet) selfDerive() {
+	w.log.Debug("HD wallet self-derivation started")
+	defer w.log.Debug("HD wallet self-derivation stopped")
+
+	// Execute self-derivations until termination
+	var (
+		reqc chan struct{}
+		errc chan error
+	)
+	for errc == nil {
+		// Wait until either derivation or termination is requested
+		select {
+		case errc = <-w.deriveQuit:
+			// Termination requested
+			continue
+		case reqc = <-w.deriveReq:
+			// Account discovery requested
+		}
+		// Derivation needs a chain, skip if unavailable
+		w.stateLock.RLock()
+		if w.deriveChain == nil {
+			w.stateLock.RUnlock()
+			reqc <- struct{}{}
+			continue
+		}
+		var (
+			paths []accounts.DerivationPath
+			addrs []common.Address
+
+			nextPaths = make([]accounts.DerivationPath, len(w.deriveNextPaths))
+			nextAddrs = append([]common.Address{}, w.deriveNextAddrs...)
+
+			ctx = context.Background()
+		)
+		for i, path := range w.deriveNextPaths {
+			nextPaths[i] = append(accounts.DerivationPath{}, path...)
+		}
+		for i := 0; i < len(nextAddrs); i++ {
+			for empty := false; !empty; {
+				// Retrieve the next derived Ethereum account
+				if nextAddrs[i] == (common.Address{}) {
+					key, err := w.master.privateKey(nextPaths[i])
+					if err != nil {
+						w.log.Warn("HD wallet account derivation failed", "path", nextPaths[i], "err", err)
+						break
+					}
+					nextAddrs[i] = crypto.PubkeyToAddress(key.PublicKey)
+					zeroKey(key)
+				}
+				// Check the account's status against the current chain state
+				balance, err := w.deriveChain.BalanceAt(ctx, nextAddrs[i], nil)
+				if err != nil {
+					w.log.Warn("HD wallet balance retrieval failed", "err", err)
+					break
+				}
+				nonce, err := w.deriveChain.NonceAt(ctx, nextAddrs[i], nil)
+				if err != nil {
+					w.log.Warn("HD wallet nonce retrieval failed", "err", err)
+					break
+				}
+				// Track the used accounts and the first empty one of the
+				// last base, which is where new funds would go
+				if balance.Sign() == 0 && nonce == 0 {
+					empty = true
+					if i < len(nextAddrs)-1 {
+						break
+					}
+				}
+				paths = append(paths, append(accounts.DerivationPath{}, nextPaths[i]...))
+				addrs = append(addrs, nextAddrs[i])
+
+				if _, known := w.paths[nextAddrs[i]]; !known {
+					w.log.Info("HD wallet discovered new account", "address", nextAddrs[i], "path", nextPaths[i], "balance", balance, "nonce", nonce)
+				}
+				// Fetch the next potential account
+				if !empty {
+					nextAddrs[i] = common.Address{}
+					nextPaths[i][len(nextPaths[i])-1]++
+				}
+			}
+		}
+		w.stateLock.RUnlock()
+
+		// Insert any accounts successfully derived and shift the derivation
+		// forward, unless the wallet was closed or reconfigured meanwhile
+		w.stateLock.Lock()
+		if w.master != nil && len(w.deriveNextAddrs) == len(nextAddrs) {
+			for i := range addrs {
+				w.track(addrs[i], paths[i])
+			}
+			w.deriveNextAddrs = nextAddrs
+			w.deriveNextPaths = nextPaths
+		}
+		w.stateLock.Unlock()
+
+		// Notify the user of termination and loop after a bit of time (to avoid trashing)
+		reqc <- struct{}{}
+		select {
+		case errc = <-w.deriveQuit:
+			// Termination requested, abort
+		case <-time.After(selfDeriveThrottling):
+			// Waited enough, willing to self-derive again
+		}
+	}
+	errc <- nil
+}
+
+// Contains implements accounts.Wallet, returning whether a particular account is
+// or is not pinned into this wallet instance.
+func (w *wallet) Contains(account accounts.Account) bool {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+
+	_, exists := w.paths[account.Address]
+	return exists
+}
+
+// Derive implements accounts.Wallet, deriving a new account at the specific
+// derivation path. If pin is set to true, the account will be added to the list
+// of tracked accounts and stored in the wallet file.
+func (w *wallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+
+	if w.master == nil {
+		return accounts.Account{}, accounts.ErrWalletClosed
+	}
+	key, err := w.master.privateKey(path)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	zeroKey(key)
+
+	account := accounts.Account{
+		Address: address,
+		URL:     accounts.URL{Scheme: w.url.Scheme, Path: fmt.Sprintf("%s/%s", w.url.Path, path)},
+	}
+	if !pin || w.pinned[address] {
+		return account, nil
+	}
+	w.track(address, path)
+	w.pinned[address] = true
+	if err := w.store(); err != nil {
+		delete(w.pinned, address)
+		return accounts.Account{}, err
+	}
+	return account, nil
+}
+
+// SelfDerive implements accounts.Wallet, trying to discover accounts that the
+// user used previously (based on the chain state), but ones that they did not
+// explicitly pin to the wallet manually.
+//
+// Note, self derivation will increment the last component of the specified path
+// opposed to descending into a child path to allow discovering accounts starting
+// from non zero components.
+func (w *wallet) SelfDerive(bases []accounts.DerivationPath, chain ethereum.ChainStateReader) {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+
+	w.deriveNextPaths = make([]accounts.DerivationPath, len(bases))
+	for i, base := range bases {
+		w.deriveNextPaths[i] = make(accounts.DerivationPath, len(base))
+		copy(w.deriveNextPaths[i][:], base[:])
+	}
+	w.deriveNextAddrs = make([]common.Address, len(bases))
+	w.deriveChain = chain
+}
+
+// privateKey derives the private key of an account of the wallet, temporarily
+// unlocking it with the passphrase if it isn't open.
+func (w *wallet) privateKey(account accounts.Account, passphrase *string) (*ecdsa.PrivateKey, error) {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+
+	path, ok := w.paths[account.Address]
+	if !ok {
+		return nil, accounts.ErrUnknownAccount
+	}
+	master := w.master
+	if master == nil {
+		if passphrase == nil {
+			return nil, errLocked
+		}
+		var err error
+		if master, err = w.unlock(*passphrase); err != nil {
+			return nil, err
+		}
+		defer master.zero()
+	}
+	return master.privateKey(path)
+}
+
+// signHash signs a hash with the key of an account.
+func (w *wallet) signHash(account accounts.Account, passphrase *string, hash []byte) ([]byte, error) {
+	key, err := w.privateKey(account, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key)
+	return crypto.Sign(hash, key)
+}
+
+// signTx signs a transaction with the key of an account.
+func (w *wallet) signTx(account accounts.Account, passphrase *string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	key, err := w.privateKey(account, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key)
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+}
+
+// SignData implements accounts.Wallet, signing the keccak256 hash of the data.
+func (w *wallet) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	return w.signHash(account, nil, crypto.Keccak256(data))
+}
+
+// SignDataWithPassphrase implements accounts.Wallet, signing the keccak256 hash
+// of the data with the wallet temporarily unlocked if it isn't open.
+func (w *wallet) SignDataWithPassphrase(account accounts.Account, passphrase, mimeType string, data []byte) ([]byte, error) {
+	return w.signHash(account, &passphrase, crypto.Keccak256(data))
+}
+
+// SignText implements accounts.Wallet, signing the hash of the given text in
+// the personal message format.
+func (w *wallet) SignText(account accounts.Account, text []byte) ([]byte, error) {
+	return w.signHash(account, nil, accounts.TextHash(text))
+}
+
+// SignTextWithPassphrase implements accounts.Wallet, signing the hash of the
+// given text with the wallet temporarily unlocked if it isn't open.
+func (w *wallet) SignTextWithPassphrase(account accounts.Account, passphrase string, text []byte) ([]byte, error) {
+	return w.signHash(account, &passphrase, accounts.TextHash(text))
+}
+
+// SignTx implements accounts.Wallet, signing the transaction with the key of the
+// account.
+func (w *wallet) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return w.signTx(account, nil, tx, chainID)
+}
+
+// SignTxWithPassphrase implements accounts.Wallet, signing the transaction with
+// the wallet temporarily unlocked if it isn't open.
+func (w *wallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return w.signTx(account, &passphrase, tx, chainID)
+}
+
+// zeroKey zeroes a private key in memory.
+func zeroKey(k *ecdsa.PrivateKey) {
+	b := k.D.Bits()
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// zeroBytes zeroes a byte slice in memory.
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -47,7 +47,9 @@ type Key struct {
 	PrivateKey *ecdsa.PrivateKey
 }
 
-type keyStore interface {
+// Storage is a backend of a KeyStore, persisting keys into files of the key
+// directory and loading them back.
+type Storage interface {
 	// Loads and decrypts the key from disk.
 	GetKey(addr common.Address, filename string, auth string) (*Key, error)
 	// Writes and encrypts the key.
@@ -171,7 +173,7 @@ func newKey(rand io.Reader) (*Key, error) {
 	return newKeyFromECDSA(privateKeyECDSA), nil
 }
 
-func storeNewKey(ks keyStore, rand io.Reader, auth string) (*Key, accounts.Account, error) {
+func storeNewKey(ks Storage, rand io.Reader, auth string) (*Key, accounts.Account, error) {
 	key, err := newKey(rand)
 	if err != nil {
 		return nil, accounts.Account{}, err
@@ -0,0 +1,198 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrKeyringMissing is returned by a Keyring if no secret is stored under
+	// the requested name.
+	ErrKeyringMissing = errors.New("secret not found in keyring")
+
+	// errNoKeyring is returned when a keyring operation is attempted on a
+	// keystore which isn't backed by a keyring.
+	errNoKeyring = errors.New("keystore is not backed by a keyring")
+)
+
+// Keyring is a secret store of the operating system, such as the Linux kernel
+// keyring, holding secrets by name.
+type Keyring interface {
+	// Get retrieves the secret stored under the given name, or ErrKeyringMissing
+	// if there is none.
+	Get(name string) ([]byte, error)
+
+	// Set stores a secret under the given name, replacing any previous one.
+	Set(name string, secret []byte) error
+
+	// Delete removes the secret stored under the given name, if any.
+	Delete(name string) error
+}
+
+// keyStoreKeyring is a storage backend holding the private keys in a keyring,
+// which allows them to be unlocked without a passphrase by UnlockFromKeyring.
+//
+// The keys are also kept as encrypted key files, which all operations taking a
+// passphrase decrypt, same as with the passphrase backend. Secrets stored in a
+// keyring of the operating system usually don't survive a reboot either.
+type keyStoreKeyring struct {
+	keyStorePassphrase
+	keyring Keyring
+}
+
+// NewKeyringStorage creates a storage backend for the given key directory which
+// holds the private keys in a keyring. The keys are also stored as files in the
+// key directory, encrypted with the passphrase using the given scrypt params.
+//
+// Keys found in the keyring can be unlocked without the passphrase, access
+// control is up to the keyring. Operations taking a passphrase still check it
+// against the key file.
+func NewKeyringStorage(keydir string, keyring Keyring, scryptN, scryptP int) Storage {
+	return &keyStoreKeyring{
+		keyStorePassphrase: keyStorePassphrase{keydir, scryptN, scryptP, false},
+		keyring:            keyring,
+	}
+}
+
+// keyringName returns the name of the secret holding the key of an address.
+func keyringName(addr common.Address) string {
+	return fmt.Sprintf("geth:keystore:%x", addr)
+}
+
+// getKeyringKey loads the key of an address from the keyring, without checking
+// any passphrase. It returns ErrKeyringMissing if the key is not in the keyring.
+func (ks *keyStoreKeyring) getKeyringKey(addr common.Address, filename string) (*Key, error) {
+	// The key file is the source of truth for the accounts in the keystore,
+	// ensure it exists and belongs to the address
+	keyjson, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Address string `json:"address"`
+		Id      string `json:"id"`
+	}
+	if err := json.Unmarshal(keyjson, &header); err != nil {
+		return nil, err
+	}
+	if common.HexToAddress(header.Address) != addr {
+		return nil, fmt.Errorf("key content mismatch: have account %s, want %x", header.Address, addr)
+	}
+	secret, err := ks.keyring.Get(keyringName(addr))
+	if err != nil {
+		return nil, err
+	}
+	privkey, err := crypto.ToECDSA(secret)
+	for i := range secret {
+		secret[i] = 0
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid key in keyring: %v", err)
+	}
+	key := &Key{
+		Address:    crypto.PubkeyToAddress(privkey.PublicKey),
+		PrivateKey: privkey,
+	}
+	if key.Address != addr {
+		zeroKey(privkey)
+		return nil, fmt.Errorf("keyring content mismatch: have account %x, want %x", key.Address, addr)
+	}
+	if key.Id, err = uuid.Parse(header.Id); err != nil {
+		// Key files of old formats might have no ID, same as the encrypted
+		// key decryption, assign a fresh one
+		if key.Id, err = uuid.NewRandom(); err != nil {
+			zeroKey(privkey)
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+func (ks *keyStoreKeyring) StoreKey(filename string, key *Key, auth string) error {
+	if err := ks.keyStorePassphrase.StoreKey(filename, key, auth); err != nil {
+		return err
+	}
+	return ks.keyring.Set(keyringName(key.Address), crypto.FromECDSA(key.PrivateKey))
+}
+
+// deleteKey removes the key of an address from the keyring.
+func (ks *keyStoreKeyring) deleteKey(addr common.Address) error {
+	return ks.keyring.Delete(keyringName(addr))
+}
+
+// InKeyring reports whether the key of an account is stored in the keyring of
+// the keystore, and can thus be unlocked without a passphrase.
+func (ks *KeyStore) InKeyring(a accounts.Account) bool {
+	storage, ok := ks.storage.(*keyStoreKeyring)
+	if !ok {
+		return false
+	}
+	secret, err := storage.keyring.Get(keyringName(a.Address))
+	for i := range secret {
+		secret[i] = 0
+	}
+	return err == nil
+}
+
+// UnlockFromKeyring unlocks an account indefinitely with the key stored in the
+// keyring of the keystore, without a passphrase. This is meant for unlocking
+// accounts on startup, all operations taking a passphrase still require the
+// passphrase of the key file.
+func (ks *KeyStore) UnlockFromKeyring(a accounts.Account) error {
+	storage, ok := ks.storage.(*keyStoreKeyring)
+	if !ok {
+		return errNoKeyring
+	}
+	a, err := ks.Find(a)
+	if err != nil {
+		return err
+	}
+	key, err := storage.getKeyringKey(a.Address, a.URL.Path)
+	if err != nil {
+		return err
+	}
+	return ks.unlock(a, key, 0)
+}
+
+// MigrateToKeyring decrypts the key file of an account with the passphrase and
+// stores the key in the keyring of the keystore, after which the account can be
+// unlocked without a passphrase by UnlockFromKeyring. The encrypted key file is left in place.
+func (ks *KeyStore) MigrateToKeyring(a accounts.Account, passphrase string) error {
+	storage, ok := ks.storage.(*keyStoreKeyring)
+	if !ok {
+		return errNoKeyring
+	}
+	a, err := ks.Find(a)
+	if err != nil {
+		return err
+	}
+	key, err := storage.keyStorePassphrase.GetKey(a.Address, a.URL.Path, passphrase)
+	if err != nil {
+		return err
+	}
+	defer zeroKey(key.PrivateKey)
+	return storage.keyring.Set(keyringName(a.Address), crypto.FromECDSA(key.PrivateKey))
+}
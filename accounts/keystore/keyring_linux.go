@@ -0,0 +1,97 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build linux
+
+package keystore
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// kernelKeyring is a Keyring storing the secrets as user keys in the Linux
+// kernel keyring.
+type kernelKeyring struct {
+	ring int // Serial number of the keyring holding the secrets
+}
+
+// NewKernelKeyring opens the persistent keyring of the current user in the Linux
+// kernel keyring. Keys stored in it survive the logout of the user, but expire
+// after a few days of not being accessed (3 by default) and are lost on reboot.
+//
+// If the kernel doesn't support persistent keyrings, the user keyring is used,
+// which lives as long as the user has any processes running.
+func NewKernelKeyring() (Keyring, error) {
+	ring, err := unix.KeyctlInt(unix.KEYCTL_GET_PERSISTENT, -1, unix.KEY_SPEC_USER_KEYRING, 0, 0)
+	if errors.Is(err, unix.EOPNOTSUPP) {
+		return &kernelKeyring{ring: unix.KEY_SPEC_USER_KEYRING}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &kernelKeyring{ring: ring}, nil
+}
+
+// find looks up the serial number of the key with the given name.
+func (kr *kernelKeyring) find(name string) (int, error) {
+	id, err := unix.KeyctlSearch(kr.ring, "user", name, 0)
+	if errors.Is(err, unix.ENOKEY) || errors.Is(err, unix.EKEYEXPIRED) || errors.Is(err, unix.EKEYREVOKED) {
+		return 0, ErrKeyringMissing
+	}
+	return id, err
+}
+
+// Get implements Keyring, reading the payload of the user key with the name.
+func (kr *kernelKeyring) Get(name string) ([]byte, error) {
+	id, err := kr.find(name)
+	if err != nil {
+		return nil, err
+	}
+	// Query the size of the payload first, then read it
+	size, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	secret := make([]byte, size)
+	n, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, secret, 0)
+	if err != nil {
+		return nil, err
+	}
+	return secret[:n], nil
+}
+
+// Set implements Keyring, adding a user key to the keyring. An existing key of
+// the same name is updated in place.
+func (kr *kernelKeyring) Set(name string, secret []byte) error {
+	_, err := unix.AddKey("user", name, secret, kr.ring)
+	return err
+}
+
+// Delete implements Keyring, unlinking the user key with the name from the
+// keyring.
+func (kr *kernelKeyring) Delete(name string) error {
+	id, err := kr.find(name)
+	if errors.Is(err, ErrKeyringMissing) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = unix.KeyctlInt(unix.KEYCTL_UNLINK, id, kr.ring, 0, 0)
+	return err
+}
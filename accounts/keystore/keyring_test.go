@@ -0,0 +1,231 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// memKeyring is an in-memory Keyring for testing.
+type memKeyring struct {
+	secrets map[string][]byte
+	lock    sync.Mutex
+}
+
+func newMemKeyring() *memKeyring {
+	return &memKeyring{secrets: make(map[string][]byte)}
+}
+
+func (kr *memKeyring) Get(name string) ([]byte, error) {
+	kr.lock.Lock()
+	defer kr.lock.Unlock()
+
+	secret, ok := kr.secrets[name]
+	if !ok {
+		return nil, ErrKeyringMissing
+	}
+	return bytes.Clone(secret), nil
+}
+
+func (kr *memKeyring) Set(name string, secret []byte) error {
+	kr.lock.Lock()
+	defer kr.lock.Unlock()
+
+	kr.secrets[name] = bytes.Clone(secret)
+	return nil
+}
+
+func (kr *memKeyring) Delete(name string) error {
+	kr.lock.Lock()
+	defer kr.lock.Unlock()
+
+	delete(kr.secrets, name)
+	return nil
+}
+
+func tmpKeyringKeyStore(t *testing.T, dir string) (*KeyStore, *memKeyring) {
+	keyring := newMemKeyring()
+	return NewKeyStoreWithStorage(dir, NewKeyringStorage(dir, keyring, veryLightScryptN, veryLightScryptP)), keyring
+}
+
+func TestKeyringKeyStore(t *testing.T) {
+	dir := t.TempDir()
+	ks, keyring := tmpKeyringKeyStore(t, dir)
+
+	a, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ks.InKeyring(a) {
+		t.Fatal("new key not stored in keyring")
+	}
+	// The key is unlocked from the keyring without a passphrase
+	if err := ks.UnlockFromKeyring(a); err != nil {
+		t.Fatal("failed to unlock from keyring:", err)
+	}
+	if err := ks.Lock(a.Address); err != nil {
+		t.Fatal(err)
+	}
+	// Operations taking a passphrase still check it against the key file
+	if err := ks.TimedUnlock(a, "", time.Millisecond); err != ErrDecrypt {
+		t.Fatalf("wrong unlock error for bogus passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	if _, err := ks.Export(a, "bogus", "bar"); err != ErrDecrypt {
+		t.Fatalf("wrong export error for bogus passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	if _, err := ks.SignHashWithPassphrase(a, "bogus", make([]byte, 32)); err != ErrDecrypt {
+		t.Fatalf("wrong signing error for bogus passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	if err := ks.Update(a, "bogus", "bar"); err != ErrDecrypt {
+		t.Fatalf("wrong update error for bogus passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	// The key file is still encrypted with the passphrase, which is needed
+	// once the key is gone from the keyring
+	if _, err := NewKeyStore(dir, veryLightScryptN, veryLightScryptP).Export(a, "foo", "bar"); err != nil {
+		t.Fatal("key file not encrypted with passphrase:", err)
+	}
+	keyring.Delete(keyringName(a.Address))
+	if err := ks.UnlockFromKeyring(a); err != ErrKeyringMissing {
+		t.Fatalf("wrong unlock error for missing key: have %v, want %v", err, ErrKeyringMissing)
+	}
+	if err := ks.Unlock(a, "foo"); err != nil {
+		t.Fatal("failed to unlock from key file:", err)
+	}
+	// Deleting the account drops the key from the keyring
+	if err := ks.MigrateToKeyring(a, "foo"); err != nil {
+		t.Fatal("failed to migrate:", err)
+	}
+	if err := ks.Delete(a, "foo"); err != nil {
+		t.Fatal("failed to delete account:", err)
+	}
+	if len(keyring.secrets) != 0 {
+		t.Fatal("key not deleted from keyring")
+	}
+}
+
+func TestKeyringMigration(t *testing.T) {
+	dir := t.TempDir()
+
+	// Create some accounts in a plain encrypted keystore
+	plain := NewKeyStore(dir, veryLightScryptN, veryLightScryptP)
+	a1, err := plain.NewAccount("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, err := plain.NewAccount("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, _ := tmpKeyringKeyStore(t, dir)
+	if ks.InKeyring(a1) || ks.InKeyring(a2) {
+		t.Fatal("keys in keyring before migration")
+	}
+	if plain.InKeyring(a1) {
+		t.Fatal("key in keyring of plain keystore")
+	}
+	if err := plain.MigrateToKeyring(a1, "foo"); err != errNoKeyring {
+		t.Fatalf("wrong migration error: have %v, want %v", err, errNoKeyring)
+	}
+	// Migrate the first account, only the right passphrase is accepted
+	if err := ks.MigrateToKeyring(a1, "bar"); err != ErrDecrypt {
+		t.Fatalf("wrong migration error: have %v, want %v", err, ErrDecrypt)
+	}
+	if err := ks.MigrateToKeyring(a1, "foo"); err != nil {
+		t.Fatal("failed to migrate:", err)
+	}
+	if !ks.InKeyring(a1) || ks.InKeyring(a2) {
+		t.Fatal("wrong accounts migrated")
+	}
+	if err := ks.UnlockFromKeyring(a1); err != nil {
+		t.Fatal("failed to unlock migrated account:", err)
+	}
+	if err := ks.UnlockFromKeyring(a2); err != ErrKeyringMissing {
+		t.Fatalf("wrong unlock error: have %v, want %v", err, ErrKeyringMissing)
+	}
+	if err := plain.UnlockFromKeyring(a1); err != errNoKeyring {
+		t.Fatalf("wrong unlock error: have %v, want %v", err, errNoKeyring)
+	}
+	// Migration leaves the key file encrypted with the passphrase
+	if err := plain.Unlock(a1, "foo"); err != nil {
+		t.Fatal("migrated key file changed:", err)
+	}
+}
+
+// Tests that keyring contents of a different key are rejected.
+func TestKeyringMismatch(t *testing.T) {
+	dir := t.TempDir()
+	ks, keyring := tmpKeyringKeyStore(t, dir)
+
+	a, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := crypto.GenerateKey()
+	keyring.Set(keyringName(a.Address), crypto.FromECDSA(other))
+
+	if err := ks.UnlockFromKeyring(a); err == nil {
+		t.Fatal("unlocked account with mismatching key in keyring")
+	}
+	// Key files with a different address are rejected before the keyring
+	// is consulted
+	storage := ks.storage.(*keyStoreKeyring)
+	if _, err := storage.getKeyringKey(crypto.PubkeyToAddress(other.PublicKey), a.URL.Path); err == nil {
+		t.Fatal("loaded key of mismatching key file")
+	}
+}
+
+func TestKernelKeyring(t *testing.T) {
+	keyring, err := NewKernelKeyring()
+	if err != nil {
+		t.Skip("kernel keyring unavailable:", err)
+	}
+	name := fmt.Sprintf("geth:test:%d", os.Getpid())
+	defer keyring.Delete(name)
+
+	if _, err := keyring.Get(name); !errors.Is(err, ErrKeyringMissing) {
+		t.Fatalf("wrong error for missing secret: have %v, want %v", err, ErrKeyringMissing)
+	}
+	for _, secret := range [][]byte{[]byte("secret"), []byte("updated secret")} {
+		if err := keyring.Set(name, secret); err != nil {
+			t.Skip("kernel keyring not writable:", err)
+		}
+		have, err := keyring.Get(name)
+		if err != nil {
+			t.Fatal("failed to read secret:", err)
+		}
+		if !bytes.Equal(have, secret) {
+			t.Fatalf("wrong secret: have %q, want %q", have, secret)
+		}
+	}
+	if err := keyring.Delete(name); err != nil {
+		t.Fatal("failed to delete secret:", err)
+	}
+	if _, err := keyring.Get(name); !errors.Is(err, ErrKeyringMissing) {
+		t.Fatalf("secret not deleted: %v", err)
+	}
+	if err := keyring.Delete(name); err != nil {
+		t.Fatal("failed to delete missing secret:", err)
+	}
+}
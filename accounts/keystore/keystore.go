@@ -60,7 +60,7 @@ const walletRefreshCycle = 3 * time.Second
 
 // KeyStore manages a key storage directory on disk.
 type KeyStore struct {
-	storage  keyStore                     // Storage backend, might be cleartext or encrypted
+	storage  Storage                      // Storage backend, might be cleartext or encrypted
 	cache    *accountCache                // In-memory account cache over the filesystem storage
 	changes  chan struct{}                // Channel receiving change notifications from the cache
 	unlocked map[common.Address]*unlocked // Currently unlocked account (decrypted private keys)
@@ -96,6 +96,17 @@ func NewPlaintextKeyStore(keydir string) *KeyStore {
 	return ks
 }
 
+// NewKeyStoreWithStorage creates a keystore for the given directory, persisting
+// the keys through a custom storage backend. The storage must keep a key file
+// for every account in the directory, which is what the account list is built
+// from.
+func NewKeyStoreWithStorage(keydir string, storage Storage) *KeyStore {
+	keydir, _ = filepath.Abs(keydir)
+	ks := &KeyStore{storage: storage}
+	ks.init(keydir)
+	return ks
+}
+
 func (ks *KeyStore) init(keydir string) {
 	// Lock the mutex since the account cache might call back with events
 	ks.mu.Lock()
@@ -254,6 +265,12 @@ func (ks *KeyStore) Delete(a accounts.Account, passphrase string) error {
 	if err == nil {
 		ks.cache.delete(a)
 		ks.refreshWallets()
+
+		// Drop the key from the keyring too, unless another key file of
+		// the same address remains in the keystore
+		if storage, ok := ks.storage.(*keyStoreKeyring); ok && !ks.cache.hasAddress(a.Address) {
+			err = storage.deleteKey(a.Address)
+		}
 	}
 	return err
 }
@@ -342,7 +359,12 @@ func (ks *KeyStore) TimedUnlock(a accounts.Account, passphrase string, timeout t
 	if err != nil {
 		return err
 	}
+	return ks.unlock(a, key, timeout)
+}
 
+// unlock stores the decrypted key of an account in memory until the timeout, or
+// indefinitely if the timeout is 0.
+func (ks *KeyStore) unlock(a accounts.Account, key *Key, timeout time.Duration) error {
 	ks.mu.Lock()
 	defer ks.mu.Unlock()
 	u, found := ks.unlocked[a.Address]
@@ -0,0 +1,270 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package blsync implements a beacon chain light client which drives an
+// execution client through the engine API. It follows the head and the
+// finalized block of the beacon chain using the light client endpoints of a
+// beacon node REST API, verifying all data against a trusted checkpoint.
+package blsync
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/beacon/light"
+	"github.com/ethereum/go-ethereum/beacon/light/api"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	slotDuration    = 12 * time.Second
+	retryDelay      = 5 * time.Second
+	maxUpdateFetch  = 16 // maximum number of committee updates per request
+	engineCallLimit = 30 * time.Second
+)
+
+// lightAPI is the beacon node API used by the client.
+type lightAPI interface {
+	GetCheckpointData(checkpointHash common.Hash) (*types.BootstrapData, error)
+	GetBestUpdatesAndCommittees(firstPeriod, count uint64) ([]*types.LightClientUpdate, []*types.SerializedSyncCommittee, error)
+	GetOptimisticUpdate() (types.OptimisticUpdate, error)
+	GetFinalityUpdate() (types.FinalityUpdate, error)
+	GetExecutionPayload(blockRoot common.Hash) (*engine.ExecutableData, []common.Hash, error)
+}
+
+// Client is the beacon light sync client. It is a node lifecycle, and is used
+// to track the chain head on nodes without a consensus client.
+type Client struct {
+	config Config
+	api    lightAPI
+	chain  *light.CommitteeChain
+	engine *rpc.Client
+	now    func() time.Time
+
+	headRoot  common.Hash // beacon root of the last head sent to the engine
+	finalRoot common.Hash // beacon root of the last finalized header sent to the engine
+
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewClient creates a light sync client.
+func NewClient(config Config) *Client {
+	config = config.withDefaults()
+	return newClient(config, api.NewBeaconLightApi(config.ApiURL, config.CustomHeaders))
+}
+
+func newClient(config Config, api lightAPI) *Client {
+	return &Client{
+		config:  config,
+		api:     api,
+		chain:   light.NewCommitteeChain(&config.ChainConfig, config.Threshold),
+		now:     time.Now,
+		closeCh: make(chan struct{}),
+	}
+}
+
+// SetEngineRPC sets the engine API endpoint of the execution client. It must be
+// called before Start.
+func (c *Client) SetEngineRPC(engine *rpc.Client) {
+	c.engine = engine
+}
+
+// Start launches the sync loop.
+func (c *Client) Start() error {
+	if c.engine == nil {
+		return errors.New("engine API endpoint not set")
+	}
+	log.Info("Starting beacon light client", "api", c.config.ApiURL, "checkpoint", c.config.Checkpoint)
+	c.wg.Add(1)
+	go c.loop()
+	return nil
+}
+
+// Stop terminates the sync loop.
+func (c *Client) Stop() error {
+	close(c.closeCh)
+	c.wg.Wait()
+	return nil
+}
+
+func (c *Client) loop() {
+	defer c.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if err := c.update(); err != nil {
+				log.Warn("Beacon light client update failed", "err", err)
+				timer.Reset(retryDelay)
+			} else {
+				timer.Reset(c.untilNextSlot())
+			}
+		case <-c.closeCh:
+			return
+		}
+	}
+}
+
+// update performs a single sync step: it extends the committee chain up to the
+// current period, then fetches and verifies the latest head and finalized
+// header, and forwards them to the execution client.
+func (c *Client) update() error {
+	if _, ok := c.chain.NextSyncPeriod(); !ok {
+		bootstrap, err := c.api.GetCheckpointData(c.config.Checkpoint)
+		if err != nil {
+			return fmt.Errorf("can't fetch checkpoint: %w", err)
+		}
+		if err := c.chain.CheckpointInit(bootstrap); err != nil {
+			return fmt.Errorf("invalid checkpoint: %w", err)
+		}
+	}
+	if err := c.syncCommittees(types.SyncPeriod(c.currentSlot())); err != nil {
+		return err
+	}
+
+	finality, err := c.api.GetFinalityUpdate()
+	if err != nil {
+		return fmt.Errorf("can't fetch finality update: %w", err)
+	}
+	if err := c.verifyHeader(finality.SignedHeader()); err != nil {
+		return fmt.Errorf("invalid finality update: %w", err)
+	}
+	optimistic, err := c.api.GetOptimisticUpdate()
+	if err != nil {
+		return fmt.Errorf("can't fetch optimistic update: %w", err)
+	}
+	if err := c.verifyHeader(optimistic.SignedHeader()); err != nil {
+		return fmt.Errorf("invalid optimistic update: %w", err)
+	}
+	head := optimistic.Attested
+	if finality.Attested.Beacon.Slot > head.Beacon.Slot {
+		head = finality.Attested
+	}
+	return c.updateEngine(head, finality.Finalized)
+}
+
+// syncCommittees extends the committee chain up to the given period.
+func (c *Client) syncCommittees(target uint64) error {
+	for {
+		next, _ := c.chain.NextSyncPeriod()
+		if next >= target {
+			return nil
+		}
+		count := target - next
+		if count > maxUpdateFetch {
+			count = maxUpdateFetch
+		}
+		updates, committees, err := c.api.GetBestUpdatesAndCommittees(next, count)
+		if err != nil {
+			return fmt.Errorf("can't fetch committee updates: %w", err)
+		}
+		if len(updates) == 0 {
+			return fmt.Errorf("no committee update available for period %d", next)
+		}
+		for i, update := range updates {
+			if err := c.chain.InsertUpdate(update, committees[i]); err != nil {
+				return fmt.Errorf("invalid committee update for period %d: %w", next+uint64(i), err)
+			}
+		}
+	}
+}
+
+// verifyHeader checks the signature of a header. If the header is signed by a
+// committee which isn't known yet, the committee chain is extended first.
+func (c *Client) verifyHeader(head types.SignedHeader) error {
+	err := c.chain.VerifySignedHeader(head)
+	if errors.Is(err, light.ErrNeedCommittee) {
+		if err := c.syncCommittees(types.SyncPeriod(head.SignatureSlot)); err != nil {
+			return err
+		}
+		err = c.chain.VerifySignedHeader(head)
+	}
+	return err
+}
+
+// updateEngine sends the payload of the given head to the execution client and
+// updates its fork choice.
+func (c *Client) updateEngine(head, finalized types.HeaderWithExecProof) error {
+	headRoot, finalRoot := head.Beacon.Hash(), finalized.Beacon.Hash()
+	if headRoot == c.headRoot && finalRoot == c.finalRoot {
+		return nil
+	}
+	payload, versionedHashes, err := c.api.GetExecutionPayload(headRoot)
+	if err != nil {
+		return fmt.Errorf("can't fetch execution payload: %w", err)
+	}
+	// The execution header is proven by the signed beacon header. Checking the
+	// block hash of the payload against it authenticates the whole payload.
+	if payload.BlockHash != head.Execution.BlockHash {
+		return fmt.Errorf("execution payload hash mismatch: have %v, want %v", payload.BlockHash, head.Execution.BlockHash)
+	}
+	var beaconRoot *common.Hash
+	if payload.BlobGasUsed != nil {
+		beaconRoot = &head.Beacon.ParentRoot
+	}
+	if _, err := engine.ExecutableDataToBlock(*payload, versionedHashes, beaconRoot); err != nil {
+		return fmt.Errorf("invalid execution payload: %w", err)
+	}
+
+	status, err := c.callNewPayload(payload, versionedHashes, beaconRoot)
+	if err != nil {
+		return fmt.Errorf("newPayload failed: %w", err)
+	}
+	if status.Status == engine.INVALID {
+		log.Error("Execution client rejected payload", "number", payload.Number, "hash", payload.BlockHash, "err", status.ValidationError)
+	}
+	fcs := engine.ForkchoiceStateV1{
+		HeadBlockHash:      head.Execution.BlockHash,
+		SafeBlockHash:      finalized.Execution.BlockHash,
+		FinalizedBlockHash: finalized.Execution.BlockHash,
+	}
+	resp, err := c.callForkchoiceUpdated(fcs, beaconRoot != nil)
+	if err != nil {
+		return fmt.Errorf("forkchoiceUpdated failed: %w", err)
+	}
+	c.headRoot, c.finalRoot = headRoot, finalRoot
+	log.Info("Beacon light client updated head", "slot", head.Beacon.Slot, "number", payload.Number, "hash", payload.BlockHash,
+		"finalized", finalized.Execution.BlockNumber, "status", resp.PayloadStatus.Status)
+	return nil
+}
+
+// currentSlot returns the slot of the current time.
+func (c *Client) currentSlot() uint64 {
+	now := uint64(c.now().Unix())
+	if now < c.config.ChainConfig.GenesisTime {
+		return 0
+	}
+	return (now - c.config.ChainConfig.GenesisTime) / uint64(slotDuration/time.Second)
+}
+
+// untilNextSlot returns the time until updates for the next slot are expected to
+// be available, which is one third into the slot.
+func (c *Client) untilNextSlot() time.Duration {
+	var (
+		genesis = time.Unix(int64(c.config.ChainConfig.GenesisTime), 0)
+		next    = genesis.Add(time.Duration(c.currentSlot()+1)*slotDuration + slotDuration/3)
+	)
+	return next.Sub(c.now())
+}
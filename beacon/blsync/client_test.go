@@ -0,0 +1,263 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package blsync
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/beacon/internal/lighttest"
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testAPI is a light API backed by in-memory data.
+type testAPI struct {
+	bootstrap  *types.BootstrapData
+	updates    map[uint64]*types.LightClientUpdate
+	committees map[uint64]*types.SerializedSyncCommittee
+	optimistic types.OptimisticUpdate
+	finality   types.FinalityUpdate
+	payloads   map[common.Hash]*engine.ExecutableData
+}
+
+func (api *testAPI) GetCheckpointData(checkpointHash common.Hash) (*types.BootstrapData, error) {
+	return api.bootstrap, nil
+}
+
+func (api *testAPI) GetBestUpdatesAndCommittees(firstPeriod, count uint64) ([]*types.LightClientUpdate, []*types.SerializedSyncCommittee, error) {
+	var (
+		updates    []*types.LightClientUpdate
+		committees []*types.SerializedSyncCommittee
+	)
+	for period := firstPeriod; period < firstPeriod+count; period++ {
+		if api.updates[period] == nil {
+			break
+		}
+		updates = append(updates, api.updates[period])
+		committees = append(committees, api.committees[period+1])
+	}
+	return updates, committees, nil
+}
+
+func (api *testAPI) GetOptimisticUpdate() (types.OptimisticUpdate, error) {
+	return api.optimistic, nil
+}
+
+func (api *testAPI) GetFinalityUpdate() (types.FinalityUpdate, error) {
+	return api.finality, nil
+}
+
+func (api *testAPI) GetExecutionPayload(blockRoot common.Hash) (*engine.ExecutableData, []common.Hash, error) {
+	return api.payloads[blockRoot], nil, nil
+}
+
+// testEngine records the engine API calls of the client.
+type testEngine struct {
+	payloads []common.Hash
+	fcs      []engine.ForkchoiceStateV1
+}
+
+func (e *testEngine) NewPayloadV2(payload engine.ExecutableData) engine.PayloadStatusV1 {
+	e.payloads = append(e.payloads, payload.BlockHash)
+	return engine.PayloadStatusV1{Status: engine.VALID, LatestValidHash: &payload.BlockHash}
+}
+
+func (e *testEngine) ForkchoiceUpdatedV2(fcs engine.ForkchoiceStateV1, attr *engine.PayloadAttributes) engine.ForkChoiceResponse {
+	e.fcs = append(e.fcs, fcs)
+	return engine.ForkChoiceResponse{PayloadStatus: engine.PayloadStatusV1{Status: engine.VALID}}
+}
+
+// makePayload creates a valid Shanghai execution payload.
+func makePayload(number uint64) *engine.ExecutableData {
+	header := &ctypes.Header{
+		Number:          new(big.Int).SetUint64(number),
+		Difficulty:      common.Big0,
+		GasLimit:        30000000,
+		Time:            lighttest.Config.GenesisTime + number*12,
+		BaseFee:         big.NewInt(7),
+		UncleHash:       ctypes.EmptyUncleHash,
+		TxHash:          ctypes.EmptyTxsHash,
+		ReceiptHash:     ctypes.EmptyReceiptsHash,
+		WithdrawalsHash: &ctypes.EmptyWithdrawalsHash,
+	}
+	block := ctypes.NewBlockWithHeader(header).WithWithdrawals([]*ctypes.Withdrawal{})
+	return engine.BlockToExecutableData(block, nil, nil).ExecutionPayload
+}
+
+// makeExecHeader creates a beacon header proving the given payload.
+func makeExecHeader(slot uint64, state common.Hash, payload *engine.ExecutableData) types.HeaderWithExecProof {
+	exec := &types.ExecutionHeader{
+		BlockNumber:   payload.Number,
+		GasLimit:      payload.GasLimit,
+		Timestamp:     payload.Timestamp,
+		BaseFeePerGas: payload.BaseFeePerGas,
+		BlockHash:     payload.BlockHash,
+	}
+	body := lighttest.Tree{params.BodyIndexExecPayload: merkle.Value(exec.Root())}
+	return types.HeaderWithExecProof{
+		Beacon:          types.Header{Slot: slot, StateRoot: state, BodyRoot: body.Root()},
+		Execution:       exec,
+		ExecutionBranch: body.Branch(params.BodyIndexExecPayload),
+	}
+}
+
+func newTestClient(t *testing.T, api *testAPI, now time.Time) (*Client, *testEngine) {
+	client := newClient(Config{ChainConfig: *lighttest.Config}.withDefaults(), api)
+	client.now = func() time.Time { return now }
+
+	var (
+		backend = new(testEngine)
+		server  = rpc.NewServer()
+	)
+	if err := server.RegisterName("engine", backend); err != nil {
+		t.Fatal(err)
+	}
+	client.SetEngineRPC(rpc.DialInProc(server))
+	t.Cleanup(server.Stop)
+	return client, backend
+}
+
+func TestClientUpdate(t *testing.T) {
+	var (
+		c0, c1 = lighttest.NewCommittee(0), lighttest.NewCommittee(1)
+		slot   = uint64(params.SyncPeriodLength + 100) // current slot, in period 1
+		now    = time.Unix(int64(lighttest.Config.GenesisTime+slot*12), 0)
+	)
+	// Checkpoint in period 0, and the update proving the committee of period 1.
+	checkpointState := lighttest.Tree{params.StateIndexSyncCommittee: merkle.Value(c0.Serialized.Root())}
+	checkpoint := types.Header{Slot: 10, StateRoot: checkpointState.Root()}
+	updateState := lighttest.Tree{params.StateIndexNextSyncCommittee: merkle.Value(c1.Serialized.Root())}
+	updateHeader := types.Header{Slot: 100, StateRoot: updateState.Root()}
+
+	// Finalized header and head, both signed by the committee of period 1.
+	var (
+		finalPayload = makePayload(1)
+		headPayload  = makePayload(2)
+		finalized    = makeExecHeader(slot-64, common.Hash{1}, finalPayload)
+		finalState   = lighttest.Tree{params.StateIndexFinalBlock: merkle.Value(finalized.Beacon.Hash())}
+		attested     = makeExecHeader(slot-2, finalState.Root(), makePayload(3))
+		head         = makeExecHeader(slot-1, common.Hash{2}, headPayload)
+	)
+	attestedSig := c1.Sign(attested.Beacon, slot-1, params.SyncCommitteeSize)
+	headSig := c1.Sign(head.Beacon, slot, params.SyncCommitteeSize)
+
+	api := &testAPI{
+		bootstrap: &types.BootstrapData{
+			Header:          checkpoint,
+			CommitteeRoot:   c0.Serialized.Root(),
+			Committee:       c0.Serialized,
+			CommitteeBranch: checkpointState.Branch(params.StateIndexSyncCommittee),
+		},
+		updates: map[uint64]*types.LightClientUpdate{
+			0: {
+				AttestedHeader:          c0.Sign(updateHeader, 101, params.SyncCommitteeSize),
+				NextSyncCommitteeRoot:   c1.Serialized.Root(),
+				NextSyncCommitteeBranch: updateState.Branch(params.StateIndexNextSyncCommittee),
+			},
+		},
+		committees: map[uint64]*types.SerializedSyncCommittee{1: c1.Serialized},
+		finality: types.FinalityUpdate{
+			Attested:       attested,
+			Finalized:      finalized,
+			FinalityBranch: finalState.Branch(params.StateIndexFinalBlock),
+			Signature:      attestedSig.Signature,
+			SignatureSlot:  attestedSig.SignatureSlot,
+		},
+		optimistic: types.OptimisticUpdate{
+			Attested:      head,
+			Signature:     headSig.Signature,
+			SignatureSlot: headSig.SignatureSlot,
+		},
+		payloads: map[common.Hash]*engine.ExecutableData{head.Beacon.Hash(): headPayload},
+	}
+	client, backend := newTestClient(t, api, now)
+	if err := client.update(); err != nil {
+		t.Fatal("update failed:", err)
+	}
+	if len(backend.payloads) != 1 || backend.payloads[0] != headPayload.BlockHash {
+		t.Fatalf("wrong payloads sent to engine: %v", backend.payloads)
+	}
+	want := engine.ForkchoiceStateV1{
+		HeadBlockHash:      headPayload.BlockHash,
+		SafeBlockHash:      finalPayload.BlockHash,
+		FinalizedBlockHash: finalPayload.BlockHash,
+	}
+	if len(backend.fcs) != 1 || backend.fcs[0] != want {
+		t.Fatalf("wrong fork choice updates: %v", backend.fcs)
+	}
+
+	// Unchanged heads are not sent again.
+	if err := client.update(); err != nil {
+		t.Fatal("update failed:", err)
+	}
+	if len(backend.payloads) != 1 || len(backend.fcs) != 1 {
+		t.Fatal("unchanged head sent to engine")
+	}
+}
+
+func TestClientRejectPayload(t *testing.T) {
+	var (
+		c0      = lighttest.NewCommittee(0)
+		payload = makePayload(1)
+		state   = lighttest.Tree{
+			params.StateIndexSyncCommittee: merkle.Value(c0.Serialized.Root()),
+		}
+		head = makeExecHeader(20, state.Root(), payload)
+		now  = time.Unix(int64(lighttest.Config.GenesisTime+22*12), 0)
+	)
+	finalState := lighttest.Tree{params.StateIndexFinalBlock: merkle.Value(head.Beacon.Hash())}
+	attested := makeExecHeader(21, finalState.Root(), makePayload(2))
+	attestedSig := c0.Sign(attested.Beacon, 22, params.SyncCommitteeSize)
+	headSig := c0.Sign(head.Beacon, 21, params.SyncCommitteeSize)
+
+	// The API serves a payload which doesn't match the signed execution header.
+	api := &testAPI{
+		bootstrap: &types.BootstrapData{
+			Header:          head.Beacon,
+			CommitteeRoot:   c0.Serialized.Root(),
+			Committee:       c0.Serialized,
+			CommitteeBranch: state.Branch(params.StateIndexSyncCommittee),
+		},
+		finality: types.FinalityUpdate{
+			Attested:       attested,
+			Finalized:      head,
+			FinalityBranch: finalState.Branch(params.StateIndexFinalBlock),
+			Signature:      attestedSig.Signature,
+			SignatureSlot:  attestedSig.SignatureSlot,
+		},
+		optimistic: types.OptimisticUpdate{
+			Attested:      head,
+			Signature:     headSig.Signature,
+			SignatureSlot: headSig.SignatureSlot,
+		},
+		payloads: map[common.Hash]*engine.ExecutableData{attested.Beacon.Hash(): makePayload(5)},
+	}
+	client, backend := newTestClient(t, api, now)
+	if err := client.update(); err == nil {
+		t.Fatal("invalid payload accepted")
+	}
+	if len(backend.payloads) != 0 || len(backend.fcs) != 0 {
+		t.Fatal("invalid payload sent to engine")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package blsync
+
+import (
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Config holds the configuration of the beacon light sync client.
+type Config struct {
+	ChainConfig   types.ChainConfig
+	Checkpoint    common.Hash       // trusted beacon block root where sync starts
+	ApiURL        string            // beacon node REST API endpoint
+	CustomHeaders map[string]string // HTTP headers added to API requests
+	Threshold     int               // minimum number of signers of accepted heads (default supermajority)
+}
+
+var (
+	// MainnetConfig is the beacon chain configuration of mainnet.
+	MainnetConfig = types.ChainConfig{
+		GenesisValidatorsRoot: common.HexToHash("0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95"),
+		GenesisTime:           1606824023,
+	}
+
+	// SepoliaConfig is the beacon chain configuration of the Sepolia testnet.
+	SepoliaConfig = types.ChainConfig{
+		GenesisValidatorsRoot: common.HexToHash("0xd8ea171f3c94aea21ebc42a1ed61052acf3f9209c00e4efbaaddac09ed9b8078"),
+		GenesisTime:           1655733600,
+	}
+
+	// HoleskyConfig is the beacon chain configuration of the Holesky testnet.
+	HoleskyConfig = types.ChainConfig{
+		GenesisValidatorsRoot: common.HexToHash("0x9143aa7c615a7f7115e2b6aac319c03529df8242ae705fba9df39b79c59fa8b1"),
+		GenesisTime:           1695902400,
+	}
+)
+
+func init() {
+	MainnetConfig.
+		AddFork("GENESIS", 0, []byte{0, 0, 0, 0}).
+		AddFork("ALTAIR", 74240, []byte{1, 0, 0, 0}).
+		AddFork("BELLATRIX", 144896, []byte{2, 0, 0, 0}).
+		AddFork("CAPELLA", 194048, []byte{3, 0, 0, 0})
+
+	SepoliaConfig.
+		AddFork("GENESIS", 0, []byte{0x90, 0x00, 0x00, 0x69}).
+		AddFork("ALTAIR", 50, []byte{0x90, 0x00, 0x00, 0x70}).
+		AddFork("BELLATRIX", 100, []byte{0x90, 0x00, 0x00, 0x71}).
+		AddFork("CAPELLA", 56832, []byte{0x90, 0x00, 0x00, 0x72})
+
+	HoleskyConfig.
+		AddFork("GENESIS", 0, []byte{0x01, 0x01, 0x70, 0x00}).
+		AddFork("ALTAIR", 0, []byte{0x02, 0x01, 0x70, 0x00}).
+		AddFork("BELLATRIX", 0, []byte{0x03, 0x01, 0x70, 0x00}).
+		AddFork("CAPELLA", 256, []byte{0x04, 0x01, 0x70, 0x00})
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Threshold == 0 {
+		cfg.Threshold = params.SyncCommitteeSupermajority
+	}
+	return cfg
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package blsync
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// callNewPayload sends an execution payload to the engine API. Payloads with blob
+// gas fields are sent using the Cancun version of the method.
+func (c *Client) callNewPayload(payload *engine.ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash) (engine.PayloadStatusV1, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), engineCallLimit)
+	defer cancel()
+
+	var (
+		status engine.PayloadStatusV1
+		err    error
+	)
+	if beaconRoot != nil {
+		if versionedHashes == nil {
+			versionedHashes = []common.Hash{}
+		}
+		err = c.engine.CallContext(ctx, &status, "engine_newPayloadV3", payload, versionedHashes, beaconRoot)
+	} else {
+		err = c.engine.CallContext(ctx, &status, "engine_newPayloadV2", payload)
+	}
+	return status, err
+}
+
+// callForkchoiceUpdated updates the fork choice of the execution client.
+func (c *Client) callForkchoiceUpdated(fcs engine.ForkchoiceStateV1, cancun bool) (engine.ForkChoiceResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), engineCallLimit)
+	defer cancel()
+
+	method := "engine_forkchoiceUpdatedV2"
+	if cancun {
+		method = "engine_forkchoiceUpdatedV3"
+	}
+	var resp engine.ForkChoiceResponse
+	err := c.engine.CallContext(ctx, &resp, method, fcs, nil)
+	return resp, err
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package lighttest implements helpers for testing the beacon light client.
+package lighttest
+
+import (
+	"crypto/sha256"
+
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	bls "github.com/protolambda/bls12-381-util"
+)
+
+// Config is the beacon chain configuration used in tests.
+var Config = (&types.ChainConfig{
+	GenesisTime:           1700000000,
+	GenesisValidatorsRoot: common.Hash{1},
+}).AddFork("GENESIS", 0, []byte{0, 0, 0, 0})
+
+// Committee is a sync committee for testing. To keep signing cheap, all members
+// of the committee share the same key.
+type Committee struct {
+	key        *bls.SecretKey
+	Serialized *types.SerializedSyncCommittee
+}
+
+// NewCommittee creates a committee. Committees created with different seeds have
+// different keys.
+func NewCommittee(seed byte) *Committee {
+	var (
+		keyBytes [32]byte
+		key      = new(bls.SecretKey)
+	)
+	keyBytes[31] = seed
+	keyBytes[30] = 1 // avoid the zero key
+	if err := key.Deserialize(&keyBytes); err != nil {
+		panic(err)
+	}
+	pub, err := bls.SkToPk(key)
+	if err != nil {
+		panic(err)
+	}
+	var (
+		c       = &Committee{key: key, Serialized: new(types.SerializedSyncCommittee)}
+		pubkeys = make([]*bls.Pubkey, params.SyncCommitteeSize)
+		enc     = pub.Serialize()
+	)
+	for i := range pubkeys {
+		pubkeys[i] = pub
+		copy(c.Serialized[i*params.BLSPubkeySize:], enc[:])
+	}
+	agg, err := bls.AggregatePubkeys(pubkeys)
+	if err != nil {
+		panic(err)
+	}
+	enc = agg.Serialize()
+	copy(c.Serialized[params.SyncCommitteeSize*params.BLSPubkeySize:], enc[:])
+	return c
+}
+
+// Sign creates a signature of the header by the given number of committee members.
+func (c *Committee) Sign(header types.Header, signatureSlot uint64, signers int) types.SignedHeader {
+	root, err := Config.Forks.SigningRoot(header)
+	if err != nil {
+		panic(err)
+	}
+	var (
+		sig  = bls.Sign(c.key, root[:])
+		sigs = make([]*bls.Signature, signers)
+		agg  types.SyncAggregate
+	)
+	for i := range sigs {
+		sigs[i] = sig
+		agg.Signers[i/8] |= 1 << (i % 8)
+	}
+	aggSig, err := bls.Aggregate(sigs)
+	if err != nil {
+		panic(err)
+	}
+	agg.Signature = aggSig.Serialize()
+	return types.SignedHeader{Header: header, Signature: agg, SignatureSlot: signatureSlot}
+}
+
+// Tree is a sparse binary merkle tree, mapping generalized indices to values.
+// Nodes which are neither set nor have a set descendant are zero. It can be
+// used to create consistent proofs of multiple values.
+type Tree map[uint64]merkle.Value
+
+// Root returns the root hash of the tree.
+func (t Tree) Root() common.Hash {
+	return common.Hash(t.node(1))
+}
+
+// Branch returns the proof of the value at the given index.
+func (t Tree) Branch(index uint64) merkle.Values {
+	var branch merkle.Values
+	for ; index > 1; index >>= 1 {
+		branch = append(branch, t.node(index^1))
+	}
+	return branch
+}
+
+func (t Tree) node(index uint64) merkle.Value {
+	if v, ok := t[index]; ok {
+		return v
+	}
+	if !t.hasDescendant(index) {
+		return merkle.Value{}
+	}
+	var (
+		left, right = t.node(index * 2), t.node(index*2 + 1)
+		hasher      = sha256.New()
+		v           merkle.Value
+	)
+	hasher.Write(left[:])
+	hasher.Write(right[:])
+	hasher.Sum(v[:0])
+	return v
+}
+
+func (t Tree) hasDescendant(index uint64) bool {
+	for i := range t {
+		for i > index {
+			i >>= 1
+		}
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
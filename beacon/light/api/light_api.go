@@ -0,0 +1,394 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package api implements a client for the light client endpoints of the beacon
+// node REST API.
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const requestTimeout = 10 * time.Second
+
+var ErrNotFound = errors.New("404 Not Found")
+
+// BeaconLightApi requests light client information from a beacon node REST API.
+//
+// Note: all required API endpoints are currently only implemented by Lodestar,
+// Nimbus and Prysm.
+type BeaconLightApi struct {
+	url           string
+	client        *http.Client
+	customHeaders map[string]string
+}
+
+// NewBeaconLightApi creates an API client for the beacon node at the given URL.
+// The custom headers are added to every request, e.g. for authentication.
+func NewBeaconLightApi(url string, customHeaders map[string]string) *BeaconLightApi {
+	return &BeaconLightApi{
+		url:           strings.TrimSuffix(url, "/"),
+		client:        &http.Client{Timeout: requestTimeout},
+		customHeaders: customHeaders,
+	}
+}
+
+func (api *BeaconLightApi) httpGet(path string) ([]byte, error) {
+	req, err := http.NewRequest("GET", api.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range api.customHeaders {
+		req.Header.Set(k, v)
+	}
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case 200:
+		return io.ReadAll(resp.Body)
+	case 404:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("unexpected error from API endpoint %q: status %d", path, resp.StatusCode)
+	}
+}
+
+type jsonHeaderWithExecProof struct {
+	Beacon          types.Header           `json:"beacon"`
+	Execution       *types.ExecutionHeader `json:"execution"`
+	ExecutionBranch merkle.Values          `json:"execution_branch"`
+}
+
+func (h *jsonHeaderWithExecProof) header() types.HeaderWithExecProof {
+	return types.HeaderWithExecProof{
+		Beacon:          h.Beacon,
+		Execution:       h.Execution,
+		ExecutionBranch: h.ExecutionBranch,
+	}
+}
+
+type jsonLightClientUpdate struct {
+	AttestedHeader          jsonHeaderWithExecProof        `json:"attested_header"`
+	NextSyncCommittee       *types.SerializedSyncCommittee `json:"next_sync_committee"`
+	NextSyncCommitteeBranch merkle.Values                  `json:"next_sync_committee_branch"`
+	FinalizedHeader         *jsonHeaderWithExecProof       `json:"finalized_header,omitempty"`
+	FinalityBranch          merkle.Values                  `json:"finality_branch,omitempty"`
+	SyncAggregate           types.SyncAggregate            `json:"sync_aggregate"`
+	SignatureSlot           common.Decimal                 `json:"signature_slot"`
+}
+
+// GetBestUpdatesAndCommittees fetches and validates the best committee updates
+// of count periods starting from firstPeriod, along with the committees they
+// prove. The sync committee signatures are not checked.
+//
+// See data structure definition here:
+// https://github.com/ethereum/beacon-APIs/blob/master/apis/beacon/light_client/updates.yaml
+func (api *BeaconLightApi) GetBestUpdatesAndCommittees(firstPeriod, count uint64) ([]*types.LightClientUpdate, []*types.SerializedSyncCommittee, error) {
+	resp, err := api.httpGet(fmt.Sprintf("/eth/v1/beacon/light_client/updates?start_period=%d&count=%d", firstPeriod, count))
+	if err != nil {
+		return nil, nil, err
+	}
+	var data []struct {
+		Data jsonLightClientUpdate `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, nil, err
+	}
+	if len(data) > int(count) {
+		return nil, nil, errors.New("invalid number of committee updates")
+	}
+	var (
+		updates    = make([]*types.LightClientUpdate, len(data))
+		committees = make([]*types.SerializedSyncCommittee, len(data))
+	)
+	for i, d := range data {
+		if d.Data.NextSyncCommittee == nil {
+			return nil, nil, errors.New("missing next sync committee")
+		}
+		update := &types.LightClientUpdate{
+			AttestedHeader: types.SignedHeader{
+				Header:        d.Data.AttestedHeader.Beacon,
+				Signature:     d.Data.SyncAggregate,
+				SignatureSlot: uint64(d.Data.SignatureSlot),
+			},
+			NextSyncCommitteeRoot:   d.Data.NextSyncCommittee.Root(),
+			NextSyncCommitteeBranch: d.Data.NextSyncCommitteeBranch,
+		}
+		// The finalized header is only relevant if it belongs to the same period.
+		// It is not needed for committee updates, so it's dropped otherwise.
+		if fh := d.Data.FinalizedHeader; fh != nil && fh.Beacon.SyncPeriod() == update.AttestedHeader.Header.SyncPeriod() {
+			update.FinalizedHeader = &fh.Beacon
+			update.FinalityBranch = d.Data.FinalityBranch
+		}
+		if update.AttestedHeader.Header.SyncPeriod() != firstPeriod+uint64(i) {
+			return nil, nil, errors.New("wrong committee update period")
+		}
+		if err := update.Validate(); err != nil {
+			return nil, nil, err
+		}
+		updates[i], committees[i] = update, d.Data.NextSyncCommittee
+	}
+	return updates, committees, nil
+}
+
+// GetOptimisticUpdate fetches the latest available optimistic update. The
+// execution payload proof is validated, the signature is not checked.
+//
+// See data structure definition here:
+// https://github.com/ethereum/beacon-APIs/blob/master/apis/beacon/light_client/optimistic_update.yaml
+func (api *BeaconLightApi) GetOptimisticUpdate() (types.OptimisticUpdate, error) {
+	resp, err := api.httpGet("/eth/v1/beacon/light_client/optimistic_update")
+	if err != nil {
+		return types.OptimisticUpdate{}, err
+	}
+	var data struct {
+		Data struct {
+			AttestedHeader jsonHeaderWithExecProof `json:"attested_header"`
+			SyncAggregate  types.SyncAggregate     `json:"sync_aggregate"`
+			SignatureSlot  common.Decimal          `json:"signature_slot"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return types.OptimisticUpdate{}, err
+	}
+	update := types.OptimisticUpdate{
+		Attested:      data.Data.AttestedHeader.header(),
+		Signature:     data.Data.SyncAggregate,
+		SignatureSlot: uint64(data.Data.SignatureSlot),
+	}
+	if err := update.Validate(); err != nil {
+		return types.OptimisticUpdate{}, err
+	}
+	return update, nil
+}
+
+// GetFinalityUpdate fetches the latest available finality update. The Merkle
+// proofs are validated, the signature is not checked.
+//
+// See data structure definition here:
+// https://github.com/ethereum/beacon-APIs/blob/master/apis/beacon/light_client/finality_update.yaml
+func (api *BeaconLightApi) GetFinalityUpdate() (types.FinalityUpdate, error) {
+	resp, err := api.httpGet("/eth/v1/beacon/light_client/finality_update")
+	if err != nil {
+		return types.FinalityUpdate{}, err
+	}
+	var data struct {
+		Data struct {
+			AttestedHeader  jsonHeaderWithExecProof `json:"attested_header"`
+			FinalizedHeader jsonHeaderWithExecProof `json:"finalized_header"`
+			FinalityBranch  merkle.Values           `json:"finality_branch"`
+			SyncAggregate   types.SyncAggregate     `json:"sync_aggregate"`
+			SignatureSlot   common.Decimal          `json:"signature_slot"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return types.FinalityUpdate{}, err
+	}
+	update := types.FinalityUpdate{
+		Attested:       data.Data.AttestedHeader.header(),
+		Finalized:      data.Data.FinalizedHeader.header(),
+		FinalityBranch: data.Data.FinalityBranch,
+		Signature:      data.Data.SyncAggregate,
+		SignatureSlot:  uint64(data.Data.SignatureSlot),
+	}
+	if err := update.Validate(); err != nil {
+		return types.FinalityUpdate{}, err
+	}
+	return update, nil
+}
+
+// GetCheckpointData fetches and validates the bootstrap data belonging to the
+// given checkpoint block root.
+//
+// See data structure definition here:
+// https://github.com/ethereum/beacon-APIs/blob/master/apis/beacon/light_client/bootstrap.yaml
+func (api *BeaconLightApi) GetCheckpointData(checkpointHash common.Hash) (*types.BootstrapData, error) {
+	resp, err := api.httpGet("/eth/v1/beacon/light_client/bootstrap/" + url.PathEscape(checkpointHash.Hex()))
+	if err != nil {
+		return nil, err
+	}
+	var data struct {
+		Data struct {
+			Header          jsonHeaderWithExecProof        `json:"header"`
+			Committee       *types.SerializedSyncCommittee `json:"current_sync_committee"`
+			CommitteeBranch merkle.Values                  `json:"current_sync_committee_branch"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, err
+	}
+	if data.Data.Committee == nil {
+		return nil, errors.New("sync committee is missing")
+	}
+	header := data.Data.Header.Beacon
+	if header.Hash() != checkpointHash {
+		return nil, fmt.Errorf("invalid checkpoint block header, have %v want %v", header.Hash(), checkpointHash)
+	}
+	checkpoint := &types.BootstrapData{
+		Header:          header,
+		CommitteeBranch: data.Data.CommitteeBranch,
+		CommitteeRoot:   data.Data.Committee.Root(),
+		Committee:       data.Data.Committee,
+	}
+	if err := checkpoint.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	return checkpoint, nil
+}
+
+// GetGenesis fetches the genesis time and the genesis validators root of the
+// beacon chain.
+func (api *BeaconLightApi) GetGenesis() (genesisTime uint64, validatorsRoot common.Hash, err error) {
+	resp, err := api.httpGet("/eth/v1/beacon/genesis")
+	if err != nil {
+		return 0, common.Hash{}, err
+	}
+	var data struct {
+		Data struct {
+			GenesisTime           common.Decimal `json:"genesis_time"`
+			GenesisValidatorsRoot common.Hash    `json:"genesis_validators_root"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return 0, common.Hash{}, err
+	}
+	return uint64(data.Data.GenesisTime), data.Data.GenesisValidatorsRoot, nil
+}
+
+type jsonWithdrawal struct {
+	Index     common.Decimal `json:"index"`
+	Validator common.Decimal `json:"validator_index"`
+	Address   common.Address `json:"address"`
+	Amount    common.Decimal `json:"amount"`
+}
+
+type jsonExecutionPayload struct {
+	ParentHash    common.Hash           `json:"parent_hash"`
+	FeeRecipient  common.Address        `json:"fee_recipient"`
+	StateRoot     common.Hash           `json:"state_root"`
+	ReceiptsRoot  common.Hash           `json:"receipts_root"`
+	LogsBloom     hexutil.Bytes         `json:"logs_bloom"`
+	PrevRandao    common.Hash           `json:"prev_randao"`
+	BlockNumber   common.Decimal        `json:"block_number"`
+	GasLimit      common.Decimal        `json:"gas_limit"`
+	GasUsed       common.Decimal        `json:"gas_used"`
+	Timestamp     common.Decimal        `json:"timestamp"`
+	ExtraData     hexutil.Bytes         `json:"extra_data"`
+	BaseFeePerGas *math.HexOrDecimal256 `json:"base_fee_per_gas"`
+	BlockHash     common.Hash           `json:"block_hash"`
+	Transactions  []hexutil.Bytes       `json:"transactions"`
+	Withdrawals   []jsonWithdrawal      `json:"withdrawals"`
+	BlobGasUsed   *common.Decimal       `json:"blob_gas_used,omitempty"`
+	ExcessBlobGas *common.Decimal       `json:"excess_blob_gas,omitempty"`
+}
+
+// GetExecutionPayload fetches the execution payload of the beacon block with the
+// given root, along with the versioned hashes of the blobs committed to in the
+// block. The payload is not verified against the beacon block.
+//
+// See data structure definition here:
+// https://github.com/ethereum/beacon-APIs/blob/master/apis/beacon/blocks/block.v2.yaml
+func (api *BeaconLightApi) GetExecutionPayload(blockRoot common.Hash) (*engine.ExecutableData, []common.Hash, error) {
+	resp, err := api.httpGet("/eth/v2/beacon/blocks/" + url.PathEscape(blockRoot.Hex()))
+	if err != nil {
+		return nil, nil, err
+	}
+	var data struct {
+		Data struct {
+			Message struct {
+				Body struct {
+					ExecutionPayload   *jsonExecutionPayload `json:"execution_payload"`
+					BlobKzgCommitments []hexutil.Bytes       `json:"blob_kzg_commitments"`
+				} `json:"body"`
+			} `json:"message"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, nil, err
+	}
+	p := data.Data.Message.Body.ExecutionPayload
+	if p == nil {
+		return nil, nil, errors.New("missing execution payload")
+	}
+	if p.BaseFeePerGas == nil {
+		return nil, nil, errors.New("missing base fee")
+	}
+	payload := &engine.ExecutableData{
+		ParentHash:    p.ParentHash,
+		FeeRecipient:  p.FeeRecipient,
+		StateRoot:     p.StateRoot,
+		ReceiptsRoot:  p.ReceiptsRoot,
+		LogsBloom:     p.LogsBloom,
+		Random:        p.PrevRandao,
+		Number:        uint64(p.BlockNumber),
+		GasLimit:      uint64(p.GasLimit),
+		GasUsed:       uint64(p.GasUsed),
+		Timestamp:     uint64(p.Timestamp),
+		ExtraData:     p.ExtraData,
+		BaseFeePerGas: (*big.Int)(p.BaseFeePerGas),
+		BlockHash:     p.BlockHash,
+		Transactions:  make([][]byte, len(p.Transactions)),
+		Withdrawals:   make([]*ctypes.Withdrawal, len(p.Withdrawals)),
+	}
+	for i, tx := range p.Transactions {
+		payload.Transactions[i] = tx
+	}
+	for i, w := range p.Withdrawals {
+		payload.Withdrawals[i] = &ctypes.Withdrawal{
+			Index:     uint64(w.Index),
+			Validator: uint64(w.Validator),
+			Address:   w.Address,
+			Amount:    uint64(w.Amount),
+		}
+	}
+	var versionedHashes []common.Hash
+	if p.BlobGasUsed != nil && p.ExcessBlobGas != nil {
+		blobGasUsed, excessBlobGas := uint64(*p.BlobGasUsed), uint64(*p.ExcessBlobGas)
+		payload.BlobGasUsed, payload.ExcessBlobGas = &blobGasUsed, &excessBlobGas
+
+		versionedHashes = make([]common.Hash, len(data.Data.Message.Body.BlobKzgCommitments))
+		for i, commitment := range data.Data.Message.Body.BlobKzgCommitments {
+			hasher := sha256.New()
+			hasher.Write(commitment)
+			hasher.Sum(versionedHashes[i][:0])
+			versionedHashes[i][0] = params.BlobTxHashVersion
+		}
+	}
+	return payload, versionedHashes, nil
+}
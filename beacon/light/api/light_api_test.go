@@ -0,0 +1,274 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/beacon/internal/lighttest"
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// testServer serves canned JSON responses by request path.
+func testServer(t *testing.T, responses map[string]any) *BeaconLightApi {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Test") != "yes" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		resp, ok := responses[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return NewBeaconLightApi(srv.URL, map[string]string{"X-Test": "yes"})
+}
+
+func encodeValues(values merkle.Values) []string {
+	enc := make([]string, len(values))
+	for i, v := range values {
+		enc[i] = hexutil.Encode(v[:])
+	}
+	return enc
+}
+
+func encodeHeader(h types.Header) map[string]any {
+	return map[string]any{
+		"slot":           fmt.Sprint(h.Slot),
+		"proposer_index": fmt.Sprint(h.ProposerIndex),
+		"parent_root":    h.ParentRoot,
+		"state_root":     h.StateRoot,
+		"body_root":      h.BodyRoot,
+	}
+}
+
+func encodeExecHeader(h *types.ExecutionHeader) map[string]any {
+	enc := map[string]any{
+		"parent_hash":       h.ParentHash,
+		"fee_recipient":     h.FeeRecipient,
+		"state_root":        h.StateRoot,
+		"receipts_root":     h.ReceiptsRoot,
+		"logs_bloom":        hexutil.Bytes(h.LogsBloom[:]),
+		"prev_randao":       h.PrevRandao,
+		"block_number":      fmt.Sprint(h.BlockNumber),
+		"gas_limit":         fmt.Sprint(h.GasLimit),
+		"gas_used":          fmt.Sprint(h.GasUsed),
+		"timestamp":         fmt.Sprint(h.Timestamp),
+		"extra_data":        hexutil.Bytes(h.ExtraData),
+		"base_fee_per_gas":  h.BaseFeePerGas.String(),
+		"block_hash":        h.BlockHash,
+		"transactions_root": h.TransactionsRoot,
+		"withdrawals_root":  h.WithdrawalsRoot,
+	}
+	if h.BlobGasUsed != nil {
+		enc["blob_gas_used"] = fmt.Sprint(*h.BlobGasUsed)
+		enc["excess_blob_gas"] = fmt.Sprint(*h.ExcessBlobGas)
+	}
+	return enc
+}
+
+// makeExecHeader creates a beacon header with an execution payload proof.
+func makeExecHeader(slot uint64, state common.Hash, number uint64) types.HeaderWithExecProof {
+	blobGasUsed, excessBlobGas := uint64(131072), uint64(0)
+	exec := &types.ExecutionHeader{
+		BlockNumber:   number,
+		GasLimit:      30000000,
+		Timestamp:     1700000000 + slot*12,
+		ExtraData:     []byte("test"),
+		BaseFeePerGas: big.NewInt(7),
+		BlockHash:     common.Hash{byte(number)},
+		BlobGasUsed:   &blobGasUsed,
+		ExcessBlobGas: &excessBlobGas,
+	}
+	body := lighttest.Tree{params.BodyIndexExecPayload: merkle.Value(exec.Root())}
+	return types.HeaderWithExecProof{
+		Beacon:          types.Header{Slot: slot, StateRoot: state, BodyRoot: body.Root()},
+		Execution:       exec,
+		ExecutionBranch: body.Branch(params.BodyIndexExecPayload),
+	}
+}
+
+func encodeHeaderWithExecProof(h types.HeaderWithExecProof) map[string]any {
+	return map[string]any{
+		"beacon":           encodeHeader(h.Beacon),
+		"execution":        encodeExecHeader(h.Execution),
+		"execution_branch": encodeValues(h.ExecutionBranch),
+	}
+}
+
+func TestGetCheckpointData(t *testing.T) {
+	var (
+		committee = lighttest.NewCommittee(1)
+		state     = lighttest.Tree{params.StateIndexSyncCommittee: merkle.Value(committee.Serialized.Root())}
+		header    = makeExecHeader(100, state.Root(), 1)
+		hash      = header.Beacon.Hash()
+	)
+	api := testServer(t, map[string]any{
+		"/eth/v1/beacon/light_client/bootstrap/" + hash.Hex(): map[string]any{
+			"data": map[string]any{
+				"header":                        encodeHeaderWithExecProof(header),
+				"current_sync_committee":        committee.Serialized,
+				"current_sync_committee_branch": encodeValues(state.Branch(params.StateIndexSyncCommittee)),
+			},
+		},
+	})
+	bootstrap, err := api.GetCheckpointData(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bootstrap.Header != header.Beacon || *bootstrap.Committee != *committee.Serialized {
+		t.Fatal("wrong bootstrap data")
+	}
+	if _, err := api.GetCheckpointData(common.Hash{1}); err != ErrNotFound {
+		t.Fatalf("wrong error for unknown checkpoint: %v", err)
+	}
+}
+
+func TestGetBestUpdatesAndCommittees(t *testing.T) {
+	var (
+		signer = lighttest.NewCommittee(1)
+		next   = lighttest.NewCommittee(2)
+		state  = lighttest.Tree{params.StateIndexNextSyncCommittee: merkle.Value(next.Serialized.Root())}
+		header = makeExecHeader(params.SyncPeriodLength+100, state.Root(), 1)
+		signed = signer.Sign(header.Beacon, header.Beacon.Slot+1, params.SyncCommitteeSize)
+	)
+	update := map[string]any{
+		"attested_header":            encodeHeaderWithExecProof(header),
+		"next_sync_committee":        next.Serialized,
+		"next_sync_committee_branch": encodeValues(state.Branch(params.StateIndexNextSyncCommittee)),
+		"sync_aggregate":             &signed.Signature,
+		"signature_slot":             fmt.Sprint(signed.SignatureSlot),
+	}
+	api := testServer(t, map[string]any{
+		"/eth/v1/beacon/light_client/updates?start_period=1&count=2": []any{
+			map[string]any{"version": "deneb", "data": update},
+		},
+		"/eth/v1/beacon/light_client/updates?start_period=2&count=1": []any{
+			map[string]any{"version": "deneb", "data": update},
+		},
+	})
+	updates, committees, err := api.GetBestUpdatesAndCommittees(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 1 || len(committees) != 1 {
+		t.Fatalf("wrong number of updates %d", len(updates))
+	}
+	if updates[0].AttestedHeader != signed || updates[0].NextSyncCommitteeRoot != next.Serialized.Root() {
+		t.Fatal("wrong update")
+	}
+	if _, _, err := api.GetBestUpdatesAndCommittees(2, 1); err == nil {
+		t.Fatal("update of wrong period accepted")
+	}
+}
+
+func TestGetFinalityUpdate(t *testing.T) {
+	var (
+		finalized = makeExecHeader(100, common.Hash{2}, 1)
+		state     = lighttest.Tree{params.StateIndexFinalBlock: merkle.Value(finalized.Beacon.Hash())}
+		attested  = makeExecHeader(200, state.Root(), 3)
+	)
+	response := map[string]any{
+		"data": map[string]any{
+			"attested_header":  encodeHeaderWithExecProof(attested),
+			"finalized_header": encodeHeaderWithExecProof(finalized),
+			"finality_branch":  encodeValues(state.Branch(params.StateIndexFinalBlock)),
+			"sync_aggregate":   &types.SyncAggregate{},
+			"signature_slot":   "201",
+		},
+	}
+	api := testServer(t, map[string]any{"/eth/v1/beacon/light_client/finality_update": response})
+	update, err := api.GetFinalityUpdate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.Attested.Beacon != attested.Beacon || update.Finalized.Execution.BlockHash != finalized.Execution.BlockHash || update.SignatureSlot != 201 {
+		t.Fatal("wrong finality update")
+	}
+
+	// Check that an invalid execution payload proof is rejected.
+	response["data"].(map[string]any)["attested_header"].(map[string]any)["execution"].(map[string]any)["block_hash"] = common.Hash{9}
+	if _, err := api.GetFinalityUpdate(); err == nil {
+		t.Fatal("update with wrong execution header accepted")
+	}
+}
+
+func TestGetExecutionPayload(t *testing.T) {
+	root := common.Hash{1}
+	api := testServer(t, map[string]any{
+		"/eth/v2/beacon/blocks/" + root.Hex(): map[string]any{
+			"version": "deneb",
+			"data": map[string]any{
+				"message": map[string]any{
+					"slot": "100",
+					"body": map[string]any{
+						"execution_payload": map[string]any{
+							"parent_hash":      common.Hash{2},
+							"fee_recipient":    common.Address{3},
+							"state_root":       common.Hash{4},
+							"receipts_root":    common.Hash{5},
+							"logs_bloom":       hexutil.Bytes(make([]byte, 256)),
+							"prev_randao":      common.Hash{6},
+							"block_number":     "7",
+							"gas_limit":        "30000000",
+							"gas_used":         "21000",
+							"timestamp":        "1700000000",
+							"extra_data":       "0x",
+							"base_fee_per_gas": "1000000000",
+							"block_hash":       common.Hash{8},
+							"transactions":     []string{"0x01"},
+							"withdrawals": []any{
+								map[string]any{"index": "1", "validator_index": "2", "address": common.Address{9}, "amount": "3"},
+							},
+							"blob_gas_used":   "131072",
+							"excess_blob_gas": "0",
+						},
+						"blob_kzg_commitments": []string{hexutil.Encode(make([]byte, 48))},
+					},
+				},
+			},
+		},
+	})
+	payload, versionedHashes, err := api.GetExecutionPayload(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.Number != 7 || payload.BaseFeePerGas.Uint64() != 1000000000 || payload.BlockHash != (common.Hash{8}) {
+		t.Fatalf("wrong payload %+v", payload)
+	}
+	if len(payload.Withdrawals) != 1 || payload.Withdrawals[0].Validator != 2 || len(payload.Transactions) != 1 {
+		t.Fatal("wrong payload body")
+	}
+	if payload.BlobGasUsed == nil || *payload.BlobGasUsed != 131072 {
+		t.Fatal("wrong blob gas")
+	}
+	if len(versionedHashes) != 1 || versionedHashes[0][0] != 1 {
+		t.Fatalf("wrong versioned hashes %v", versionedHashes)
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package light implements the verification logic of the beacon chain light
+// client sync protocol.
+package light
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxCommittees is the number of most recent sync committees kept in memory.
+// Signatures can only be verified for the periods of these committees.
+const maxCommittees = 4
+
+var (
+	ErrNotInitialized      = errors.New("committee chain not initialized")
+	ErrNeedCommittee       = errors.New("sync committee required")
+	ErrInvalidUpdate       = errors.New("invalid committee update")
+	ErrInvalidPeriod       = errors.New("invalid update period")
+	ErrWrongCommitteeRoot  = errors.New("wrong committee root")
+	ErrInsufficientSigners = errors.New("insufficient signer participation")
+	ErrInvalidSignature    = errors.New("invalid sync committee signature")
+)
+
+// CommitteeChain tracks the sync committees of consecutive sync periods. It is
+// initialized from a trusted checkpoint, after which each committee is proven by
+// an update signed by the committee of the previous period. Once the committee
+// of a period is known, headers signed in that period can be verified.
+//
+// Committees are only kept in memory, the chain needs to be initialized again
+// from a checkpoint after a restart.
+type CommitteeChain struct {
+	lock            sync.RWMutex
+	config          *types.ChainConfig
+	signerThreshold int
+
+	lastPeriod uint64                          // period of the most recent committee
+	committees map[uint64]*types.SyncCommittee // recent committees by period
+	roots      map[uint64]common.Hash          // committee roots by period
+}
+
+// NewCommitteeChain creates an uninitialized committee chain. Signed headers and
+// updates are only accepted if at least signerThreshold committee members have
+// signed them.
+func NewCommitteeChain(config *types.ChainConfig, signerThreshold int) *CommitteeChain {
+	return &CommitteeChain{
+		config:          config,
+		signerThreshold: signerThreshold,
+		committees:      make(map[uint64]*types.SyncCommittee),
+		roots:           make(map[uint64]common.Hash),
+	}
+}
+
+// CheckpointInit initializes the chain with the committee of the checkpoint. The
+// caller is responsible for checking that the bootstrap header is trusted.
+func (s *CommitteeChain) CheckpointInit(bootstrap *types.BootstrapData) error {
+	if err := bootstrap.Validate(); err != nil {
+		return err
+	}
+	committee, err := bootstrap.Committee.Deserialize()
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	period := bootstrap.Header.SyncPeriod()
+	s.committees = map[uint64]*types.SyncCommittee{period: committee}
+	s.roots = map[uint64]common.Hash{period: bootstrap.CommitteeRoot}
+	s.lastPeriod = period
+	log.Info("Initialized beacon committee chain", "period", period, "checkpoint", bootstrap.Header.Hash())
+	return nil
+}
+
+// NextSyncPeriod returns the period of the update needed to extend the chain,
+// which is the period of the most recent committee.
+func (s *CommitteeChain) NextSyncPeriod() (period uint64, initialized bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if len(s.committees) == 0 {
+		return 0, false
+	}
+	return s.lastPeriod, true
+}
+
+// InsertUpdate adds the committee of the next period. The update must be signed
+// by the most recent committee of the chain and prove the root of the given
+// next committee.
+func (s *CommitteeChain) InsertUpdate(update *types.LightClientUpdate, nextCommittee *types.SerializedSyncCommittee) error {
+	if err := update.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidUpdate, err)
+	}
+	if nextCommittee.Root() != update.NextSyncCommitteeRoot {
+		return ErrWrongCommitteeRoot
+	}
+	committee, err := nextCommittee.Deserialize()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidUpdate, err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.committees) == 0 {
+		return ErrNotInitialized
+	}
+	period := update.AttestedHeader.Header.SyncPeriod()
+	if period != s.lastPeriod {
+		return ErrInvalidPeriod
+	}
+	// Committee updates need a supermajority, regardless of the threshold used
+	// for head tracking.
+	if update.AttestedHeader.Signature.SignerCount() < params.SyncCommitteeSupermajority {
+		return ErrInsufficientSigners
+	}
+	if err := s.verifySignedHeader(update.AttestedHeader); err != nil {
+		return err
+	}
+	s.lastPeriod = period + 1
+	s.committees[s.lastPeriod] = committee
+	s.roots[s.lastPeriod] = update.NextSyncCommitteeRoot
+	delete(s.committees, s.lastPeriod-maxCommittees)
+	log.Debug("Added beacon sync committee", "period", s.lastPeriod, "root", update.NextSyncCommitteeRoot)
+	return nil
+}
+
+// VerifySignedHeader checks the sync committee signature of the given header.
+func (s *CommitteeChain) VerifySignedHeader(head types.SignedHeader) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if head.Signature.SignerCount() < s.signerThreshold {
+		return ErrInsufficientSigners
+	}
+	return s.verifySignedHeader(head)
+}
+
+func (s *CommitteeChain) verifySignedHeader(head types.SignedHeader) error {
+	if head.SignatureSlot <= head.Header.Slot {
+		return errors.New("signature slot not after header slot")
+	}
+	committee := s.committees[types.SyncPeriod(head.SignatureSlot)]
+	if committee == nil {
+		return ErrNeedCommittee
+	}
+	signingRoot, err := s.config.Forks.SigningRoot(head.Header)
+	if err != nil {
+		return err
+	}
+	if !committee.VerifySignature(signingRoot, &head.Signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// CommitteeRoot returns the root of the committee of the given period, if known.
+func (s *CommitteeChain) CommitteeRoot(period uint64) (common.Hash, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	root, ok := s.roots[period]
+	return root, ok
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/beacon/internal/lighttest"
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/beacon/types"
+)
+
+func makeBootstrap(slot uint64, committee *lighttest.Committee) *types.BootstrapData {
+	root := committee.Serialized.Root()
+	state := lighttest.Tree{params.StateIndexSyncCommittee: merkle.Value(root)}
+	return &types.BootstrapData{
+		Header:          types.Header{Slot: slot, StateRoot: state.Root()},
+		CommitteeRoot:   root,
+		Committee:       committee.Serialized,
+		CommitteeBranch: state.Branch(params.StateIndexSyncCommittee),
+	}
+}
+
+func makeUpdate(slot uint64, signer, next *lighttest.Committee, signers int) *types.LightClientUpdate {
+	root := next.Serialized.Root()
+	state := lighttest.Tree{params.StateIndexNextSyncCommittee: merkle.Value(root)}
+	header := types.Header{Slot: slot, StateRoot: state.Root()}
+	return &types.LightClientUpdate{
+		AttestedHeader:          signer.Sign(header, slot+1, signers),
+		NextSyncCommitteeRoot:   root,
+		NextSyncCommitteeBranch: state.Branch(params.StateIndexNextSyncCommittee),
+	}
+}
+
+func TestCommitteeChain(t *testing.T) {
+	var (
+		c0, c1, c2 = lighttest.NewCommittee(0), lighttest.NewCommittee(1), lighttest.NewCommittee(2)
+		chain      = NewCommitteeChain(lighttest.Config, 100)
+		update     = makeUpdate(200, c0, c1, params.SyncCommitteeSize)
+	)
+	if _, ok := chain.NextSyncPeriod(); ok {
+		t.Fatal("new chain is initialized")
+	}
+	if err := chain.InsertUpdate(update, c1.Serialized); err != ErrNotInitialized {
+		t.Fatalf("wrong error for uninitialized chain: %v", err)
+	}
+
+	// Initialize from a checkpoint in period zero.
+	bootstrap := makeBootstrap(100, c0)
+	bootstrap.CommitteeBranch[0][0]++
+	if err := chain.CheckpointInit(bootstrap); err == nil {
+		t.Fatal("checkpoint with invalid proof accepted")
+	}
+	if err := chain.CheckpointInit(makeBootstrap(100, c0)); err != nil {
+		t.Fatal("checkpoint init failed:", err)
+	}
+	if period, ok := chain.NextSyncPeriod(); !ok || period != 0 {
+		t.Fatalf("wrong next sync period %d after init", period)
+	}
+
+	// Check invalid updates are rejected.
+	if err := chain.InsertUpdate(update, c2.Serialized); err != ErrWrongCommitteeRoot {
+		t.Fatalf("wrong error for wrong committee: %v", err)
+	}
+	if err := chain.InsertUpdate(makeUpdate(200, c0, c1, params.SyncCommitteeSupermajority-1), c1.Serialized); err != ErrInsufficientSigners {
+		t.Fatalf("wrong error for insufficient signers: %v", err)
+	}
+	if err := chain.InsertUpdate(makeUpdate(200, c2, c1, params.SyncCommitteeSize), c1.Serialized); err != ErrInvalidSignature {
+		t.Fatalf("wrong error for wrong signer: %v", err)
+	}
+	if err := chain.InsertUpdate(makeUpdate(params.SyncPeriodLength+200, c1, c2, params.SyncCommitteeSize), c2.Serialized); err != ErrInvalidPeriod {
+		t.Fatalf("wrong error for future period: %v", err)
+	}
+	badProof := makeUpdate(200, c0, c1, params.SyncCommitteeSize)
+	badProof.NextSyncCommitteeBranch[1][0]++
+	if err := chain.InsertUpdate(badProof, c1.Serialized); !errors.Is(err, ErrInvalidUpdate) {
+		t.Fatalf("wrong error for invalid proof: %v", err)
+	}
+
+	// Add the committee of period one.
+	if err := chain.InsertUpdate(update, c1.Serialized); err != nil {
+		t.Fatal("valid update rejected:", err)
+	}
+	if period, _ := chain.NextSyncPeriod(); period != 1 {
+		t.Fatalf("wrong next sync period %d after update", period)
+	}
+	if root, ok := chain.CommitteeRoot(1); !ok || root != c1.Serialized.Root() {
+		t.Fatal("wrong committee root for period one")
+	}
+	if err := chain.InsertUpdate(update, c1.Serialized); err != ErrInvalidPeriod {
+		t.Fatalf("wrong error for repeated update: %v", err)
+	}
+
+	// Verify headers signed in period one.
+	header := types.Header{Slot: params.SyncPeriodLength + 10}
+	if err := chain.VerifySignedHeader(c1.Sign(header, header.Slot+1, 100)); err != nil {
+		t.Fatal("valid signed header rejected:", err)
+	}
+	if err := chain.VerifySignedHeader(c1.Sign(header, header.Slot+1, 99)); err != ErrInsufficientSigners {
+		t.Fatalf("wrong error for insufficient signers: %v", err)
+	}
+	if err := chain.VerifySignedHeader(c0.Sign(header, header.Slot+1, 100)); err != ErrInvalidSignature {
+		t.Fatalf("wrong error for wrong signer: %v", err)
+	}
+	if err := chain.VerifySignedHeader(c2.Sign(header, 2*params.SyncPeriodLength, 100)); err != ErrNeedCommittee {
+		t.Fatalf("wrong error for unknown committee: %v", err)
+	}
+}
@@ -42,3 +42,7 @@ const (
 	StateIndexExecPayload       = 56
 	StateIndexExecHead          = 908
 )
+
+const (
+	BodyIndexExecPayload = 25
+)
@@ -0,0 +1,242 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+const (
+	logsBloomSize    = 256
+	maxExtraDataSize = 32
+)
+
+// ExecutionHeader is the header of the execution payload in a beacon block, as
+// defined since the Capella fork. The blob gas fields are only present after
+// the Deneb fork.
+//
+// See data structure definition here:
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/deneb/beacon-chain.md#executionpayloadheader
+type ExecutionHeader struct {
+	ParentHash       common.Hash
+	FeeRecipient     common.Address
+	StateRoot        common.Hash
+	ReceiptsRoot     common.Hash
+	LogsBloom        [logsBloomSize]byte
+	PrevRandao       common.Hash
+	BlockNumber      uint64
+	GasLimit         uint64
+	GasUsed          uint64
+	Timestamp        uint64
+	ExtraData        []byte
+	BaseFeePerGas    *big.Int
+	BlockHash        common.Hash
+	TransactionsRoot common.Hash
+	WithdrawalsRoot  common.Hash
+	BlobGasUsed      *uint64 // nil before Deneb
+	ExcessBlobGas    *uint64 // nil before Deneb
+}
+
+type jsonExecutionHeader struct {
+	ParentHash       common.Hash           `json:"parent_hash"`
+	FeeRecipient     common.Address        `json:"fee_recipient"`
+	StateRoot        common.Hash           `json:"state_root"`
+	ReceiptsRoot     common.Hash           `json:"receipts_root"`
+	LogsBloom        hexutil.Bytes         `json:"logs_bloom"`
+	PrevRandao       common.Hash           `json:"prev_randao"`
+	BlockNumber      common.Decimal        `json:"block_number"`
+	GasLimit         common.Decimal        `json:"gas_limit"`
+	GasUsed          common.Decimal        `json:"gas_used"`
+	Timestamp        common.Decimal        `json:"timestamp"`
+	ExtraData        hexutil.Bytes         `json:"extra_data"`
+	BaseFeePerGas    *math.HexOrDecimal256 `json:"base_fee_per_gas"`
+	BlockHash        common.Hash           `json:"block_hash"`
+	TransactionsRoot common.Hash           `json:"transactions_root"`
+	WithdrawalsRoot  common.Hash           `json:"withdrawals_root"`
+	BlobGasUsed      *common.Decimal       `json:"blob_gas_used,omitempty"`
+	ExcessBlobGas    *common.Decimal       `json:"excess_blob_gas,omitempty"`
+}
+
+// UnmarshalJSON decodes the header in beacon API format.
+func (h *ExecutionHeader) UnmarshalJSON(input []byte) error {
+	var dec jsonExecutionHeader
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if len(dec.LogsBloom) != logsBloomSize {
+		return fmt.Errorf("invalid logs bloom size %d", len(dec.LogsBloom))
+	}
+	if len(dec.ExtraData) > maxExtraDataSize {
+		return fmt.Errorf("extra data too long (%d bytes)", len(dec.ExtraData))
+	}
+	if dec.BaseFeePerGas == nil {
+		return errors.New("missing base fee")
+	}
+	if (dec.BlobGasUsed == nil) != (dec.ExcessBlobGas == nil) {
+		return errors.New("incomplete blob gas fields")
+	}
+	*h = ExecutionHeader{
+		ParentHash:       dec.ParentHash,
+		FeeRecipient:     dec.FeeRecipient,
+		StateRoot:        dec.StateRoot,
+		ReceiptsRoot:     dec.ReceiptsRoot,
+		PrevRandao:       dec.PrevRandao,
+		BlockNumber:      uint64(dec.BlockNumber),
+		GasLimit:         uint64(dec.GasLimit),
+		GasUsed:          uint64(dec.GasUsed),
+		Timestamp:        uint64(dec.Timestamp),
+		ExtraData:        dec.ExtraData,
+		BaseFeePerGas:    (*big.Int)(dec.BaseFeePerGas),
+		BlockHash:        dec.BlockHash,
+		TransactionsRoot: dec.TransactionsRoot,
+		WithdrawalsRoot:  dec.WithdrawalsRoot,
+	}
+	copy(h.LogsBloom[:], dec.LogsBloom)
+	if dec.BlobGasUsed != nil {
+		blobGasUsed, excessBlobGas := uint64(*dec.BlobGasUsed), uint64(*dec.ExcessBlobGas)
+		h.BlobGasUsed, h.ExcessBlobGas = &blobGasUsed, &excessBlobGas
+	}
+	return nil
+}
+
+// Root calculates the SSZ hash tree root of the header.
+//
+// TODO(zsfelfoldi): Remove this when an SSZ encoder lands.
+func (h *ExecutionHeader) Root() common.Hash {
+	var (
+		leaves    = make([]merkle.Value, 0, 17)
+		extraData merkle.Value
+		baseFee   merkle.Value
+	)
+	copy(extraData[:], h.ExtraData)
+	h.BaseFeePerGas.FillBytes(baseFee[:])
+	for i := 0; i < len(baseFee)/2; i++ { // SSZ integers are little endian
+		baseFee[i], baseFee[len(baseFee)-1-i] = baseFee[len(baseFee)-1-i], baseFee[i]
+	}
+	leaves = append(leaves,
+		merkle.Value(h.ParentHash),
+		addressValue(h.FeeRecipient),
+		merkle.Value(h.StateRoot),
+		merkle.Value(h.ReceiptsRoot),
+		merkleRoot(bytesToValues(h.LogsBloom[:])),
+		merkle.Value(h.PrevRandao),
+		uint64Value(h.BlockNumber),
+		uint64Value(h.GasLimit),
+		uint64Value(h.GasUsed),
+		uint64Value(h.Timestamp),
+		hashPair(extraData, uint64Value(uint64(len(h.ExtraData)))), // mix in the length of the list
+		baseFee,
+		merkle.Value(h.BlockHash),
+		merkle.Value(h.TransactionsRoot),
+		merkle.Value(h.WithdrawalsRoot),
+	)
+	if h.BlobGasUsed != nil {
+		leaves = append(leaves, uint64Value(*h.BlobGasUsed), uint64Value(*h.ExcessBlobGas))
+	}
+	return common.Hash(merkleRoot(leaves))
+}
+
+// HeaderWithExecProof is a beacon header along with the header of the execution
+// payload of its block, proven against the block body root.
+type HeaderWithExecProof struct {
+	Beacon          Header
+	Execution       *ExecutionHeader
+	ExecutionBranch merkle.Values
+}
+
+// Validate verifies the execution payload proof of the header.
+func (h *HeaderWithExecProof) Validate() error {
+	if h.Execution == nil {
+		return errors.New("missing execution payload header")
+	}
+	if err := merkle.VerifyProof(h.Beacon.BodyRoot, params.BodyIndexExecPayload, h.ExecutionBranch, merkle.Value(h.Execution.Root())); err != nil {
+		return fmt.Errorf("invalid execution payload proof: %w", err)
+	}
+	return nil
+}
+
+func addressValue(addr common.Address) (v merkle.Value) {
+	copy(v[:], addr[:])
+	return v
+}
+
+func uint64Value(n uint64) (v merkle.Value) {
+	binary.LittleEndian.PutUint64(v[:8], n)
+	return v
+}
+
+// bytesToValues splits a byte vector into chunks.
+func bytesToValues(data []byte) merkle.Values {
+	values := make(merkle.Values, (len(data)+31)/32)
+	for i := range values {
+		copy(values[i][:], data[i*32:])
+	}
+	return values
+}
+
+func hashPair(left, right merkle.Value) (v merkle.Value) {
+	hasher := sha256.New()
+	hasher.Write(left[:])
+	hasher.Write(right[:])
+	hasher.Sum(v[:0])
+	return v
+}
+
+// merkleRoot calculates the root of a binary merkle tree of the given leaves,
+// padded with zero values to a power of two.
+func merkleRoot(leaves merkle.Values) merkle.Value {
+	if len(leaves) == 0 {
+		return merkle.Value{}
+	}
+	layer := leaves
+	for len(layer) > 1 {
+		next := make(merkle.Values, (len(layer)+1)/2)
+		for i := range next {
+			var right merkle.Value
+			if 2*i+1 < len(layer) {
+				right = layer[2*i+1]
+			} else {
+				right = zeroHash(len(leaves), len(layer))
+			}
+			next[i] = hashPair(layer[2*i], right)
+		}
+		layer = next
+	}
+	return layer[0]
+}
+
+// zeroHash returns the root of an all-zero subtree with the same depth as the
+// nodes of the current layer, where width is the number of nodes in the layer
+// and count the number of leaves.
+func zeroHash(count, width int) merkle.Value {
+	var v merkle.Value
+	for n := count; n > width; n = (n + 1) / 2 {
+		v = hashPair(v, v)
+	}
+	return v
+}
@@ -57,7 +57,7 @@ var (
 	}
 	pkgFlag = &cli.StringFlag{
 		Name:  "pkg",
-		Usage: "Package name to generate the binding into",
+		Usage: "Package name to generate the binding into (go only)",
 	}
 	outFlag = &cli.StringFlag{
 		Name:  "out",
@@ -65,13 +65,17 @@ var (
 	}
 	langFlag = &cli.StringFlag{
 		Name:  "lang",
-		Usage: "Destination language for the bindings (go)",
+		Usage: "Destination language for the bindings (go, ts, python)",
 		Value: "go",
 	}
 	aliasFlag = &cli.StringFlag{
 		Name:  "alias",
 		Usage: "Comma separated aliases for function and event renaming, e.g. original1=alias1, original2=alias2",
 	}
+	v2Flag = &cli.BoolFlag{
+		Name:  "v2",
+		Usage: "Generate lightweight pack/unpack bindings for use with accounts/abi/bind/v2 (go only)",
+	}
 )
 
 var app = flags.NewApp("Ethereum ABI wrapper code generator")
@@ -88,6 +92,7 @@ func init() {
 		outFlag,
 		langFlag,
 		aliasFlag,
+		v2Flag,
 	}
 	app.Action = abigen
 }
@@ -95,16 +100,20 @@ func init() {
 func abigen(c *cli.Context) error {
 	utils.CheckExclusive(c, abiFlag, jsonFlag) // Only one source can be selected.
 
-	if c.String(pkgFlag.Name) == "" {
-		utils.Fatalf("No destination package specified (--pkg)")
-	}
 	var lang bind.Lang
 	switch c.String(langFlag.Name) {
 	case "go":
 		lang = bind.LangGo
+	case "ts", "typescript":
+		lang = bind.LangTypeScript
+	case "py", "python":
+		lang = bind.LangPython
 	default:
 		utils.Fatalf("Unsupported destination language \"%s\" (--lang)", c.String(langFlag.Name))
 	}
+	if lang == bind.LangGo && c.String(pkgFlag.Name) == "" {
+		utils.Fatalf("No destination package specified (--pkg)")
+	}
 	// If the entire solidity code was specified, build and bind based on that
 	var (
 		abis    []string
@@ -216,7 +225,18 @@ func abigen(c *cli.Context) error {
 		}
 	}
 	// Generate the contract binding
-	code, err := bind.Bind(types, abis, bins, sigs, c.String(pkgFlag.Name), lang, libs, aliases)
+	var (
+		code string
+		err  error
+	)
+	if c.Bool(v2Flag.Name) {
+		if lang != bind.LangGo {
+			utils.Fatalf("The --%s flag only supports Go bindings", v2Flag.Name)
+		}
+		code, err = bind.BindV2(types, abis, bins, sigs, c.String(pkgFlag.Name), libs, aliases)
+	} else {
+		code, err = bind.Bind(types, abis, bins, sigs, c.String(pkgFlag.Name), lang, libs, aliases)
+	}
 	if err != nil {
 		utils.Fatalf("Failed to generate ABI binding: %v", err)
 	}
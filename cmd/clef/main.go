@@ -52,6 +52,7 @@ import (
 	"github.com/ethereum/go-ethereum/signer/core"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/ethereum/go-ethereum/signer/fourbyte"
+	"github.com/ethereum/go-ethereum/signer/policy"
 	"github.com/ethereum/go-ethereum/signer/rules"
 	"github.com/ethereum/go-ethereum/signer/storage"
 	"github.com/mattn/go-colorable"
@@ -281,6 +282,11 @@ func init() {
 		customDBFlag,
 		auditLogFlag,
 		ruleFlag,
+		policyFlag,
+		quorumApproversFlag,
+		quorumThresholdFlag,
+		quorumTimeoutFlag,
+		quorumPortFlag,
 		stdiouiFlag,
 		testFlag,
 		advancedMode,
@@ -296,6 +302,7 @@ func init() {
 		gendocCommand,
 		listAccountsCommand,
 		listWalletsCommand,
+		policyCommand,
 	}
 }
 
@@ -378,10 +385,21 @@ func attestFile(ctx *cli.Context) error {
 	if ctx.NArg() < 1 {
 		utils.Fatalf("This command requires an argument.")
 	}
-	if err := initialize(ctx); err != nil {
+	configStorage, err := openConfigStorage(ctx)
+	if err != nil {
 		return err
 	}
+	val := ctx.Args().First()
+	configStorage.Put("ruleset_sha256", val)
+	log.Info("Ruleset attestation updated", "sha256", val)
+	return nil
+}
 
+// openConfigStorage opens the encrypted storage of the attestations.
+func openConfigStorage(ctx *cli.Context) (storage.Storage, error) {
+	if err := initialize(ctx); err != nil {
+		return nil, err
+	}
 	stretchedKey, err := readMasterKey(ctx, nil)
 	if err != nil {
 		utils.Fatalf(err.Error())
@@ -391,11 +409,7 @@ func attestFile(ctx *cli.Context) error {
 	confKey := crypto.Keccak256([]byte("config"), stretchedKey)
 
 	// Initialize the encrypted storages
-	configStorage := storage.NewAESEncryptedStorage(filepath.Join(vaultLocation, "config.json"), confKey)
-	val := ctx.Args().First()
-	configStorage.Put("ruleset_sha256", val)
-	log.Info("Ruleset attestation updated", "sha256", val)
-	return nil
+	return storage.NewAESEncryptedStorage(filepath.Join(vaultLocation, "config.json"), confKey), nil
 }
 
 func initInternalApi(c *cli.Context) (*core.UIServerAPI, core.UIClientAPI, error) {
@@ -638,6 +652,20 @@ func signer(c *cli.Context) error {
 		log.Info("Using CLI as UI-channel")
 		ui = core.NewCommandlineUI()
 	}
+	// Audit logging, shared by the external API and the approvers
+	var audit log.Logger
+	if logfile := c.String(auditLogFlag.Name); logfile != "" {
+		var err error
+		if audit, err = core.NewAuditLog(logfile); err != nil {
+			utils.Fatalf(err.Error())
+		}
+		log.Info("Audit logs configured", "file", logfile)
+	}
+	// Signing requests not decided by the policy or the rules go to the quorum
+	// of approvers instead of the UI, if configured
+	ui, approvalURL, stopApproval := setupQuorum(c, ui, audit)
+	defer stopApproval()
+
 	// 4bytedb data
 	fourByteLocal := c.String(customDBFlag.Name)
 	db, err := fourbyte.NewWithFile(fourByteLocal)
@@ -690,6 +718,29 @@ func signer(c *cli.Context) error {
 				}
 			}
 		}
+		// Do we have a policy file? It's evaluated before the rules
+		if policyFile := c.String(policyFlag.Name); policyFile != "" {
+			policyData, err := os.ReadFile(policyFile)
+			if err != nil {
+				log.Warn("Could not load policy, disabling", "file", policyFile, "err", err)
+			} else {
+				shasum := sha256.Sum256(policyData)
+				foundShaSum := hex.EncodeToString(shasum[:])
+				storedShasum, _ := configStorage.Get("policy_sha256")
+				if storedShasum != foundShaSum {
+					log.Warn("Policy hash not attested, disabling", "hash", foundShaSum, "attested", storedShasum)
+				} else {
+					pol, err := policy.Parse(policyData)
+					if err != nil {
+						utils.Fatalf("Invalid policy %s: %v", policyFile, err)
+					}
+					policyKey := crypto.Keccak256([]byte("policy"), stretchedKey)
+					policyStorage := storage.NewAESEncryptedStorage(filepath.Join(vaultLocation, "policy.json"), policyKey)
+					ui = policy.NewPolicyEvaluator(ui, policy.NewEngine(pol, policyStorage))
+					log.Info("Policy engine configured", "file", policyFile)
+				}
+			}
+		}
 	}
 	var (
 		chainId  = c.Int64(chainIdFlag.Name)
@@ -709,13 +760,8 @@ func signer(c *cli.Context) error {
 	ui.RegisterUIServer(core.NewUIServerAPI(apiImpl))
 	api = apiImpl
 
-	// Audit logging
-	if logfile := c.String(auditLogFlag.Name); logfile != "" {
-		api, err = core.NewAuditLogger(logfile, api)
-		if err != nil {
-			utils.Fatalf(err.Error())
-		}
-		log.Info("Audit logs configured", "file", logfile)
+	if audit != nil {
+		api = core.NewAuditLogger(audit, api)
 	}
 	// register signer API with server
 	var (
@@ -781,6 +827,7 @@ func signer(c *cli.Context) error {
 			"extapi_version": core.ExternalAPIVersion,
 			"extapi_http":    extapiURL,
 			"extapi_ipc":     ipcapiURL,
+			"approval_http":  approvalURL,
 		}})
 
 	abortChan := make(chan os.Signal, 1)
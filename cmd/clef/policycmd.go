@@ -0,0 +1,183 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ethereum/go-ethereum/signer/fourbyte"
+	"github.com/ethereum/go-ethereum/signer/policy"
+	"github.com/ethereum/go-ethereum/signer/storage"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	policyFlag = &cli.StringFlag{
+		Name:  "policy",
+		Usage: "Path to the declarative policy file to auto-authorize requests with",
+	}
+	policyCommand = &cli.Command{
+		Name:  "policy",
+		Usage: "Manage declarative signing policies",
+		Subcommands: []*cli.Command{
+			{
+				Action:    policyAttest,
+				Name:      "attest",
+				Usage:     "Attest that a policy file is to be used",
+				ArgsUsage: "<sha256sum>",
+				Flags: []cli.Flag{
+					logLevelFlag,
+					configdirFlag,
+					signerSecretFlag,
+				},
+				Description: `
+The attest command stores the sha256 of the policy file that you want to use for
+automatic processing of incoming requests.
+
+Whenever you make an edit to the policy file, you need to use attestation to tell
+Clef that the file is 'safe' to use.`,
+			},
+			{
+				Action:    policyTest,
+				Name:      "test",
+				Usage:     "Replay sample transaction requests against a policy",
+				ArgsUsage: "<requests.json>",
+				Flags: []cli.Flag{
+					logLevelFlag,
+					policyFlag,
+				},
+				Description: `
+The test command evaluates the transaction requests in the given file against
+the policy, and prints the outcome of each. The file contains a JSON list of
+requests:
+
+    [
+      {
+        "transaction": {"from": "0x...", "to": "0x...", "value": "0x0", ...},
+        "methodSelector": "transfer(address,uint256)",
+        "time": "2023-10-17T10:00:00Z",
+        "expect": "approve"
+      }
+    ]
+
+The transactions are validated like incoming requests, using the optional
+method selector and the embedded 4byte database. Requests are evaluated at the given time, or the current time
+if omitted, and accumulate towards the daily limits of the policy in order.
+If the expected outcome of any request ("approve", "reject" or "manual") isn't
+met, the command fails.`,
+			},
+		},
+	}
+)
+
+// policyTestRequest is a sample request replayed by the policy test command.
+type policyTestRequest struct {
+	Transaction apitypes.SendTxArgs `json:"transaction"`
+	Selector    *string             `json:"methodSelector"`
+	Time        *time.Time          `json:"time"`
+	Expect      string              `json:"expect"`
+}
+
+func policyAttest(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	configStorage, err := openConfigStorage(ctx)
+	if err != nil {
+		return err
+	}
+	val := ctx.Args().First()
+	configStorage.Put("policy_sha256", val)
+	log.Info("Policy attestation updated", "sha256", val)
+	return nil
+}
+
+func policyTest(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		utils.Fatalf("This command requires the file of the requests as argument.")
+	}
+	if !ctx.IsSet(policyFlag.Name) {
+		utils.Fatalf("The policy to test must be given with --%s.", policyFlag.Name)
+	}
+	pol, err := policy.Load(ctx.String(policyFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid policy: %v", err)
+	}
+	blob, err := os.ReadFile(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to read requests: %v", err)
+	}
+	var requests []policyTestRequest
+	if err := json.Unmarshal(blob, &requests); err != nil {
+		utils.Fatalf("Invalid requests: %v", err)
+	}
+	// Don't use the custom 4byte database, it would be updated with the selectors
+	db, err := fourbyte.New()
+	if err != nil {
+		utils.Fatalf(err.Error())
+	}
+	var (
+		engine = policy.NewEngine(pol, storage.NewEphemeralStorage())
+		failed int
+	)
+	for i, req := range requests {
+		msgs, err := db.ValidateTransaction(req.Selector, &req.Transaction)
+		if err != nil {
+			fmt.Printf("#%d: invalid transaction: %v\n", i, err)
+			if req.Expect != "" && req.Expect != string(policy.Reject) {
+				failed++
+			}
+			continue
+		}
+		now := time.Now()
+		if req.Time != nil {
+			now = *req.Time
+		}
+		decision := engine.EvaluateTxAt(&core.SignTxRequest{Transaction: req.Transaction, Callinfo: msgs.Messages}, now)
+
+		outcome := string(decision.Action)
+		switch decision.Action {
+		case policy.Approve:
+			// Approved transactions count against the daily limits as if signed
+			engine.ConfirmTx(req.Transaction.From.Address(), uint64(req.Transaction.Nonce))
+			fmt.Printf("#%d: approved by rule %q\n", i, decision.Rule)
+		case policy.Reject:
+			fmt.Printf("#%d: rejected by rule %q\n", i, decision.Rule)
+		default:
+			outcome = "manual"
+			fmt.Printf("#%d: not matched, passed on for manual approval\n", i)
+		}
+		for _, reason := range decision.Reasons {
+			fmt.Printf("    %s\n", reason)
+		}
+		if req.Expect != "" && req.Expect != outcome {
+			fmt.Printf("    expected %s\n", req.Expect)
+			failed++
+		}
+	}
+	if failed > 0 {
+		utils.Fatalf("%d of %d requests did not meet expectations", failed, len(requests))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestPolicyTest tests clef policy test
+func TestPolicyTest(t *testing.T) {
+	t.Parallel()
+	t.Run("expectations-met", func(t *testing.T) {
+		clef := runClef(t, "--suppress-bootwarn", "policy", "test", "--policy", "testdata/policy.yaml", "testdata/policy_requests.json")
+		out := string(clef.Output())
+		for _, want := range []string{
+			`#0: approved by rule "batcher"`,
+			`#1: approved by rule "treasury"`,
+			`treasury: daily value 2000000000000000000 above limit 1500000000000000000`,
+			`treasury: not allowed on Saturday`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output misses %q:\n%s", want, out)
+			}
+		}
+		if clef.ExitStatus() != 0 {
+			t.Errorf("exit status %d", clef.ExitStatus())
+		}
+	})
+	t.Run("expectations-failed", func(t *testing.T) {
+		blob, err := os.ReadFile("testdata/policy_requests.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		requests := filepath.Join(t.TempDir(), "requests.json")
+		os.WriteFile(requests, []byte(strings.Replace(string(blob), `"expect": "manual"`, `"expect": "reject"`, 1)), 0600)
+
+		clef := runClef(t, "--suppress-bootwarn", "policy", "test", "--policy", "testdata/policy.yaml", requests)
+		clef.WaitExit()
+		if have, want := clef.StderrText(), "Fatal: 1 of 4 requests did not meet expectations\n"; have != want {
+			t.Errorf("have %q, want %q", have, want)
+		}
+	})
+}
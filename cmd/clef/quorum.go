@@ -0,0 +1,106 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/quorum"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	quorumApproversFlag = &cli.StringFlag{
+		Name:  "quorum.approvers",
+		Usage: "Comma separated list of approver addresses, which have to approve signing requests instead of the UI",
+	}
+	quorumThresholdFlag = &cli.IntFlag{
+		Name:  "quorum.threshold",
+		Usage: "Number of approvers required to approve a signing request",
+		Value: 1,
+	}
+	quorumTimeoutFlag = &cli.DurationFlag{
+		Name:  "quorum.timeout",
+		Usage: "Time after which signing requests without enough approvals are rejected",
+		Value: time.Hour,
+	}
+	quorumPortFlag = &cli.IntFlag{
+		Name:  "quorum.port",
+		Usage: "HTTP-RPC server listening port of the approval API",
+		Value: node.DefaultHTTPPort + 7,
+	}
+)
+
+// setupQuorum wraps the UI into a quorum of approvers, if configured, and starts
+// serving the approval API on http.addr. It returns the URL of the approval API
+// and a function stopping it. The votes are recorded in the audit log, if any.
+func setupQuorum(c *cli.Context, ui core.UIClientAPI, audit log.Logger) (core.UIClientAPI, string, func()) {
+	if !c.IsSet(quorumApproversFlag.Name) {
+		return ui, "n/a", func() {}
+	}
+	var approvers []common.Address
+	for _, addr := range utils.SplitAndTrim(c.String(quorumApproversFlag.Name)) {
+		if !common.IsHexAddress(addr) {
+			utils.Fatalf("Invalid approver address %q", addr)
+		}
+		approvers = append(approvers, common.HexToAddress(addr))
+	}
+	if audit == nil {
+		audit = log.Root()
+	}
+	q, err := quorum.New(ui, quorum.Config{
+		Approvers: approvers,
+		Threshold: c.Int(quorumThresholdFlag.Name),
+		Timeout:   c.Duration(quorumTimeoutFlag.Name),
+		ChainID:   big.NewInt(c.Int64(chainIdFlag.Name)),
+	}, audit.New("api", "approval"))
+	if err != nil {
+		utils.Fatalf("Invalid quorum: %v", err)
+	}
+	// The approval API is served separately, it must not be reachable by the
+	// external callers unless intended
+	srv := rpc.NewServer()
+	srv.SetBatchLimits(node.DefaultConfig.BatchRequestLimit, node.DefaultConfig.BatchResponseMaxSize)
+	if err := srv.RegisterName("approval", quorum.NewApprovalAPI(q)); err != nil {
+		utils.Fatalf("Could not register approval API: %v", err)
+	}
+	vhosts := utils.SplitAndTrim(c.String(utils.HTTPVirtualHostsFlag.Name))
+	handler := node.NewHTTPHandlerStack(srv, nil, vhosts, nil)
+
+	endpoint := net.JoinHostPort(c.String(utils.HTTPListenAddrFlag.Name), fmt.Sprintf("%d", c.Int(quorumPortFlag.Name)))
+	httpServer, addr, err := node.StartHTTPEndpoint(endpoint, rpc.DefaultHTTPTimeouts, handler)
+	if err != nil {
+		utils.Fatalf("Could not start approval api: %v", err)
+	}
+	url := fmt.Sprintf("http://%v/", addr)
+	log.Info("Approval endpoint opened", "url", url, "approvers", len(approvers), "threshold", c.Int(quorumThresholdFlag.Name))
+
+	return q, url, func() {
+		httpServer.Shutdown(context.Background())
+		log.Info("Approval endpoint closed", "url", url)
+	}
+}
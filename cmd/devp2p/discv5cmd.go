@@ -38,6 +38,8 @@ var (
 			discv5CrawlCommand,
 			discv5TestCommand,
 			discv5ListenCommand,
+			discv5TopicRegisterCommand,
+			discv5TopicQueryCommand,
 		},
 	}
 	discv5PingCommand = &cli.Command{
@@ -77,6 +79,22 @@ var (
 		Action: discv5Listen,
 		Flags:  discoveryNodeFlags,
 	}
+	discv5TopicRegisterCommand = &cli.Command{
+		Name:      "topic-register",
+		Usage:     "Runs a node advertising itself for a topic",
+		ArgsUsage: "<topic>",
+		Action:    discv5TopicRegister,
+		Flags:     discoveryNodeFlags,
+	}
+	discv5TopicQueryCommand = &cli.Command{
+		Name:      "topic-query",
+		Usage:     "Finds the nodes registered for a topic",
+		ArgsUsage: "<topic>",
+		Action:    discv5TopicQuery,
+		Flags: flags.Merge(discoveryNodeFlags, []cli.Flag{
+			crawlTimeoutFlag,
+		}),
+	}
 )
 
 func discv5Ping(ctx *cli.Context) error {
@@ -138,6 +156,35 @@ func discv5Listen(ctx *cli.Context) error {
 	select {}
 }
 
+func discv5TopicRegister(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("need topic as argument")
+	}
+	disc, _ := startV5(ctx)
+	defer disc.Close()
+
+	disc.RegisterTopic(discover.NewTopic(ctx.Args().First()))
+	fmt.Println(disc.Self())
+	select {}
+}
+
+func discv5TopicQuery(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("need topic as argument")
+	}
+	disc, _ := startV5(ctx)
+	defer disc.Close()
+
+	it := disc.TopicNodes(discover.NewTopic(ctx.Args().First()))
+	timer := time.AfterFunc(ctx.Duration(crawlTimeoutFlag.Name), it.Close)
+	defer timer.Stop()
+
+	for it.Next() {
+		fmt.Println(it.Node())
+	}
+	return nil
+}
+
 // startV5 starts an ephemeral discovery v5 node.
 func startV5(ctx *cli.Context) (*discover.UDPv5, discover.Config) {
 	ln, config := makeDiscoveryConfig(ctx)
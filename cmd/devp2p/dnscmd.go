@@ -22,12 +22,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/dnsdisc"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/urfave/cli/v2"
@@ -44,6 +47,7 @@ var (
 			dnsCloudflareCommand,
 			dnsRoute53Command,
 			dnsRoute53NukeCommand,
+			dnsServeCommand,
 		},
 	}
 	dnsSyncCommand = &cli.Command{
@@ -97,6 +101,16 @@ var (
 			route53RegionFlag,
 		},
 	}
+	dnsServeCommand = &cli.Command{
+		Name:  "serve",
+		Usage: "Run an authoritative DNS server for a discovery tree",
+		Description: `Serve publishes the TXT records of a signed tree. The tree directory is checked for
+updates periodically. If a key file is given, updates are signed automatically,
+otherwise they must be signed using 'devp2p dns sign' before they are published.`,
+		ArgsUsage: "<tree-directory> [ <key-file> ]",
+		Action:    dnsServe,
+		Flags:     []cli.Flag{dnsAddrFlag, dnsReloadFlag, dnsDomainFlag},
+	}
 )
 
 var (
@@ -112,6 +126,16 @@ var (
 		Name:  "seq",
 		Usage: "New sequence number of the tree",
 	}
+	dnsAddrFlag = &cli.StringFlag{
+		Name:  "addr",
+		Usage: "Listening address of the DNS server",
+		Value: ":53",
+	}
+	dnsReloadFlag = &cli.DurationFlag{
+		Name:  "reload",
+		Usage: "Interval between checks for updates of the tree directory",
+		Value: time.Minute,
+	}
 )
 
 const (
@@ -154,38 +178,75 @@ func dnsSign(ctx *cli.Context) error {
 		defdir  = ctx.Args().Get(0)
 		keyfile = ctx.Args().Get(1)
 		def     = loadTreeDefinition(defdir)
-		domain  = directoryName(defdir)
 	)
-	if def.Meta.URL != "" {
-		d, _, err := dnsdisc.ParseURL(def.Meta.URL)
-		if err != nil {
-			return fmt.Errorf("invalid 'url' field: %v", err)
-		}
-		domain = d
-	}
-	if ctx.IsSet(dnsDomainFlag.Name) {
-		domain = ctx.String(dnsDomainFlag.Name)
+	domain, err := treeDomain(ctx, defdir, def)
+	if err != nil {
+		return err
 	}
+	key := loadSigningKey(keyfile)
 	if ctx.IsSet(dnsSeqFlag.Name) {
 		def.Meta.Seq = ctx.Uint(dnsSeqFlag.Name)
+	} else if currentSignedTree(def, domain, &key.PublicKey) != nil {
+		fmt.Println("Tree is unchanged, keeping existing signature.")
+		return nil
 	} else {
 		def.Meta.Seq++ // Auto-bump sequence number if not supplied via flag.
 	}
-	t, err := dnsdisc.MakeTree(def.Meta.Seq, def.Nodes, def.Meta.Links)
+	_, def, err = signTree(def, domain, key)
 	if err != nil {
 		return err
 	}
+	writeTreeMetadata(defdir, def)
+	return nil
+}
 
-	key := loadSigningKey(keyfile)
+// treeDomain returns the domain name of a tree. It is taken from the --domain flag,
+// the URL of the last signature or the name of the tree directory, in this order.
+func treeDomain(ctx *cli.Context, defdir string, def *dnsDefinition) (string, error) {
+	if ctx.IsSet(dnsDomainFlag.Name) {
+		return ctx.String(dnsDomainFlag.Name), nil
+	}
+	if def.Meta.URL != "" {
+		d, _, err := dnsdisc.ParseURL(def.Meta.URL)
+		if err != nil {
+			return "", fmt.Errorf("invalid 'url' field: %v", err)
+		}
+		return d, nil
+	}
+	return directoryName(defdir), nil
+}
+
+// signTree creates and signs the tree of a definition. It returns the tree and
+// the definition including the new signature.
+func signTree(def *dnsDefinition, domain string, key *ecdsa.PrivateKey) (*dnsdisc.Tree, *dnsDefinition, error) {
+	t, err := dnsdisc.MakeTree(def.Meta.Seq, def.Nodes, def.Meta.Links)
+	if err != nil {
+		return nil, nil, err
+	}
 	url, err := t.Sign(key, domain)
 	if err != nil {
-		return fmt.Errorf("can't sign: %v", err)
+		return nil, nil, fmt.Errorf("can't sign: %v", err)
 	}
-
 	def = treeToDefinition(url, t)
 	def.Meta.LastModified = time.Now()
-	writeTreeMetadata(defdir, def)
-	return nil
+	return t, def, nil
+}
+
+// currentSignedTree returns the tree of a definition if its signature was made for
+// the current content, domain and signer of the tree. Otherwise it returns nil.
+func currentSignedTree(def *dnsDefinition, domain string, pubkey *ecdsa.PublicKey) *dnsdisc.Tree {
+	if def.Meta.URL == "" || def.Meta.Sig == "" {
+		return nil
+	}
+	d, signer, err := dnsdisc.ParseURL(def.Meta.URL)
+	if err != nil || d != domain || !signer.Equal(pubkey) {
+		return nil
+	}
+	t, err := dnsdisc.MakeTree(def.Meta.Seq, def.Nodes, def.Meta.Links)
+	if err != nil || t.SetSignature(pubkey, def.Meta.Sig) != nil {
+		return nil
+	}
+	return t
 }
 
 // directoryName returns the directory name of the given path.
@@ -242,6 +303,85 @@ func dnsToRoute53(ctx *cli.Context) error {
 	return client.deploy(domain, t)
 }
 
+// dnsServe performs dnsServeCommand.
+func dnsServe(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("need tree definition directory as argument")
+	}
+	var (
+		defdir = ctx.Args().Get(0)
+		key    *ecdsa.PrivateKey
+	)
+	if ctx.NArg() > 1 {
+		key = loadSigningKey(ctx.Args().Get(1))
+	}
+	domain, t, err := loadServedTree(ctx, defdir, key)
+	if err != nil {
+		return err
+	}
+	srv := dnsdisc.NewServer(dnsdisc.ServerConfig{
+		RootTTL: rootTTL * time.Second,
+		NodeTTL: treeNodeTTL * time.Second,
+	})
+	srv.SetTree(domain, t)
+	if err := srv.Start(ctx.String(dnsAddrFlag.Name)); err != nil {
+		return err
+	}
+	defer srv.Close()
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	reload := time.NewTicker(ctx.Duration(dnsReloadFlag.Name))
+	defer reload.Stop()
+	for {
+		select {
+		case <-reload.C:
+			newDomain, t, err := loadServedTree(ctx, defdir, key)
+			if err != nil {
+				log.Error("Can't load tree", "err", err)
+				continue
+			}
+			if newDomain != domain {
+				log.Error("Tree domain has changed, restart required", "old", domain, "new", newDomain)
+				continue
+			}
+			srv.SetTree(domain, t)
+		case <-sigc:
+			return nil
+		}
+	}
+}
+
+// loadServedTree loads the tree of a definition directory for serving. If a
+// signing key is given and the tree has changed since it was last signed, the
+// tree is signed with an increased sequence number. Otherwise the tree must carry
+// a valid signature.
+func loadServedTree(ctx *cli.Context, defdir string, key *ecdsa.PrivateKey) (string, *dnsdisc.Tree, error) {
+	if key == nil {
+		return loadTreeDefinitionForExport(defdir)
+	}
+	def, err := readTreeDefinition(defdir)
+	if err != nil {
+		return "", nil, err
+	}
+	domain, err := treeDomain(ctx, defdir, def)
+	if err != nil {
+		return "", nil, err
+	}
+	if t := currentSignedTree(def, domain, &key.PublicKey); t != nil {
+		return domain, t, nil
+	}
+	def.Meta.Seq++
+	t, def, err := signTree(def, domain, key)
+	if err != nil {
+		return "", nil, err
+	}
+	writeTreeMetadata(defdir, def)
+	log.Info("Signed updated tree", "domain", domain, "seq", t.Seq())
+	return domain, t, nil
+}
+
 // dnsNukeRoute53 performs dnsRoute53NukeCommand.
 func dnsNukeRoute53(ctx *cli.Context) error {
 	if ctx.NArg() != 1 {
@@ -316,11 +456,21 @@ func treeToDefinition(url string, t *dnsdisc.Tree) *dnsDefinition {
 
 // loadTreeDefinition loads a directory in 'definition' format.
 func loadTreeDefinition(directory string) *dnsDefinition {
+	def, err := readTreeDefinition(directory)
+	if err != nil {
+		exit(err)
+	}
+	return def
+}
+
+// readTreeDefinition is like loadTreeDefinition, but returns errors instead of
+// exiting.
+func readTreeDefinition(directory string) (*dnsDefinition, error) {
 	metaFile, nodesFile := treeDefinitionFiles(directory)
 	var def dnsDefinition
 	err := common.LoadJSON(metaFile, &def.Meta)
 	if err != nil && !os.IsNotExist(err) {
-		exit(err)
+		return nil, err
 	}
 	if def.Meta.Links == nil {
 		def.Meta.Links = []string{}
@@ -328,22 +478,28 @@ func loadTreeDefinition(directory string) *dnsDefinition {
 	// Check link syntax.
 	for _, link := range def.Meta.Links {
 		if _, _, err := dnsdisc.ParseURL(link); err != nil {
-			exit(fmt.Errorf("invalid link %q: %v", link, err))
+			return nil, fmt.Errorf("invalid link %q: %v", link, err)
 		}
 	}
 	// Check/convert nodes.
-	nodes := loadNodesJSON(nodesFile)
+	var nodes nodeSet
+	if err := common.LoadJSON(nodesFile, &nodes); err != nil {
+		return nil, err
+	}
 	if err := nodes.verify(); err != nil {
-		exit(err)
+		return nil, err
 	}
 	def.Nodes = nodes.nodes()
-	return &def
+	return &def, nil
 }
 
 // loadTreeDefinitionForExport loads a DNS tree and ensures it is signed.
 func loadTreeDefinitionForExport(dir string) (domain string, t *dnsdisc.Tree, err error) {
 	metaFile, _ := treeDefinitionFiles(dir)
-	def := loadTreeDefinition(dir)
+	def, err := readTreeDefinition(dir)
+	if err != nil {
+		return "", nil, err
+	}
 	if def.Meta.URL == "" {
 		return "", nil, fmt.Errorf("missing 'url' field in %v", metaFile)
 	}
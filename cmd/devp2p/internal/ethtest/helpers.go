@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/internal/utesting"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/rlpx"
 )
 
@@ -47,12 +48,17 @@ var (
 // dial attempts to dial the given node and perform a handshake,
 // returning the created Conn if successful.
 func (s *Suite) dial() (*Conn, error) {
+	return dialNode(s.Dest)
+}
+
+// dialNode performs the encryption handshake with the given node.
+func dialNode(dest *enode.Node) (*Conn, error) {
 	// dial
-	fd, err := net.Dial("tcp", fmt.Sprintf("%v:%d", s.Dest.IP(), s.Dest.TCP()))
+	fd, err := net.Dial("tcp", fmt.Sprintf("%v:%d", dest.IP(), dest.TCP()))
 	if err != nil {
 		return nil, err
 	}
-	conn := Conn{Conn: rlpx.NewConn(fd, s.Dest.Pubkey())}
+	conn := Conn{Conn: rlpx.NewConn(fd, dest.Pubkey())}
 	// do encHandshake
 	conn.ourKey, _ = crypto.GenerateKey()
 	_, err = conn.Handshake(conn.ourKey)
@@ -174,6 +180,20 @@ loop:
 			}
 			message = msg
 			break loop
+		case *Status69:
+			head := chain.blocks[chain.Len()-1]
+			if have, want := msg.LatestBlockHash, head.Hash(); have != want {
+				return nil, fmt.Errorf("wrong latest block in status, want:  %#x (block %d) have %#x",
+					want, head.NumberU64(), have)
+			}
+			if have, want := msg.ForkID, chain.ForkID(); !reflect.DeepEqual(have, want) {
+				return nil, fmt.Errorf("wrong fork ID in status: have %v, want %v", have, want)
+			}
+			if have, want := msg.ProtocolVersion, c.ourHighestProtoVersion; have != uint32(want) {
+				return nil, fmt.Errorf("wrong protocol version: have %v, want %v", have, want)
+			}
+			message = msg
+			break loop
 		case *Disconnect:
 			return nil, fmt.Errorf("disconnect received: %v", msg.Reason)
 		case *Ping:
@@ -187,6 +207,22 @@ loop:
 	if c.negotiatedProtoVersion == 0 {
 		return nil, errors.New("eth protocol version must be set in Conn")
 	}
+	if status == nil && c.negotiatedProtoVersion >= eth.ETH69 {
+		// eth/69 drops the total difficulty, announce the served blocks instead
+		head := chain.blocks[chain.Len()-1]
+		if err := c.Write(&Status69{
+			ProtocolVersion: uint32(c.negotiatedProtoVersion),
+			NetworkID:       chain.chainConfig.ChainID.Uint64(),
+			Genesis:         chain.blocks[0].Hash(),
+			ForkID:          chain.ForkID(),
+			EarliestBlock:   0,
+			LatestBlock:     head.NumberU64(),
+			LatestBlockHash: head.Hash(),
+		}); err != nil {
+			return nil, fmt.Errorf("write to connection failed: %v", err)
+		}
+		return message, nil
+	}
 	if status == nil {
 		// default status message
 		status = &Status{
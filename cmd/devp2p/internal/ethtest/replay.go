@@ -0,0 +1,201 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package ethtest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/capture"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var errReplayDone = errors.New("replay done")
+
+// Replayer feeds the eth messages recorded in a capture into a node. Every peer
+// of the capture is impersonated by a separate connection, which sends the
+// messages that the peer sent to the capturing node.
+type Replayer struct {
+	Dest  *enode.Node
+	chain *Chain
+
+	// Speed is the replay speed relative to the capture. If zero, messages are
+	// sent as fast as possible.
+	Speed float64
+
+	// Linger is the time to keep the connections open after the last message,
+	// allowing the node to process the messages.
+	Linger time.Duration
+}
+
+// ReplayResult is the outcome of replaying the messages of a captured peer.
+type ReplayResult struct {
+	Peer enode.ID // ID of the captured peer
+	Sent int      // Number of messages sent
+	Err  error    // Reason why the session ended early, e.g. disconnect by the node
+}
+
+// NewReplayer creates a replayer for the given node. The node must serve the
+// given chain, which is used for the status exchange.
+func NewReplayer(dest *enode.Node, chainfile string, genesisfile string) (*Replayer, error) {
+	chain, err := loadChain(chainfile, genesisfile)
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{Dest: dest, chain: chain, Linger: time.Second}, nil
+}
+
+// Replay sends the inbound eth messages of the given records. Messages of other
+// protocols, the messages sent by the capturing node and the status handshake
+// are skipped, the handshake is performed with the replayer's chain instead.
+func (r *Replayer) Replay(records []*capture.Record) ([]*ReplayResult, error) {
+	var (
+		sessions = make(map[enode.ID]*replaySession)
+		results  []*ReplayResult
+		start    = time.Now()
+		first    time.Time
+	)
+	defer func() {
+		for _, s := range sessions {
+			s.close()
+		}
+	}()
+	for _, rec := range records {
+		if !rec.Inbound || rec.Protocol != eth.ProtocolName || rec.Code == eth.StatusMsg {
+			continue
+		}
+		if first.IsZero() {
+			first = rec.Time
+		}
+		if r.Speed > 0 {
+			due := start.Add(time.Duration(float64(rec.Time.Sub(first)) / r.Speed))
+			time.Sleep(time.Until(due))
+		}
+		s := sessions[rec.Peer]
+		if s == nil {
+			s = &replaySession{result: &ReplayResult{Peer: rec.Peer}}
+			sessions[rec.Peer] = s
+			results = append(results, s.result)
+			if err := s.open(r.Dest, r.chain, rec.Version); err != nil {
+				s.fail(fmt.Errorf("can't connect: %v", err))
+			}
+		}
+		s.send(rec)
+	}
+	time.Sleep(r.Linger)
+	return results, nil
+}
+
+// replaySession is the connection of a single captured peer.
+type replaySession struct {
+	conn   *Conn
+	wg     sync.WaitGroup
+	mu     sync.Mutex // protects writes and result
+	result *ReplayResult
+}
+
+func (s *replaySession) open(dest *enode.Node, chain *Chain, version uint) error {
+	conn, err := dialNode(dest)
+	if err != nil {
+		return err
+	}
+	// Speak the eth version of the captured peer, so messages have the
+	// expected encoding.
+	conn.caps = []p2p.Cap{{Name: eth.ProtocolName, Version: version}}
+	conn.ourHighestProtoVersion = version
+	if err := conn.peer(chain, nil); err != nil {
+		conn.Close()
+		return err
+	}
+	s.conn = conn
+	s.wg.Add(1)
+	go s.readLoop()
+	return nil
+}
+
+// readLoop keeps the connection alive, answering pings and tracking disconnects.
+func (s *replaySession) readLoop() {
+	defer s.wg.Done()
+	for {
+		code, data, _, err := s.conn.Conn.Read()
+		if err != nil {
+			s.fail(err)
+			return
+		}
+		switch int(code) {
+		case (Ping{}).Code():
+			s.mu.Lock()
+			s.conn.Write(&Pong{})
+			s.mu.Unlock()
+		case (Disconnect{}).Code():
+			var reason []p2p.DiscReason
+			rlp.DecodeBytes(data, &reason)
+			if len(reason) == 0 {
+				s.fail(errors.New("disconnected by node"))
+			} else {
+				s.fail(fmt.Errorf("disconnected by node: %v", reason[0]))
+			}
+			return
+		}
+	}
+}
+
+func (s *replaySession) send(rec *capture.Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.result.Err != nil {
+		return
+	}
+	code := uint64((Status{}).Code()) + rec.Code
+	if _, err := s.conn.Conn.Write(code, rec.Payload); err != nil {
+		s.result.Err = err
+		return
+	}
+	s.result.Sent++
+}
+
+// fail records the first error of the session.
+func (s *replaySession) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.result.Err == nil {
+		s.result.Err = err
+	}
+}
+
+func (s *replaySession) close() {
+	if s.conn != nil {
+		s.mu.Lock()
+		if s.result.Err == nil {
+			// Mark the session as done, so the read error on close isn't reported.
+			s.result.Err = errReplayDone
+		}
+		s.mu.Unlock()
+		s.conn.Close()
+		s.wg.Wait()
+	}
+	if s.result.Err == errReplayDone {
+		s.result.Err = nil
+	}
+}
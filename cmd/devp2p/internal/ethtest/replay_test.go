@@ -0,0 +1,74 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package ethtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p/capture"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestReplay68(t *testing.T) { testReplay(t, eth.ETH68) }
+func TestReplay69(t *testing.T) { testReplay(t, eth.ETH69) }
+
+func testReplay(t *testing.T, version uint) {
+	geth, err := StartNode(halfchainFile, genesisFile)
+	if err != nil {
+		t.Fatalf("could not run geth: %v", err)
+	}
+	defer geth.Close()
+
+	replayer, err := NewReplayer(geth.Server().Self(), halfchainFile, genesisFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer.Linger = 500 * time.Millisecond
+
+	request, _ := rlp.EncodeToBytes(&eth.GetBlockHeadersPacket{
+		RequestId:              1,
+		GetBlockHeadersRequest: &eth.GetBlockHeadersRequest{Origin: eth.HashOrNumber{Number: 1}, Amount: 1},
+	})
+	var (
+		good  = enode.ID{1}
+		bad   = enode.ID{2}
+		now   = time.Now()
+		inbox = []*capture.Record{
+			{Time: now, Peer: good, Inbound: true, Protocol: "eth", Version: version, Code: eth.StatusMsg, Payload: []byte{0xc0}},
+			{Time: now, Peer: good, Inbound: true, Protocol: "eth", Version: version, Code: eth.GetBlockHeadersMsg, Payload: request},
+			{Time: now, Peer: good, Inbound: false, Protocol: "eth", Version: version, Code: eth.BlockHeadersMsg, Payload: []byte{0xc0}},
+			{Time: now, Peer: good, Inbound: true, Protocol: "snap", Version: 1, Code: 0, Payload: []byte{0xc0}},
+			{Time: now, Peer: bad, Inbound: true, Protocol: "eth", Version: version, Code: eth.GetBlockHeadersMsg, Payload: []byte{0x01, 0x02}},
+		}
+	)
+	results, err := replayer.Replay(inbox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("wrong number of sessions: %d", len(results))
+	}
+	if r := results[0]; r.Peer != good || r.Sent != 1 || r.Err != nil {
+		t.Errorf("wrong result for good peer: %+v", r)
+	}
+	if r := results[1]; r.Peer != bad || r.Sent != 1 || r.Err == nil {
+		t.Errorf("wrong result for bad peer: %+v", r)
+	}
+}
@@ -19,13 +19,8 @@ package ethtest
 import (
 	"os"
 	"testing"
-	"time"
 
-	"github.com/ethereum/go-ethereum/eth"
-	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/internal/utesting"
-	"github.com/ethereum/go-ethereum/node"
-	"github.com/ethereum/go-ethereum/p2p"
 )
 
 var (
@@ -35,7 +30,7 @@ var (
 )
 
 func TestEthSuite(t *testing.T) {
-	geth, err := runGeth()
+	geth, err := StartNode(halfchainFile, genesisFile)
 	if err != nil {
 		t.Fatalf("could not run geth: %v", err)
 	}
@@ -56,7 +51,7 @@ func TestEthSuite(t *testing.T) {
 }
 
 func TestSnapSuite(t *testing.T) {
-	geth, err := runGeth()
+	geth, err := StartNode(halfchainFile, genesisFile)
 	if err != nil {
 		t.Fatalf("could not run geth: %v", err)
 	}
@@ -75,53 +70,3 @@ func TestSnapSuite(t *testing.T) {
 		})
 	}
 }
-
-// runGeth creates and starts a geth node
-func runGeth() (*node.Node, error) {
-	stack, err := node.New(&node.Config{
-		P2P: p2p.Config{
-			ListenAddr:  "127.0.0.1:0",
-			NoDiscovery: true,
-			MaxPeers:    10, // in case a test requires multiple connections, can be changed in the future
-			NoDial:      true,
-		},
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	err = setupGeth(stack)
-	if err != nil {
-		stack.Close()
-		return nil, err
-	}
-	if err = stack.Start(); err != nil {
-		stack.Close()
-		return nil, err
-	}
-	return stack, nil
-}
-
-func setupGeth(stack *node.Node) error {
-	chain, err := loadChain(halfchainFile, genesisFile)
-	if err != nil {
-		return err
-	}
-
-	backend, err := eth.New(stack, &ethconfig.Config{
-		Genesis:        &chain.genesis,
-		NetworkId:      chain.genesis.Config.ChainID.Uint64(), // 19763
-		DatabaseCache:  10,
-		TrieCleanCache: 10,
-		TrieDirtyCache: 16,
-		TrieTimeout:    60 * time.Minute,
-		SnapshotCache:  10,
-	})
-	if err != nil {
-		return err
-	}
-	backend.SetSynced()
-
-	_, err = backend.BlockChain().InsertChain(chain.blocks[1:])
-	return err
-}
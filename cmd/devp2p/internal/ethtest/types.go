@@ -87,6 +87,12 @@ type Status eth.StatusPacket
 func (msg Status) Code() int     { return 16 }
 func (msg Status) ReqID() uint64 { return 0 }
 
+// Status69 is the network packet for the status message for eth/69 and later.
+type Status69 eth.StatusPacket69
+
+func (msg Status69) Code() int     { return 16 }
+func (msg Status69) ReqID() uint64 { return 0 }
+
 // NewBlockHashes is the network packet for the block announcements.
 type NewBlockHashes eth.NewBlockHashesPacket
 
@@ -178,7 +184,11 @@ func (c *Conn) Read() Message {
 	case (Disconnect{}).Code():
 		msg = new(Disconnect)
 	case (Status{}).Code():
-		msg = new(Status)
+		if c.negotiatedProtoVersion >= eth.ETH69 {
+			msg = new(Status69)
+		} else {
+			msg = new(Status)
+		}
 	case (GetBlockHeaders{}).Code():
 		ethMsg := new(eth.GetBlockHeadersPacket)
 		if err := rlp.DecodeBytes(rawData, ethMsg); err != nil {
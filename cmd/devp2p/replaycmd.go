@@ -0,0 +1,108 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/cmd/devp2p/internal/ethtest"
+	"github.com/ethereum/go-ethereum/p2p/capture"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	replayCommand = &cli.Command{
+		Name:  "replay",
+		Usage: "Replays a message capture into an eth node",
+		Description: `Replay sends the eth messages recorded by geth --netcapture to a node, impersonating
+each captured peer with a separate connection. Unless --remote is given, the messages
+are fed into an in-process node initialized with the given chain.`,
+		ArgsUsage: "<capture> <chain.rlp> <genesis.json>",
+		Action:    replay,
+		Flags: []cli.Flag{
+			replaySpeedFlag,
+			remoteEnodeFlag,
+		},
+	}
+	replaySpeedFlag = &cli.Float64Flag{
+		Name:  "speed",
+		Usage: "Replay speed relative to the capture (0 = as fast as possible)",
+	}
+)
+
+func replay(ctx *cli.Context) error {
+	if ctx.NArg() < 3 {
+		exit("need capture file, chain.rlp and genesis.json as arguments")
+	}
+	var (
+		capfile     = ctx.Args().Get(0)
+		chainfile   = ctx.Args().Get(1)
+		genesisfile = ctx.Args().Get(2)
+	)
+	records, err := readCapture(capfile)
+	if err != nil {
+		return err
+	}
+
+	// Start the target node.
+	var dest *enode.Node
+	if ctx.IsSet(remoteEnodeFlag.Name) {
+		if dest, err = parseNode(ctx.String(remoteEnodeFlag.Name)); err != nil {
+			return err
+		}
+	} else {
+		stack, err := ethtest.StartNode(chainfile, genesisfile)
+		if err != nil {
+			return fmt.Errorf("can't start node: %v", err)
+		}
+		defer stack.Close()
+		dest = stack.Server().Self()
+	}
+
+	replayer, err := ethtest.NewReplayer(dest, chainfile, genesisfile)
+	if err != nil {
+		return err
+	}
+	replayer.Speed = ctx.Float64(replaySpeedFlag.Name)
+	results, err := replayer.Replay(records)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		status := "ok"
+		if r.Err != nil {
+			status = r.Err.Error()
+		}
+		fmt.Printf("%x  sent %d messages  %s\n", r.Peer[:8], r.Sent, status)
+	}
+	return nil
+}
+
+func readCapture(file string) ([]*capture.Record, error) {
+	fd, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	r, err := capture.NewReader(fd)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadAll()
+}
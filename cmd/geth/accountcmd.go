@@ -19,16 +19,23 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/hdwallet"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/console/prompt"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/urfave/cli/v2"
 )
 
 var (
+	bip39PassphraseFlag = &cli.BoolFlag{
+		Name:  "bip39passphrase",
+		Usage: "Prompt for the BIP-39 passphrase extending the mnemonic",
+	}
 	walletCommand = &cli.Command{
 		Name:      "wallet",
 		Usage:     "Manage Ethereum presale wallets",
@@ -182,6 +189,65 @@ Note:
 As you can directly copy your encrypted accounts to another ethereum instance,
 this import mechanism is not needed when you transfer an account between
 nodes.
+`,
+			},
+			{
+				Name:   "import-mnemonic",
+				Usage:  "Import a BIP-39 mnemonic into a new HD wallet",
+				Action: accountImportMnemonic,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.KeyStoreDirFlag,
+					utils.PasswordFileFlag,
+					utils.LightKDFFlag,
+					bip39PassphraseFlag,
+				},
+				ArgsUsage: "[<mnemonicFile>]",
+				Description: `
+    geth account import-mnemonic [options] [<mnemonicFile>]
+
+Imports a BIP-39 mnemonic from <mnemonicFile>, or from the terminal if no file is
+given, and creates a new HD wallet in the "hd" directory of the keystore. Prints
+the first account of the wallet, derived along the default path m/44'/60'/0'/0/0.
+
+The seed of the wallet is saved in encrypted format, you are prompted for a
+password. For non-interactive use the password can be specified with the
+--password flag. If the mnemonic is extended with a BIP-39 passphrase, use
+--bip39passphrase to be prompted for it.
+
+Further accounts are derived when the wallet is opened, through the
+personal_deriveAccount RPC method, or discovered automatically based on the
+chain state.
+
+EIP-2335 keystores can't be imported: they hold BLS12-381 validator keys, which
+can't sign execution layer transactions.
+`,
+			},
+			{
+				Name:      "migrate",
+				Usage:     "Store the keys of existing accounts in the kernel keyring",
+				Action:    accountMigrate,
+				ArgsUsage: "[<address> ...]",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.KeyStoreDirFlag,
+					utils.PasswordFileFlag,
+					utils.LightKDFFlag,
+				},
+				Description: `
+    geth account migrate [options] [<address> ...]
+
+Decrypts the keys of the given accounts, or all accounts in the keystore if none
+are given, and stores them in the kernel keyring (Linux only). Geth started with
+--keystore.keyring then unlocks these accounts without a password.
+
+You are prompted for the password of each account. For non-interactive use the
+passwords can be specified with the --password flag, one per line in the order
+of the accounts.
+
+The encrypted key files are left in place. The kernel keyring doesn't survive
+reboots, and its keys expire after a few days of not being used, after which the
+accounts need to be migrated again.
 `,
 			},
 		},
@@ -225,6 +291,13 @@ func unlockAccount(ks *keystore.KeyStore, address string, i int, passwords []str
 	if err != nil {
 		utils.Fatalf("Could not list accounts: %v", err)
 	}
+	// Keys held in the keyring are unlocked without a password
+	if ks.InKeyring(account) {
+		if err = ks.UnlockFromKeyring(account); err == nil {
+			log.Info("Unlocked account from keyring", "address", account.Address.Hex())
+			return account, ""
+		}
+	}
 	for trials := 0; trials < 3; trials++ {
 		prompt := fmt.Sprintf("Unlocking account %s | Attempt %d/%d", address, trials+1, 3)
 		password := utils.GetPassPhraseWithList(prompt, false, i, passwords)
@@ -382,3 +455,98 @@ func accountImport(ctx *cli.Context) error {
 	fmt.Printf("Address: {%x}\n", acct.Address)
 	return nil
 }
+
+// accountImportMnemonic imports a BIP-39 mnemonic into a new HD wallet.
+func accountImportMnemonic(ctx *cli.Context) error {
+	if ctx.Args().Len() > 1 {
+		utils.Fatalf("mnemonic file must be given as the only argument")
+	}
+	var mnemonic string
+	if ctx.Args().Len() == 1 {
+		blob, err := os.ReadFile(ctx.Args().First())
+		if err != nil {
+			utils.Fatalf("Failed to read the mnemonic: %v", err)
+		}
+		mnemonic = string(blob)
+	} else {
+		input, err := prompt.Stdin.PromptPassword("Mnemonic: ")
+		if err != nil {
+			utils.Fatalf("Failed to read the mnemonic: %v", err)
+		}
+		mnemonic = input
+	}
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+
+	var mnemonicPassword string
+	if ctx.Bool(bip39PassphraseFlag.Name) {
+		input, err := prompt.Stdin.PromptPassword("BIP-39 passphrase: ")
+		if err != nil {
+			utils.Fatalf("Failed to read the BIP-39 passphrase: %v", err)
+		}
+		mnemonicPassword = input
+	}
+	am := makeAccountManager(ctx)
+	backends := am.Backends(hdwallet.HubType)
+	if len(backends) == 0 {
+		utils.Fatalf("HD wallets are not available")
+	}
+	hub := backends[0].(*hdwallet.Hub)
+	passphrase := utils.GetPassPhraseWithList("Your new wallet is locked with a password. Please give a password. Do not forget this password.", true, 0, utils.MakePasswordList(ctx))
+
+	wallet, err := hub.ImportMnemonic(mnemonic, mnemonicPassword, passphrase)
+	if err != nil {
+		utils.Fatalf("Could not import the mnemonic: %v", err)
+	}
+	fmt.Printf("Wallet: %s\n", wallet.URL())
+	fmt.Printf("Address: {%x}\n", wallet.Accounts()[0].Address)
+	return nil
+}
+
+// accountMigrate stores the keys of existing accounts in the kernel keyring.
+func accountMigrate(ctx *cli.Context) error {
+	cfg := loadBaseConfig(ctx)
+	keydir, isEphemeral, err := cfg.Node.GetKeyStoreDir()
+	if err != nil {
+		utils.Fatalf("Failed to get the keystore directory: %v", err)
+	}
+	if isEphemeral {
+		utils.Fatalf("Can't use ephemeral directory as keystore path")
+	}
+	scryptN := keystore.StandardScryptN
+	scryptP := keystore.StandardScryptP
+	if cfg.Node.UseLightweightKDF {
+		scryptN = keystore.LightScryptN
+		scryptP = keystore.LightScryptP
+	}
+	keyring, err := keystore.NewKernelKeyring()
+	if err != nil {
+		utils.Fatalf("Failed to open the kernel keyring: %v", err)
+	}
+	ks := keystore.NewKeyStoreWithStorage(keydir, keystore.NewKeyringStorage(keydir, keyring, scryptN, scryptP))
+
+	accs := ks.Accounts()
+	if ctx.Args().Len() > 0 {
+		accs = accs[:0]
+		for _, addr := range ctx.Args().Slice() {
+			account, err := utils.MakeAddress(ks, addr)
+			if err != nil {
+				utils.Fatalf("Could not list accounts: %v", err)
+			}
+			accs = append(accs, account)
+		}
+	}
+	passwords := utils.MakePasswordList(ctx)
+	for i, account := range accs {
+		if ks.InKeyring(account) {
+			fmt.Printf("Account {%x} already in keyring\n", account.Address)
+			continue
+		}
+		prompt := fmt.Sprintf("Migrating account %s", account.Address.Hex())
+		password := utils.GetPassPhraseWithList(prompt, false, i, passwords)
+		if err := ks.MigrateToKeyring(account, password); err != nil {
+			utils.Fatalf("Could not migrate account %s: %v", account.Address.Hex(), err)
+		}
+		fmt.Printf("Account {%x} migrated to keyring\n", account.Address)
+	}
+	return nil
+}
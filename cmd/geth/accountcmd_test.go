@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/cespare/cp"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 )
 
 // These tests are 'smoke tests' for the account related
@@ -117,6 +118,37 @@ func TestAccountImport(t *testing.T) {
 	}
 }
 
+func TestAccountImportMnemonic(t *testing.T) {
+	dir := t.TempDir()
+	mnemonicFile := filepath.Join(dir, "mnemonic.txt")
+	if err := os.WriteFile(mnemonicFile, []byte("test test test test test test test test test test test junk\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	passwordFile := filepath.Join(dir, "password.txt")
+	if err := os.WriteFile(passwordFile, []byte("foobar"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	datadir := filepath.Join(dir, "data")
+	geth := runGeth(t, "--datadir", datadir, "--lightkdf", "account", "import-mnemonic", "--password", passwordFile, mnemonicFile)
+	geth.Expect(`
+Wallet: hd://` + filepath.Join(datadir, "keystore", "hd", "f39fd6e51aad88f6f4ce6ab8827279cfffb92266.json") + `
+Address: {f39fd6e51aad88f6f4ce6ab8827279cfffb92266}
+`)
+	geth.ExpectExit()
+
+	// The wallet is listed along with the keystore accounts
+	geth = runGeth(t, "--datadir", datadir, "account", "list")
+	geth.Expect(`
+Account #0: {f39fd6e51aad88f6f4ce6ab8827279cfffb92266} hd://` + filepath.Join(datadir, "keystore", "hd", "f39fd6e51aad88f6f4ce6ab8827279cfffb92266.json") + `/m/44'/60'/0'/0/0
+`)
+	geth.ExpectExit()
+
+	// Importing the same mnemonic twice fails
+	geth = runGeth(t, "--datadir", datadir, "--lightkdf", "account", "import-mnemonic", "--password", passwordFile, mnemonicFile)
+	geth.Expect("Fatal: Could not import the mnemonic: wallet already exists\n")
+	geth.ExpectExit()
+}
+
 func TestAccountHelp(t *testing.T) {
 	geth := runGeth(t, "account", "-h")
 	geth.WaitExit()
@@ -360,3 +392,41 @@ Fatal: None of the listed files could be unlocked.
 `)
 	geth.ExpectExit()
 }
+
+func TestAccountMigrateKeyring(t *testing.T) {
+	keyring, err := keystore.NewKernelKeyring()
+	if err != nil {
+		t.Skip("kernel keyring unavailable:", err)
+	}
+	defer keyring.Delete("geth:keystore:f466859ead1932d743d622cb74fc058882e8648a")
+
+	datadir := tmpDatadirWithKeystore(t)
+	migrate := runGeth(t, "account", "migrate",
+		"--datadir", datadir, "--lightkdf",
+		"f466859ead1932d743d622cb74fc058882e8648a")
+	migrate.Expect(`
+Migrating account 0xf466859eAD1932D743d622CB74FC058882E8648A
+!! Unsupported terminal, password will be echoed.
+Password: {{.InputLine "foobar"}}
+Account {f466859ead1932d743d622cb74fc058882e8648a} migrated to keyring
+`)
+	migrate.ExpectExit()
+
+	// The migrated account is unlocked without a password prompt
+	geth := runMinimalGeth(t, "--port", "0", "--ipcdisable", "--datadir", datadir, "--keystore.keyring",
+		"--unlock", "f466859ead1932d743d622cb74fc058882e8648a", "console", "--exec", "loadScript('testdata/empty.js')")
+	geth.Expect(`
+undefined
+`)
+	geth.ExpectExit()
+
+	wantMessages := []string{
+		"Unlocked account from keyring",
+		"=0xf466859eAD1932D743d622CB74FC058882E8648A",
+	}
+	for _, m := range wantMessages {
+		if !strings.Contains(geth.StderrText(), m) {
+			t.Errorf("stderr text does not contain %q", m)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/accounts/hdwallet"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/accounts/scwallet"
 	"github.com/ethereum/go-ethereum/accounts/usbwallet"
@@ -227,6 +229,10 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 		if err != nil {
 			utils.Fatalf("failed to register catalyst service: %v", err)
 		}
+		// Drive the engine API with the embedded beacon light client if requested.
+		if ctx.IsSet(utils.BeaconApiFlag.Name) {
+			utils.RegisterBeaconLightClient(ctx, stack)
+		}
 	}
 	return stack, backend
 }
@@ -343,7 +349,21 @@ func setAccountManagerBackends(conf *node.Config, am *accounts.Manager, keydir s
 	// If/when we implement some form of lockfile for USB and keystore wallets,
 	// we can have both, but it's very confusing for the user to see the same
 	// accounts in both externally and locally, plus very racey.
-	am.AddBackend(keystore.NewKeyStore(keydir, scryptN, scryptP))
+	if conf.UseKeyring {
+		keyring, err := keystore.NewKernelKeyring()
+		if err != nil {
+			return fmt.Errorf("error opening kernel keyring: %v", err)
+		}
+		am.AddBackend(keystore.NewKeyStoreWithStorage(keydir, keystore.NewKeyringStorage(keydir, keyring, scryptN, scryptP)))
+	} else {
+		am.AddBackend(keystore.NewKeyStore(keydir, scryptN, scryptP))
+	}
+	// Load the software HD wallets imported from mnemonics
+	hdhub, err := hdwallet.NewHub(filepath.Join(keydir, hdwallet.Scheme), scryptN, scryptP)
+	if err != nil {
+		return fmt.Errorf("error loading HD wallets: %v", err)
+	}
+	am.AddBackend(hdhub)
 	if conf.USB {
 		// Start a USB hub for Ledger hardware wallets
 		if ledgerhub, err := usbwallet.NewLedgerHub(); err != nil {
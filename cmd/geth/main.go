@@ -78,12 +78,20 @@ var (
 		utils.TxPoolGlobalSlotsFlag,
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
+		utils.TxPoolAccountBytesFlag,
+		utils.TxPoolGlobalBytesFlag,
+		utils.TxPoolAccountQueueBytesFlag,
+		utils.TxPoolGlobalQueueBytesFlag,
 		utils.TxPoolLifetimeFlag,
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
 		utils.SyncModeFlag,
 		utils.SyncTargetFlag,
+		utils.BeaconApiFlag,
+		utils.BeaconApiHeaderFlag,
+		utils.BeaconCheckpointFlag,
+		utils.BeaconThresholdFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
@@ -96,6 +104,7 @@ var (
 		utils.LightMaxPeersFlag,
 		utils.LightNoPruneFlag,
 		utils.LightKDFFlag,
+		utils.KeyringFlag,
 		utils.LightNoSyncServeFlag,
 		utils.EthRequiredBlocksFlag,
 		utils.LegacyWhitelistFlag,
@@ -114,6 +123,9 @@ var (
 		utils.CryptoKZGFlag,
 		utils.ListenPortFlag,
 		utils.DiscoveryPortFlag,
+		utils.QUICPortFlag,
+		utils.TxUploadCapFlag,
+		utils.TxDownloadCapFlag,
 		utils.MaxPeersFlag,
 		utils.MaxPendingPeersFlag,
 		utils.MiningEnabledFlag,
@@ -129,6 +141,7 @@ var (
 		utils.DiscoveryV5Flag,
 		utils.LegacyDiscoveryV5Flag,
 		utils.NetrestrictFlag,
+		utils.NetCaptureFlag,
 		utils.NodeKeyFileFlag,
 		utils.NodeKeyHexFlag,
 		utils.DNSDiscoveryFlag,
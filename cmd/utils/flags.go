@@ -36,8 +36,11 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/beacon/blsync"
+	bparams "github.com/ethereum/go-ethereum/beacon/params"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
@@ -232,6 +235,11 @@ var (
 		Usage:    "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
 		Category: flags.AccountCategory,
 	}
+	KeyringFlag = &cli.BoolFlag{
+		Name:     "keystore.keyring",
+		Usage:    "Unlock accounts without passwords if their keys are stored in the kernel keyring (Linux only)",
+		Category: flags.AccountCategory,
+	}
 	EthRequiredBlocksFlag = &cli.StringFlag{
 		Name:     "eth.requiredblocks",
 		Usage:    "Comma separated block number-to-hash mappings to require for peering (<number>=<hash>)",
@@ -376,6 +384,30 @@ var (
 		Value:    ethconfig.Defaults.TxPool.GlobalQueue,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolAccountBytesFlag = &cli.Uint64Flag{
+		Name:     "txpool.accountbytes",
+		Usage:    "Size of executable transactions guaranteed per account in byte budgeted mode",
+		Value:    ethconfig.Defaults.TxPool.AccountBytes,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolGlobalBytesFlag = &cli.Uint64Flag{
+		Name:     "txpool.globalbytes",
+		Usage:    "Maximum size of executable transactions for all accounts (enables byte budgeted mode, 0 = slot based limits)",
+		Value:    ethconfig.Defaults.TxPool.GlobalBytes,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolAccountQueueBytesFlag = &cli.Uint64Flag{
+		Name:     "txpool.accountqueuebytes",
+		Usage:    "Maximum size of non-executable transactions permitted per account in byte budgeted mode",
+		Value:    ethconfig.Defaults.TxPool.AccountQueueBytes,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolGlobalQueueBytesFlag = &cli.Uint64Flag{
+		Name:     "txpool.globalqueuebytes",
+		Usage:    "Maximum size of non-executable transactions for all accounts in byte budgeted mode",
+		Value:    ethconfig.Defaults.TxPool.GlobalQueueBytes,
+		Category: flags.TxPoolCategory,
+	}
 	TxPoolLifetimeFlag = &cli.DurationFlag{
 		Name:     "txpool.lifetime",
 		Usage:    "Maximum amount of time non-executable transaction are queued",
@@ -589,6 +621,29 @@ var (
 		Category: flags.LoggingCategory,
 	}
 
+	// Beacon light client settings
+	BeaconApiFlag = &cli.StringFlag{
+		Name:     "beacon.api",
+		Usage:    "Beacon node light client API URL. Enables the embedded beacon light client",
+		Category: flags.BeaconCategory,
+	}
+	BeaconApiHeaderFlag = &cli.StringSliceFlag{
+		Name:     "beacon.api.header",
+		Usage:    "Additional HTTP header for beacon API requests (key:value)",
+		Category: flags.BeaconCategory,
+	}
+	BeaconCheckpointFlag = &cli.StringFlag{
+		Name:     "beacon.checkpoint",
+		Usage:    "Trusted beacon block root where the beacon light client starts syncing",
+		Category: flags.BeaconCategory,
+	}
+	BeaconThresholdFlag = &cli.IntFlag{
+		Name:     "beacon.threshold",
+		Usage:    "Minimum number of sync committee signers of accepted beacon heads",
+		Value:    bparams.SyncCommitteeSupermajority,
+		Category: flags.BeaconCategory,
+	}
+
 	// MISC settings
 	SyncTargetFlag = &cli.StringFlag{
 		Name:      "synctarget",
@@ -771,7 +826,7 @@ var (
 	}
 	NATFlag = &cli.StringFlag{
 		Name:     "nat",
-		Usage:    "NAT port mapping mechanism (any|none|upnp|pmp|pmp:<IP>|extip:<IP>)",
+		Usage:    "NAT port mapping mechanism (any|none|upnp|pmp|pmp:<IP>|pcp|pcp:<IP>|extip:<IP>)",
 		Value:    "any",
 		Category: flags.NetworkingCategory,
 	}
@@ -798,6 +853,11 @@ var (
 		Usage:    "Restricts network communication to the given IP networks (CIDR masks)",
 		Category: flags.NetworkingCategory,
 	}
+	NetCaptureFlag = &cli.StringFlag{
+		Name:     "netcapture",
+		Usage:    "Records all peer messages into the given file (for debugging, see 'devp2p replay')",
+		Category: flags.NetworkingCategory,
+	}
 	DNSDiscoveryFlag = &cli.StringFlag{
 		Name:     "discovery.dns",
 		Usage:    "Sets DNS discovery entry points (use \"\" to disable DNS)",
@@ -809,6 +869,21 @@ var (
 		Value:    30303,
 		Category: flags.NetworkingCategory,
 	}
+	QUICPortFlag = &cli.IntFlag{
+		Name:     "quic.port",
+		Usage:    "Experimental: accept P2P connections over QUIC on the given UDP port (0 = disabled)",
+		Category: flags.NetworkingCategory,
+	}
+	TxUploadCapFlag = &cli.Uint64Flag{
+		Name:     "txgossip.uploadcap",
+		Usage:    "Outgoing bandwidth limit for transaction propagation (kilobytes/sec, 0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
+	TxDownloadCapFlag = &cli.Uint64Flag{
+		Name:     "txgossip.downloadcap",
+		Usage:    "Incoming bandwidth limit for transaction retrieval (kilobytes/sec, 0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
 
 	// Console
 	JSpathFlag = &flags.DirectoryFlag{
@@ -1096,6 +1171,9 @@ func setListenAddress(ctx *cli.Context, cfg *p2p.Config) {
 	if ctx.IsSet(DiscoveryPortFlag.Name) {
 		cfg.DiscAddr = fmt.Sprintf(":%d", ctx.Int(DiscoveryPortFlag.Name))
 	}
+	if ctx.Int(QUICPortFlag.Name) != 0 {
+		cfg.QUICListenAddr = fmt.Sprintf(":%d", ctx.Int(QUICPortFlag.Name))
+	}
 }
 
 // setNAT creates a port mapper from command line flags.
@@ -1399,6 +1477,9 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		}
 		cfg.NetRestrict = list
 	}
+	if ctx.IsSet(NetCaptureFlag.Name) {
+		cfg.CaptureFile = ctx.String(NetCaptureFlag.Name)
+	}
 
 	if ctx.Bool(DeveloperFlag.Name) {
 		// --dev mode can't use p2p networking.
@@ -1442,6 +1523,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(LightKDFFlag.Name) {
 		cfg.UseLightweightKDF = ctx.Bool(LightKDFFlag.Name)
 	}
+	if ctx.IsSet(KeyringFlag.Name) {
+		cfg.UseKeyring = ctx.Bool(KeyringFlag.Name)
+	}
 	if ctx.IsSet(NoUSBFlag.Name) || cfg.NoUSB {
 		log.Warn("Option nousb is deprecated and USB is deactivated by default. Use --usb to enable")
 	}
@@ -1554,6 +1638,18 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolGlobalQueueFlag.Name) {
 		cfg.GlobalQueue = ctx.Uint64(TxPoolGlobalQueueFlag.Name)
 	}
+	if ctx.IsSet(TxPoolAccountBytesFlag.Name) {
+		cfg.AccountBytes = ctx.Uint64(TxPoolAccountBytesFlag.Name)
+	}
+	if ctx.IsSet(TxPoolGlobalBytesFlag.Name) {
+		cfg.GlobalBytes = ctx.Uint64(TxPoolGlobalBytesFlag.Name)
+	}
+	if ctx.IsSet(TxPoolAccountQueueBytesFlag.Name) {
+		cfg.AccountQueueBytes = ctx.Uint64(TxPoolAccountQueueBytesFlag.Name)
+	}
+	if ctx.IsSet(TxPoolGlobalQueueBytesFlag.Name) {
+		cfg.GlobalQueueBytes = ctx.Uint64(TxPoolGlobalQueueBytesFlag.Name)
+	}
 	if ctx.IsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.Duration(TxPoolLifetimeFlag.Name)
 	}
@@ -1661,6 +1757,13 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
 
+	if ctx.IsSet(TxUploadCapFlag.Name) {
+		cfg.TxUploadCap = ctx.Uint64(TxUploadCapFlag.Name) * 1024
+	}
+	if ctx.IsSet(TxDownloadCapFlag.Name) {
+		cfg.TxDownloadCap = ctx.Uint64(TxDownloadCapFlag.Name) * 1024
+	}
+
 	// Cap the cache allowance and tune the garbage collector
 	mem, err := gopsutil.VirtualMemory()
 	if err == nil {
@@ -1967,6 +2070,49 @@ func RegisterFullSyncTester(stack *node.Node, eth *eth.Ethereum, target common.H
 	log.Info("Registered full-sync tester", "hash", target)
 }
 
+// RegisterBeaconLightClient adds the beacon light client into the node. It
+// drives the execution client through the in-process engine API, replacing an
+// external consensus client.
+func RegisterBeaconLightClient(ctx *cli.Context, stack *node.Node) {
+	var config blsync.Config
+	switch {
+	case ctx.Bool(MainnetFlag.Name):
+		config.ChainConfig = blsync.MainnetConfig
+	case ctx.Bool(HoleskyFlag.Name):
+		config.ChainConfig = blsync.HoleskyConfig
+	case ctx.Bool(SepoliaFlag.Name):
+		config.ChainConfig = blsync.SepoliaConfig
+	case ctx.Bool(GoerliFlag.Name), ctx.Bool(DeveloperFlag.Name):
+		Fatalf("Beacon light client is not supported on this network")
+	default:
+		config.ChainConfig = blsync.MainnetConfig
+	}
+	if !ctx.IsSet(BeaconCheckpointFlag.Name) {
+		Fatalf("Beacon light client requires a checkpoint (--%s)", BeaconCheckpointFlag.Name)
+	}
+	checkpoint, err := hexutil.Decode(ctx.String(BeaconCheckpointFlag.Name))
+	if err != nil || len(checkpoint) != common.HashLength {
+		Fatalf("Invalid beacon checkpoint %q", ctx.String(BeaconCheckpointFlag.Name))
+	}
+	config.Checkpoint = common.BytesToHash(checkpoint)
+	config.ApiURL = ctx.String(BeaconApiFlag.Name)
+	config.Threshold = ctx.Int(BeaconThresholdFlag.Name)
+	if headers := ctx.StringSlice(BeaconApiHeaderFlag.Name); len(headers) > 0 {
+		config.CustomHeaders = make(map[string]string)
+		for _, header := range headers {
+			key, value, ok := strings.Cut(header, ":")
+			if !ok {
+				Fatalf("Invalid beacon API header %q, expected key:value", header)
+			}
+			config.CustomHeaders[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	client := blsync.NewClient(config)
+	client.SetEngineRPC(stack.Attach())
+	stack.RegisterLifecycle(client)
+	log.Info("Registered beacon light client", "api", config.ApiURL, "checkpoint", config.Checkpoint)
+}
+
 func SetupMetrics(ctx *cli.Context) {
 	if metrics.Enabled {
 		log.Info("Enabling metrics collection")
@@ -1557,3 +1557,34 @@ func (p *BlobPool) Status(hash common.Hash) txpool.TxStatus {
 	}
 	return txpool.TxStatusUnknown
 }
+
+// Clear implements txpool.SubPool, removing all tracked transactions from the
+// pool and its persistent store. Blobs in limbo are kept, as they belong to
+// already included transactions.
+func (p *BlobPool) Clear() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for addr, txs := range p.index {
+		for _, tx := range txs {
+			if err := p.store.Delete(tx.id); err != nil {
+				log.Error("Failed to delete blob transaction", "from", addr, "id", tx.id, "err", err)
+			}
+		}
+		p.reserve(addr, false)
+	}
+	p.lookup = make(map[common.Hash]uint64)
+	p.index = make(map[common.Address][]*blobTxMeta)
+	p.spent = make(map[common.Address]*uint256.Int)
+	p.stored = 0
+
+	var (
+		basefee = uint256.MustFromBig(eip1559.CalcBaseFee(p.chain.Config(), p.head))
+		blobfee = uint256.MustFromBig(big.NewInt(params.BlobTxMinBlobGasprice))
+	)
+	if p.head.ExcessBlobGas != nil {
+		blobfee = uint256.MustFromBig(eip4844.CalcBlobFee(*p.head.ExcessBlobGas))
+	}
+	p.evict = newPriceHeap(basefee, blobfee, &p.index)
+	p.updateStorageMetrics()
+}
@@ -18,6 +18,7 @@
 package legacypool
 
 import (
+	"container/heap"
 	"errors"
 	"math"
 	"math/big"
@@ -99,6 +100,13 @@ var (
 	localGauge   = metrics.NewRegisteredGauge("txpool/local", nil)
 	slotsGauge   = metrics.NewRegisteredGauge("txpool/slots", nil)
 
+	// Metrics for the byte budgeted mode, the limits are only set if enabled
+	bytesGauge             = metrics.NewRegisteredGauge("txpool/bytes", nil)
+	pendingBytesGauge      = metrics.NewRegisteredGauge("txpool/pending/bytes", nil)
+	queuedBytesGauge       = metrics.NewRegisteredGauge("txpool/queued/bytes", nil)
+	pendingBytesLimitGauge = metrics.NewRegisteredGauge("txpool/pending/bytes/limit", nil)
+	queuedBytesLimitGauge  = metrics.NewRegisteredGauge("txpool/queued/bytes/limit", nil)
+
 	reheapTimer = metrics.NewRegisteredTimer("txpool/reheap", nil)
 )
 
@@ -133,6 +141,13 @@ type Config struct {
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
+	// Byte budgets measured on the encoded transaction sizes. If GlobalBytes is
+	// set, these supersede the slot and count based limits above.
+	AccountBytes      uint64 // Size of executable transactions guaranteed per account
+	GlobalBytes       uint64 // Maximum size of executable transactions for all accounts
+	AccountQueueBytes uint64 // Maximum size of non-executable transactions permitted per account
+	GlobalQueueBytes  uint64 // Maximum size of non-executable transactions for all accounts
+
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
 }
 
@@ -188,9 +203,35 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultConfig.Lifetime)
 		conf.Lifetime = DefaultConfig.Lifetime
 	}
+	// If byte budgets are enabled, make sure every limit can hold at least one
+	// maximum sized transaction
+	if conf.GlobalBytes > 0 {
+		if conf.GlobalBytes < txMaxSize {
+			log.Warn("Sanitizing invalid txpool global bytes", "provided", conf.GlobalBytes, "updated", txMaxSize)
+			conf.GlobalBytes = txMaxSize
+		}
+		if conf.AccountBytes < txMaxSize {
+			log.Warn("Sanitizing invalid txpool account bytes", "provided", conf.AccountBytes, "updated", txMaxSize)
+			conf.AccountBytes = txMaxSize
+		}
+		if conf.AccountQueueBytes < txMaxSize {
+			log.Warn("Sanitizing invalid txpool account queue bytes", "provided", conf.AccountQueueBytes, "updated", txMaxSize)
+			conf.AccountQueueBytes = txMaxSize
+		}
+		if conf.GlobalQueueBytes < txMaxSize {
+			log.Warn("Sanitizing invalid txpool global queue bytes", "provided", conf.GlobalQueueBytes, "updated", txMaxSize)
+			conf.GlobalQueueBytes = txMaxSize
+		}
+	}
 	return conf
 }
 
+// budgeted returns whether the pool limits are driven by byte budgets instead
+// of slot and transaction counts.
+func (config *Config) budgeted() bool {
+	return config.GlobalBytes > 0
+}
+
 // LegacyPool contains all currently known transactions. Transactions
 // enter the pool when they are received from the network or submitted
 // locally. They exit the pool when they are included in the blockchain.
@@ -266,6 +307,11 @@ func New(config Config, chain BlockChain) *LegacyPool {
 	}
 	pool.priced = newPricedList(pool.all)
 
+	if config.budgeted() {
+		pendingBytesLimitGauge.Update(int64(config.GlobalBytes))
+		queuedBytesLimitGauge.Update(int64(config.GlobalQueueBytes))
+	}
+
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
 	}
@@ -480,6 +526,33 @@ func (pool *LegacyPool) stats() (int, int) {
 	return pending, queued
 }
 
+// sizes returns the total encoded size of the pending and queued transactions.
+// The caller must hold the pool lock.
+func (pool *LegacyPool) sizes() (uint64, uint64) {
+	var pending, queued uint64
+	for _, list := range pool.pending {
+		pending += list.Size()
+	}
+	for _, list := range pool.queue {
+		queued += list.Size()
+	}
+	return pending, queued
+}
+
+// Usage returns the total encoded size of the pending and queued transactions
+// in the pool, along with the configured byte budget (zero if the pool is not
+// limited by size).
+func (pool *LegacyPool) Usage() (uint64, uint64, uint64) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	pending, queued := pool.sizes()
+	if !pool.config.budgeted() {
+		return pending, queued, 0
+	}
+	return pending, queued, pool.config.GlobalBytes + pool.config.GlobalQueueBytes
+}
+
 // Content retrieves the data content of the transaction pool, returning all the
 // pending as well as queued transactions, grouped by account and sorted by nonce.
 func (pool *LegacyPool) Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
@@ -694,7 +767,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		}()
 	}
 	// If the transaction pool is full, discard underpriced transactions
-	if uint64(pool.all.Slots()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
+	if overflow := pool.overflow(tx); overflow > 0 {
 		// If the new transaction is underpriced, don't accept it
 		if !isLocal && pool.priced.Underpriced(tx) {
 			log.Trace("Discarding underpriced transaction", "hash", hash, "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
@@ -714,7 +787,15 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		// New transaction is better than our worse ones, make room for it.
 		// If it's a local transaction, forcibly discard all available transactions.
 		// Otherwise if we can't make enough room for new one, abort the operation.
-		drop, success := pool.priced.Discard(pool.all.Slots()-int(pool.config.GlobalSlots+pool.config.GlobalQueue)+numSlots(tx), isLocal)
+		var (
+			drop    types.Transactions
+			success bool
+		)
+		if pool.config.budgeted() {
+			drop, success = pool.priced.DiscardBytes(overflow, isLocal)
+		} else {
+			drop, success = pool.priced.Discard(overflow, isLocal)
+		}
 
 		// Special case, we still can't make the room for the new remote one.
 		if !isLocal && !success {
@@ -799,6 +880,16 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 	return replaced, nil
 }
 
+// overflow returns by how much the pool capacity would be exceeded if the given
+// transaction was added. Depending on the configuration it is measured either
+// in slots or in bytes. Non-positive values mean the transaction fits.
+func (pool *LegacyPool) overflow(tx *types.Transaction) int {
+	if pool.config.budgeted() {
+		return int(pool.all.Bytes()+tx.Size()) - int(pool.config.GlobalBytes+pool.config.GlobalQueueBytes)
+	}
+	return pool.all.Slots() + numSlots(tx) - int(pool.config.GlobalSlots+pool.config.GlobalQueue)
+}
+
 // isGapped reports whether the given transaction is immediately executable.
 func (pool *LegacyPool) isGapped(from common.Address, tx *types.Transaction) bool {
 	// Short circuit if transaction falls within the scope of the pending list
@@ -1043,6 +1134,38 @@ func (pool *LegacyPool) Status(hash common.Hash) txpool.TxStatus {
 	return txpool.TxStatusUnknown
 }
 
+// Clear implements txpool.SubPool, removing all tracked transactions from the
+// pool and rotating the journal.
+func (pool *LegacyPool) Clear() {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	// Release the reservations of all accounts tracked by the pool, which may
+	// have both pending and queued transactions
+	tracked := make(map[common.Address]struct{})
+	for addr := range pool.pending {
+		tracked[addr] = struct{}{}
+	}
+	for addr := range pool.queue {
+		tracked[addr] = struct{}{}
+	}
+	for addr := range tracked {
+		pool.reserve(addr, false)
+	}
+	pool.all = newLookup()
+	pool.priced = newPricedList(pool.all)
+	pool.pending = make(map[common.Address]*list)
+	pool.queue = make(map[common.Address]*list)
+	pool.beats = make(map[common.Address]time.Time)
+	pool.pendingNonces = newNoncer(pool.currentState)
+
+	if pool.journal != nil {
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate transaction journal", "err", err)
+		}
+	}
+}
+
 // Get returns a transaction if it is contained in the pool and nil otherwise.
 func (pool *LegacyPool) Get(hash common.Hash) *types.Transaction {
 	tx := pool.get(hash)
@@ -1297,6 +1420,10 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 	pool.truncatePending()
 	pool.truncateQueue()
 
+	pendingBytes, queuedBytes := pool.sizes()
+	pendingBytesGauge.Update(int64(pendingBytes))
+	queuedBytesGauge.Update(int64(queuedBytes))
+
 	dropBetweenReorgHistogram.Update(int64(pool.changesSinceReorg))
 	pool.changesSinceReorg = 0 // Reset change counter
 	pool.mu.Unlock()
@@ -1461,7 +1588,11 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		// Drop all transactions over the allowed limit
 		var caps types.Transactions
 		if !pool.locals.contains(addr) {
-			caps = list.Cap(int(pool.config.AccountQueue))
+			if pool.config.budgeted() {
+				caps = list.CapSize(pool.config.AccountQueueBytes)
+			} else {
+				caps = list.Cap(int(pool.config.AccountQueue))
+			}
 			for _, tx := range caps {
 				hash := tx.Hash()
 				pool.all.Remove(hash)
@@ -1491,6 +1622,10 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 // pending limit. The algorithm tries to reduce transaction counts by an approximately
 // equal number for all for accounts with many pending transactions.
 func (pool *LegacyPool) truncatePending() {
+	if pool.config.budgeted() {
+		pool.truncatePendingBytes()
+		return
+	}
 	pending := uint64(0)
 	for _, list := range pool.pending {
 		pending += uint64(list.Len())
@@ -1574,13 +1709,75 @@ func (pool *LegacyPool) truncatePending() {
 	pendingRateLimitMeter.Mark(int64(pendingBeforeCap - pending))
 }
 
-// truncateQueue drops the oldest transactions in the queue if the pool is above the global queue limit.
+// truncatePendingBytes is the byte budgeted counterpart of truncatePending. Accounts
+// exceeding their guaranteed budget are trimmed from their highest nonces, always
+// evicting the cheapest trailing transaction first and preferring the accounts
+// with the oldest heartbeat on ties, until the pending set fits the global budget.
+func (pool *LegacyPool) truncatePendingBytes() {
+	pending := uint64(0)
+	for _, list := range pool.pending {
+		pending += list.Size()
+	}
+	if pending <= pool.config.GlobalBytes {
+		return
+	}
+	// Assemble the eviction order of the accounts above their allowance
+	offenders := &byteOffenders{prices: &priceHeap{baseFee: pool.priced.urgent.baseFee}}
+	for addr, list := range pool.pending {
+		if !pool.locals.contains(addr) && list.Size() > pool.config.AccountBytes && list.Len() > 1 {
+			offenders.items = append(offenders.items, &byteOffender{addr: addr, tail: list.LastElement(), beat: pool.beats[addr]})
+		}
+	}
+	heap.Init(offenders)
+
+	var dropped int
+	for pending > pool.config.GlobalBytes && offenders.Len() > 0 {
+		offender := offenders.items[0]
+		list := pool.pending[offender.addr]
+
+		caps := list.Cap(list.Len() - 1)
+		for _, tx := range caps {
+			// Drop the transaction from the global pools too
+			hash := tx.Hash()
+			pool.all.Remove(hash)
+
+			// Update the account nonce to the dropped transaction
+			pool.pendingNonces.setIfLower(offender.addr, tx.Nonce())
+			log.Trace("Removed budget-exceeding pending transaction", "hash", hash)
+			pending -= tx.Size()
+		}
+		pool.priced.Removed(len(caps))
+		pendingGauge.Dec(int64(len(caps)))
+		dropped += len(caps)
+
+		// Reschedule the account if it's still above its allowance
+		if list.Size() > pool.config.AccountBytes && list.Len() > 1 {
+			offender.tail = list.LastElement()
+			heap.Fix(offenders, 0)
+		} else {
+			heap.Pop(offenders)
+		}
+	}
+	pendingRateLimitMeter.Mark(int64(dropped))
+}
+
+// truncateQueue drops the oldest transactions in the queue if the pool is above
+// the global queue limit, measured in transactions or bytes depending on the
+// configuration.
 func (pool *LegacyPool) truncateQueue() {
+	cost, limit := func(tx *types.Transaction) uint64 { return 1 }, pool.config.GlobalQueue
+	if pool.config.budgeted() {
+		cost, limit = func(tx *types.Transaction) uint64 { return tx.Size() }, pool.config.GlobalQueueBytes
+	}
 	queued := uint64(0)
 	for _, list := range pool.queue {
-		queued += uint64(list.Len())
+		if pool.config.budgeted() {
+			queued += list.Size()
+		} else {
+			queued += uint64(list.Len())
+		}
 	}
-	if queued <= pool.config.GlobalQueue {
+	if queued <= limit {
 		return
 	}
 
@@ -1594,26 +1791,35 @@ func (pool *LegacyPool) truncateQueue() {
 	sort.Sort(sort.Reverse(addresses))
 
 	// Drop transactions until the total is below the limit or only locals remain
-	for drop := queued - pool.config.GlobalQueue; drop > 0 && len(addresses) > 0; {
+	for drop := queued - limit; drop > 0 && len(addresses) > 0; {
 		addr := addresses[len(addresses)-1]
 		list := pool.queue[addr.address]
 
 		addresses = addresses[:len(addresses)-1]
 
 		// Drop all transactions if they are less than the overflow
-		if size := uint64(list.Len()); size <= drop {
-			for _, tx := range list.Flatten() {
+		txs := list.Flatten()
+
+		var size uint64
+		for _, tx := range txs {
+			size += cost(tx)
+		}
+		if size <= drop {
+			for _, tx := range txs {
 				pool.removeTx(tx.Hash(), true, true)
 			}
 			drop -= size
-			queuedRateLimitMeter.Mark(int64(size))
+			queuedRateLimitMeter.Mark(int64(len(txs)))
 			continue
 		}
 		// Otherwise drop only last few transactions
-		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 			pool.removeTx(txs[i].Hash(), true, true)
-			drop--
+			if c := cost(txs[i]); c < drop {
+				drop -= c
+			} else {
+				drop = 0
+			}
 			queuedRateLimitMeter.Mark(1)
 		}
 	}
@@ -1693,6 +1899,44 @@ func (a addressesByHeartbeat) Len() int           { return len(a) }
 func (a addressesByHeartbeat) Less(i, j int) bool { return a[i].heartbeat.Before(a[j].heartbeat) }
 func (a addressesByHeartbeat) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 
+// byteOffender is an account exceeding its pending byte allowance, tracked with
+// its highest nonce'd transaction which is the next candidate for eviction.
+type byteOffender struct {
+	addr common.Address
+	tail *types.Transaction
+	beat time.Time
+}
+
+// byteOffenders is a heap.Interface implementation over budget exceeding accounts,
+// ordered by the price of their trailing transaction and then by heartbeat.
+type byteOffenders struct {
+	prices *priceHeap // Price comparator aware of the current base fee
+	items  []*byteOffender
+}
+
+func (h *byteOffenders) Len() int      { return len(h.items) }
+func (h *byteOffenders) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *byteOffenders) Less(i, j int) bool {
+	if c := h.prices.cmp(h.items[i].tail, h.items[j].tail); c != 0 {
+		return c < 0
+	}
+	return h.items[i].beat.Before(h.items[j].beat)
+}
+
+func (h *byteOffenders) Push(x interface{}) {
+	h.items = append(h.items, x.(*byteOffender))
+}
+
+func (h *byteOffenders) Pop() interface{} {
+	old := h.items
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	h.items = old[0 : n-1]
+	return x
+}
+
 // accountSet is simply a set of addresses to check for existence, and a signer
 // capable of deriving addresses from transactions.
 type accountSet struct {
@@ -1777,6 +2021,7 @@ func (as *accountSet) merge(other *accountSet) {
 // to build upper-level structure.
 type lookup struct {
 	slots   int
+	bytes   uint64
 	lock    sync.RWMutex
 	locals  map[common.Hash]*types.Transaction
 	remotes map[common.Hash]*types.Transaction
@@ -1872,6 +2117,14 @@ func (t *lookup) Slots() int {
 	return t.slots
 }
 
+// Bytes returns the total encoded size of the transactions in the lookup.
+func (t *lookup) Bytes() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.bytes
+}
+
 // Add adds a transaction to the lookup.
 func (t *lookup) Add(tx *types.Transaction, local bool) {
 	t.lock.Lock()
@@ -1880,6 +2133,9 @@ func (t *lookup) Add(tx *types.Transaction, local bool) {
 	t.slots += numSlots(tx)
 	slotsGauge.Update(int64(t.slots))
 
+	t.bytes += tx.Size()
+	bytesGauge.Update(int64(t.bytes))
+
 	if local {
 		t.locals[tx.Hash()] = tx
 	} else {
@@ -1903,6 +2159,9 @@ func (t *lookup) Remove(hash common.Hash) {
 	t.slots -= numSlots(tx)
 	slotsGauge.Update(int64(t.slots))
 
+	t.bytes -= tx.Size()
+	bytesGauge.Update(int64(t.bytes))
+
 	delete(t.locals, hash)
 	delete(t.remotes, hash)
 }
@@ -181,6 +181,11 @@ func validatePoolInternals(pool *LegacyPool) error {
 	if total := pool.all.Count(); total != pending+queued {
 		return fmt.Errorf("total transaction count %d != %d pending + %d queued", total, pending, queued)
 	}
+	// Ensure the tracked sizes are consistent with the contents
+	pendingBytes, queuedBytes := pool.sizes()
+	if total := pool.all.Bytes(); total != pendingBytes+queuedBytes {
+		return fmt.Errorf("total transaction size %d != %d pending + %d queued", total, pendingBytes, queuedBytes)
+	}
 	pool.priced.Reheap()
 	priced, remote := pool.priced.urgent.Len()+pool.priced.floating.Len(), pool.all.RemoteCount()
 	if priced != remote {
@@ -2470,6 +2475,49 @@ func TestStatusCheck(t *testing.T) {
 	}
 }
 
+// Tests that clearing the pool drops both pending and queued transactions and
+// releases the account reservations.
+func TestClear(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	txs := types.Transactions{
+		transaction(0, 100000, key),
+		transaction(2, 100000, key),
+	}
+	for i, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+	}
+	pool.Clear()
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("transactions left after clear: pending %d, queued %d", pending, queued)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	for i, tx := range txs {
+		if status := pool.Status(tx.Hash()); status != txpool.TxStatusUnknown {
+			t.Errorf("transaction %d: status mismatch: have %v, want %v", i, status, txpool.TxStatusUnknown)
+		}
+	}
+	// The account was released, so the transactions can be added again
+	for i, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("tx %d: failed to re-add transaction: %v", i, err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Fatalf("transactions mismatch after re-adding: pending %d, queued %d", pending, queued)
+	}
+}
+
 // Test the transaction slots consumption is computed correctly
 func TestSlotCount(t *testing.T) {
 	t.Parallel()
@@ -2488,6 +2536,105 @@ func TestSlotCount(t *testing.T) {
 	}
 }
 
+// Tests that in byte budgeted mode the pending pool is trimmed down to the global
+// byte budget, evicting the cheapest transactions of the largest accounts first.
+func TestPendingBytesLimiting(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool to test the limit enforcement with
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.GlobalBytes = 4 * txMaxSize
+	config.AccountBytes = txMaxSize
+	config.GlobalQueueBytes = txMaxSize
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	// Create a cheap and an expensive account, both well above their allowance
+	cheapKey, _ := crypto.GenerateKey()
+	cheap := crypto.PubkeyToAddress(cheapKey.PublicKey)
+	testAddBalance(pool, cheap, big.NewInt(1000000000))
+
+	priceyKey, _ := crypto.GenerateKey()
+	pricey := crypto.PubkeyToAddress(priceyKey.PublicKey)
+	testAddBalance(pool, pricey, big.NewInt(1000000000))
+
+	txs := types.Transactions{}
+	for i := 0; i < 14; i++ {
+		txs = append(txs, pricedDataTransaction(uint64(i), 400000, big.NewInt(1), cheapKey, 20000))
+		txs = append(txs, pricedDataTransaction(uint64(i), 400000, big.NewInt(2), priceyKey, 20000))
+	}
+	// Import the batch and verify that limits have been enforced
+	pool.addRemotesSync(txs)
+
+	pendingBytes, _, limit := pool.Usage()
+	if pendingBytes > config.GlobalBytes {
+		t.Fatalf("total pending size overflows allowance: %d > %d", pendingBytes, config.GlobalBytes)
+	}
+	if limit != config.GlobalBytes+config.GlobalQueueBytes {
+		t.Fatalf("reported byte limit mismatch: have %d, want %d", limit, config.GlobalBytes+config.GlobalQueueBytes)
+	}
+	if have := pool.pending[pricey].Len(); have != 14 {
+		t.Errorf("expensive account pending mismatch: have %d, want %d", have, 14)
+	}
+	if have := pool.pending[cheap].Len(); have >= 14 {
+		t.Errorf("cheap account not trimmed: have %d pending", have)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that in byte budgeted mode the future queue is limited both per account
+// and globally by the encoded size of the transactions.
+func TestQueueBytesLimiting(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool to test the limit enforcement with
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.NoLocals = true
+	config.GlobalBytes = 4 * txMaxSize
+	config.AccountQueueBytes = txMaxSize
+	config.GlobalQueueBytes = 2 * txMaxSize
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	// Create a number of test accounts and queue gapped transactions for them
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	txs := types.Transactions{}
+	for _, key := range keys {
+		for j := 1; j <= 10; j++ {
+			txs = append(txs, pricedDataTransaction(uint64(j), 400000, big.NewInt(1), key, 20000))
+		}
+	}
+	pool.addRemotesSync(txs)
+
+	for addr, list := range pool.queue {
+		if list.Size() > config.AccountQueueBytes {
+			t.Errorf("account %x queue size overflows allowance: %d > %d", addr, list.Size(), config.AccountQueueBytes)
+		}
+	}
+	if _, queuedBytes, _ := pool.Usage(); queuedBytes > config.GlobalQueueBytes {
+		t.Fatalf("total queued size overflows allowance: %d > %d", queuedBytes, config.GlobalQueueBytes)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Benchmarks the speed of validating the contents of the pending queue of the
 // transaction pool.
 func BenchmarkPendingDemotion100(b *testing.B)   { benchmarkPendingDemotion(b, 100) }
@@ -55,6 +55,7 @@ func (h *nonceHeap) Pop() interface{} {
 type sortedMap struct {
 	items   map[uint64]*types.Transaction // Hash map storing the transaction data
 	index   *nonceHeap                    // Heap of nonces of all the stored transactions (non-strict mode)
+	size    uint64                        // Total encoded size of all the stored transactions
 	cache   types.Transactions            // Cache of the transactions already sorted
 	cacheMu sync.Mutex                    // Mutex covering the cache
 }
@@ -76,9 +77,12 @@ func (m *sortedMap) Get(nonce uint64) *types.Transaction {
 // index. If a transaction already exists with the same nonce, it's overwritten.
 func (m *sortedMap) Put(tx *types.Transaction) {
 	nonce := tx.Nonce()
-	if m.items[nonce] == nil {
+	if old := m.items[nonce]; old == nil {
 		heap.Push(m.index, nonce)
+	} else {
+		m.size -= old.Size()
 	}
+	m.size += tx.Size()
 	m.cacheMu.Lock()
 	m.items[nonce], m.cache = tx, nil
 	m.cacheMu.Unlock()
@@ -94,6 +98,7 @@ func (m *sortedMap) Forward(threshold uint64) types.Transactions {
 	for m.index.Len() > 0 && (*m.index)[0] < threshold {
 		nonce := heap.Pop(m.index).(uint64)
 		removed = append(removed, m.items[nonce])
+		m.size -= m.items[nonce].Size()
 		delete(m.items, nonce)
 	}
 	// If we had a cached order, shift the front
@@ -139,6 +144,7 @@ func (m *sortedMap) filter(filter func(*types.Transaction) bool) types.Transacti
 	for nonce, tx := range m.items {
 		if filter(tx) {
 			removed = append(removed, tx)
+			m.size -= tx.Size()
 			delete(m.items, nonce)
 		}
 	}
@@ -163,6 +169,7 @@ func (m *sortedMap) Cap(threshold int) types.Transactions {
 	sort.Sort(*m.index)
 	for size := len(m.items); size > threshold; size-- {
 		drops = append(drops, m.items[(*m.index)[size-1]])
+		m.size -= m.items[(*m.index)[size-1]].Size()
 		delete(m.items, (*m.index)[size-1])
 	}
 	*m.index = (*m.index)[:threshold]
@@ -181,7 +188,7 @@ func (m *sortedMap) Cap(threshold int) types.Transactions {
 // transaction was found.
 func (m *sortedMap) Remove(nonce uint64) bool {
 	// Short circuit if no transaction is present
-	_, ok := m.items[nonce]
+	tx, ok := m.items[nonce]
 	if !ok {
 		return false
 	}
@@ -192,6 +199,7 @@ func (m *sortedMap) Remove(nonce uint64) bool {
 			break
 		}
 	}
+	m.size -= tx.Size()
 	delete(m.items, nonce)
 	m.cacheMu.Lock()
 	m.cache = nil
@@ -216,6 +224,7 @@ func (m *sortedMap) Ready(start uint64) types.Transactions {
 	var ready types.Transactions
 	for next := (*m.index)[0]; m.index.Len() > 0 && (*m.index)[0] == next; next++ {
 		ready = append(ready, m.items[next])
+		m.size -= m.items[next].Size()
 		delete(m.items, next)
 		heap.Pop(m.index)
 	}
@@ -231,6 +240,11 @@ func (m *sortedMap) Len() int {
 	return len(m.items)
 }
 
+// Size returns the total encoded size of the transactions in the map.
+func (m *sortedMap) Size() uint64 {
+	return m.size
+}
+
 func (m *sortedMap) flatten() types.Transactions {
 	m.cacheMu.Lock()
 	defer m.cacheMu.Unlock()
@@ -434,6 +448,33 @@ func (l *list) Len() int {
 	return l.txs.Len()
 }
 
+// Size returns the total encoded size of the transactions in the list.
+func (l *list) Size() uint64 {
+	return l.txs.Size()
+}
+
+// CapSize places a hard limit on the total encoded size of the transactions,
+// returning the highest nonce'd ones that do not fit into the budget.
+func (l *list) CapSize(limit uint64) types.Transactions {
+	// Short circuit if the list is within the budget
+	if l.Size() <= limit {
+		return nil
+	}
+	// Otherwise find how many of the lowest nonce'd transactions fit and cap
+	var (
+		size uint64
+		keep int
+	)
+	for _, tx := range l.txs.flatten() {
+		if size+tx.Size() > limit {
+			break
+		}
+		size += tx.Size()
+		keep++
+	}
+	return l.Cap(keep)
+}
+
 // Empty returns whether the list of transactions is empty or not.
 func (l *list) Empty() bool {
 	return l.Len() == 0
@@ -605,8 +646,21 @@ func (l *pricedList) underpricedFor(h *priceHeap, tx *types.Transaction) bool {
 //
 // Note local transaction won't be considered for eviction.
 func (l *pricedList) Discard(slots int, force bool) (types.Transactions, bool) {
-	drop := make(types.Transactions, 0, slots) // Remote underpriced transactions to drop
-	for slots > 0 {
+	return l.discard(slots, numSlots, force)
+}
+
+// DiscardBytes is the byte budgeted counterpart of Discard, finding a number of
+// the most underpriced transactions whose total encoded size covers the given
+// amount of bytes.
+func (l *pricedList) DiscardBytes(bytes int, force bool) (types.Transactions, bool) {
+	return l.discard(bytes, func(tx *types.Transaction) int { return int(tx.Size()) }, force)
+}
+
+// discard removes the most underpriced transactions until the requested amount
+// of capacity, as measured by the cost function, is freed up.
+func (l *pricedList) discard(amount int, cost func(*types.Transaction) int, force bool) (types.Transactions, bool) {
+	drop := make(types.Transactions, 0) // Remote underpriced transactions to drop
+	for amount > 0 {
 		if len(l.urgent.list)*floatingRatio > len(l.floating.list)*urgentRatio {
 			// Discard stale transactions if found during cleanup
 			tx := heap.Pop(&l.urgent).(*types.Transaction)
@@ -629,11 +683,11 @@ func (l *pricedList) Discard(slots int, force bool) (types.Transactions, bool) {
 			}
 			// Non stale transaction found, discard it
 			drop = append(drop, tx)
-			slots -= numSlots(tx)
+			amount -= cost(tx)
 		}
 	}
 	// If we still can't make enough room for the new transaction
-	if slots > 0 && !force {
+	if amount > 0 && !force {
 		for _, tx := range drop {
 			heap.Push(&l.urgent, tx)
 		}
@@ -51,6 +51,48 @@ func TestStrictListAdd(t *testing.T) {
 	}
 }
 
+// Tests that the total size of a list is tracked across modifications and that
+// capping by size drops the highest nonce'd transactions.
+func TestListSizeCap(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	txs := make(types.Transactions, 16)
+	for i := 0; i < len(txs); i++ {
+		txs[i] = transaction(uint64(i), 0, key)
+	}
+	list := newList(true)
+	for _, v := range rand.Perm(len(txs)) {
+		list.Add(txs[v], DefaultConfig.PriceBump)
+	}
+	sizeOf := func(txs types.Transactions) (size uint64) {
+		for _, tx := range txs {
+			size += tx.Size()
+		}
+		return size
+	}
+	if have, want := list.Size(), sizeOf(txs); have != want {
+		t.Fatalf("list size mismatch: have %d, want %d", have, want)
+	}
+	// Cap the list to fit 10 transactions and ensure the tail was dropped
+	drops := list.CapSize(sizeOf(txs[:10]))
+	if len(drops) != 6 {
+		t.Fatalf("dropped transaction count mismatch: have %d, want %d", len(drops), 6)
+	}
+	for _, tx := range drops {
+		if tx.Nonce() < 10 {
+			t.Errorf("dropped transaction with low nonce %d", tx.Nonce())
+		}
+	}
+	if have, want := list.Size(), sizeOf(txs[:10]); have != want {
+		t.Fatalf("capped list size mismatch: have %d, want %d", have, want)
+	}
+	// Forwarding the list should shrink the size too
+	list.Forward(5)
+	if have, want := list.Size(), sizeOf(txs[5:10]); have != want {
+		t.Fatalf("forwarded list size mismatch: have %d, want %d", have, want)
+	}
+}
+
 func BenchmarkListAdd(b *testing.B) {
 	// Generate a list of transactions to insert
 	key, _ := crypto.GenerateKey()
@@ -0,0 +1,270 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	// ErrPolicyRejected is returned if a transaction was refused by one of the
+	// admission policies configured on the pool. The concrete reason is wrapped
+	// together with the name of the rejecting policy.
+	ErrPolicyRejected = errors.New("rejected by txpool policy")
+
+	// errSenderNotAllowed is returned if the sender is not on the allow-list.
+	errSenderNotAllowed = errors.New("sender not allowed")
+
+	// errAddressDenied is returned if the sender or recipient is on the deny-list.
+	errAddressDenied = errors.New("address denied")
+
+	// errCreationNotAllowed is returned if an unapproved sender attempts to
+	// deploy a contract.
+	errCreationNotAllowed = errors.New("contract creation not allowed")
+
+	// errRateLimited is returned if a sender exceeded its admission rate.
+	errRateLimited = errors.New("sender rate limit exceeded")
+)
+
+// Policy is an admission hook consulted by the TxPool before a transaction is
+// dispatched to its subpool. It allows enforcing custom rules on top of the
+// consensus and resource checks done by the subpools themselves.
+//
+// Policies are called concurrently and must be safe for such use.
+type Policy interface {
+	// Name returns a short identifier of the policy, used in rejection errors.
+	Name() string
+
+	// Admit decides whether a transaction from the given (already recovered)
+	// sender may enter the pool. A non-nil error rejects the transaction.
+	Admit(tx *types.Transaction, from common.Address, local bool) error
+}
+
+// RefundPolicy is an optional extension of Policy for hooks that claim some
+// resource when admitting a transaction (e.g. a rate limit quota). If the tx
+// is rejected afterwards, either by a later policy or by its subpool, Refund
+// is called to release whatever Admit claimed.
+type RefundPolicy interface {
+	Policy
+
+	// Refund releases the resources claimed by a successful Admit call with
+	// the same parameters.
+	Refund(tx *types.Transaction, from common.Address, local bool)
+}
+
+// PolicyConfig are the configuration parameters of the built-in admission
+// policies. The zero value disables all of them.
+type PolicyConfig struct {
+	AllowedSenders  []common.Address // Senders permitted to submit transactions (empty = everyone)
+	DeniedAddresses []common.Address // Addresses never permitted as sender or recipient
+	AllowedCreators []common.Address // Senders permitted to deploy contracts (empty = everyone)
+	SenderRateLimit uint64           // Maximum transactions admitted per sender and period (0 = unlimited)
+	RateLimitPeriod time.Duration    // Period over which the sender rate limit is measured
+	RateLimitLocals bool             // Whether local transactions are subject to rate limiting
+}
+
+// DefaultPolicyConfig contains the default admission policy configuration.
+var DefaultPolicyConfig = PolicyConfig{
+	RateLimitPeriod: time.Minute,
+}
+
+// sanitize checks the provided user configurations and changes anything that's
+// unreasonable or unworkable.
+func (config *PolicyConfig) sanitize() PolicyConfig {
+	conf := *config
+	if conf.SenderRateLimit > 0 && conf.RateLimitPeriod <= 0 {
+		log.Warn("Sanitizing invalid txpool rate limit period", "provided", conf.RateLimitPeriod, "updated", DefaultPolicyConfig.RateLimitPeriod)
+		conf.RateLimitPeriod = DefaultPolicyConfig.RateLimitPeriod
+	}
+	return conf
+}
+
+// Policies assembles the built-in admission policies enabled by the config.
+func (config *PolicyConfig) Policies() []Policy {
+	conf := config.sanitize()
+
+	var policies []Policy
+	if len(conf.AllowedSenders) > 0 || len(conf.DeniedAddresses) > 0 {
+		policies = append(policies, NewAddressPolicy(conf.AllowedSenders, conf.DeniedAddresses))
+	}
+	if len(conf.AllowedCreators) > 0 {
+		policies = append(policies, NewCreationPolicy(conf.AllowedCreators))
+	}
+	if conf.SenderRateLimit > 0 {
+		policies = append(policies, NewRateLimitPolicy(conf.SenderRateLimit, conf.RateLimitPeriod, conf.RateLimitLocals, mclock.System{}))
+	}
+	return policies
+}
+
+// addressSet converts a list of addresses into a lookup set.
+func addressSet(addrs []common.Address) map[common.Address]struct{} {
+	set := make(map[common.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = struct{}{}
+	}
+	return set
+}
+
+// AddressPolicy admits transactions based on sender allow-lists and sender or
+// recipient deny-lists.
+type AddressPolicy struct {
+	allowed map[common.Address]struct{}
+	denied  map[common.Address]struct{}
+}
+
+// NewAddressPolicy creates an address based admission policy. If the allowed
+// list is empty, any sender not explicitly denied is accepted.
+func NewAddressPolicy(allowed, denied []common.Address) *AddressPolicy {
+	return &AddressPolicy{
+		allowed: addressSet(allowed),
+		denied:  addressSet(denied),
+	}
+}
+
+// Name implements Policy.
+func (p *AddressPolicy) Name() string { return "address" }
+
+// Admit implements Policy, rejecting denied senders and recipients as well as
+// senders missing from a non-empty allow-list.
+func (p *AddressPolicy) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	if _, ok := p.denied[from]; ok {
+		return fmt.Errorf("%w: sender %v", errAddressDenied, from)
+	}
+	if to := tx.To(); to != nil {
+		if _, ok := p.denied[*to]; ok {
+			return fmt.Errorf("%w: recipient %v", errAddressDenied, *to)
+		}
+	}
+	if len(p.allowed) > 0 {
+		if _, ok := p.allowed[from]; !ok {
+			return fmt.Errorf("%w: %v", errSenderNotAllowed, from)
+		}
+	}
+	return nil
+}
+
+// CreationPolicy restricts contract deployments to a set of approved senders.
+type CreationPolicy struct {
+	creators map[common.Address]struct{}
+}
+
+// NewCreationPolicy creates a policy only admitting contract creations from the
+// given senders.
+func NewCreationPolicy(creators []common.Address) *CreationPolicy {
+	return &CreationPolicy{creators: addressSet(creators)}
+}
+
+// Name implements Policy.
+func (p *CreationPolicy) Name() string { return "creation" }
+
+// Admit implements Policy, rejecting contract creations from unapproved senders.
+func (p *CreationPolicy) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	if tx.To() != nil {
+		return nil
+	}
+	if _, ok := p.creators[from]; !ok {
+		return fmt.Errorf("%w: %v", errCreationNotAllowed, from)
+	}
+	return nil
+}
+
+// rateWindow tracks the admissions of a single sender in the current period.
+type rateWindow struct {
+	start mclock.AbsTime
+	count uint64
+}
+
+// RateLimitPolicy limits the number of transactions a single sender may get
+// admitted within a fixed time window.
+type RateLimitPolicy struct {
+	limit  uint64
+	period time.Duration
+	locals bool // Whether local transactions are counted too
+	clock  mclock.Clock
+
+	windows map[common.Address]*rateWindow
+	pruned  mclock.AbsTime // Last time stale windows were dropped
+	lock    sync.Mutex
+}
+
+// NewRateLimitPolicy creates a policy admitting at most limit transactions per
+// sender within each period.
+func NewRateLimitPolicy(limit uint64, period time.Duration, locals bool, clock mclock.Clock) *RateLimitPolicy {
+	return &RateLimitPolicy{
+		limit:   limit,
+		period:  period,
+		locals:  locals,
+		clock:   clock,
+		windows: make(map[common.Address]*rateWindow),
+		pruned:  clock.Now(),
+	}
+}
+
+// Name implements Policy.
+func (p *RateLimitPolicy) Name() string { return "ratelimit" }
+
+// Admit implements Policy, counting the transaction against the sender's quota
+// for the current window.
+func (p *RateLimitPolicy) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	if local && !p.locals {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.clock.Now()
+	if time.Duration(now-p.pruned) > p.period {
+		for addr, window := range p.windows {
+			if time.Duration(now-window.start) > p.period {
+				delete(p.windows, addr)
+			}
+		}
+		p.pruned = now
+	}
+	window := p.windows[from]
+	if window == nil || time.Duration(now-window.start) > p.period {
+		window = &rateWindow{start: now}
+		p.windows[from] = window
+	}
+	if window.count >= p.limit {
+		return fmt.Errorf("%w: %d per %v", errRateLimited, p.limit, p.period)
+	}
+	window.count++
+	return nil
+}
+
+// Refund implements RefundPolicy, giving back the quota claimed by a tx that
+// was admitted but subsequently rejected by the pool.
+func (p *RateLimitPolicy) Refund(tx *types.Transaction, from common.Address, local bool) {
+	if local && !p.locals {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if window := p.windows[from]; window != nil && window.count > 0 {
+		window.count--
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// policyTx creates a signed dynamic fee transaction to the given recipient.
+func policyTx(t *testing.T, key *ecdsa.PrivateKey, nonce uint64, to *common.Address) *types.Transaction {
+	t.Helper()
+
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       100000,
+		To:        to,
+	})
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	return tx
+}
+
+// Tests that the address policy enforces both the allow- and the deny-lists.
+func TestAddressPolicy(t *testing.T) {
+	var (
+		allowedKey, _ = crypto.GenerateKey()
+		otherKey, _   = crypto.GenerateKey()
+		allowed       = crypto.PubkeyToAddress(allowedKey.PublicKey)
+		other         = crypto.PubkeyToAddress(otherKey.PublicKey)
+		denied        = common.Address{0xde, 0xad}
+		benign        = common.Address{0x01}
+	)
+	policy := NewAddressPolicy([]common.Address{allowed}, []common.Address{denied})
+
+	if err := policy.Admit(policyTx(t, allowedKey, 0, &benign), allowed, false); err != nil {
+		t.Errorf("allowed sender rejected: %v", err)
+	}
+	if err := policy.Admit(policyTx(t, otherKey, 0, &benign), other, false); !errors.Is(err, errSenderNotAllowed) {
+		t.Errorf("unlisted sender error mismatch: have %v, want %v", err, errSenderNotAllowed)
+	}
+	if err := policy.Admit(policyTx(t, allowedKey, 0, &denied), allowed, false); !errors.Is(err, errAddressDenied) {
+		t.Errorf("denied recipient error mismatch: have %v, want %v", err, errAddressDenied)
+	}
+	// Without an allow-list, only the deny-list should be enforced
+	policy = NewAddressPolicy(nil, []common.Address{allowed})
+	if err := policy.Admit(policyTx(t, otherKey, 0, &benign), other, false); err != nil {
+		t.Errorf("unlisted sender rejected: %v", err)
+	}
+	if err := policy.Admit(policyTx(t, allowedKey, 0, &benign), allowed, false); !errors.Is(err, errAddressDenied) {
+		t.Errorf("denied sender error mismatch: have %v, want %v", err, errAddressDenied)
+	}
+}
+
+// Tests that contract creations are only admitted from approved senders.
+func TestCreationPolicy(t *testing.T) {
+	var (
+		creatorKey, _ = crypto.GenerateKey()
+		otherKey, _   = crypto.GenerateKey()
+		creator       = crypto.PubkeyToAddress(creatorKey.PublicKey)
+		other         = crypto.PubkeyToAddress(otherKey.PublicKey)
+		recipient     = common.Address{0x01}
+	)
+	policy := NewCreationPolicy([]common.Address{creator})
+
+	if err := policy.Admit(policyTx(t, creatorKey, 0, nil), creator, false); err != nil {
+		t.Errorf("approved creation rejected: %v", err)
+	}
+	if err := policy.Admit(policyTx(t, otherKey, 0, nil), other, false); !errors.Is(err, errCreationNotAllowed) {
+		t.Errorf("unapproved creation error mismatch: have %v, want %v", err, errCreationNotAllowed)
+	}
+	if err := policy.Admit(policyTx(t, otherKey, 0, &recipient), other, false); err != nil {
+		t.Errorf("plain transfer rejected: %v", err)
+	}
+}
+
+// Tests that the rate limiter caps admissions per sender and window, and that
+// the quota is replenished once the window passes.
+func TestRateLimitPolicy(t *testing.T) {
+	var (
+		clock     = new(mclock.Simulated)
+		key, _    = crypto.GenerateKey()
+		from      = crypto.PubkeyToAddress(key.PublicKey)
+		other     = common.Address{0x02}
+		recipient = common.Address{0x01}
+		policy    = NewRateLimitPolicy(2, time.Minute, false, clock)
+	)
+	for i := 0; i < 2; i++ {
+		if err := policy.Admit(policyTx(t, key, uint64(i), &recipient), from, false); err != nil {
+			t.Fatalf("transaction %d rejected: %v", i, err)
+		}
+	}
+	if err := policy.Admit(policyTx(t, key, 2, &recipient), from, false); !errors.Is(err, errRateLimited) {
+		t.Fatalf("rate limit error mismatch: have %v, want %v", err, errRateLimited)
+	}
+	if err := policy.Admit(policyTx(t, key, 2, &recipient), from, true); err != nil {
+		t.Fatalf("exempt local transaction rejected: %v", err)
+	}
+	if err := policy.Admit(policyTx(t, key, 2, &recipient), other, false); err != nil {
+		t.Fatalf("different sender rejected: %v", err)
+	}
+	// Refunding an admission should free up quota for another transaction
+	policy.Refund(policyTx(t, key, 1, &recipient), from, false)
+	if err := policy.Admit(policyTx(t, key, 2, &recipient), from, false); err != nil {
+		t.Fatalf("transaction rejected after refund: %v", err)
+	}
+	if err := policy.Admit(policyTx(t, key, 3, &recipient), from, false); !errors.Is(err, errRateLimited) {
+		t.Fatalf("rate limit error mismatch after refund: have %v, want %v", err, errRateLimited)
+	}
+	clock.Run(time.Minute + time.Second)
+	if err := policy.Admit(policyTx(t, key, 2, &recipient), from, false); err != nil {
+		t.Fatalf("transaction rejected after window passed: %v", err)
+	}
+}
+
+// Tests that the pool consults the configured policies before dispatching the
+// transactions to the subpools, and that rejections are reported in order.
+func TestPoolPolicies(t *testing.T) {
+	var (
+		goodKey, _ = crypto.GenerateKey()
+		badKey, _  = crypto.GenerateKey()
+		bad        = crypto.PubkeyToAddress(badKey.PublicKey)
+		recipient  = common.Address{0x01}
+	)
+	pool := new(TxPool)
+	pool.SetPolicies(NewAddressPolicy(nil, []common.Address{bad}))
+
+	errs := pool.Add([]*types.Transaction{
+		policyTx(t, goodKey, 0, &recipient),
+		policyTx(t, badKey, 0, &recipient),
+	}, true, false)
+
+	// Without subpools, admitted transactions are unsupported
+	if !errors.Is(errs[0], core.ErrTxTypeNotSupported) {
+		t.Errorf("admitted transaction error mismatch: have %v, want %v", errs[0], core.ErrTxTypeNotSupported)
+	}
+	if !errors.Is(errs[1], ErrPolicyRejected) {
+		t.Errorf("denied transaction error mismatch: have %v, want %v", errs[1], ErrPolicyRejected)
+	}
+}
+
+// Tests that transactions admitted by the policies but rejected afterwards, be
+// it by a later policy or by the subpools, don't use up the sender's quota.
+func TestPoolPolicyRefunds(t *testing.T) {
+	var (
+		key, _    = crypto.GenerateKey()
+		from      = crypto.PubkeyToAddress(key.PublicKey)
+		recipient = common.Address{0x01}
+		denied    = common.Address{0xde, 0xad}
+		limiter   = NewRateLimitPolicy(1, time.Minute, false, new(mclock.Simulated))
+	)
+	pool := new(TxPool)
+	pool.SetPolicies(limiter, NewAddressPolicy(nil, []common.Address{denied}))
+
+	// Without subpools, every admitted transaction is rejected as unsupported
+	for i := 0; i < 3; i++ {
+		errs := pool.Add([]*types.Transaction{
+			policyTx(t, key, uint64(i), &recipient),
+			policyTx(t, key, uint64(i), &denied),
+		}, false, false)
+
+		if !errors.Is(errs[0], core.ErrTxTypeNotSupported) {
+			t.Fatalf("round %d: admitted transaction error mismatch: have %v, want %v", i, errs[0], core.ErrTxTypeNotSupported)
+		}
+		if !errors.Is(errs[1], ErrPolicyRejected) {
+			t.Fatalf("round %d: denied transaction error mismatch: have %v, want %v", i, errs[1], ErrPolicyRejected)
+		}
+	}
+	// The quota must still be available for the sender
+	if err := limiter.Admit(policyTx(t, key, 3, &recipient), from, false); err != nil {
+		t.Fatalf("quota consumed by rejected transactions: %v", err)
+	}
+}
@@ -70,6 +70,9 @@ type TxPool struct {
 	reservations map[common.Address]SubPool // Map with the account to pool reservations
 	reserveLock  sync.Mutex                 // Lock protecting the account reservations
 
+	policies   []Policy     // Admission hooks consulted before dispatching to subpools
+	policyLock sync.RWMutex // Lock protecting the admission policies
+
 	subs event.SubscriptionScope // Subscription scope to unsubscribe all on shutdown
 	quit chan chan error         // Quit channel to tear down the head updater
 }
@@ -239,6 +242,47 @@ func (p *TxPool) SetGasTip(tip *big.Int) {
 	}
 }
 
+// SetPolicies replaces the admission policies consulted for every transaction
+// added to the pool. Transactions already in the pool are not re-checked.
+func (p *TxPool) SetPolicies(policies ...Policy) {
+	p.policyLock.Lock()
+	defer p.policyLock.Unlock()
+
+	p.policies = policies
+}
+
+// admit runs a transaction through the given admission policies and returns
+// the recovered sender along with the first rejection, if any. Policies that
+// already admitted the transaction are refunded if a later one rejects it.
+func (p *TxPool) admit(policies []Policy, tx *types.Transaction, local bool) (common.Address, error) {
+	if len(policies) == 0 {
+		return common.Address{}, nil
+	}
+	// The pool itself is not chain aware, so derive the signer from the tx. Any
+	// transaction signed for a different chain will be rejected by the subpools.
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return common.Address{}, ErrInvalidSender
+	}
+	for i, policy := range policies {
+		if err := policy.Admit(tx, from, local); err != nil {
+			p.refund(policies[:i], tx, from, local)
+			return from, fmt.Errorf("%w: %s: %v", ErrPolicyRejected, policy.Name(), err)
+		}
+	}
+	return from, nil
+}
+
+// refund releases the resources claimed by the given policies when admitting
+// a transaction which ended up being rejected.
+func (p *TxPool) refund(policies []Policy, tx *types.Transaction, from common.Address, local bool) {
+	for _, policy := range policies {
+		if refunder, ok := policy.(RefundPolicy); ok {
+			refunder.Refund(tx, from, local)
+		}
+	}
+}
+
 // Has returns an indicator whether the pool has a transaction cached with the
 // given hash.
 func (p *TxPool) Has(hash common.Hash) bool {
@@ -272,11 +316,26 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 	// so we can piece back the returned errors into the original order.
 	txsets := make([][]*types.Transaction, len(p.subpools))
 	splits := make([]int, len(txs))
+	denied := make([]error, len(txs))
+	senders := make([]common.Address, len(txs))
+
+	// Snapshot the policies so admissions and refunds hit the same set
+	p.policyLock.RLock()
+	policies := p.policies
+	p.policyLock.RUnlock()
 
 	for i, tx := range txs {
 		// Mark this transaction belonging to no-subpool
 		splits[i] = -1
 
+		// Consult the admission policies before handing the tx to any subpool
+		from, err := p.admit(policies, tx, local)
+		if err != nil {
+			denied[i] = err
+			continue
+		}
+		senders[i] = from
+
 		// Try to find a subpool that accepts the transaction
 		for j, subpool := range p.subpools {
 			if subpool.Filter(tx) {
@@ -294,6 +353,11 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 	}
 	errs := make([]error, len(txs))
 	for i, split := range splits {
+		// If the transaction was refused by a policy, report the reason
+		if denied[i] != nil {
+			errs[i] = denied[i]
+			continue
+		}
 		// If the transaction was rejected by all subpools, mark it unsupported
 		if split == -1 {
 			errs[i] = core.ErrTxTypeNotSupported
@@ -303,6 +367,13 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 		errs[i] = errsets[split][0]
 		errsets[split] = errsets[split][1:]
 	}
+	// Release anything the policies claimed for transactions that were admitted
+	// but ultimately not accepted by the pool
+	for i, err := range errs {
+		if denied[i] == nil && err != nil {
+			p.refund(policies, txs[i], senders[i], local)
+		}
+	}
 	return errs
 }
 
@@ -343,6 +414,29 @@ func (p *TxPool) Nonce(addr common.Address) uint64 {
 	return nonce
 }
 
+// usageReporter is an optional interface for subpools able to report the total
+// encoded size of the transactions they hold and the byte budget enforced.
+type usageReporter interface {
+	Usage() (pending uint64, queued uint64, limit uint64)
+}
+
+// Usage retrieves the total encoded size of the pending and the queued (non-
+// executable) transactions, along with the byte budget enforced on them. Only
+// subpools tracking their contents by size contribute to the reported values.
+func (p *TxPool) Usage() (uint64, uint64, uint64) {
+	var pending, queued, limit uint64
+	for _, subpool := range p.subpools {
+		if reporter, ok := subpool.(usageReporter); ok {
+			pend, queue, lim := reporter.Usage()
+
+			pending += pend
+			queued += queue
+			limit += lim
+		}
+	}
+	return pending, queued, limit
+}
+
 // Stats retrieves the current pool stats, namely the number of pending and the
 // number of queued (non-executable) transactions.
 func (p *TxPool) Stats() (int, int) {
@@ -415,3 +509,11 @@ func (p *TxPool) Status(hash common.Hash) TxStatus {
 	}
 	return TxStatusUnknown
 }
+
+// Clear removes all tracked transactions from the pool. It is meant for testing
+// environments only, such as simulated chains discarding their pending state.
+func (p *TxPool) Clear() {
+	for _, subpool := range p.subpools {
+		subpool.Clear()
+	}
+}
@@ -19,6 +19,8 @@ package catalyst
 import (
 	"crypto/rand"
 	"errors"
+	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
@@ -28,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -79,6 +82,14 @@ type SimulatedBeacon struct {
 	engineAPI          *ConsensusAPI
 	curForkchoiceState engine.ForkchoiceStateV1
 	lastBlockTime      uint64
+
+	// Fields below are used to script the simulated consensus layer. They are
+	// all protected by the sealing lock, which also serializes block production.
+	sealLock   sync.Mutex
+	timeOffset uint64        // Seconds added to the wall clock when picking block timestamps
+	forkParent *types.Header // Canonical ancestor to build the next block on (nil = head)
+	safe       *common.Hash  // Safe block pinned via the API (nil = follow the head)
+	finalized  *common.Hash  // Finalized block pinned via the API (nil = epoch based)
 }
 
 func NewSimulatedBeacon(period uint64, eth *eth.Ethereum) (*SimulatedBeacon, error) {
@@ -134,64 +145,288 @@ func (c *SimulatedBeacon) Stop() error {
 }
 
 // sealBlock initiates payload building for a new block and creates a new block
-// with the completed payload.
-func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal) error {
-	tstamp := uint64(time.Now().Unix())
-	if tstamp <= c.lastBlockTime {
-		tstamp = c.lastBlockTime + 1
-	}
-	c.feeRecipientLock.Lock()
-	feeRecipient := c.feeRecipient
-	c.feeRecipientLock.Unlock()
+// with the completed payload. A zero timestamp means the next block time is
+// derived from the (adjusted) wall clock, a nil fee recipient means the one
+// configured via setFeeRecipient is used.
+func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp uint64, feeRecipient *common.Address) (common.Hash, error) {
+	c.sealLock.Lock()
+	defer c.sealLock.Unlock()
 
 	// Reset to CurrentBlock in case of the chain was rewound
 	if header := c.eth.BlockChain().CurrentBlock(); c.curForkchoiceState.HeadBlockHash != header.Hash() {
 		finalizedHash := c.finalizedBlockHash(header.Number.Uint64())
 		c.setCurrentState(header.Hash(), *finalizedHash)
 	}
-
+	parent := c.eth.BlockChain().CurrentBlock()
+	if fork := c.forkParent; fork != nil {
+		// Drop the fork point if the chain was rewound below it in the meantime
+		if number := fork.Number.Uint64(); number >= parent.Number.Uint64() || c.eth.BlockChain().GetCanonicalHash(number) != fork.Hash() {
+			c.forkParent = nil
+		} else {
+			parent = fork
+		}
+	}
+	if timestamp == 0 {
+		timestamp = uint64(time.Now().Unix()) + c.timeOffset
+		if timestamp <= parent.Time {
+			timestamp = parent.Time + 1
+		}
+	} else if timestamp <= parent.Time {
+		return common.Hash{}, fmt.Errorf("timestamp %d not after parent timestamp %d", timestamp, parent.Time)
+	}
+	if feeRecipient == nil {
+		c.feeRecipientLock.Lock()
+		recipient := c.feeRecipient
+		c.feeRecipientLock.Unlock()
+		feeRecipient = &recipient
+	}
 	var random [32]byte
 	rand.Read(random[:])
-	fcResponse, err := c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, &engine.PayloadAttributes{
-		Timestamp:             tstamp,
-		SuggestedFeeRecipient: feeRecipient,
-		Withdrawals:           withdrawals,
-		Random:                random,
-	})
-	if err != nil {
-		return err
+
+	// There is no beacon chain to take the parent block root from post-Cancun,
+	// use the zero hash instead
+	var beaconRoot *common.Hash
+	if c.eth.BlockChain().Config().IsCancun(new(big.Int).Add(parent.Number, common.Big1), timestamp) {
+		beaconRoot = new(common.Hash)
 	}
-	if fcResponse == engine.STATUS_SYNCING {
-		return errors.New("chain rewind prevented invocation of payload creation")
+	var (
+		envelope *engine.ExecutionPayloadEnvelope
+		err      error
+	)
+	if c.forkParent == nil {
+		envelope, err = c.buildOnHead(timestamp, *feeRecipient, random, withdrawals, beaconRoot)
+	} else {
+		envelope, err = c.buildOnAncestor(c.forkParent.Hash(), timestamp, *feeRecipient, random, withdrawals, beaconRoot)
 	}
-
-	envelope, err := c.engineAPI.getPayload(*fcResponse.PayloadID, true)
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 	payload := envelope.ExecutionPayload
 
 	var finalizedHash common.Hash
-	if payload.Number%devEpochLength == 0 {
+	if payload.Number%devEpochLength == 0 && c.finalized == nil && c.safe == nil {
 		finalizedHash = payload.BlockHash
 	} else {
 		if fh := c.finalizedBlockHash(payload.Number); fh == nil {
-			return errors.New("chain rewind interrupted calculation of finalized block hash")
+			return common.Hash{}, errors.New("chain rewind interrupted calculation of finalized block hash")
 		} else {
 			finalizedHash = *fh
 		}
 	}
 
 	// Mark the payload as canon
-	if _, err = c.engineAPI.NewPayloadV2(*payload); err != nil {
-		return err
+	if beaconRoot == nil {
+		_, err = c.engineAPI.NewPayloadV2(*payload)
+	} else {
+		var versionedHashes []common.Hash
+		versionedHashes, err = blobHashes(payload)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		_, err = c.engineAPI.NewPayloadV3(*payload, versionedHashes, beaconRoot)
+	}
+	if err != nil {
+		return common.Hash{}, err
 	}
 	c.setCurrentState(payload.BlockHash, finalizedHash)
 	// Mark the block containing the payload as canonical
 	if _, err = c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, nil); err != nil {
-		return err
+		return common.Hash{}, err
 	}
+	c.forkParent = nil
 	c.lastBlockTime = payload.Timestamp
+	return payload.BlockHash, nil
+}
+
+// buildOnHead requests a payload on top of the current head via the regular
+// forkchoiceUpdated flow and waits for the full payload to be assembled.
+func (c *SimulatedBeacon) buildOnHead(timestamp uint64, feeRecipient common.Address, random common.Hash, withdrawals []*types.Withdrawal, beaconRoot *common.Hash) (*engine.ExecutionPayloadEnvelope, error) {
+	attributes := &engine.PayloadAttributes{
+		Timestamp:             timestamp,
+		SuggestedFeeRecipient: feeRecipient,
+		Withdrawals:           withdrawals,
+		Random:                random,
+		BeaconRoot:            beaconRoot,
+	}
+	var (
+		fcResponse engine.ForkChoiceResponse
+		err        error
+	)
+	if beaconRoot == nil {
+		fcResponse, err = c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, attributes)
+	} else {
+		fcResponse, err = c.engineAPI.ForkchoiceUpdatedV3(c.curForkchoiceState, attributes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fcResponse == engine.STATUS_SYNCING {
+		return nil, errors.New("chain rewind prevented invocation of payload creation")
+	}
+	return c.engineAPI.getPayload(*fcResponse.PayloadID, true)
+}
+
+// buildOnAncestor assembles a payload on top of a canonical ancestor of the
+// head. The engine API refuses to build on old canonical blocks, so the miner
+// is invoked directly; the result becomes a sibling of the current chain which
+// is made canonical by the subsequent forkchoice update.
+func (c *SimulatedBeacon) buildOnAncestor(parent common.Hash, timestamp uint64, feeRecipient common.Address, random common.Hash, withdrawals []*types.Withdrawal, beaconRoot *common.Hash) (*engine.ExecutionPayloadEnvelope, error) {
+	payload, err := c.eth.Miner().BuildPayload(&miner.BuildPayloadArgs{
+		Parent:       parent,
+		Timestamp:    timestamp,
+		FeeRecipient: feeRecipient,
+		Random:       random,
+		Withdrawals:  withdrawals,
+		BeaconRoot:   beaconRoot,
+	})
+	if err != nil {
+		return nil, err
+	}
+	envelope := payload.ResolveFull()
+	if envelope == nil {
+		return nil, errors.New("payload building was interrupted")
+	}
+	return envelope, nil
+}
+
+// blobHashes collects the versioned hashes of the blobs referenced by the
+// transactions of a payload, in inclusion order.
+func blobHashes(payload *engine.ExecutableData) ([]common.Hash, error) {
+	hashes := make([]common.Hash, 0)
+	for i, enc := range payload.Transactions {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(enc); err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %v", i, err)
+		}
+		hashes = append(hashes, tx.BlobHashes()...)
+	}
+	return hashes, nil
+}
+
+// Commit seals a block on demand, optionally with an explicit timestamp and
+// fee recipient, and returns the hash of the new head.
+func (c *SimulatedBeacon) Commit(timestamp uint64, feeRecipient *common.Address) (common.Hash, error) {
+	withdrawals := c.withdrawals.gatherPending(10)
+	return c.sealBlock(withdrawals, timestamp, feeRecipient)
+}
+
+// AdjustTime shifts the clock used to derive block timestamps forward by the
+// given amount. It returns the total offset from the wall clock.
+func (c *SimulatedBeacon) AdjustTime(adjustment time.Duration) (time.Duration, error) {
+	if adjustment < 0 {
+		return 0, errors.New("cannot move time backwards")
+	}
+	c.sealLock.Lock()
+	defer c.sealLock.Unlock()
+
+	c.timeOffset += uint64(adjustment / time.Second)
+	return time.Duration(c.timeOffset) * time.Second, nil
+}
+
+// Rollback discards the last n blocks from the point of view of block
+// production: the next sealed block is built on top of the n-th ancestor of
+// the current head. Once sealed, the new block triggers a reorg onto the
+// sibling chain. Rolling back behind a pinned finalized block is refused.
+func (c *SimulatedBeacon) Rollback(n uint64) (common.Hash, error) {
+	c.sealLock.Lock()
+	defer c.sealLock.Unlock()
+
+	var (
+		chain = c.eth.BlockChain()
+		head  = chain.CurrentBlock()
+	)
+	if c.forkParent != nil {
+		head = c.forkParent
+	}
+	number := head.Number.Uint64()
+	if n > number {
+		return common.Hash{}, fmt.Errorf("cannot roll back %d blocks from block %d", n, number)
+	}
+	ancestor := chain.GetHeaderByNumber(number - n)
+	if ancestor == nil {
+		return common.Hash{}, fmt.Errorf("block %d not found", number-n)
+	}
+	if c.finalized != nil {
+		if final := chain.GetHeaderByHash(*c.finalized); final != nil && final.Number.Uint64() > ancestor.Number.Uint64() {
+			return common.Hash{}, fmt.Errorf("cannot roll back behind pinned finalized block %d", final.Number)
+		}
+	}
+	if c.safe != nil {
+		if safe := chain.GetHeaderByHash(*c.safe); safe != nil && safe.Number.Uint64() > ancestor.Number.Uint64() {
+			c.safe = nil
+		}
+	}
+	if n == 0 {
+		c.forkParent = nil
+	} else {
+		c.forkParent = ancestor
+	}
+	return ancestor.Hash(), nil
+}
+
+// SetSafe pins the safe block to the given canonical block. Passing the zero
+// hash makes the safe block follow the head again.
+func (c *SimulatedBeacon) SetSafe(hash common.Hash) error {
+	return c.pinForkchoice(&c.safe, hash)
+}
+
+// SetFinalized pins the finalized block to the given canonical block. Passing
+// the zero hash reverts to finalizing blocks at epoch boundaries.
+func (c *SimulatedBeacon) SetFinalized(hash common.Hash) error {
+	return c.pinForkchoice(&c.finalized, hash)
+}
+
+// pinForkchoice updates one of the pinned forkchoice markers and immediately
+// announces the new forkchoice state to the execution layer.
+func (c *SimulatedBeacon) pinForkchoice(pin **common.Hash, hash common.Hash) error {
+	c.sealLock.Lock()
+	defer c.sealLock.Unlock()
+
+	chain := c.eth.BlockChain()
+	if hash != (common.Hash{}) {
+		header := chain.GetHeaderByHash(hash)
+		if header == nil {
+			return fmt.Errorf("block %x not found", hash)
+		}
+		if chain.GetCanonicalHash(header.Number.Uint64()) != hash {
+			return fmt.Errorf("block %x not canonical", hash)
+		}
+	}
+	old := *pin
+	if hash == (common.Hash{}) {
+		*pin = nil
+	} else {
+		*pin = &hash
+	}
+	if err := c.validatePins(); err != nil {
+		*pin = old
+		return err
+	}
+	head := chain.CurrentBlock()
+	finalizedHash := c.finalizedBlockHash(head.Number.Uint64())
+	if finalizedHash == nil {
+		*pin = old
+		return errors.New("chain rewind interrupted calculation of finalized block hash")
+	}
+	c.setCurrentState(head.Hash(), *finalizedHash)
+	if _, err := c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, nil); err != nil {
+		*pin = old
+		return err
+	}
+	return nil
+}
+
+// validatePins checks that the pinned finalized block is not ahead of the
+// pinned safe block.
+func (c *SimulatedBeacon) validatePins() error {
+	if c.safe == nil || c.finalized == nil {
+		return nil
+	}
+	chain := c.eth.BlockChain()
+	safe, final := chain.GetHeaderByHash(*c.safe), chain.GetHeaderByHash(*c.finalized)
+	if safe != nil && final != nil && final.Number.Cmp(safe.Number) > 0 {
+		return fmt.Errorf("finalized block %d ahead of safe block %d", final.Number, safe.Number)
+	}
 	return nil
 }
 
@@ -209,12 +444,12 @@ func (c *SimulatedBeacon) loopOnDemand() {
 			return
 		case w := <-c.withdrawals.pending:
 			withdrawals := append(c.withdrawals.gatherPending(9), w)
-			if err := c.sealBlock(withdrawals); err != nil {
+			if _, err := c.sealBlock(withdrawals, 0, nil); err != nil {
 				log.Warn("Error performing sealing work", "err", err)
 			}
 		case <-newTxs:
 			withdrawals := c.withdrawals.gatherPending(10)
-			if err := c.sealBlock(withdrawals); err != nil {
+			if _, err := c.sealBlock(withdrawals, 0, nil); err != nil {
 				log.Warn("Error performing sealing work", "err", err)
 			}
 		}
@@ -230,7 +465,7 @@ func (c *SimulatedBeacon) loop() {
 			return
 		case <-timer.C:
 			withdrawals := c.withdrawals.gatherPending(10)
-			if err := c.sealBlock(withdrawals); err != nil {
+			if _, err := c.sealBlock(withdrawals, 0, nil); err != nil {
 				log.Warn("Error performing sealing work", "err", err)
 			} else {
 				timer.Reset(time.Second * time.Duration(c.period))
@@ -242,6 +477,11 @@ func (c *SimulatedBeacon) loop() {
 // finalizedBlockHash returns the block hash of the finalized block corresponding to the given number
 // or nil if doesn't exist in the chain.
 func (c *SimulatedBeacon) finalizedBlockHash(number uint64) *common.Hash {
+	if c.finalized != nil {
+		if header := c.eth.BlockChain().GetHeaderByHash(*c.finalized); header != nil && header.Number.Uint64() <= number {
+			return c.finalized
+		}
+	}
 	var finalizedNumber uint64
 	if number%devEpochLength == 0 {
 		finalizedNumber = number
@@ -249,6 +489,12 @@ func (c *SimulatedBeacon) finalizedBlockHash(number uint64) *common.Hash {
 		finalizedNumber = (number - 1) / devEpochLength * devEpochLength
 	}
 
+	// Never finalize past a block explicitly pinned as safe
+	if c.safe != nil {
+		if header := c.eth.BlockChain().GetHeaderByHash(*c.safe); header != nil && header.Number.Uint64() < finalizedNumber {
+			finalizedNumber = header.Number.Uint64()
+		}
+	}
 	if finalizedBlock := c.eth.BlockChain().GetBlockByNumber(finalizedNumber); finalizedBlock != nil {
 		fh := finalizedBlock.Hash()
 		return &fh
@@ -258,9 +504,13 @@ func (c *SimulatedBeacon) finalizedBlockHash(number uint64) *common.Hash {
 
 // setCurrentState sets the current forkchoice state
 func (c *SimulatedBeacon) setCurrentState(headHash, finalizedHash common.Hash) {
+	safeHash := headHash
+	if c.safe != nil {
+		safeHash = *c.safe
+	}
 	c.curForkchoiceState = engine.ForkchoiceStateV1{
 		HeadBlockHash:      headHash,
-		SafeBlockHash:      headHash,
+		SafeBlockHash:      safeHash,
 		FinalizedBlockHash: finalizedHash,
 	}
 }
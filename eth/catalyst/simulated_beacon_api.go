@@ -18,8 +18,10 @@ package catalyst
 
 import (
 	"context"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
@@ -27,6 +29,12 @@ type api struct {
 	simBeacon *SimulatedBeacon
 }
 
+// MineArgs are the optional overrides for a block produced via dev_mine.
+type MineArgs struct {
+	Timestamp    *hexutil.Uint64 `json:"timestamp"`
+	FeeRecipient *common.Address `json:"feeRecipient"`
+}
+
 func (a *api) AddWithdrawal(ctx context.Context, withdrawal *types.Withdrawal) error {
 	return a.simBeacon.withdrawals.add(withdrawal)
 }
@@ -34,3 +42,41 @@ func (a *api) AddWithdrawal(ctx context.Context, withdrawal *types.Withdrawal) e
 func (a *api) SetFeeRecipient(ctx context.Context, feeRecipient common.Address) {
 	a.simBeacon.setFeeRecipient(feeRecipient)
 }
+
+// Mine seals a new block on demand and returns its hash.
+func (a *api) Mine(ctx context.Context, args *MineArgs) (common.Hash, error) {
+	var (
+		timestamp    uint64
+		feeRecipient *common.Address
+	)
+	if args != nil {
+		if args.Timestamp != nil {
+			timestamp = uint64(*args.Timestamp)
+		}
+		feeRecipient = args.FeeRecipient
+	}
+	return a.simBeacon.Commit(timestamp, feeRecipient)
+}
+
+// AdjustTime moves the block timestamp clock forward by the given number of
+// seconds and returns the total offset from the wall clock.
+func (a *api) AdjustTime(ctx context.Context, seconds hexutil.Uint64) (hexutil.Uint64, error) {
+	offset, err := a.simBeacon.AdjustTime(time.Duration(seconds) * time.Second)
+	return hexutil.Uint64(offset / time.Second), err
+}
+
+// Rollback makes the next mined block a child of the n-th ancestor of the
+// head, creating a reorg. It returns the hash of the new fork point.
+func (a *api) Rollback(ctx context.Context, n hexutil.Uint64) (common.Hash, error) {
+	return a.simBeacon.Rollback(uint64(n))
+}
+
+// SetSafe pins the safe block, the zero hash unpins it.
+func (a *api) SetSafe(ctx context.Context, hash common.Hash) error {
+	return a.simBeacon.SetSafe(hash)
+}
+
+// SetFinalized pins the finalized block, the zero hash unpins it.
+func (a *api) SetFinalized(ctx context.Context, hash common.Hash) error {
+	return a.simBeacon.SetFinalized(hash)
+}
@@ -34,7 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 )
 
-func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis) (*node.Node, *eth.Ethereum, *SimulatedBeacon) {
+func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis, period uint64) (*node.Node, *eth.Ethereum, *SimulatedBeacon) {
 	t.Helper()
 
 	n, err := node.New(&node.Config{
@@ -54,7 +54,7 @@ func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis) (*node.
 		t.Fatal("can't create eth service:", err)
 	}
 
-	simBeacon, err := NewSimulatedBeacon(1, ethservice)
+	simBeacon, err := NewSimulatedBeacon(period, ethservice)
 	if err != nil {
 		t.Fatal("can't create simulated beacon:", err)
 	}
@@ -86,7 +86,7 @@ func TestSimulatedBeaconSendWithdrawals(t *testing.T) {
 	// short period (1 second) for testing purposes
 	var gasLimit uint64 = 10_000_000
 	genesis := core.DeveloperGenesisBlock(gasLimit, testAddr)
-	node, ethService, mock := startSimulatedBeaconEthService(t, genesis)
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 1)
 	_ = mock
 	defer node.Close()
 
@@ -139,3 +139,89 @@ func TestSimulatedBeaconSendWithdrawals(t *testing.T) {
 		}
 	}
 }
+
+// Tests that blocks can be produced on demand with scripted timestamps, fee
+// recipients, reorgs and forkchoice markers.
+func TestSimulatedBeaconScripting(t *testing.T) {
+	var (
+		testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+		coinbase   = common.Address{0xc0, 0xff, 0xee}
+	)
+	genesis := core.DeveloperGenesisBlock(10_000_000, testAddr)
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 0)
+	defer node.Close()
+
+	chain := ethService.BlockChain()
+
+	// Mine a block with explicit timestamp and fee recipient
+	genesisTime := chain.CurrentBlock().Time
+	hash, err := mock.Commit(genesisTime+100, &coinbase)
+	if err != nil {
+		t.Fatal("failed to commit block:", err)
+	}
+	head := chain.CurrentBlock()
+	if head.Hash() != hash || head.Time != genesisTime+100 || head.Coinbase != coinbase {
+		t.Fatalf("unexpected head: hash %x time %d coinbase %x", head.Hash(), head.Time, head.Coinbase)
+	}
+	if _, err := mock.Commit(head.Time, nil); err == nil {
+		t.Fatal("expected error for non-increasing timestamp")
+	}
+	// Advance the clock and ensure the next block honours it
+	if _, err := mock.AdjustTime(time.Hour); err != nil {
+		t.Fatal("failed to adjust time:", err)
+	}
+	if _, err := mock.Commit(0, nil); err != nil {
+		t.Fatal("failed to commit block:", err)
+	}
+	if now := uint64(time.Now().Unix()); chain.CurrentBlock().Time < now+3600 {
+		t.Fatalf("block time not adjusted: have %d, want >= %d", chain.CurrentBlock().Time, now+3600)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := mock.Commit(0, nil); err != nil {
+			t.Fatal("failed to commit block:", err)
+		}
+	}
+	// Roll back two blocks and build a sibling chain
+	oldHead := chain.CurrentBlock()
+	ancestor, err := mock.Rollback(2)
+	if err != nil {
+		t.Fatal("failed to roll back:", err)
+	}
+	if _, err := mock.Commit(0, nil); err != nil {
+		t.Fatal("failed to commit sibling block:", err)
+	}
+	head = chain.CurrentBlock()
+	if head.ParentHash != ancestor || head.Number.Uint64() != oldHead.Number.Uint64()-1 {
+		t.Fatalf("sibling not built on fork point: parent %x, number %d", head.ParentHash, head.Number)
+	}
+	if chain.GetCanonicalHash(oldHead.Number.Uint64()) != (common.Hash{}) {
+		t.Fatal("old head still canonical after reorg")
+	}
+	// Pin the safe and finalized blocks
+	safe, final := chain.GetHeaderByNumber(3), chain.GetHeaderByNumber(2)
+	if err := mock.SetFinalized(safe.Hash()); err != nil {
+		t.Fatal("failed to set finalized block:", err)
+	}
+	if err := mock.SetSafe(final.Hash()); err == nil {
+		t.Fatal("expected error for safe block behind finalized block")
+	}
+	if err := mock.SetFinalized(final.Hash()); err != nil {
+		t.Fatal("failed to set finalized block:", err)
+	}
+	if err := mock.SetSafe(safe.Hash()); err != nil {
+		t.Fatal("failed to set safe block:", err)
+	}
+	if _, err := mock.Commit(0, nil); err != nil {
+		t.Fatal("failed to commit block:", err)
+	}
+	if have := chain.CurrentSafeBlock().Hash(); have != safe.Hash() {
+		t.Fatalf("safe block mismatch: have %x, want %x", have, safe.Hash())
+	}
+	if have := chain.CurrentFinalBlock().Hash(); have != final.Hash() {
+		t.Fatalf("finalized block mismatch: have %x, want %x", have, final.Hash())
+	}
+	if _, err := mock.Rollback(chain.CurrentBlock().Number.Uint64() - 1); err == nil {
+		t.Fatal("expected error rolling back behind finalized block")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
 )
 
 // timeoutGracePeriod is the amount of time to allow for a peer to deliver a
@@ -145,7 +146,7 @@ func (d *Downloader) concurrentFetch(queue typedQueue, beaconMode bool) error {
 				pending, stale := pending[peer.id], stales[peer.id]
 				if pending == nil && stale == nil {
 					idles = append(idles, peer)
-					caps = append(caps, queue.capacity(peer, time.Second))
+					caps = append(caps, weightedCapacity(queue.capacity(peer, time.Second), peer.score()))
 				} else if stale != nil {
 					if waited := time.Since(stale.Sent); waited > timeoutGracePeriod {
 						// Request has been in flight longer than the grace period
@@ -311,6 +312,7 @@ func (d *Downloader) concurrentFetch(queue typedQueue, beaconMode bool) error {
 				log.Error("Delivery timeout from unknown peer", "peer", req.Peer)
 				continue
 			}
+			peer.report(p2p.RequestTimeout)
 			if fails > 2 {
 				queue.updateCapacity(peer, 0, 0)
 			} else {
@@ -367,6 +369,12 @@ func (d *Downloader) concurrentFetch(queue typedQueue, beaconMode bool) error {
 				// idle. If the delivery's stale, the peer should have already been idled.
 				if !errors.Is(err, errStaleDelivery) {
 					queue.updateCapacity(peer, accepted, res.Time)
+
+					if err == nil && accepted > 0 {
+						peer.report(p2p.UsefulResponse)
+					} else {
+						peer.report(p2p.UselessResponse)
+					}
 				}
 			}
 
@@ -29,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/msgrate"
 )
 
@@ -65,6 +66,13 @@ type Peer interface {
 	RequestReceipts([]common.Hash, chan *eth.Response) (*eth.Request, error)
 }
 
+// reputationPeer is implemented by peers whose reputation is tracked by the p2p
+// server, allowing the downloader to share its observations about them.
+type reputationPeer interface {
+	Score() int64
+	Report(p2p.ReputationEvent)
+}
+
 // newPeerConnection creates a new downloader peer.
 func newPeerConnection(id string, version uint, peer Peer, logger log.Logger) *peerConnection {
 	return &peerConnection{
@@ -84,6 +92,21 @@ func (p *peerConnection) Reset() {
 	p.lacking = make(map[common.Hash]struct{})
 }
 
+// report forwards an observation about the peer to its reputation tracker.
+func (p *peerConnection) report(ev p2p.ReputationEvent) {
+	if rp, ok := p.peer.(reputationPeer); ok {
+		rp.Report(ev)
+	}
+}
+
+// score returns the reputation score of the peer, zero if it's not tracked.
+func (p *peerConnection) score() int64 {
+	if rp, ok := p.peer.(reputationPeer); ok {
+		return rp.Score()
+	}
+	return 0
+}
+
 // UpdateHeaderRate updates the peer's estimated header retrieval throughput with
 // the current measurement.
 func (p *peerConnection) UpdateHeaderRate(delivered int, elapsed time.Duration) {
@@ -269,6 +292,18 @@ func (ps *peerSet) AllPeers() []*peerConnection {
 	return list
 }
 
+// weightedCapacity scales down the capacity of peers with a negative reputation,
+// so that idle peers with a better track record are served requests first.
+func weightedCapacity(cap int, score int64) int {
+	if score >= 0 {
+		return cap
+	}
+	if score < p2p.MinReputation {
+		score = p2p.MinReputation
+	}
+	return int(int64(cap) * (score - p2p.MinReputation) / -p2p.MinReputation)
+}
+
 // peerCapacitySort implements sort.Interface.
 // It sorts peer connections by capacity (descending).
 type peerCapacitySort struct {
@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
@@ -73,6 +74,7 @@ var Defaults = Config{
 	Miner:              miner.DefaultConfig,
 	TxPool:             legacypool.DefaultConfig,
 	BlobPool:           blobpool.DefaultConfig,
+	TxPoolPolicy:       txpool.DefaultPolicyConfig,
 	RPCGasCap:          50000000,
 	RPCEVMTimeout:      5 * time.Second,
 	GPO:                FullNodeGPO,
@@ -142,8 +144,13 @@ type Config struct {
 	Miner miner.Config
 
 	// Transaction pool options
-	TxPool   legacypool.Config
-	BlobPool blobpool.Config
+	TxPool       legacypool.Config
+	BlobPool     blobpool.Config
+	TxPoolPolicy txpool.PolicyConfig
+
+	// Transaction propagation options
+	TxUploadCap   uint64 `toml:",omitempty"` // Upload bandwidth cap for transaction propagation in bytes/s (0 = unlimited)
+	TxDownloadCap uint64 `toml:",omitempty"` // Download bandwidth cap for transaction retrieval in bytes/s (0 = unlimited)
 
 	// Gas Price Oracle options
 	GPO gasprice.Config
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
@@ -48,6 +49,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Miner                   miner.Config
 		TxPool                  legacypool.Config
 		BlobPool                blobpool.Config
+		TxPoolPolicy            txpool.PolicyConfig
+		TxUploadCap             uint64 `toml:",omitempty"`
+		TxDownloadCap           uint64 `toml:",omitempty"`
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
 		DocRoot                 string `toml:"-"`
@@ -89,6 +93,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Miner = c.Miner
 	enc.TxPool = c.TxPool
 	enc.BlobPool = c.BlobPool
+	enc.TxPoolPolicy = c.TxPoolPolicy
+	enc.TxUploadCap = c.TxUploadCap
+	enc.TxDownloadCap = c.TxDownloadCap
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.DocRoot = c.DocRoot
@@ -134,6 +141,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Miner                   *miner.Config
 		TxPool                  *legacypool.Config
 		BlobPool                *blobpool.Config
+		TxPoolPolicy            *txpool.PolicyConfig
+		TxUploadCap             *uint64 `toml:",omitempty"`
+		TxDownloadCap           *uint64 `toml:",omitempty"`
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
 		DocRoot                 *string `toml:"-"`
@@ -240,6 +250,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.BlobPool != nil {
 		c.BlobPool = *dec.BlobPool
 	}
+	if dec.TxPoolPolicy != nil {
+		c.TxPoolPolicy = *dec.TxPoolPolicy
+	}
+	if dec.TxUploadCap != nil {
+		c.TxUploadCap = *dec.TxUploadCap
+	}
+	if dec.TxDownloadCap != nil {
+		c.TxDownloadCap = *dec.TxDownloadCap
+	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 )
@@ -70,6 +71,10 @@ const (
 	// txGatherSlack is the interval used to collate almost-expired announces
 	// with network fetches.
 	txGatherSlack = 100 * time.Millisecond
+
+	// txUnknownSize is the size charged against the download budget for the
+	// retrieval of a transaction announced without metadata (before eth/68).
+	txUnknownSize = 1024
 )
 
 var (
@@ -193,6 +198,10 @@ type TxFetcher struct {
 	fetchTxs func(string, []common.Hash) error  // Retrieves a set of txs from a remote peer
 	dropPeer func(string)                       // Drops a peer in case of announcement violation
 
+	budget        *eth.TxBudget // Bandwidth budget for retrievals (nil = unlimited)
+	throttle      chan struct{} // Notification channel when the budget was replenished
+	throttleTimer mclock.Timer  // Timer waiting for the budget to replenish, nil if idle
+
 	step  chan struct{} // Notification channel when the fetcher loop iterates
 	clock mclock.Clock  // Time wrapper to simulate in tests
 	rand  *mrand.Rand   // Randomizer to use in tests instead of map range loops (soft-random)
@@ -214,6 +223,7 @@ func NewTxFetcherForTests(
 		cleanup:     make(chan *txDelivery),
 		drop:        make(chan *txDrop),
 		quit:        make(chan struct{}),
+		throttle:    make(chan struct{}, 1),
 		waitlist:    make(map[common.Hash]map[string]struct{}),
 		waittime:    make(map[common.Hash]mclock.AbsTime),
 		waitslots:   make(map[string]map[common.Hash]*txMetadata),
@@ -232,6 +242,12 @@ func NewTxFetcherForTests(
 	}
 }
 
+// SetBudget sets the bandwidth budget that transaction retrievals are charged
+// against. It must be called before Start.
+func (f *TxFetcher) SetBudget(budget *eth.TxBudget) {
+	f.budget = budget
+}
+
 // Notify announces the fetcher of the potential availability of a new batch of
 // transactions in the network.
 func (f *TxFetcher) Notify(peer string, types []byte, sizes []uint32, hashes []common.Hash) error {
@@ -291,6 +307,11 @@ func (f *TxFetcher) isKnownUnderpriced(hash common.Hash) bool {
 	return ok
 }
 
+// ErrTxSpam is returned by Enqueue if a large share of the delivered transactions
+// was rejected by the pool. The transactions are still processed, the error only
+// flags the delivering peer.
+var ErrTxSpam = errors.New("peer delivering rejected transactions")
+
 // Enqueue imports a batch of received transaction into the transaction pool
 // and the fetcher. This method may be called by both transaction broadcasts and
 // direct request replies. The differentiation is important so the fetcher can
@@ -316,6 +337,7 @@ func (f *TxFetcher) Enqueue(peer string, txs []*types.Transaction, direct bool)
 	var (
 		added = make([]common.Hash, 0, len(txs))
 		metas = make([]txMetadata, 0, len(txs))
+		spam  bool
 	)
 	// proceed in batches
 	for i := 0; i < len(txs); i += 128 {
@@ -364,10 +386,14 @@ func (f *TxFetcher) Enqueue(peer string, txs []*types.Transaction, direct bool)
 		if otherreject > 128/4 {
 			time.Sleep(200 * time.Millisecond)
 			log.Debug("Peer delivering stale transactions", "peer", peer, "rejected", otherreject)
+			spam = true
 		}
 	}
 	select {
 	case f.cleanup <- &txDelivery{origin: peer, hashes: added, metas: metas, direct: direct}:
+		if spam {
+			return ErrTxSpam
+		}
 		return nil
 	case <-f.quit:
 		return errTerminated
@@ -581,6 +607,11 @@ func (f *TxFetcher) loop() {
 			// TODO(karalabe): this is kind of lame, can't we dump it into scheduleFetches somehow?
 			f.rescheduleTimeout(timeoutTimer, timeoutTrigger)
 
+		case <-f.throttle:
+			// The bandwidth budget was replenished, resume the stalled retrievals
+			f.throttleTimer = nil
+			f.scheduleFetches(timeoutTimer, timeoutTrigger, nil)
+
 		case delivery := <-f.cleanup:
 			// Independent if the delivery was direct or broadcast, remove all
 			// traces of the hash from internal trackers. That said, compare any
@@ -858,9 +889,14 @@ func (f *TxFetcher) scheduleFetches(timer *mclock.Timer, timeout chan struct{},
 		return
 	}
 	// For each active peer, try to schedule some transaction fetches
-	idle := len(f.requests) == 0
-
+	var (
+		idle      = len(f.requests) == 0
+		throttled bool
+	)
 	f.forEachPeer(actives, func(peer string) {
+		if throttled {
+			return // bandwidth budget exhausted, wait for it to replenish
+		}
 		if f.requests[peer] != nil {
 			return // continue in the for-each
 		}
@@ -876,6 +912,16 @@ func (f *TxFetcher) scheduleFetches(timer *mclock.Timer, timeout chan struct{},
 			if _, ok := f.fetching[hash]; ok {
 				return true
 			}
+			// Charge the retrieval against the bandwidth budget, stopping all
+			// scheduling if it's exhausted
+			size := uint64(txUnknownSize)
+			if meta != nil {
+				size = uint64(meta.size)
+			}
+			if !f.budget.ReserveDownload(size) {
+				throttled = true
+				return false
+			}
 			// Mark the hash as fetching and stash away possible alternates
 			f.fetching[hash] = peer
 
@@ -917,6 +963,12 @@ func (f *TxFetcher) scheduleFetches(timer *mclock.Timer, timeout chan struct{},
 	if idle && len(f.requests) > 0 {
 		f.rescheduleTimeout(timer, timeout)
 	}
+	// If the budget ran out, retry scheduling once it's replenished
+	if throttled && f.throttleTimer == nil {
+		f.throttleTimer = f.clock.AfterFunc(f.budget.DownloadDelay(), func() {
+			f.throttle <- struct{}{}
+		})
+	}
 }
 
 // forEachPeer does a range loop over a map of peers in production, but during
@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -89,6 +90,8 @@ type isUnderpriced int
 type txFetcherTest struct {
 	init  func() *TxFetcher
 	steps []interface{}
+
+	downloadCap uint64 // Download budget in bytes/s on the simulated clock (0 = unlimited)
 }
 
 // Tests that transaction announcements are added to a waitlist, and none
@@ -1091,6 +1094,82 @@ func TestTransactionFetcherBandwidthLimiting(t *testing.T) {
 	})
 }
 
+// Tests that retrievals are charged against the download budget, and that
+// scheduling resumes once the budget is replenished.
+func TestTransactionFetcherBudgetThrottling(t *testing.T) {
+	testTransactionFetcherParallel(t, txFetcherTest{
+		init: func() *TxFetcher {
+			return NewTxFetcher(
+				func(common.Hash) bool { return false },
+				nil,
+				func(string, []common.Hash) error { return nil },
+				nil,
+			)
+		},
+		downloadCap: 16 * 1024,
+		steps: []interface{}{
+			// Announce two transactions from A, only the first of which fits
+			// into the budget.
+			doTxNotify{peer: "A",
+				hashes: []common.Hash{{0x01}, {0x02}},
+				types:  []byte{types.LegacyTxType, types.LegacyTxType},
+				sizes:  []uint32{48 * 1024, 48 * 1024},
+			},
+			doWait{time: txArriveTimeout, step: true},
+			isScheduledWithMeta{
+				tracking: map[string][]announce{
+					"A": {
+						{common.Hash{0x01}, typeptr(types.LegacyTxType), sizeptr(48 * 1024)},
+						{common.Hash{0x02}, typeptr(types.LegacyTxType), sizeptr(48 * 1024)},
+					},
+				},
+				fetching: map[string][]common.Hash{
+					"A": {{0x02}},
+				},
+			},
+			// Announce a transaction from B, which is throttled too as the
+			// budget is still in debt.
+			doTxNotify{peer: "B",
+				hashes: []common.Hash{{0x03}},
+				types:  []byte{types.LegacyTxType},
+				sizes:  []uint32{1024},
+			},
+			doWait{time: txArriveTimeout, step: true},
+			isScheduledWithMeta{
+				tracking: map[string][]announce{
+					"A": {
+						{common.Hash{0x01}, typeptr(types.LegacyTxType), sizeptr(48 * 1024)},
+						{common.Hash{0x02}, typeptr(types.LegacyTxType), sizeptr(48 * 1024)},
+					},
+					"B": {
+						{common.Hash{0x03}, typeptr(types.LegacyTxType), sizeptr(1024)},
+					},
+				},
+				fetching: map[string][]common.Hash{
+					"A": {{0x02}},
+				},
+			},
+			// Wait for the budget to replenish and check that B is requested.
+			doWait{time: 2 * time.Second, step: true},
+			isScheduledWithMeta{
+				tracking: map[string][]announce{
+					"A": {
+						{common.Hash{0x01}, typeptr(types.LegacyTxType), sizeptr(48 * 1024)},
+						{common.Hash{0x02}, typeptr(types.LegacyTxType), sizeptr(48 * 1024)},
+					},
+					"B": {
+						{common.Hash{0x03}, typeptr(types.LegacyTxType), sizeptr(1024)},
+					},
+				},
+				fetching: map[string][]common.Hash{
+					"A": {{0x02}},
+					"B": {{0x03}},
+				},
+			},
+		},
+	})
+}
+
 // Tests that then number of transactions a peer is allowed to announce and/or
 // request at the same time is hard capped.
 func TestTransactionFetcherDoSProtection(t *testing.T) {
@@ -1633,6 +1712,9 @@ func testTransactionFetcher(t *testing.T, tt txFetcherTest) {
 
 	fetcher := tt.init()
 	fetcher.clock = clock
+	if tt.downloadCap != 0 {
+		fetcher.SetBudget(eth.NewTxBudgetForTests(0, tt.downloadCap, clock))
+	}
 	fetcher.step = wait
 	fetcher.rand = rand.New(rand.NewSource(0x3a29))
 
@@ -2028,3 +2110,37 @@ func TestTransactionForgotten(t *testing.T) {
 		t.Fatal("transaction should be known underpriced")
 	}
 }
+
+// Tests that deliveries mostly rejected by the pool are flagged as spam, while
+// still being processed.
+func TestTransactionFetcherSpam(t *testing.T) {
+	reject := false
+	fetcher := NewTxFetcher(
+		func(common.Hash) bool { return false },
+		func(txs []*types.Transaction) []error {
+			errs := make([]error, len(txs))
+			if reject {
+				for i := range errs {
+					errs[i] = errors.New("invalid transaction")
+				}
+			}
+			return errs
+		},
+		func(string, []common.Hash) error { return nil },
+		func(string) {},
+	)
+	fetcher.Start()
+	defer fetcher.Stop()
+
+	txs := make([]*types.Transaction, 64)
+	for i := range txs {
+		txs[i] = types.NewTx(&types.LegacyTx{Nonce: uint64(i)})
+	}
+	if err := fetcher.Enqueue("A", txs, false); err != nil {
+		t.Fatalf("accepted delivery flagged: %v", err)
+	}
+	reject = true
+	if err := fetcher.Enqueue("B", txs, false); !errors.Is(err, ErrTxSpam) {
+		t.Fatalf("rejected delivery not flagged: %v", err)
+	}
+}
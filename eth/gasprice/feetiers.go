@@ -0,0 +1,270 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	errNoBaseFee = errors.New("fee tiers unavailable before London")
+	errNoHistory = errors.New("no fee history available")
+)
+
+// feeTier is the definition of an urgency level offered by the oracle.
+type feeTier struct {
+	name       string
+	percentile float64 // Reward percentile sampled from the recent blocks
+	target     uint64  // Number of blocks the tier aims to get included within
+}
+
+// feeTiers are the urgency levels suggested by the oracle, ordered by
+// increasing urgency.
+var feeTiers = []feeTier{
+	{name: "slow", percentile: 10, target: 10},
+	{name: "standard", percentile: 50, target: 3},
+	{name: "fast", percentile: 90, target: 1},
+}
+
+// FeeTier is a fee suggestion for a single urgency level.
+type FeeTier struct {
+	Name           string   // Name of the urgency level
+	MaxPriorityFee *big.Int // Suggested priority fee (tip) per gas
+	MaxFee         *big.Int // Suggested fee cap, covering the worst case base fee until inclusion
+	MaxBlobFee     *big.Int // Suggested blob fee cap, covering the worst case blob fee until inclusion (nil pre-Cancun)
+	Blocks         uint64   // Expected number of blocks until inclusion
+}
+
+// FeeSuggestion is a set of tiered fee suggestions on top of a given head.
+type FeeSuggestion struct {
+	Number       *big.Int  // Head block the suggestions were computed on
+	BaseFee      *big.Int  // Base fee of the next block
+	BlobBaseFee  *big.Int  // Blob base fee of the next block (nil pre-Cancun)
+	GasUsedRatio float64   // Average fullness of the sampled blocks
+	PendingGas   uint64    // Gas of the executable pool transactions paying the next base fee
+	Tiers        []FeeTier // Suggestions ordered by increasing urgency
+}
+
+// poolBackend is an optional extension to OracleBackend for backends that have
+// access to a transaction pool. If available, the pending pool pressure is
+// taken into account when computing tiered suggestions.
+type poolBackend interface {
+	GetPoolTransactions() (types.Transactions, error)
+}
+
+// pendingTip is the effective tip and gas demand of a pending pool transaction.
+type pendingTip struct {
+	tip *big.Int
+	gas uint64
+}
+
+// SuggestFeeTiers returns tiered priority fee suggestions together with the
+// number of blocks each tier is expected to wait for inclusion.
+//
+// The tips are derived from the reward percentiles of the recent blocks as
+// reported by FeeHistory, raised if necessary to outbid the pending pool demand
+// competing for the blocks within the tier's target. The fee caps cover the
+// worst case base fee (and blob base fee) growth until the expected inclusion.
+func (oracle *Oracle) SuggestFeeTiers(ctx context.Context) (*FeeSuggestion, error) {
+	head, err := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if head.BaseFee == nil {
+		return nil, errNoBaseFee
+	}
+	percentiles := make([]float64, len(feeTiers))
+	for i, tier := range feeTiers {
+		percentiles[i] = tier.percentile
+	}
+	_, rewards, baseFees, ratios, err := oracle.FeeHistory(ctx, uint64(oracle.checkBlocks), rpc.BlockNumber(head.Number.Int64()), percentiles)
+	if err != nil {
+		return nil, err
+	}
+	if len(baseFees) == 0 {
+		return nil, errNoHistory
+	}
+	var (
+		config  = oracle.backend.ChainConfig()
+		baseFee = baseFees[len(baseFees)-1]
+		result  = &FeeSuggestion{
+			Number:  new(big.Int).Set(head.Number),
+			BaseFee: new(big.Int).Set(baseFee),
+		}
+	)
+	for _, ratio := range ratios {
+		result.GasUsedRatio += ratio
+	}
+	if len(ratios) > 0 {
+		result.GasUsedRatio /= float64(len(ratios))
+	}
+	if head.ExcessBlobGas != nil && head.BlobGasUsed != nil {
+		result.BlobBaseFee = eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(*head.ExcessBlobGas, *head.BlobGasUsed))
+	}
+	// Gather the pending demand that would compete for the upcoming blocks
+	pending := oracle.pendingTips(head.Hash(), baseFee)
+	for _, p := range pending {
+		result.PendingGas += p.gas
+	}
+	// Assemble the individual tiers, keeping them monotonic in urgency
+	var prev *big.Int
+	for i, tier := range feeTiers {
+		tip := medianReward(rewards, i)
+		if cutoff := pendingCutoff(pending, tier.target*head.GasLimit); cutoff != nil && cutoff.Cmp(tip) > 0 {
+			tip = cutoff
+		}
+		if prev != nil && tip.Cmp(prev) < 0 {
+			tip = new(big.Int).Set(prev)
+		}
+		if tip.Cmp(oracle.maxPrice) > 0 {
+			tip = new(big.Int).Set(oracle.maxPrice)
+		}
+		prev = tip
+
+		blocks := 1 + pendingAhead(pending, tip)/head.GasLimit
+		suggestion := FeeTier{
+			Name:           tier.name,
+			MaxPriorityFee: tip,
+			MaxFee:         new(big.Int).Add(tip, projectBaseFee(config, head, blocks)),
+			Blocks:         blocks,
+		}
+		if result.BlobBaseFee != nil {
+			suggestion.MaxBlobFee = projectBlobFee(*head.ExcessBlobGas, *head.BlobGasUsed, blocks)
+		}
+		result.Tiers = append(result.Tiers, suggestion)
+	}
+	return result, nil
+}
+
+// pendingTips collects the effective tips of the executable pool transactions
+// at the given base fee, sorted by decreasing tip. Transactions not able to pay
+// the base fee are not competing for inclusion and are skipped.
+//
+// Scanning the pool is expensive, so the tips are only gathered once per head
+// block and the cached result is returned for any subsequent call. The result
+// is shared and must not be modified.
+func (oracle *Oracle) pendingTips(headHash common.Hash, baseFee *big.Int) []pendingTip {
+	pool, ok := oracle.backend.(poolBackend)
+	if !ok {
+		return nil
+	}
+	oracle.tipsLock.Lock()
+	defer oracle.tipsLock.Unlock()
+
+	if headHash == oracle.tipsHead {
+		return oracle.tips
+	}
+	txs, err := pool.GetPoolTransactions()
+	if err != nil {
+		return nil
+	}
+	tips := make([]pendingTip, 0, len(txs))
+	for _, tx := range txs {
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil {
+			continue
+		}
+		tips = append(tips, pendingTip{tip: tip, gas: tx.Gas()})
+	}
+	slices.SortStableFunc(tips, func(a, b pendingTip) int {
+		return b.tip.Cmp(a.tip)
+	})
+	oracle.tipsHead, oracle.tips = headHash, tips
+	return tips
+}
+
+// pendingCutoff returns the lowest tip still fitting into the given amount of
+// gas if the pending transactions were included in order of their tips, or nil
+// if the pending demand does not exhaust the gas.
+func pendingCutoff(pending []pendingTip, gas uint64) *big.Int {
+	var used uint64
+	for _, p := range pending {
+		if used += p.gas; used >= gas {
+			return new(big.Int).Set(p.tip)
+		}
+	}
+	return nil
+}
+
+// pendingAhead returns the amount of pending gas outbidding the given tip.
+func pendingAhead(pending []pendingTip, tip *big.Int) uint64 {
+	var ahead uint64
+	for _, p := range pending {
+		if p.tip.Cmp(tip) <= 0 {
+			break
+		}
+		ahead += p.gas
+	}
+	return ahead
+}
+
+// medianReward returns the median of the given reward percentile across the
+// sampled blocks. Empty blocks carry no pricing information and are ignored.
+func medianReward(rewards [][]*big.Int, index int) *big.Int {
+	var samples []*big.Int
+	for _, reward := range rewards {
+		if index < len(reward) && reward[index].Sign() > 0 {
+			samples = append(samples, reward[index])
+		}
+	}
+	if len(samples) == 0 {
+		return new(big.Int)
+	}
+	slices.SortFunc(samples, func(a, b *big.Int) int { return a.Cmp(b) })
+	return new(big.Int).Set(samples[len(samples)/2])
+}
+
+// projectBaseFee returns the base fee of the block the given number of blocks
+// after head, assuming all blocks in between are full.
+func projectBaseFee(config *params.ChainConfig, head *types.Header, blocks uint64) *big.Int {
+	parent := &types.Header{
+		Number:   new(big.Int).Set(head.Number),
+		GasLimit: head.GasLimit,
+		GasUsed:  head.GasUsed,
+		BaseFee:  head.BaseFee,
+	}
+	for i := uint64(0); ; i++ {
+		baseFee := eip1559.CalcBaseFee(config, parent)
+		if i+1 >= blocks {
+			return baseFee
+		}
+		parent.Number = new(big.Int).Add(parent.Number, common.Big1)
+		parent.GasUsed = parent.GasLimit
+		parent.BaseFee = baseFee
+	}
+}
+
+// projectBlobFee returns the blob base fee of the block the given number of
+// blocks after a head with the given blob gas fields, assuming all blocks in
+// between carry the maximum number of blobs.
+func projectBlobFee(excess, used uint64, blocks uint64) *big.Int {
+	excess = eip4844.CalcExcessBlobGas(excess, used)
+	for i := uint64(1); i < blocks; i++ {
+		excess = eip4844.CalcExcessBlobGas(excess, params.MaxBlobGasPerBlock)
+	}
+	return eip4844.CalcBlobFee(excess)
+}
@@ -0,0 +1,160 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testPoolBackend is a test backend which also exposes a transaction pool.
+type testPoolBackend struct {
+	*testBackend
+	txs   types.Transactions
+	scans int
+}
+
+func (b *testPoolBackend) GetPoolTransactions() (types.Transactions, error) {
+	b.scans++
+	return b.txs, nil
+}
+
+func TestSuggestFeeTiers(t *testing.T) {
+	config := Config{
+		Blocks:           3,
+		Percentile:       60,
+		MaxHeaderHistory: 1000,
+		MaxBlockHistory:  1000,
+		Default:          big.NewInt(params.GWei),
+	}
+	backend := newTestBackend(t, big.NewInt(0), false)
+	defer backend.teardown()
+
+	// Without pool access, the tiers are derived from the block history alone.
+	// The sampled tips are: 32G, 31G, 30G
+	tiers, err := NewOracle(backend, config).SuggestFeeTiers(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to retrieve fee tiers: %v", err)
+	}
+	if len(tiers.Tiers) != len(feeTiers) {
+		t.Fatalf("Tier count mismatch, want %d, got %d", len(feeTiers), len(tiers.Tiers))
+	}
+	if tiers.BlobBaseFee != nil {
+		t.Fatalf("Blob base fee suggested pre-Cancun: %v", tiers.BlobBaseFee)
+	}
+	for _, tier := range tiers.Tiers {
+		if want := big.NewInt(31 * params.GWei); tier.MaxPriorityFee.Cmp(want) != 0 {
+			t.Errorf("Tier %s tip mismatch, want %v, got %v", tier.Name, want, tier.MaxPriorityFee)
+		}
+		if tier.Blocks != 1 {
+			t.Errorf("Tier %s inclusion mismatch, want %d, got %d", tier.Name, 1, tier.Blocks)
+		}
+		if tier.MaxFee.Cmp(new(big.Int).Add(tier.MaxPriorityFee, tiers.BaseFee)) < 0 {
+			t.Errorf("Tier %s fee cap %v below tip and base fee", tier.Name, tier.MaxFee)
+		}
+	}
+	// Fill the pool with two blocks worth of high paying transactions and check
+	// that only the fast tier outbids them while the others wait.
+	var (
+		key, _ = crypto.GenerateKey()
+		head   = backend.chain.CurrentBlock()
+		signer = types.LatestSigner(backend.chain.Config())
+		txs    types.Transactions
+	)
+	for i := 0; i < 2; i++ {
+		txs = append(txs, types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   backend.chain.Config().ChainID,
+			Nonce:     uint64(i),
+			Gas:       head.GasLimit,
+			GasFeeCap: big.NewInt(100 * params.GWei),
+			GasTipCap: big.NewInt(50 * params.GWei),
+		}))
+	}
+	// Transactions not able to pay the base fee should not be competing
+	txs = append(txs, types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID:   backend.chain.Config().ChainID,
+		Nonce:     2,
+		Gas:       head.GasLimit,
+		GasFeeCap: big.NewInt(1),
+		GasTipCap: big.NewInt(1),
+	}))
+	pooled := &testPoolBackend{testBackend: backend, txs: txs}
+	oracle := NewOracle(pooled, config)
+
+	tiers, err = oracle.SuggestFeeTiers(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to retrieve fee tiers: %v", err)
+	}
+	if want := 2 * head.GasLimit; tiers.PendingGas != want {
+		t.Fatalf("Pending gas mismatch, want %d, got %d", want, tiers.PendingGas)
+	}
+	expect := []struct {
+		tip    *big.Int
+		blocks uint64
+	}{
+		{big.NewInt(31 * params.GWei), 3},
+		{big.NewInt(31 * params.GWei), 3},
+		{big.NewInt(50 * params.GWei), 1},
+	}
+	for i, tier := range tiers.Tiers {
+		if tier.MaxPriorityFee.Cmp(expect[i].tip) != 0 {
+			t.Errorf("Tier %s tip mismatch, want %v, got %v", tier.Name, expect[i].tip, tier.MaxPriorityFee)
+		}
+		if tier.Blocks != expect[i].blocks {
+			t.Errorf("Tier %s inclusion mismatch, want %d, got %d", tier.Name, expect[i].blocks, tier.Blocks)
+		}
+	}
+	// Waiting longer should require a fee cap covering more base fee growth
+	if tiers.Tiers[0].MaxFee.Cmp(new(big.Int).Add(tiers.Tiers[0].MaxPriorityFee, tiers.BaseFee)) <= 0 {
+		t.Errorf("Slow tier fee cap %v does not cover base fee growth", tiers.Tiers[0].MaxFee)
+	}
+	// Repeated suggestions on the same head should not rescan the pool
+	again, err := oracle.SuggestFeeTiers(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to retrieve fee tiers: %v", err)
+	}
+	if pooled.scans != 1 {
+		t.Errorf("Pool scan count mismatch, want %d, got %d", 1, pooled.scans)
+	}
+	if again.PendingGas != tiers.PendingGas {
+		t.Errorf("Cached pending gas mismatch, want %d, got %d", tiers.PendingGas, again.PendingGas)
+	}
+}
+
+func TestProjectBlobFee(t *testing.T) {
+	var (
+		excess = uint64(50_000_000)
+		used   = uint64(params.BlobTxTargetBlobGasPerBlock)
+	)
+	if have, want := projectBlobFee(excess, used, 1), eip4844.CalcBlobFee(excess); have.Cmp(want) != 0 {
+		t.Fatalf("Next blob fee mismatch, want %v, got %v", want, have)
+	}
+	prev := projectBlobFee(excess, used, 1)
+	for blocks := uint64(2); blocks <= 5; blocks++ {
+		fee := projectBlobFee(excess, used, blocks)
+		if fee.Cmp(prev) <= 0 {
+			t.Fatalf("Blob fee not increasing after %d full blocks: %v <= %v", blocks, fee, prev)
+		}
+		prev = fee
+	}
+}
@@ -70,6 +70,10 @@ type Oracle struct {
 	cacheLock   sync.RWMutex
 	fetchLock   sync.Mutex
 
+	tipsHead common.Hash  // Head block the pending pool tips were gathered on
+	tips     []pendingTip // Pending pool tips sorted by decreasing value
+	tipsLock sync.Mutex   // Lock serializing the pool scans
+
 	checkBlocks, percentile           int
 	maxHeaderHistory, maxBlockHistory uint64
 
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"math"
 	"math/big"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -53,6 +54,17 @@ const (
 	// All transactions with a higher size will be announced and need to be fetched
 	// by the peer.
 	txMaxBroadcastSize = 4096
+
+	// txAnnounceSize is the approximate number of bytes a transaction announcement
+	// takes on the wire, charged against the upload budget.
+	txAnnounceSize = common.HashLength + 5
+
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+
+	// blockRangeInterval is the number of blocks after which the servable block
+	// range is re-announced to eth/69 peers.
+	blockRangeInterval = 32
 )
 
 var syncChallengeTimeout = 15 * time.Second // Time allowance for a node to reply to the sync progress challenge
@@ -93,6 +105,8 @@ type handlerConfig struct {
 	BloomCache     uint64                 // Megabytes to alloc for snap sync bloom
 	EventMux       *event.TypeMux         // Legacy event mux, deprecate for `feed`
 	RequiredBlocks map[uint64]common.Hash // Hard coded map of required block hashes for sync challenges
+	TxUploadCap    uint64                 // Upload bandwidth cap for transaction propagation in bytes/s (0 = unlimited)
+	TxDownloadCap  uint64                 // Download bandwidth cap for transaction retrieval in bytes/s (0 = unlimited)
 }
 
 type handler struct {
@@ -110,6 +124,7 @@ type handler struct {
 	downloader   *downloader.Downloader
 	blockFetcher *fetcher.BlockFetcher
 	txFetcher    *fetcher.TxFetcher
+	txBudget     *eth.TxBudget // Bandwidth budget for transaction exchange (nil = unlimited)
 	peers        *peerSet
 	merger       *consensus.Merger
 
@@ -117,6 +132,8 @@ type handler struct {
 	txsCh         chan core.NewTxsEvent
 	txsSub        event.Subscription
 	minedBlockSub *event.TypeMuxSubscription
+	chainHeadCh   chan core.ChainHeadEvent
+	chainHeadSub  event.Subscription
 
 	requiredBlocks map[uint64]common.Hash
 
@@ -265,7 +282,11 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return h.chain.InsertChain(blocks)
 	}
-	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.removePeer)
+	dropBadBlockPeer := func(id string) {
+		h.reportPeer(id, p2p.InvalidBlock)
+		h.removePeer(id)
+	}
+	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, dropBadBlockPeer)
 
 	fetchTx := func(peer string, hashes []common.Hash) error {
 		p := h.peers.peer(peer)
@@ -278,6 +299,10 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		return h.txpool.Add(txs, false, false)
 	}
 	h.txFetcher = fetcher.NewTxFetcher(h.txpool.Has, addTxs, fetchTx, h.removePeer)
+	if config.TxUploadCap != 0 || config.TxDownloadCap != 0 {
+		h.txBudget = eth.NewTxBudget(config.TxUploadCap, config.TxDownloadCap)
+		h.txFetcher.SetBudget(h.txBudget)
+	}
 	h.chainSync = newChainSyncer(h)
 	return h, nil
 }
@@ -343,7 +368,12 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 		td      = h.chain.GetTd(hash, number)
 	)
 	forkID := forkid.NewID(h.chain.Config(), genesis, number, head.Time)
-	if err := peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter); err != nil {
+	if peer.Version() >= eth.ETH69 {
+		err = peer.Handshake69(h.networkID, genesis.Hash(), forkID, h.forkFilter, h.blockRange(head))
+	} else {
+		err = peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter)
+	}
+	if err != nil {
 		peer.Log().Debug("Ethereum handshake failed", "err", err)
 		return err
 	}
@@ -476,6 +506,14 @@ func (h *handler) removePeer(id string) {
 	}
 }
 
+// reportPeer forwards an observation about a peer to its reputation tracker.
+func (h *handler) reportPeer(id string, ev p2p.ReputationEvent) {
+	peer := h.peers.peer(id)
+	if peer != nil {
+		peer.Peer.Report(ev)
+	}
+}
+
 // unregisterPeer removes a peer from the downloader, fetchers and main peer set.
 func (h *handler) unregisterPeer(id string) {
 	// Create a custom logger to avoid printing the entire id
@@ -521,6 +559,12 @@ func (h *handler) Start(maxPeers int) {
 	h.minedBlockSub = h.eventMux.Subscribe(core.NewMinedBlockEvent{})
 	go h.minedBroadcastLoop()
 
+	// announce the servable block range to eth/69 peers
+	h.wg.Add(1)
+	h.chainHeadCh = make(chan core.ChainHeadEvent, chainHeadChanSize)
+	h.chainHeadSub = h.chain.SubscribeChainHeadEvent(h.chainHeadCh)
+	go h.blockRangeLoop()
+
 	// start sync handlers
 	h.wg.Add(1)
 	go h.chainSync.loop()
@@ -533,6 +577,7 @@ func (h *handler) Start(maxPeers int) {
 func (h *handler) Stop() {
 	h.txsSub.Unsubscribe()        // quits txBroadcastLoop
 	h.minedBlockSub.Unsubscribe() // quits blockBroadcastLoop
+	h.chainHeadSub.Unsubscribe()  // quits blockRangeLoop
 
 	// Quit chainSync and txsync64.
 	// After this is done, no new peers will be accepted.
@@ -596,10 +641,23 @@ func (h *handler) BroadcastBlock(block *types.Block, propagate bool) {
 // - To a square root of all peers for non-blob transactions
 // - And, separately, as announcements to all peers which are not known to
 // already have the given transaction.
+//
+// If an upload bandwidth cap is configured, transactions are propagated in the
+// order of their effective tip, and the ones not fitting into the budget are
+// only announced. Direct sends also go to the peers with the highest measured
+// throughput first.
 func (h *handler) BroadcastTransactions(txs types.Transactions) {
+	if h.txBudget.LimitedUpload() {
+		baseFee := h.chain.CurrentBlock().BaseFee
+		txs = append(types.Transactions(nil), txs...)
+		sort.SliceStable(txs, func(i, j int) bool {
+			return txs[i].EffectiveGasTipCmp(txs[j], baseFee) > 0
+		})
+	}
 	var (
-		blobTxs  int // Number of blob transactions to announce only
-		largeTxs int // Number of large transactions to announce only
+		blobTxs     int // Number of blob transactions to announce only
+		largeTxs    int // Number of large transactions to announce only
+		budgetedTxs int // Number of transactions announced only due to the upload cap
 
 		directCount int // Number of transactions sent directly to peers (duplicates included)
 		directPeers int // Number of peers that were sent transactions directly
@@ -622,7 +680,20 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		default:
 			numDirect = int(math.Sqrt(float64(len(peers))))
 		}
-		// Send the tx unconditionally to a subset of our peers
+		if numDirect > 0 && h.txBudget.LimitedUpload() {
+			sort.SliceStable(peers, func(i, j int) bool {
+				return peers[i].TxThroughput() > peers[j].TxThroughput()
+			})
+			if !h.txBudget.ReserveUpload(tx.Size() * uint64(numDirect)) {
+				budgetedTxs++
+				numDirect = 0
+			}
+		}
+		// Announcements are cheap and needed for the transaction to propagate at
+		// all, so they are sent even if the budget is exhausted.
+		h.txBudget.ChargeUpload(uint64(txAnnounceSize * (len(peers) - numDirect)))
+
+		// Send the tx to a subset of our peers
 		for _, peer := range peers[:numDirect] {
 			txset[peer] = append(txset[peer], tx.Hash())
 		}
@@ -641,7 +712,7 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		annCount += len(hashes)
 		peer.AsyncSendPooledTransactionHashes(hashes)
 	}
-	log.Debug("Distributed transactions", "plaintxs", len(txs)-blobTxs-largeTxs, "blobtxs", blobTxs, "largetxs", largeTxs,
+	log.Debug("Distributed transactions", "plaintxs", len(txs)-blobTxs-largeTxs, "blobtxs", blobTxs, "largetxs", largeTxs, "budgetedtxs", budgetedTxs,
 		"bcastpeers", directPeers, "bcastcount", directCount, "annpeers", annPeers, "anncount", annCount)
 }
 
@@ -670,6 +741,54 @@ func (h *handler) txBroadcastLoop() {
 	}
 }
 
+// blockRange returns the range of blocks the local node is able to serve, with
+// the given header as the latest one.
+func (h *handler) blockRange(head *types.Header) eth.BlockRangeUpdatePacket {
+	return eth.BlockRangeUpdatePacket{
+		EarliestBlock:   h.historyTail(),
+		LatestBlock:     head.Number.Uint64(),
+		LatestBlockHash: head.Hash(),
+	}
+}
+
+// historyTail returns the number of the first block whose body and receipts are
+// still available locally, i.e. the tail of the chain freezer.
+func (h *handler) historyTail() uint64 {
+	tail, err := h.database.Tail()
+	if err != nil {
+		// No freezer attached, the entire history is in the key-value store
+		return 0
+	}
+	return tail
+}
+
+// blockRangeLoop announces the servable block range to connected peers whenever
+// the chain head progressed by blockRangeInterval blocks since the last update.
+func (h *handler) blockRangeLoop() {
+	defer h.wg.Done()
+
+	var last uint64
+	for {
+		select {
+		case ev := <-h.chainHeadCh:
+			head := ev.Block.Header()
+			if number := head.Number.Uint64(); number >= last && number-last < blockRangeInterval {
+				continue
+			}
+			last = head.Number.Uint64()
+
+			// Queue the update on the broadcast loop of each peer, so slow peers
+			// can't hold up the chain head feed.
+			update := h.blockRange(head)
+			for _, peer := range h.peers.all() {
+				peer.AsyncSendBlockRangeUpdate(update)
+			}
+		case <-h.chainHeadSub.Err():
+			return
+		}
+	}
+}
+
 // enableSyncedFeatures enables the post-sync functionalities when the initial
 // sync is finished.
 func (h *handler) enableSyncedFeatures() {
@@ -25,7 +25,9 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/fetcher"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 )
 
@@ -55,6 +57,12 @@ func (h *ethHandler) AcceptTxs() bool {
 	return h.synced.Load()
 }
 
+// TxBudget retrieves the bandwidth budget of transaction exchange, nil if
+// unlimited.
+func (h *ethHandler) TxBudget() *eth.TxBudget {
+	return h.txBudget
+}
+
 // Handle is invoked from a peer's message handler when it receives a new remote
 // message that the handler couldn't consume and serve itself.
 func (h *ethHandler) Handle(peer *eth.Peer, packet eth.Packet) error {
@@ -79,16 +87,27 @@ func (h *ethHandler) Handle(peer *eth.Peer, packet eth.Packet) error {
 				return errors.New("disallowed broadcast blob transaction")
 			}
 		}
-		return h.txFetcher.Enqueue(peer.ID(), *packet, false)
+		return h.enqueueTxs(peer, *packet, false)
 
 	case *eth.PooledTransactionsResponse:
-		return h.txFetcher.Enqueue(peer.ID(), *packet, true)
+		return h.enqueueTxs(peer, *packet, true)
 
 	default:
 		return fmt.Errorf("unexpected eth packet type: %T", packet)
 	}
 }
 
+// enqueueTxs hands received transactions over to the fetcher, lowering the
+// reputation of peers delivering too many transactions rejected by the pool.
+func (h *ethHandler) enqueueTxs(peer *eth.Peer, txs []*types.Transaction, direct bool) error {
+	err := h.txFetcher.Enqueue(peer.ID(), txs, direct)
+	if errors.Is(err, fetcher.ErrTxSpam) {
+		peer.Report(p2p.TxSpam)
+		return nil
+	}
+	return err
+}
+
 // handleBlockAnnounces is invoked from a peer's message handler when it transmits a
 // batch of block announcements for the local node to process.
 func (h *ethHandler) handleBlockAnnounces(peer *eth.Peer, hashes []common.Hash, numbers []uint64) error {
@@ -49,6 +49,7 @@ type testEthHandler struct {
 func (h *testEthHandler) Chain() *core.BlockChain              { panic("no backing chain") }
 func (h *testEthHandler) TxPool() eth.TxPool                   { panic("no backing tx pool") }
 func (h *testEthHandler) AcceptTxs() bool                      { return true }
+func (h *testEthHandler) TxBudget() *eth.TxBudget              { return nil }
 func (h *testEthHandler) RunPeer(*eth.Peer, eth.Handler) error { panic("not used in tests") }
 func (h *testEthHandler) PeerInfo(enode.ID) interface{}        { panic("not used in tests") }
 
@@ -377,6 +378,90 @@ func testSendTransactions(t *testing.T, protocol uint) {
 	}
 }
 
+// Tests that with an upload cap, the transactions paying the highest tips are
+// broadcast directly and the ones not fitting into the budget are only announced.
+func TestSendTransactionsBudget(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler()
+	defer handler.close()
+
+	handler.handler.txBudget = eth.NewTxBudget(1000, 0)
+
+	// Create a source handler to send messages through and a sink peer to receive them
+	p2pSrc, p2pSink := p2p.MsgPipe()
+	defer p2pSrc.Close()
+	defer p2pSink.Close()
+
+	src := eth.NewPeer(eth.ETH68, p2p.NewPeerPipe(enode.ID{1}, "", nil, p2pSrc), p2pSrc, handler.txpool)
+	sink := eth.NewPeer(eth.ETH68, p2p.NewPeerPipe(enode.ID{2}, "", nil, p2pSink), p2pSink, handler.txpool)
+	defer src.Close()
+	defer sink.Close()
+
+	go handler.handler.runEthPeer(src, func(peer *eth.Peer) error {
+		return eth.Handle((*ethHandler)(handler.handler), peer)
+	})
+	var (
+		genesis = handler.chain.Genesis()
+		head    = handler.chain.CurrentBlock()
+		td      = handler.chain.GetTd(head.Hash(), head.Number.Uint64())
+	)
+	if err := sink.Handshake(1, td, head.Hash(), genesis.Hash(), forkid.NewIDWithChain(handler.chain), forkid.NewFilter(handler.chain)); err != nil {
+		t.Fatalf("failed to run protocol handshake")
+	}
+	backend := new(testEthHandler)
+
+	anns := make(chan []common.Hash)
+	annSub := backend.txAnnounces.Subscribe(anns)
+	defer annSub.Unsubscribe()
+
+	bcasts := make(chan []*types.Transaction)
+	bcastSub := backend.txBroadcasts.Subscribe(bcasts)
+	defer bcastSub.Unsubscribe()
+
+	go eth.Handle(backend, sink)
+
+	for handler.handler.peers.len() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Add transactions of ~600 bytes each, only two of which fit into the budget.
+	var (
+		tips   = []int64{2, 5, 1, 4}
+		insert = make([]*types.Transaction, len(tips))
+		direct = make(map[common.Hash]bool)
+	)
+	for i, tip := range tips {
+		price := new(big.Int).Add(head.BaseFee, big.NewInt(tip))
+		tx := types.NewTransaction(uint64(i), common.Address{}, big.NewInt(0), 100000, price, make([]byte, 500))
+		tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+		insert[i] = tx
+		direct[tx.Hash()] = tip >= 4
+	}
+	go handler.txpool.Add(insert, false, false)
+
+	seen := make(map[common.Hash]struct{})
+	for len(seen) < len(insert) {
+		select {
+		case hashes := <-anns:
+			for _, hash := range hashes {
+				if direct[hash] {
+					t.Errorf("high tip transaction announced: %x", hash)
+				}
+				seen[hash] = struct{}{}
+			}
+		case txs := <-bcasts:
+			for _, tx := range txs {
+				if !direct[tx.Hash()] {
+					t.Errorf("low tip transaction broadcast: %x", tx.Hash())
+				}
+				seen[tx.Hash()] = struct{}{}
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("transaction propagation timed out: have %d, want %d", len(seen), len(insert))
+		}
+	}
+}
+
 // Tests that transactions get propagated to all attached peers, either via direct
 // broadcasts or via announcements/retrievals.
 func TestTransactionPropagation67(t *testing.T) { testTransactionPropagation(t, eth.ETH67) }
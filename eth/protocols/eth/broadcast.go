@@ -18,6 +18,7 @@ package eth
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -36,8 +37,8 @@ type blockPropagation struct {
 	td    *big.Int
 }
 
-// broadcastBlocks is a write loop that multiplexes blocks and block announcements
-// to the remote peer. The goal is to have an async writer that does not lock up
+// broadcastBlocks is a write loop that multiplexes blocks, block announcements
+// and block range updates to the remote peer. The goal is to have an async writer that does not lock up
 // node internals and at the same time rate limits queued data.
 func (p *Peer) broadcastBlocks() {
 	for {
@@ -54,6 +55,12 @@ func (p *Peer) broadcastBlocks() {
 			}
 			p.Log().Trace("Announced block", "number", block.Number(), "hash", block.Hash())
 
+		case update := <-p.queuedBlockRange:
+			if err := p.SendBlockRangeUpdate(*update); err != nil {
+				return
+			}
+			p.Log().Trace("Announced block range", "earliest", update.EarliestBlock, "latest", update.LatestBlock)
+
 		case <-p.term:
 			return
 		}
@@ -92,10 +99,14 @@ func (p *Peer) broadcastTransactions() {
 			if len(txs) > 0 {
 				done = make(chan struct{})
 				go func() {
+					// Measure how fast the peer takes the data off the wire,
+					// so that propagation can favour fast links.
+					start := time.Now()
 					if err := p.SendTransactions(txs); err != nil {
 						fail <- err
 						return
 					}
+					p.txRate.Update(TransactionsMsg, time.Since(start), int(size))
 					close(done)
 					p.Log().Trace("Sent transactions", "count", len(txs))
 				}()
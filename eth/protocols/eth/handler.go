@@ -68,6 +68,10 @@ type Backend interface {
 	// or if inbound transactions should simply be dropped.
 	AcceptTxs() bool
 
+	// TxBudget retrieves the bandwidth budget that replies to transaction
+	// requests are charged against, nil if unlimited.
+	TxBudget() *TxBudget
+
 	// RunPeer is invoked when a peer joins on the `eth` protocol. The handler
 	// should do any peer maintenance work, handshakes and validations. If all
 	// is passed, control should be given back to the `handler` to process the
@@ -97,6 +101,11 @@ func MakeProtocols(backend Backend, network uint64, dnsdisc enode.Iterator) []p2
 		if version <= ETH67 && backend.Chain().Config().CancunTime != nil {
 			continue
 		}
+		// Dropping total difficulty from the handshake is only safe post-merge,
+		// disable eth/69 on networks still relying on it for synchronisation
+		if version >= ETH69 && !backend.Chain().Config().TerminalTotalDifficultyPassed {
+			continue
+		}
 		version := version // Closure
 
 		protocols = append(protocols, p2p.Protocol{
@@ -196,6 +205,22 @@ var eth68 = map[uint64]msgHandler{
 	PooledTransactionsMsg:         handlePooledTransactions,
 }
 
+var eth69 = map[uint64]msgHandler{
+	NewBlockHashesMsg:             handleNewBlockhashes,
+	NewBlockMsg:                   handleNewBlock,
+	TransactionsMsg:               handleTransactions,
+	NewPooledTransactionHashesMsg: handleNewPooledTransactionHashes68,
+	GetBlockHeadersMsg:            handleGetBlockHeaders,
+	BlockHeadersMsg:               handleBlockHeaders,
+	GetBlockBodiesMsg:             handleGetBlockBodies,
+	BlockBodiesMsg:                handleBlockBodies,
+	GetReceiptsMsg:                handleGetReceipts69,
+	ReceiptsMsg:                   handleReceipts69,
+	GetPooledTransactionsMsg:      handleGetPooledTransactions,
+	PooledTransactionsMsg:         handlePooledTransactions,
+	BlockRangeUpdateMsg:           handleBlockRangeUpdate,
+}
+
 // handleMessage is invoked whenever an inbound message is received from a remote
 // peer. The remote connection is torn down upon returning any error.
 func handleMessage(backend Backend, peer *Peer) error {
@@ -210,7 +235,9 @@ func handleMessage(backend Backend, peer *Peer) error {
 	defer msg.Discard()
 
 	var handlers = eth67
-	if peer.Version() >= ETH68 {
+	if peer.Version() >= ETH69 {
+		handlers = eth69
+	} else if peer.Version() >= ETH68 {
 		handlers = eth68
 	}
 	// Track the amount of time it takes to serve the request and run the handler
@@ -17,10 +17,13 @@
 package eth
 
 import (
+	"bytes"
+	"errors"
 	"math"
 	"math/big"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
@@ -37,6 +40,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 var (
@@ -56,6 +60,7 @@ type testBackend struct {
 	db     ethdb.Database
 	chain  *core.BlockChain
 	txpool *txpool.TxPool
+	budget *TxBudget
 }
 
 // newTestBackend creates an empty chain and wraps it into a mock backend.
@@ -134,6 +139,7 @@ func (b *testBackend) close() {
 
 func (b *testBackend) Chain() *core.BlockChain { return b.chain }
 func (b *testBackend) TxPool() TxPool          { return b.txpool }
+func (b *testBackend) TxBudget() *TxBudget     { return b.budget }
 
 func (b *testBackend) RunPeer(peer *Peer, handler Handler) error {
 	// Normally the backend would do peer maintenance and handshakes. All that
@@ -152,6 +158,7 @@ func (b *testBackend) Handle(*Peer, Packet) error {
 // Tests that block headers can be retrieved from a remote chain based on user queries.
 func TestGetBlockHeaders67(t *testing.T) { testGetBlockHeaders(t, ETH67) }
 func TestGetBlockHeaders68(t *testing.T) { testGetBlockHeaders(t, ETH68) }
+func TestGetBlockHeaders69(t *testing.T) { testGetBlockHeaders(t, ETH69) }
 
 func testGetBlockHeaders(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -338,6 +345,7 @@ func testGetBlockHeaders(t *testing.T, protocol uint) {
 // Tests that block contents can be retrieved from a remote chain based on their hashes.
 func TestGetBlockBodies67(t *testing.T) { testGetBlockBodies(t, ETH67) }
 func TestGetBlockBodies68(t *testing.T) { testGetBlockBodies(t, ETH68) }
+func TestGetBlockBodies69(t *testing.T) { testGetBlockBodies(t, ETH69) }
 
 func testGetBlockBodies(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -433,6 +441,7 @@ func testGetBlockBodies(t *testing.T, protocol uint) {
 // Tests that the transaction receipts can be retrieved based on hashes.
 func TestGetBlockReceipts67(t *testing.T) { testGetBlockReceipts(t, ETH67) }
 func TestGetBlockReceipts68(t *testing.T) { testGetBlockReceipts(t, ETH68) }
+func TestGetBlockReceipts69(t *testing.T) { testGetBlockReceipts(t, ETH69) }
 
 func testGetBlockReceipts(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -495,6 +504,23 @@ func testGetBlockReceipts(t *testing.T, protocol uint) {
 		RequestId:          123,
 		GetReceiptsRequest: hashes,
 	})
+	if protocol >= ETH69 {
+		// Newer protocol versions send receipts without the bloom filters
+		network := make(ReceiptsResponse69, len(receipts))
+		for i, block := range receipts {
+			network[i] = make([]*Receipt69, len(block))
+			for j, receipt := range block {
+				network[i][j] = newReceipt69(receipt)
+			}
+		}
+		if err := p2p.ExpectMsg(peer.app, ReceiptsMsg, &ReceiptsPacket69{
+			RequestId:          123,
+			ReceiptsResponse69: network,
+		}); err != nil {
+			t.Errorf("receipts mismatch: %v", err)
+		}
+		return
+	}
 	if err := p2p.ExpectMsg(peer.app, ReceiptsMsg, &ReceiptsPacket{
 		RequestId:        123,
 		ReceiptsResponse: receipts,
@@ -502,3 +528,154 @@ func testGetBlockReceipts(t *testing.T, protocol uint) {
 		t.Errorf("receipts mismatch: %v", err)
 	}
 }
+
+// Tests that eth/69 receipts can be converted back into their consensus form,
+// regenerating the bloom filters dropped from the network encoding.
+func TestReceipt69Unpack(t *testing.T) {
+	receipt := &types.Receipt{
+		Type:              types.DynamicFeeTxType,
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		Logs: []*types.Log{{
+			Address: common.Address{0x11},
+			Topics:  []common.Hash{{0x22}},
+			Data:    []byte{0x33},
+		}},
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+	blob, err := rlp.EncodeToBytes(ReceiptsResponse69{{newReceipt69(receipt)}})
+	if err != nil {
+		t.Fatalf("failed to encode receipts: %v", err)
+	}
+	var network ReceiptsResponse69
+	if err := rlp.DecodeBytes(blob, &network); err != nil {
+		t.Fatalf("failed to decode receipts: %v", err)
+	}
+	unpacked, err := network.Unpack()
+	if err != nil {
+		t.Fatalf("failed to unpack receipts: %v", err)
+	}
+	have, _ := rlp.EncodeToBytes(unpacked)
+	want, _ := rlp.EncodeToBytes(ReceiptsResponse{{receipt}})
+	if !bytes.Equal(have, want) {
+		t.Errorf("receipt mismatch: have %x, want %x", have, want)
+	}
+}
+
+// Tests that block range announcements are tracked and invalid ones rejected.
+func TestBlockRangeUpdate69(t *testing.T) {
+	t.Parallel()
+
+	backend := newTestBackend(3)
+	defer backend.close()
+
+	peer, errc := newTestPeer("peer", ETH69, backend)
+	defer peer.close()
+
+	update := BlockRangeUpdatePacket{EarliestBlock: 0, LatestBlock: 10, LatestBlockHash: common.Hash{0x01}}
+	p2p.Send(peer.app, BlockRangeUpdateMsg, &update)
+
+	// Range updates are not replied to, wait until the peer processed it
+	for start := time.Now(); peer.BlockRange() == nil; {
+		if time.Since(start) > time.Second {
+			t.Fatalf("block range not updated")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if have := peer.BlockRange(); *have != update {
+		t.Fatalf("block range mismatch: have %v, want %v", have, update)
+	}
+	// An inverted range should drop the peer
+	p2p.Send(peer.app, BlockRangeUpdateMsg, &BlockRangeUpdatePacket{EarliestBlock: 11, LatestBlock: 10})
+	select {
+	case err := <-errc:
+		if !errors.Is(err, errInvalidBlockRange) {
+			t.Fatalf("wrong error: have %v, want %v", err, errInvalidBlockRange)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("peer not dropped on invalid block range")
+	}
+}
+
+// Tests that queued block range announcements don't block the caller and that
+// stale ones are superseded by newer updates.
+func TestAsyncBlockRangeUpdate69(t *testing.T) {
+	t.Parallel()
+
+	backend := newTestBackend(3)
+	defer backend.close()
+
+	peer, _ := newTestPeer("peer", ETH69, backend)
+	defer peer.close()
+
+	// Message pipes are unbuffered, so at most one update is in flight while the
+	// rest are queued.
+	for i := uint64(1); i <= 5; i++ {
+		peer.AsyncSendBlockRangeUpdate(BlockRangeUpdatePacket{LatestBlock: i})
+	}
+	for n := 1; ; n++ {
+		msg, err := peer.app.ReadMsg()
+		if err != nil {
+			t.Fatalf("failed to read update: %v", err)
+		}
+		if msg.Code != BlockRangeUpdateMsg {
+			t.Fatalf("unexpected message: %v", msg.Code)
+		}
+		var update BlockRangeUpdatePacket
+		if err := msg.Decode(&update); err != nil {
+			t.Fatalf("failed to decode update: %v", err)
+		}
+		if update.LatestBlock == 5 {
+			break
+		}
+		if n == 2 {
+			t.Fatalf("stale update announced: %v", update)
+		}
+	}
+}
+
+// Tests that replies to pooled transaction requests are charged against the
+// upload budget and trimmed once it's exhausted.
+func TestGetPooledTransactionsBudget(t *testing.T) {
+	t.Parallel()
+
+	backend := newTestBackend(0)
+	defer backend.close()
+
+	// Add transactions of ~600 bytes each, only two of which fit into the budget.
+	var (
+		signer = types.LatestSigner(backend.chain.Config())
+		hashes []common.Hash
+	)
+	for nonce := uint64(0); nonce < 4; nonce++ {
+		tx := types.MustSignNewTx(testKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &common.Address{0x01},
+			Gas:      50000,
+			GasPrice: big.NewInt(10 * params.GWei),
+			Data:     make([]byte, 500),
+		})
+		if errs := backend.txpool.Add([]*types.Transaction{tx}, true, true); errs[0] != nil {
+			t.Fatalf("failed to add transaction %d: %v", nonce, errs[0])
+		}
+		hashes = append(hashes, tx.Hash())
+	}
+	if have, _ := answerGetPooledTransactions(backend, hashes); len(have) != len(hashes) {
+		t.Fatalf("unlimited reply trimmed: have %d, want %d", len(have), len(hashes))
+	}
+	backend.budget = NewTxBudget(1000, 0)
+	have, txs := answerGetPooledTransactions(backend, hashes)
+	if len(have) != 2 || len(txs) != 2 {
+		t.Fatalf("budgeted reply size mismatch: have %d, want %d", len(have), 2)
+	}
+	for i, hash := range have {
+		if hash != hashes[i] {
+			t.Errorf("transaction %d mismatch: have %x, want %x", i, hash, hashes[i])
+		}
+	}
+	// Nothing is served until the budget is replenished.
+	if have, _ := answerGetPooledTransactions(backend, hashes); len(have) != 0 {
+		t.Fatalf("reply served on exhausted budget: %d transactions", len(have))
+	}
+}
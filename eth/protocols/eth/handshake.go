@@ -37,23 +37,75 @@ const (
 // Handshake executes the eth protocol handshake, negotiating version number,
 // network IDs, difficulties, head and genesis blocks.
 func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter) error {
+	if p.version >= ETH69 {
+		return fmt.Errorf("%w: legacy handshake on eth/%d", errProtocolVersionMismatch, p.version)
+	}
+	var status StatusPacket // safe to read after the exchange succeeded
+
+	err := p.exchangeStatus(&StatusPacket{
+		ProtocolVersion: uint32(p.version),
+		NetworkID:       network,
+		TD:              td,
+		Head:            head,
+		Genesis:         genesis,
+		ForkID:          forkID,
+	}, func() error {
+		return p.readStatus(network, &status, genesis, forkFilter)
+	})
+	if err != nil {
+		return err
+	}
+	p.td, p.head = status.TD, status.Head
+
+	// TD at mainnet block #7753254 is 76 bits. If it becomes 100 million times
+	// larger, it will still fit within 100 bits
+	if tdlen := p.td.BitLen(); tdlen > 100 {
+		return fmt.Errorf("too large total difficulty: bitlen %d", tdlen)
+	}
+	return nil
+}
+
+// Handshake69 executes the eth/69 protocol handshake, negotiating version number,
+// network IDs, genesis blocks and exchanging the ranges of servable blocks.
+func (p *Peer) Handshake69(network uint64, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter, served BlockRangeUpdatePacket) error {
+	var status StatusPacket69 // safe to read after the exchange succeeded
+
+	err := p.exchangeStatus(&StatusPacket69{
+		ProtocolVersion: uint32(p.version),
+		NetworkID:       network,
+		Genesis:         genesis,
+		ForkID:          forkID,
+		EarliestBlock:   served.EarliestBlock,
+		LatestBlock:     served.LatestBlock,
+		LatestBlockHash: served.LatestBlockHash,
+	}, func() error {
+		return p.readStatus69(network, &status, genesis, forkFilter)
+	})
+	if err != nil {
+		return err
+	}
+	// Total difficulty is meaningless post-merge, track it as zero to keep the
+	// legacy sync mechanisms from ever picking the peer.
+	p.td = new(big.Int)
+	p.setBlockRange(&BlockRangeUpdatePacket{
+		EarliestBlock:   status.EarliestBlock,
+		LatestBlock:     status.LatestBlock,
+		LatestBlockHash: status.LatestBlockHash,
+	})
+	return nil
+}
+
+// exchangeStatus sends the local status message and concurrently runs the given
+// reader for the remote one, waiting for both to complete or time out.
+func (p *Peer) exchangeStatus(status Packet, read func() error) error {
 	// Send out own handshake in a new thread
 	errc := make(chan error, 2)
 
-	var status StatusPacket // safe to read after two values have been received from errc
-
 	go func() {
-		errc <- p2p.Send(p.rw, StatusMsg, &StatusPacket{
-			ProtocolVersion: uint32(p.version),
-			NetworkID:       network,
-			TD:              td,
-			Head:            head,
-			Genesis:         genesis,
-			ForkID:          forkID,
-		})
+		errc <- p2p.Send(p.rw, StatusMsg, status)
 	}()
 	go func() {
-		errc <- p.readStatus(network, &status, genesis, forkFilter)
+		errc <- read()
 	}()
 	timeout := time.NewTimer(handshakeTimeout)
 	defer timeout.Stop()
@@ -69,13 +121,6 @@ func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis
 			return p2p.DiscReadTimeout
 		}
 	}
-	p.td, p.head = status.TD, status.Head
-
-	// TD at mainnet block #7753254 is 76 bits. If it becomes 100 million times
-	// larger, it will still fit within 100 bits
-	if tdlen := p.td.BitLen(); tdlen > 100 {
-		return fmt.Errorf("too large total difficulty: bitlen %d", tdlen)
-	}
 	return nil
 }
 
@@ -110,6 +155,42 @@ func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.H
 	return nil
 }
 
+// readStatus69 reads the remote eth/69 handshake message.
+func (p *Peer) readStatus69(network uint64, status *StatusPacket69, genesis common.Hash, forkFilter forkid.Filter) error {
+	msg, err := p.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Code != StatusMsg {
+		return fmt.Errorf("%w: first msg has code %x (!= %x)", errNoStatusMsg, msg.Code, StatusMsg)
+	}
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	// Decode the handshake and make sure everything matches
+	if err := msg.Decode(&status); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	if status.NetworkID != network {
+		return fmt.Errorf("%w: %d (!= %d)", errNetworkIDMismatch, status.NetworkID, network)
+	}
+	if uint(status.ProtocolVersion) != p.version {
+		return fmt.Errorf("%w: %d (!= %d)", errProtocolVersionMismatch, status.ProtocolVersion, p.version)
+	}
+	if status.Genesis != genesis {
+		return fmt.Errorf("%w: %x (!= %x)", errGenesisMismatch, status.Genesis, genesis)
+	}
+	if err := forkFilter(status.ForkID); err != nil {
+		return fmt.Errorf("%w: %v", errForkIDRejected, err)
+	}
+	served := BlockRangeUpdatePacket{
+		EarliestBlock:   status.EarliestBlock,
+		LatestBlock:     status.LatestBlock,
+		LatestBlockHash: status.LatestBlockHash,
+	}
+	return served.sanityCheck()
+}
+
 // markError registers the error with the corresponding metric.
 func markError(p *Peer, err error) {
 	if !metrics.Enabled {
@@ -18,6 +18,7 @@ package eth
 
 import (
 	"errors"
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -89,3 +90,104 @@ func testHandshake(t *testing.T, protocol uint) {
 		}
 	}
 }
+
+// Tests that eth/69 handshake failures are detected and reported correctly, and
+// that the served block range of the remote peer is tracked on success.
+func TestHandshake69(t *testing.T) {
+	t.Parallel()
+
+	// Create a test backend only to have some valid genesis chain
+	backend := newTestBackend(3)
+	defer backend.close()
+
+	var (
+		genesis = backend.chain.Genesis()
+		head    = backend.chain.CurrentBlock()
+		forkID  = forkid.NewID(backend.chain.Config(), backend.chain.Genesis(), backend.chain.CurrentHeader().Number.Uint64(), backend.chain.CurrentHeader().Time)
+		served  = BlockRangeUpdatePacket{EarliestBlock: 0, LatestBlock: head.Number.Uint64(), LatestBlockHash: head.Hash()}
+	)
+	tests := []struct {
+		code uint64
+		data interface{}
+		want error
+	}{
+		{
+			code: TransactionsMsg, data: []interface{}{},
+			want: errNoStatusMsg,
+		},
+		{
+			code: StatusMsg, data: StatusPacket69{ETH68, 1, genesis.Hash(), forkID, 0, served.LatestBlock, served.LatestBlockHash},
+			want: errProtocolVersionMismatch,
+		},
+		{
+			code: StatusMsg, data: StatusPacket69{ETH69, 999, genesis.Hash(), forkID, 0, served.LatestBlock, served.LatestBlockHash},
+			want: errNetworkIDMismatch,
+		},
+		{
+			code: StatusMsg, data: StatusPacket69{ETH69, 1, common.Hash{3}, forkID, 0, served.LatestBlock, served.LatestBlockHash},
+			want: errGenesisMismatch,
+		},
+		{
+			code: StatusMsg, data: StatusPacket69{ETH69, 1, genesis.Hash(), forkid.ID{Hash: [4]byte{0x00, 0x01, 0x02, 0x03}}, 0, served.LatestBlock, served.LatestBlockHash},
+			want: errForkIDRejected,
+		},
+		{
+			code: StatusMsg, data: StatusPacket69{ETH69, 1, genesis.Hash(), forkID, served.LatestBlock + 1, served.LatestBlock, served.LatestBlockHash},
+			want: errInvalidBlockRange,
+		},
+		{
+			code: StatusMsg, data: StatusPacket{ETH69, 1, big.NewInt(1), head.Hash(), genesis.Hash(), forkID},
+			want: errDecode,
+		},
+	}
+	for i, test := range tests {
+		// Create the two peers to shake with each other
+		app, net := p2p.MsgPipe()
+		defer app.Close()
+		defer net.Close()
+
+		peer := NewPeer(ETH69, p2p.NewPeer(enode.ID{}, "peer", nil), net, nil)
+		defer peer.Close()
+
+		// Send the junk test with one peer, check the handshake failure
+		go p2p.Send(app, test.code, test.data)
+
+		err := peer.Handshake69(1, genesis.Hash(), forkID, forkid.NewFilter(backend.chain), served)
+		if err == nil {
+			t.Errorf("test %d: protocol returned nil error, want %q", i, test.want)
+		} else if !errors.Is(err, test.want) {
+			t.Errorf("test %d: wrong error: got %q, want %q", i, err, test.want)
+		}
+	}
+	// Run a successful handshake between two peers and check the tracked ranges
+	app, net := p2p.MsgPipe()
+	defer app.Close()
+	defer net.Close()
+
+	local := NewPeer(ETH69, p2p.NewPeer(enode.ID{1}, "local", nil), net, nil)
+	defer local.Close()
+	remote := NewPeer(ETH69, p2p.NewPeer(enode.ID{2}, "remote", nil), app, nil)
+	defer remote.Close()
+
+	pruned := BlockRangeUpdatePacket{EarliestBlock: 1, LatestBlock: served.LatestBlock, LatestBlockHash: served.LatestBlockHash}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- remote.Handshake69(1, genesis.Hash(), forkID, forkid.NewFilter(backend.chain), pruned)
+	}()
+	if err := local.Handshake69(1, genesis.Hash(), forkID, forkid.NewFilter(backend.chain), served); err != nil {
+		t.Fatalf("local handshake failed: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("remote handshake failed: %v", err)
+	}
+	if have := local.BlockRange(); have == nil || *have != pruned {
+		t.Errorf("local peer range mismatch: have %v, want %v", have, pruned)
+	}
+	if have := remote.BlockRange(); have == nil || *have != served {
+		t.Errorf("remote peer range mismatch: have %v, want %v", have, served)
+	}
+	if hash, td := local.Head(); hash != head.Hash() || td.Sign() != 0 {
+		t.Errorf("local peer head mismatch: have %x/%v, want %x/0", hash, td, head.Hash())
+	}
+}
@@ -20,11 +20,13 @@ import (
 	"math/big"
 	"math/rand"
 	"sync"
+	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/msgrate"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -72,17 +74,20 @@ type Peer struct {
 	rw        p2p.MsgReadWriter // Input/output streams for snap
 	version   uint              // Protocol version negotiated
 
-	head common.Hash // Latest advertised head block hash
-	td   *big.Int    // Latest advertised head block total difficulty
+	head       common.Hash             // Latest advertised head block hash
+	td         *big.Int                // Latest advertised head block total difficulty
+	blockRange *BlockRangeUpdatePacket // Latest advertised range of servable blocks (eth/69+)
 
-	knownBlocks     *knownCache            // Set of block hashes known to be known by this peer
-	queuedBlocks    chan *blockPropagation // Queue of blocks to broadcast to the peer
-	queuedBlockAnns chan *types.Block      // Queue of blocks to announce to the peer
+	knownBlocks      *knownCache                  // Set of block hashes known to be known by this peer
+	queuedBlocks     chan *blockPropagation       // Queue of blocks to broadcast to the peer
+	queuedBlockAnns  chan *types.Block            // Queue of blocks to announce to the peer
+	queuedBlockRange chan *BlockRangeUpdatePacket // Latest block range to announce to the peer (eth/69+)
 
 	txpool      TxPool             // Transaction pool used by the broadcasters for liveness checks
 	knownTxs    *knownCache        // Set of transaction hashes known to be known by this peer
 	txBroadcast chan []common.Hash // Channel used to queue transaction propagation requests
 	txAnnounce  chan []common.Hash // Channel used to queue transaction announcement requests
+	txRate      *msgrate.Tracker   // Throughput of transaction broadcasts, in bytes
 
 	reqDispatch chan *request  // Dispatch channel to send requests and track then until fulfilment
 	reqCancel   chan *cancel   // Dispatch channel to cancel pending requests and untrack them
@@ -96,21 +101,23 @@ type Peer struct {
 // version.
 func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool) *Peer {
 	peer := &Peer{
-		id:              p.ID().String(),
-		Peer:            p,
-		rw:              rw,
-		version:         version,
-		knownTxs:        newKnownCache(maxKnownTxs),
-		knownBlocks:     newKnownCache(maxKnownBlocks),
-		queuedBlocks:    make(chan *blockPropagation, maxQueuedBlocks),
-		queuedBlockAnns: make(chan *types.Block, maxQueuedBlockAnns),
-		txBroadcast:     make(chan []common.Hash),
-		txAnnounce:      make(chan []common.Hash),
-		reqDispatch:     make(chan *request),
-		reqCancel:       make(chan *cancel),
-		resDispatch:     make(chan *response),
-		txpool:          txpool,
-		term:            make(chan struct{}),
+		id:               p.ID().String(),
+		Peer:             p,
+		rw:               rw,
+		version:          version,
+		knownTxs:         newKnownCache(maxKnownTxs),
+		knownBlocks:      newKnownCache(maxKnownBlocks),
+		queuedBlocks:     make(chan *blockPropagation, maxQueuedBlocks),
+		queuedBlockAnns:  make(chan *types.Block, maxQueuedBlockAnns),
+		queuedBlockRange: make(chan *BlockRangeUpdatePacket, 1),
+		txBroadcast:      make(chan []common.Hash),
+		txAnnounce:       make(chan []common.Hash),
+		txRate:           msgrate.NewTracker(nil, time.Second),
+		reqDispatch:      make(chan *request),
+		reqCancel:        make(chan *cancel),
+		resDispatch:      make(chan *response),
+		txpool:           txpool,
+		term:             make(chan struct{}),
 	}
 	// Start up all the broadcasters
 	go peer.broadcastBlocks()
@@ -133,6 +140,12 @@ func (p *Peer) ID() string {
 	return p.id
 }
 
+// TxThroughput returns the measured number of bytes per second which the peer
+// accepts transaction broadcasts with.
+func (p *Peer) TxThroughput() int {
+	return p.txRate.Capacity(TransactionsMsg, time.Second)
+}
+
 // Version retrieves the peer's negotiated `eth` protocol version.
 func (p *Peer) Version() uint {
 	return p.version
@@ -156,6 +169,29 @@ func (p *Peer) SetHead(hash common.Hash, td *big.Int) {
 	p.td.Set(td)
 }
 
+// BlockRange retrieves the latest range of blocks the peer advertised to be able
+// to serve, or nil if the peer's protocol version does not support announcing it.
+func (p *Peer) BlockRange() *BlockRangeUpdatePacket {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.blockRange == nil {
+		return nil
+	}
+	update := *p.blockRange
+	return &update
+}
+
+// setBlockRange updates the range of blocks the peer is able to serve, along
+// with its head hash.
+func (p *Peer) setBlockRange(update *BlockRangeUpdatePacket) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.blockRange = update
+	p.head = update.LatestBlockHash
+}
+
 // KnownBlock returns whether peer is known to already have a block.
 func (p *Peer) KnownBlock(hash common.Hash) bool {
 	return p.knownBlocks.Contains(hash)
@@ -297,6 +333,37 @@ func (p *Peer) SendNewBlock(block *types.Block, td *big.Int) error {
 	})
 }
 
+// SendBlockRangeUpdate announces the range of blocks the local node is able to
+// serve to the remote peer. Announcements are only supported on eth/69 and newer,
+// older peers are silently skipped.
+func (p *Peer) SendBlockRangeUpdate(update BlockRangeUpdatePacket) error {
+	if p.version < ETH69 {
+		return nil
+	}
+	return p2p.Send(p.rw, BlockRangeUpdateMsg, &update)
+}
+
+// AsyncSendBlockRangeUpdate queues the range of blocks the local node is able to
+// serve for announcement to the remote peer. If an older update is still queued,
+// it is replaced, as only the latest range is of interest to the peer.
+func (p *Peer) AsyncSendBlockRangeUpdate(update BlockRangeUpdatePacket) {
+	if p.version < ETH69 {
+		return
+	}
+	for {
+		select {
+		case p.queuedBlockRange <- &update:
+			return
+		default:
+		}
+		select {
+		case stale := <-p.queuedBlockRange:
+			p.Log().Trace("Replacing queued block range", "latest", stale.LatestBlock)
+		default:
+		}
+	}
+}
+
 // AsyncSendNewBlock queues an entire block for propagation to a remote peer. If
 // the peer's broadcast queue is full, the event is silently dropped.
 func (p *Peer) AsyncSendNewBlock(block *types.Block, td *big.Int) {
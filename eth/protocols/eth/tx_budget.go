@@ -0,0 +1,191 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// txBudgetBurst is the time interval worth of traffic that can be accumulated
+// in an idle budget and spent at once.
+const txBudgetBurst = time.Second
+
+var (
+	txBudgetUploadMeter     = metrics.NewRegisteredMeter("eth/fetcher/transaction/budget/upload", nil)
+	txBudgetDownloadMeter   = metrics.NewRegisteredMeter("eth/fetcher/transaction/budget/download", nil)
+	txBudgetUploadDenyMeter = metrics.NewRegisteredMeter("eth/fetcher/transaction/budget/upload/deny", nil)
+	txBudgetThrottleMeter   = metrics.NewRegisteredMeter("eth/fetcher/transaction/budget/download/throttle", nil)
+)
+
+// TxBudget is the bandwidth budget for transaction exchange with remote peers.
+// Transaction propagation and the replies to pooled transaction requests are
+// charged to the upload allowance, and retrievals to the download allowance.
+//
+// The allowances are token buckets measured in bytes. A reservation succeeds as
+// long as the bucket is not empty and may drive it into debt, so transactions
+// larger than the burst size can still be exchanged on a low cap. A nil budget,
+// or a zero cap, means unlimited bandwidth.
+type TxBudget struct {
+	upload   *txBucket
+	download *txBucket
+}
+
+// NewTxBudget creates a transaction bandwidth budget with the given upload and
+// download caps in bytes per second.
+func NewTxBudget(upload, download uint64) *TxBudget {
+	return NewTxBudgetForTests(upload, download, mclock.System{})
+}
+
+// NewTxBudgetForTests is a testing method to mock out the realtime clock with
+// a simulated version.
+func NewTxBudgetForTests(upload, download uint64, clock mclock.Clock) *TxBudget {
+	return &TxBudget{
+		upload:   newTxBucket(upload, clock),
+		download: newTxBucket(download, clock),
+	}
+}
+
+// ReserveUpload charges size bytes against the upload allowance. It reports
+// whether the transfer may proceed.
+func (b *TxBudget) ReserveUpload(size uint64) bool {
+	if b == nil {
+		return true
+	}
+	if !b.upload.reserve(size) {
+		txBudgetUploadDenyMeter.Mark(1)
+		return false
+	}
+	txBudgetUploadMeter.Mark(int64(size))
+	return true
+}
+
+// ChargeUpload charges size bytes against the upload allowance unconditionally.
+// It is used for traffic which must be sent regardless of the budget.
+func (b *TxBudget) ChargeUpload(size uint64) {
+	if b == nil {
+		return
+	}
+	b.upload.charge(size)
+	txBudgetUploadMeter.Mark(int64(size))
+}
+
+// LimitedUpload reports whether an upload cap is configured.
+func (b *TxBudget) LimitedUpload() bool {
+	return b != nil && b.upload != nil
+}
+
+// ReserveDownload charges size bytes against the download allowance. It reports
+// whether the retrieval may proceed.
+func (b *TxBudget) ReserveDownload(size uint64) bool {
+	if b == nil {
+		return true
+	}
+	if !b.download.reserve(size) {
+		txBudgetThrottleMeter.Mark(1)
+		return false
+	}
+	txBudgetDownloadMeter.Mark(int64(size))
+	return true
+}
+
+// DownloadDelay returns the time until the download allowance is replenished.
+func (b *TxBudget) DownloadDelay() time.Duration {
+	if b == nil {
+		return 0
+	}
+	return b.download.delay()
+}
+
+// txBucket is a token bucket refilling at a constant byte rate.
+type txBucket struct {
+	rate   float64 // Bytes added per second
+	burst  float64 // Maximum number of bytes stored
+	tokens float64 // Bytes available, negative if in debt
+	last   mclock.AbsTime
+	clock  mclock.Clock
+	lock   sync.Mutex
+}
+
+// newTxBucket creates a full bucket, or nil if the rate is unlimited.
+func newTxBucket(rate uint64, clock mclock.Clock) *txBucket {
+	if rate == 0 {
+		return nil
+	}
+	burst := float64(rate) * txBudgetBurst.Seconds()
+	return &txBucket{
+		rate:   float64(rate),
+		burst:  burst,
+		tokens: burst,
+		last:   clock.Now(),
+		clock:  clock,
+	}
+}
+
+// refill adds the tokens accumulated since the last update. The lock must be held.
+func (b *txBucket) refill() {
+	now := b.clock.Now()
+	b.tokens += b.rate * time.Duration(now-b.last).Seconds()
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+func (b *txBucket) reserve(size uint64) bool {
+	if b == nil {
+		return true
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refill()
+	if b.tokens <= 0 {
+		return false
+	}
+	b.tokens -= float64(size)
+	return true
+}
+
+func (b *txBucket) charge(size uint64) {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refill()
+	b.tokens -= float64(size)
+}
+
+func (b *txBucket) delay() time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refill()
+	if b.tokens > 0 {
+		return 0
+	}
+	// Wait until the debt is repaid and at least one byte is available.
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+func TestTxBudget(t *testing.T) {
+	var (
+		clock  = new(mclock.Simulated)
+		budget = NewTxBudgetForTests(1000, 0, clock)
+	)
+	if !budget.LimitedUpload() {
+		t.Fatal("upload not limited")
+	}
+	// The full burst may be spent at once, and a reservation can overshoot it.
+	if !budget.ReserveUpload(600) || !budget.ReserveUpload(600) {
+		t.Fatal("reservation within burst denied")
+	}
+	if budget.ReserveUpload(1) {
+		t.Fatal("reservation on empty budget allowed")
+	}
+	// Unconditional charges deepen the debt.
+	budget.ChargeUpload(300)
+	clock.Run(400 * time.Millisecond)
+	if budget.ReserveUpload(1) {
+		t.Fatal("reservation on indebted budget allowed")
+	}
+	clock.Run(200 * time.Millisecond)
+	if !budget.ReserveUpload(1) {
+		t.Fatal("reservation on replenished budget denied")
+	}
+	// The budget is capped at the burst size.
+	clock.Run(time.Hour)
+	if !budget.ReserveUpload(1000) || budget.ReserveUpload(1) {
+		t.Fatal("budget exceeds burst size")
+	}
+	// Downloads are unlimited.
+	for i := 0; i < 10; i++ {
+		if !budget.ReserveDownload(1 << 20) {
+			t.Fatal("unlimited download denied")
+		}
+	}
+	if delay := budget.DownloadDelay(); delay != 0 {
+		t.Fatalf("unlimited download delayed by %v", delay)
+	}
+	// A nil budget is unlimited too.
+	var unlimited *TxBudget
+	if unlimited.LimitedUpload() || !unlimited.ReserveUpload(1<<30) || !unlimited.ReserveDownload(1<<30) {
+		t.Fatal("nil budget is limited")
+	}
+}
+
+func TestTxBudgetDownloadDelay(t *testing.T) {
+	var (
+		clock  = new(mclock.Simulated)
+		budget = NewTxBudgetForTests(0, 1000, clock)
+	)
+	if budget.LimitedUpload() {
+		t.Fatal("upload limited")
+	}
+	if delay := budget.DownloadDelay(); delay != 0 {
+		t.Fatalf("full budget delayed by %v", delay)
+	}
+	budget.ReserveDownload(1499)
+	if delay := budget.DownloadDelay(); delay != 500*time.Millisecond {
+		t.Fatalf("wrong delay %v, want %v", delay, 500*time.Millisecond)
+	}
+	clock.Run(500 * time.Millisecond)
+	if !budget.ReserveDownload(1) {
+		t.Fatal("reservation after delay denied")
+	}
+}
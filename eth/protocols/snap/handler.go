@@ -23,6 +23,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -283,12 +284,19 @@ func ServiceGetAccountRangeQuery(chain *core.BlockChain, req *GetAccountRangePac
 	if req.Bytes > softResponseLimit {
 		req.Bytes = softResponseLimit
 	}
-	// Retrieve the requested state and bail out if non existent
-	tr, err := trie.New(trie.StateTrieID(req.Root), chain.TrieDB())
+	// Retrieve the requested state and bail out if non existent. States which
+	// are not covered by the snapshots any more are served from the historic
+	// tries if the database can still reconstruct them.
+	tr, historic, err := openStateTrie(chain, req.Root)
 	if err != nil {
 		return nil, nil
 	}
-	it, err := chain.Snapshots().AccountIterator(req.Root, req.Origin)
+	var it snapshot.AccountIterator
+	if historic {
+		it, err = newTrieAccountIterator(tr.Copy(), req.Origin)
+	} else {
+		it, err = chain.Snapshots().AccountIterator(req.Root, req.Origin)
+	}
 	if err != nil {
 		return nil, nil
 	}
@@ -355,7 +363,19 @@ func ServiceGetStorageRangesQuery(chain *core.BlockChain, req *GetStorageRangesP
 		slots  [][]*StorageData
 		proofs [][]byte
 		size   uint64
+
+		accTrie  *trie.Trie
+		historic bool
 	)
+	// If the state is not covered by the snapshots any more, serve the ranges
+	// from the historic tries if the database can still reconstruct them.
+	if chain.Snapshots().Snapshot(req.Root) == nil {
+		tr, ok, err := openStateTrie(chain, req.Root)
+		if err != nil || !ok {
+			return nil, nil
+		}
+		accTrie, historic = tr, true
+	}
 	for _, account := range req.Accounts {
 		// If we've exceeded the requested data limit, abort without opening
 		// a new storage range (that we'd need to prove due to exceeded size)
@@ -372,7 +392,18 @@ func ServiceGetStorageRangesQuery(chain *core.BlockChain, req *GetStorageRangesP
 			limit, req.Limit = common.BytesToHash(req.Limit), nil
 		}
 		// Retrieve the requested state and bail out if non existent
-		it, err := chain.Snapshots().StorageIterator(req.Root, account, origin)
+		var (
+			it  snapshot.StorageIterator
+			err error
+		)
+		if historic {
+			var stTrie *trie.Trie
+			if stTrie, err = openStorageTrie(chain, accTrie, req.Root, account, true); err == nil {
+				it, err = newTrieStorageIterator(stTrie, origin)
+			}
+		} else {
+			it, err = chain.Snapshots().StorageIterator(req.Root, account, origin)
+		}
 		if err != nil {
 			return nil, nil
 		}
@@ -414,16 +445,14 @@ func ServiceGetStorageRangesQuery(chain *core.BlockChain, req *GetStorageRangesP
 		if origin != (common.Hash{}) || (abort && len(storage) > 0) {
 			// Request started at a non-zero hash or was capped prematurely, add
 			// the endpoint Merkle proofs
-			accTrie, err := trie.NewStateTrie(trie.StateTrieID(req.Root), chain.TrieDB())
-			if err != nil {
-				return nil, nil
-			}
-			acc, err := accTrie.GetAccountByHash(account)
-			if err != nil || acc == nil {
-				return nil, nil
+			if accTrie == nil {
+				tr, err := trie.New(trie.StateTrieID(req.Root), chain.TrieDB())
+				if err != nil {
+					return nil, nil
+				}
+				accTrie = tr
 			}
-			id := trie.StorageTrieID(req.Root, account, acc.Root)
-			stTrie, err := trie.NewStateTrie(id, chain.TrieDB())
+			stTrie, err := openStorageTrie(chain, accTrie, req.Root, account, historic)
 			if err != nil {
 				return nil, nil
 			}
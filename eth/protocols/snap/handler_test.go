@@ -0,0 +1,210 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testStorage = common.HexToAddress("0xaa")
+)
+
+// historicTestRecipient returns the account funded in the given block.
+func historicTestRecipient(number int) common.Address {
+	return common.BigToAddress(big.NewInt(int64(0x1000 + number)))
+}
+
+// newHistoricTestChain creates a path-scheme chain of the given length, each
+// block funding a new account and storing its number in the storage of the
+// testStorage contract. The states of all but the last 128 blocks are only
+// available by reverting the state histories.
+func newHistoricTestChain(t *testing.T, blocks int) (*core.BlockChain, []*types.Block) {
+	t.Helper()
+
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: core.GenesisAlloc{
+			testAddr: {Balance: big.NewInt(params.Ether)},
+			// Stores the block number in the slot of the same number
+			testStorage: {Code: []byte{byte(vm.NUMBER), byte(vm.NUMBER), byte(vm.SSTORE)}},
+		},
+	}
+	engine := ethash.NewFaker()
+	_, bs, _ := core.GenerateChainWithGenesis(gspec, engine, blocks, func(i int, gen *core.BlockGen) {
+		signer := types.LatestSigner(gspec.Config)
+		for _, tx := range []*types.Transaction{
+			types.NewTransaction(gen.TxNonce(testAddr), historicTestRecipient(i+1), big.NewInt(1), params.TxGas, gen.BaseFee(), nil),
+			types.NewTransaction(gen.TxNonce(testAddr)+1, testStorage, common.Big0, 100000, gen.BaseFee(), nil),
+		} {
+			signed, err := types.SignTx(tx, signer, testKey)
+			if err != nil {
+				t.Fatalf("Failed to sign transaction: %v", err)
+			}
+			gen.AddTx(signed)
+		}
+	})
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	chain, err := core.NewBlockChain(db, core.DefaultCacheConfigWithScheme(rawdb.PathScheme), gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(bs); err != nil {
+		t.Fatalf("Failed to import chain: %v", err)
+	}
+	return chain, bs
+}
+
+// Tests that account ranges of historic states, which are not covered by the
+// snapshots any more, are served from the reconstructed tries.
+func TestServiceGetAccountRangeHistoric(t *testing.T) {
+	chain, blocks := newHistoricTestChain(t, 168)
+	defer chain.Stop()
+
+	// Only the states of the last 128 blocks are covered by the snapshots, the
+	// one of block 30 is available by reverting the state histories.
+	root := blocks[29].Root()
+	if chain.Snapshots().Snapshot(root) != nil {
+		t.Fatal("Historic state covered by snapshots")
+	}
+	req := &GetAccountRangePacket{
+		Root:  root,
+		Limit: common.MaxHash,
+		Bytes: softResponseLimit,
+	}
+	accounts, proof := ServiceGetAccountRangeQuery(chain, req)
+	if len(accounts) == 0 {
+		t.Fatal("No accounts served for historic state")
+	}
+	var (
+		keys   = make([][]byte, len(accounts))
+		values = make([][]byte, len(accounts))
+		found  = make(map[common.Hash]bool)
+	)
+	for i, acc := range accounts {
+		full, err := types.FullAccountRLP(acc.Body)
+		if err != nil {
+			t.Fatalf("Invalid account %d: %v", i, err)
+		}
+		keys[i], values[i] = acc.Hash[:], full
+		found[acc.Hash] = true
+	}
+	nodes := make(trienode.ProofList, len(proof))
+	for i, node := range proof {
+		nodes[i] = node
+	}
+	if _, err := trie.VerifyRangeProof(root, req.Origin[:], keys, values, nodes.Set()); err != nil {
+		t.Fatalf("Invalid account range: %v", err)
+	}
+	if !found[crypto.Keccak256Hash(historicTestRecipient(30).Bytes())] {
+		t.Fatal("Account funded in historic block missing")
+	}
+	if found[crypto.Keccak256Hash(historicTestRecipient(31).Bytes())] {
+		t.Fatal("Account funded after historic block served")
+	}
+	// States too far below the persistent one are not reconstructed
+	req = &GetAccountRangePacket{Root: blocks[4].Root(), Limit: common.MaxHash, Bytes: softResponseLimit}
+	if accounts, _ := ServiceGetAccountRangeQuery(chain, req); len(accounts) != 0 {
+		t.Fatalf("Accounts served for too deep state: %d", len(accounts))
+	}
+}
+
+// Tests that storage ranges of historic states, which are not covered by the
+// snapshots any more, are served from the reconstructed tries.
+func TestServiceGetStorageRangesHistoric(t *testing.T) {
+	chain, blocks := newHistoricTestChain(t, 168)
+	defer chain.Stop()
+
+	var (
+		root    = blocks[29].Root()
+		account = crypto.Keccak256Hash(testStorage.Bytes())
+		want    = make(map[common.Hash][]byte)
+	)
+	for number := int64(1); number <= 30; number++ {
+		slot := crypto.Keccak256Hash(common.BigToHash(big.NewInt(number)).Bytes())
+		want[slot], _ = rlp.EncodeToBytes(big.NewInt(number))
+	}
+	check := func(slots []*StorageData, origin common.Hash) {
+		t.Helper()
+
+		for _, slot := range slots {
+			if !bytes.Equal(want[slot.Hash], slot.Body) {
+				t.Fatalf("Slot %x mismatch: have %x, want %x", slot.Hash, slot.Body, want[slot.Hash])
+			}
+			if bytes.Compare(slot.Hash[:], origin[:]) < 0 {
+				t.Fatalf("Slot %x before origin %x", slot.Hash, origin)
+			}
+		}
+	}
+	// The entire storage of the historic state fits in a single response
+	slots, proof := ServiceGetStorageRangesQuery(chain, &GetStorageRangesPacket{
+		Root:     root,
+		Accounts: []common.Hash{account},
+		Bytes:    softResponseLimit,
+	})
+	if len(slots) != 1 || len(slots[0]) != len(want) {
+		t.Fatalf("Storage range mismatch: have %v, want %d slots", slots, len(want))
+	}
+	if len(proof) != 0 {
+		t.Fatalf("Proof attached to complete range: %d nodes", len(proof))
+	}
+	check(slots[0], common.Hash{})
+
+	// Ranges starting at a non-zero origin are proven against the historic trie
+	origin := common.Hash{0x80}
+	slots, proof = ServiceGetStorageRangesQuery(chain, &GetStorageRangesPacket{
+		Root:     root,
+		Accounts: []common.Hash{account},
+		Origin:   origin[:],
+		Bytes:    softResponseLimit,
+	})
+	if len(slots) != 1 || len(slots[0]) == 0 {
+		t.Fatalf("No storage served for historic state: %v", slots)
+	}
+	check(slots[0], origin)
+	if len(proof) == 0 {
+		t.Fatal("No proof attached to partial range")
+	}
+	// Storage of unknown states is not served
+	slots, _ = ServiceGetStorageRangesQuery(chain, &GetStorageRangesPacket{
+		Root:     common.Hash{0x01},
+		Accounts: []common.Hash{account},
+		Bytes:    softResponseLimit,
+	})
+	if len(slots) != 0 {
+		t.Fatalf("Storage served for unknown state: %v", slots)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// openStateTrie opens the account trie of the requested state. If the state is
+// not available any more, but the database is able to reconstruct it from the
+// retained state histories (path-based scheme only), a historic trie is opened
+// and the returned flag is set.
+func openStateTrie(chain *core.BlockChain, root common.Hash) (*trie.Trie, bool, error) {
+	tr, err := trie.New(trie.StateTrieID(root), chain.TrieDB())
+	if err == nil {
+		return tr, false, nil
+	}
+	tr, herr := trie.NewHistoric(trie.StateTrieID(root), chain.TrieDB())
+	if herr != nil {
+		return nil, false, err
+	}
+	return tr, true, nil
+}
+
+// openStorageTrie opens the storage trie of an account contained in the given
+// account trie, resolving the nodes from the same (possibly historic) state.
+func openStorageTrie(chain *core.BlockChain, accTrie *trie.Trie, root common.Hash, account common.Hash, historic bool) (*trie.Trie, error) {
+	blob, err := accTrie.Get(account[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) == 0 {
+		return nil, errors.New("account not found")
+	}
+	var acc types.StateAccount
+	if err := rlp.DecodeBytes(blob, &acc); err != nil {
+		return nil, err
+	}
+	id := trie.StorageTrieID(root, account, acc.Root)
+	if historic {
+		return trie.NewHistoric(id, chain.TrieDB())
+	}
+	return trie.New(id, chain.TrieDB())
+}
+
+// trieAccountIterator implements snapshot.AccountIterator on top of an account
+// trie, used to serve the states which are not covered by the snapshots.
+type trieAccountIterator struct {
+	it      *trie.Iterator
+	account []byte
+	err     error
+}
+
+// newTrieAccountIterator creates an account iterator over the given trie,
+// starting at the given account hash.
+func newTrieAccountIterator(tr *trie.Trie, origin common.Hash) (*trieAccountIterator, error) {
+	nodeIt, err := tr.NodeIterator(origin[:])
+	if err != nil {
+		return nil, err
+	}
+	return &trieAccountIterator{it: trie.NewIterator(nodeIt)}, nil
+}
+
+// Next steps the iterator forward one account, converting it into the slim
+// format used by the snapshots.
+func (it *trieAccountIterator) Next() bool {
+	if it.err != nil || !it.it.Next() {
+		return false
+	}
+	var acc types.StateAccount
+	if err := rlp.DecodeBytes(it.it.Value, &acc); err != nil {
+		it.err = err
+		return false
+	}
+	it.account = types.SlimAccountRLP(acc)
+	return true
+}
+
+// Error returns any failure that occurred during iteration.
+func (it *trieAccountIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.it.Err
+}
+
+// Hash returns the hash of the account the iterator is currently at.
+func (it *trieAccountIterator) Hash() common.Hash { return common.BytesToHash(it.it.Key) }
+
+// Account returns the RLP encoded slim account the iterator is currently at.
+func (it *trieAccountIterator) Account() []byte { return it.account }
+
+// Release is a noop for trie iterators as there are no held resources.
+func (it *trieAccountIterator) Release() {}
+
+// trieStorageIterator implements snapshot.StorageIterator on top of a storage
+// trie, used to serve the states which are not covered by the snapshots.
+type trieStorageIterator struct {
+	it *trie.Iterator
+}
+
+// newTrieStorageIterator creates a storage iterator over the given trie,
+// starting at the given slot hash.
+func newTrieStorageIterator(tr *trie.Trie, origin common.Hash) (*trieStorageIterator, error) {
+	nodeIt, err := tr.NodeIterator(origin[:])
+	if err != nil {
+		return nil, err
+	}
+	return &trieStorageIterator{it: trie.NewIterator(nodeIt)}, nil
+}
+
+// Next steps the iterator forward one storage slot.
+func (it *trieStorageIterator) Next() bool { return it.it.Next() }
+
+// Error returns any failure that occurred during iteration.
+func (it *trieStorageIterator) Error() error { return it.it.Err }
+
+// Hash returns the hash of the storage slot the iterator is currently at.
+func (it *trieStorageIterator) Hash() common.Hash { return common.BytesToHash(it.it.Key) }
+
+// Slot returns the RLP encoded storage slot the iterator is currently at.
+func (it *trieStorageIterator) Slot() []byte { return it.it.Value }
+
+// Release is a noop for trie iterators as there are no held resources.
+func (it *trieStorageIterator) Release() {}
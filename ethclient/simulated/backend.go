@@ -0,0 +1,192 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package simulated implements a simulated blockchain for testing code which
+// interacts with Ethereum, such as contract bindings.
+//
+// Unlike accounts/abi/bind/backends.SimulatedBackend, the chain is run by a real
+// in-process node, with blocks produced through the Engine API on demand. All
+// the RPC methods of a geth node, including tracing, log subscriptions and blob
+// transactions, are available through the ethclient returned by Client.
+package simulated
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	// Force-load the tracer engines to trigger registration
+	_ "github.com/ethereum/go-ethereum/eth/tracers/js"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
+)
+
+// Backend is a simulated blockchain. You can use it to test your contracts or
+// other code that interacts with the Ethereum chain.
+type Backend struct {
+	node   *node.Node
+	eth    *eth.Ethereum
+	beacon *catalyst.SimulatedBeacon
+	client *ethclient.Client
+}
+
+// NewBackend creates a new simulated blockchain that can be used as a backend
+// for contract bindings in unit tests. The chain has all forks up to and
+// including Cancun enabled, and uses chain ID 1337.
+//
+// The options are applied to the default node and Ethereum configurations
+// before the node is started.
+func NewBackend(alloc core.GenesisAlloc, options ...func(nodeConf *node.Config, ethConf *ethconfig.Config)) *Backend {
+	// Create the default configurations for the outer node shell and the
+	// Ethereum service to mutate with the options afterwards
+	nodeConf := node.DefaultConfig
+	nodeConf.DataDir = ""
+	nodeConf.IPCPath = ""
+	nodeConf.P2P = p2p.Config{NoDiscovery: true}
+
+	chainConf := *params.AllDevChainProtocolChanges
+	chainConf.CancunTime = new(uint64)
+
+	ethConf := ethconfig.Defaults
+	ethConf.Genesis = &core.Genesis{
+		Config:   &chainConf,
+		GasLimit: ethconfig.Defaults.Miner.GasCeil,
+		Alloc:    alloc,
+	}
+	ethConf.SyncMode = downloader.FullSync
+	ethConf.TxPool.NoLocals = true
+
+	for _, option := range options {
+		option(&nodeConf, &ethConf)
+	}
+	// Assemble the Ethereum stack to run the chain with
+	stack, err := node.New(&nodeConf)
+	if err != nil {
+		panic(err) // this should never happen
+	}
+	sim, err := newWithNode(stack, &ethConf)
+	if err != nil {
+		panic(err) // this should never happen
+	}
+	return sim
+}
+
+// newWithNode sets up a simulated backend on an existing node. The provided
+// node must not be started and will be started by this method.
+func newWithNode(stack *node.Node, conf *ethconfig.Config) (*Backend, error) {
+	backend, err := eth.New(stack, conf)
+	if err != nil {
+		return nil, err
+	}
+	// Register the filter system and the tracers
+	filterSystem := filters.NewFilterSystem(backend.APIBackend, filters.Config{})
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: "eth",
+		Service:   filters.NewFilterAPI(filterSystem, false),
+	}})
+	stack.RegisterAPIs(tracers.APIs(backend.APIBackend))
+
+	if err := stack.Start(); err != nil {
+		return nil, err
+	}
+	// Set up the simulated beacon, it is not started as blocks are only
+	// produced on request
+	beacon, err := catalyst.NewSimulatedBeacon(0, backend)
+	if err != nil {
+		stack.Close()
+		return nil, err
+	}
+	return &Backend{
+		node:   stack,
+		eth:    backend,
+		beacon: beacon,
+		client: ethclient.NewClient(stack.Attach()),
+	}, nil
+}
+
+// Close shuts down the simulated backend. The backend can't be used afterwards.
+func (n *Backend) Close() error {
+	if n.client != nil {
+		n.client.Close()
+		n.client = nil
+	}
+	var err error
+	if n.node != nil {
+		err = n.node.Close()
+		n.node = nil
+	}
+	return err
+}
+
+// Commit seals a block with the pending transactions and moves the chain
+// forward to it. It returns the hash of the new head block.
+func (n *Backend) Commit() common.Hash {
+	hash, err := n.beacon.Commit(0, nil)
+	if err != nil {
+		panic(fmt.Sprintf("failed to commit block: %v", err))
+	}
+	return hash
+}
+
+// Rollback removes all pending transactions, reverting to the last committed
+// state.
+func (n *Backend) Rollback() {
+	n.eth.TxPool().Clear()
+}
+
+// Fork creates a side-chain that can be used to simulate reorgs. It must be
+// called with the canonical ancestor block where the new side chain should
+// start. Transactions (old and new) can then be sent and the next Commit seals
+// the first block of the side chain, which becomes the new head.
+func (n *Backend) Fork(parentHash common.Hash) error {
+	chain := n.eth.BlockChain()
+
+	parent := chain.GetHeaderByHash(parentHash)
+	if parent == nil {
+		return errors.New("parent block not found")
+	}
+	if chain.GetCanonicalHash(parent.Number.Uint64()) != parentHash {
+		return errors.New("parent block not canonical")
+	}
+	head := chain.CurrentBlock()
+	_, err := n.beacon.Rollback(head.Number.Uint64() - parent.Number.Uint64())
+	return err
+}
+
+// AdjustTime moves the clock of the simulated chain forward by the given
+// amount, which shifts the timestamps of all subsequently committed blocks.
+func (n *Backend) AdjustTime(adjustment time.Duration) error {
+	_, err := n.beacon.AdjustTime(adjustment)
+	return err
+}
+
+// Client returns a client that accesses the simulated chain in-process.
+func (n *Backend) Client() *ethclient.Client {
+	return n.client
+}
@@ -0,0 +1,282 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulated
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testBalance = new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+)
+
+func newTestBackend(t *testing.T) *Backend {
+	sim := NewBackend(core.GenesisAlloc{testAddr: {Balance: testBalance}})
+	t.Cleanup(func() { sim.Close() })
+	return sim
+}
+
+// newTx creates a signed transfer of 1 wei from the test account.
+func newTx(t *testing.T, sim *Backend, key *ecdsa.PrivateKey, nonce uint64) *types.Transaction {
+	head, err := sim.Client().HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   params.AllDevChainProtocolChanges.ChainID,
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), big.NewInt(params.GWei)),
+		Gas:       params.TxGas,
+		To:        &common.Address{0xaa},
+		Value:     big.NewInt(1),
+	})
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(params.AllDevChainProtocolChanges.ChainID), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestNewBackend(t *testing.T) {
+	sim := newTestBackend(t)
+	client := sim.Client()
+
+	num, err := client.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if num != 0 {
+		t.Fatalf("expected genesis head, got block %d", num)
+	}
+	balance, err := client.BalanceAt(context.Background(), testAddr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(testBalance) != 0 {
+		t.Fatalf("wrong genesis balance: have %v, want %v", balance, testBalance)
+	}
+	id, err := client.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Cmp(params.AllDevChainProtocolChanges.ChainID) != 0 {
+		t.Fatalf("wrong chain id %v", id)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	var (
+		sim    = newTestBackend(t)
+		client = sim.Client()
+		ctx    = context.Background()
+	)
+	heads := make(chan *types.Header, 1)
+	sub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	tx := newTx(t, sim, testKey, 0)
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatal("failed to send transaction:", err)
+	}
+	hash := sim.Commit()
+
+	select {
+	case head := <-heads:
+		if head.Hash() != hash {
+			t.Fatalf("wrong head announced: have %x, want %x", head.Hash(), hash)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("new head not announced")
+	}
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal("transaction not included:", err)
+	}
+	if receipt.BlockHash != hash || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("wrong receipt %+v", receipt)
+	}
+	// Transactions can be traced, as on a real node
+	var trace json.RawMessage
+	if err := client.Client().Call(&trace, "debug_traceTransaction", tx.Hash(), map[string]string{"tracer": "callTracer"}); err != nil {
+		t.Fatal("failed to trace transaction:", err)
+	}
+	var call struct {
+		To common.Address `json:"to"`
+	}
+	if err := json.Unmarshal(trace, &call); err != nil || call.To != (common.Address{0xaa}) {
+		t.Fatalf("wrong trace %s: %v", trace, err)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	var (
+		sim    = newTestBackend(t)
+		client = sim.Client()
+		ctx    = context.Background()
+	)
+	tx := newTx(t, sim, testKey, 0)
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatal("failed to send transaction:", err)
+	}
+	sim.Rollback()
+
+	if nonce, err := client.PendingNonceAt(ctx, testAddr); err != nil || nonce != 0 {
+		t.Fatalf("pending nonce not reset: %d, err %v", nonce, err)
+	}
+	block, err := client.BlockByHash(ctx, sim.Commit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block.Transactions()) != 0 {
+		t.Fatal("rolled back transaction included")
+	}
+	// The account can send transactions again after the rollback
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatal("failed to resend transaction:", err)
+	}
+	block, err = client.BlockByHash(ctx, sim.Commit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block.Transactions()) != 1 {
+		t.Fatal("resent transaction not included")
+	}
+}
+
+func TestFork(t *testing.T) {
+	var (
+		sim    = newTestBackend(t)
+		client = sim.Client()
+		ctx    = context.Background()
+	)
+	parent := sim.Commit()
+	tx := newTx(t, sim, testKey, 0)
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatal("failed to send transaction:", err)
+	}
+	sim.Commit()
+	sim.Commit()
+
+	if err := sim.Fork(parent); err != nil {
+		t.Fatal("failed to fork:", err)
+	}
+	head := sim.Commit()
+
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Hash() != head || header.Number.Uint64() != 2 || header.ParentHash != parent {
+		t.Fatalf("chain not reorged onto the fork: head %d %x, parent %x", header.Number, header.Hash(), header.ParentHash)
+	}
+	// The transaction of the dropped chain was reinjected into the fork
+	if _, err := client.TransactionReceipt(ctx, tx.Hash()); err != nil {
+		t.Fatal("reorged transaction not included:", err)
+	}
+	if err := sim.Fork(common.Hash{1}); err == nil {
+		t.Fatal("forked from unknown block")
+	}
+}
+
+func TestAdjustTime(t *testing.T) {
+	var (
+		sim    = newTestBackend(t)
+		client = sim.Client()
+		ctx    = context.Background()
+	)
+	prev, err := client.BlockByHash(ctx, sim.Commit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.AdjustTime(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	block, err := client.BlockByHash(ctx, sim.Commit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := block.Time() - prev.Time(); diff < 3600 {
+		t.Fatalf("time not adjusted: blocks %d seconds apart", diff)
+	}
+	if err := sim.AdjustTime(-time.Second); err == nil {
+		t.Fatal("moved time backwards")
+	}
+}
+
+func TestBlobTransaction(t *testing.T) {
+	var (
+		sim    = newTestBackend(t)
+		client = sim.Client()
+		ctx    = context.Background()
+	)
+	var blob kzg4844.Blob
+	commitment, err := kzg4844.BlobToCommitment(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := kzg4844.ComputeBlobProof(blob, commitment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sidecar := &types.BlobTxSidecar{
+		Blobs:       []kzg4844.Blob{blob},
+		Commitments: []kzg4844.Commitment{commitment},
+		Proofs:      []kzg4844.Proof{proof},
+	}
+	tx := types.MustSignNewTx(testKey, types.LatestSignerForChainID(params.AllDevChainProtocolChanges.ChainID), &types.BlobTx{
+		ChainID:    uint256.MustFromBig(params.AllDevChainProtocolChanges.ChainID),
+		Nonce:      0,
+		GasTipCap:  uint256.NewInt(params.GWei),
+		GasFeeCap:  uint256.NewInt(10 * params.GWei),
+		Gas:        params.TxGas,
+		To:         common.Address{0xbb},
+		BlobFeeCap: uint256.NewInt(params.GWei),
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	})
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatal("failed to send blob transaction:", err)
+	}
+	sim.Commit()
+
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal("blob transaction not included:", err)
+	}
+	if receipt.BlobGasUsed != params.BlobTxBlobGasPerBlob {
+		t.Fatalf("wrong blob gas used: have %d, want %d", receipt.BlobGasUsed, params.BlobTxBlobGasPerBlob)
+	}
+}
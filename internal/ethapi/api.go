@@ -116,6 +116,50 @@ func (s *EthereumAPI) FeeHistory(ctx context.Context, blockCount math.HexOrDecim
 	return results, nil
 }
 
+type feeTierResult struct {
+	Name                 string         `json:"name"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxFeePerBlobGas     *hexutil.Big   `json:"maxFeePerBlobGas,omitempty"`
+	Blocks               hexutil.Uint64 `json:"blocks"`
+}
+
+type feeTiersResult struct {
+	Number            *hexutil.Big    `json:"number"`
+	BaseFeePerGas     *hexutil.Big    `json:"baseFeePerGas"`
+	BlobBaseFeePerGas *hexutil.Big    `json:"blobBaseFeePerGas,omitempty"`
+	GasUsedRatio      float64         `json:"gasUsedRatio"`
+	PendingGas        hexutil.Uint64  `json:"pendingGas"`
+	Tiers             []feeTierResult `json:"tiers"`
+}
+
+// SuggestFeeTiers returns slow, standard and fast fee suggestions for dynamic fee
+// and blob transactions, along with the expected number of blocks to inclusion.
+func (s *EthereumAPI) SuggestFeeTiers(ctx context.Context) (*feeTiersResult, error) {
+	suggestion, err := s.b.SuggestFeeTiers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := &feeTiersResult{
+		Number:            (*hexutil.Big)(suggestion.Number),
+		BaseFeePerGas:     (*hexutil.Big)(suggestion.BaseFee),
+		BlobBaseFeePerGas: (*hexutil.Big)(suggestion.BlobBaseFee),
+		GasUsedRatio:      suggestion.GasUsedRatio,
+		PendingGas:        hexutil.Uint64(suggestion.PendingGas),
+		Tiers:             make([]feeTierResult, len(suggestion.Tiers)),
+	}
+	for i, tier := range suggestion.Tiers {
+		results.Tiers[i] = feeTierResult{
+			Name:                 tier.Name,
+			MaxPriorityFeePerGas: (*hexutil.Big)(tier.MaxPriorityFee),
+			MaxFeePerGas:         (*hexutil.Big)(tier.MaxFee),
+			MaxFeePerBlobGas:     (*hexutil.Big)(tier.MaxBlobFee),
+			Blocks:               hexutil.Uint64(tier.Blocks),
+		}
+	}
+	return results, nil
+}
+
 // Syncing returns false in case the node is currently not syncing with the network. It can be up-to-date or has not
 // yet received the latest block headers from its pears. In case it is synchronizing:
 // - startingBlock: block number this node started to synchronize from
@@ -210,13 +254,22 @@ func (s *TxPoolAPI) ContentFrom(addr common.Address) map[string]map[string]*RPCT
 	return content
 }
 
-// Status returns the number of pending and queued transaction in the pool.
+// Status returns the number of pending and queued transaction in the pool, as
+// well as their total size and the byte budget of the pool, if any.
 func (s *TxPoolAPI) Status() map[string]hexutil.Uint {
 	pending, queue := s.b.Stats()
-	return map[string]hexutil.Uint{
-		"pending": hexutil.Uint(pending),
-		"queued":  hexutil.Uint(queue),
+	pendingBytes, queuedBytes, limit := s.b.TxPoolUsage()
+
+	status := map[string]hexutil.Uint{
+		"pending":      hexutil.Uint(pending),
+		"queued":       hexutil.Uint(queue),
+		"pendingBytes": hexutil.Uint(pendingBytes),
+		"queuedBytes":  hexutil.Uint(queuedBytes),
+	}
+	if limit > 0 {
+		status["bytesLimit"] = hexutil.Uint(limit)
 	}
+	return status
 }
 
 // Inspect retrieves the content of the transaction pool and flattens it into an
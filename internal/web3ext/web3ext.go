@@ -142,6 +142,16 @@ web3._extend({
 			call: 'admin_removeTrustedPeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'peerScore',
+			call: 'admin_peerScore',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setPeerScore',
+			call: 'admin_setPeerScore',
+			params: 2
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -200,6 +210,10 @@ web3._extend({
 			name: 'peers',
 			getter: 'admin_peers'
 		}),
+		new web3._extend.Property({
+			name: 'peerScores',
+			getter: 'admin_peerScores'
+		}),
 		new web3._extend.Property({
 			name: 'datadir',
 			getter: 'admin_datadir'
@@ -606,6 +620,10 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'suggestFeeTiers',
+			call: 'eth_suggestFeeTiers',
+		}),
 		new web3._extend.Method({
 			name: 'getLogs',
 			call: 'eth_getLogs',
@@ -908,6 +926,34 @@ web3._extend({
 			call: 'dev_setFeeRecipient',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'mine',
+			call: 'dev_mine',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'adjustTime',
+			call: 'dev_adjustTime',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'rollback',
+			call: 'dev_rollback',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'setSafe',
+			call: 'dev_setSafe',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setFinalized',
+			call: 'dev_setFinalized',
+			params: 1
+		}),
 	],
 });
 `
@@ -223,6 +223,10 @@ func (b *LesApiBackend) Stats() (pending int, queued int) {
 	return b.eth.txPool.Stats(), 0
 }
 
+func (b *LesApiBackend) TxPoolUsage() (pending uint64, queued uint64, limit uint64) {
+	return 0, 0, 0 // Light clients don't track transaction sizes
+}
+
 func (b *LesApiBackend) TxPoolContent() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
 	return b.eth.txPool.Content()
 }
@@ -278,6 +282,10 @@ func (b *LesApiBackend) FeeHistory(ctx context.Context, blockCount uint64, lastB
 	return b.gpo.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
 }
 
+func (b *LesApiBackend) SuggestFeeTiers(ctx context.Context) (*gasprice.FeeSuggestion, error) {
+	return b.gpo.SuggestFeeTiers(ctx)
+}
+
 func (b *LesApiBackend) ChainDb() ethdb.Database {
 	return b.eth.chainDb
 }
@@ -305,6 +305,54 @@ func (api *adminAPI) Peers() ([]*p2p.PeerInfo, error) {
 	return server.PeersInfo(), nil
 }
 
+// PeerScores retrieves the reputation scores of all nodes with a non-zero score.
+func (api *adminAPI) PeerScores() (map[enode.ID]int64, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.PeerScores(), nil
+}
+
+// PeerScore retrieves the reputation score of a node, given as enode URL or ID.
+func (api *adminAPI) PeerScore(node string) (int64, error) {
+	server := api.node.Server()
+	if server == nil {
+		return 0, ErrNodeStopped
+	}
+	id, err := parseNodeID(node)
+	if err != nil {
+		return 0, err
+	}
+	return server.PeerScore(id), nil
+}
+
+// SetPeerScore overrides the reputation score of a node, given as enode URL or
+// ID. The score is clamped to the valid range and the resulting score returned.
+func (api *adminAPI) SetPeerScore(node string, score int64) (int64, error) {
+	server := api.node.Server()
+	if server == nil {
+		return 0, ErrNodeStopped
+	}
+	id, err := parseNodeID(node)
+	if err != nil {
+		return 0, err
+	}
+	return server.SetPeerScore(id, score), nil
+}
+
+// parseNodeID parses a node given either as enode URL or as hex encoded node ID.
+func parseNodeID(node string) (enode.ID, error) {
+	if n, err := enode.Parse(enode.ValidSchemes, node); err == nil {
+		return n.ID(), nil
+	}
+	id, err := enode.ParseID(node)
+	if err != nil {
+		return enode.ID{}, fmt.Errorf("invalid node: %v", err)
+	}
+	return id, nil
+}
+
 // NodeInfo retrieves all the information we know about the host node at the
 // protocol granularity.
 func (api *adminAPI) NodeInfo() (*p2p.NodeInfo, error) {
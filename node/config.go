@@ -83,6 +83,10 @@ type Config struct {
 	// scrypt KDF at the expense of security.
 	UseLightweightKDF bool `toml:",omitempty"`
 
+	// UseKeyring additionally stores the keys of the key store in the kernel
+	// keyring, allowing them to be unlocked without a password.
+	UseKeyring bool `toml:",omitempty"`
+
 	// InsecureUnlockAllowed allows user to unlock accounts in unsafe http environment.
 	InsecureUnlockAllowed bool `toml:",omitempty"`
 
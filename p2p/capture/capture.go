@@ -0,0 +1,269 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package capture implements a file format for recording devp2p sessions.
+//
+// A capture file starts with a fixed header, followed by a sequence of RLP encoded
+// records. Each record holds a single subprotocol message exchanged with a peer,
+// along with its direction and the time it was sent or received. Message codes
+// are relative to the subprotocol, i.e. the first message of each protocol has
+// code zero.
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// fileHeader is the magic prefix of capture files. The last byte is the format version.
+var fileHeader = []byte("devp2p-capture\x01")
+
+var (
+	errBadHeader = errors.New("not a devp2p capture file")
+	errClosed    = errors.New("capture writer closed")
+)
+
+const (
+	writeQueueSize = 4096        // Number of records queued for writing before dropping
+	writeBufSize   = 256 * 1024  // Size of the buffer collecting records before writing
+	flushInterval  = time.Second // Maximum time records are held in the buffer
+)
+
+// Record is a captured message.
+type Record struct {
+	Time     time.Time
+	Peer     enode.ID
+	Inbound  bool // true if the message was received from the peer
+	Protocol string
+	Version  uint
+	Code     uint64
+	Payload  []byte
+}
+
+// String implements fmt.Stringer.
+func (r *Record) String() string {
+	dir := "->"
+	if r.Inbound {
+		dir = "<-"
+	}
+	return fmt.Sprintf("%s %s %x %s/%d code=%#02x size=%d", r.Time.Format(time.RFC3339Nano), dir, r.Peer[:8], r.Protocol, r.Version, r.Code, len(r.Payload))
+}
+
+// rlpRecord is the encoding of a record.
+type rlpRecord struct {
+	Time     uint64 // unix time in nanoseconds
+	Peer     enode.ID
+	Inbound  bool
+	Protocol string
+	Version  uint
+	Code     uint64
+	Payload  []byte
+}
+
+// Writer writes records to a capture file. It is safe for concurrent use.
+//
+// Records are written by a background goroutine through a buffer, so recording
+// never blocks on the file. If records are produced faster than they can be
+// written, the excess is dropped.
+type Writer struct {
+	queue     chan []byte
+	quit      chan struct{}
+	done      chan struct{}
+	dropped   atomic.Uint64
+	closeOnce sync.Once
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewWriter creates a writer and writes the file header.
+func NewWriter(w io.Writer) (*Writer, error) {
+	if _, err := w.Write(fileHeader); err != nil {
+		return nil, err
+	}
+	cw := &Writer{
+		queue: make(chan []byte, writeQueueSize),
+		quit:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go cw.loop(bufio.NewWriterSize(w, writeBufSize))
+	return cw, nil
+}
+
+// Write queues a record for writing. Once writing has failed, all subsequent
+// calls return the same error. Records which don't fit into the queue are
+// dropped, see Dropped.
+func (w *Writer) Write(r *Record) error {
+	if err := w.Err(); err != nil {
+		return err
+	}
+	enc, err := rlp.EncodeToBytes(&rlpRecord{
+		Time:     uint64(r.Time.UnixNano()),
+		Peer:     r.Peer,
+		Inbound:  r.Inbound,
+		Protocol: r.Protocol,
+		Version:  r.Version,
+		Code:     r.Code,
+		Payload:  r.Payload,
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case <-w.quit:
+		return errClosed
+	default:
+	}
+	select {
+	case w.queue <- enc:
+	default:
+		w.dropped.Add(1)
+	}
+	return nil
+}
+
+// loop writes the queued records into the buffer, flushing it periodically and
+// when the writer is closed.
+func (w *Writer) loop(buf *bufio.Writer) {
+	defer close(w.done)
+
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+
+	for {
+		select {
+		case enc := <-w.queue:
+			w.write(buf, enc)
+		case <-flush.C:
+			w.flush(buf)
+		case <-w.quit:
+			for {
+				select {
+				case enc := <-w.queue:
+					w.write(buf, enc)
+				default:
+					w.flush(buf)
+					return
+				}
+			}
+		}
+	}
+}
+
+// write appends an encoded record to the buffer, unless writing failed before.
+func (w *Writer) write(buf *bufio.Writer, enc []byte) {
+	if w.Err() != nil {
+		return
+	}
+	if _, err := buf.Write(enc); err != nil {
+		w.fail(err)
+	}
+}
+
+// flush writes the buffered records to the file.
+func (w *Writer) flush(buf *bufio.Writer) {
+	if w.Err() != nil {
+		return
+	}
+	if err := buf.Flush(); err != nil {
+		w.fail(err)
+	}
+}
+
+// fail records the error which made writing fail.
+func (w *Writer) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// Close writes all queued records and stops the writer. It does not close the
+// underlying writer. It returns the error which made writing fail, if any.
+func (w *Writer) Close() error {
+	w.closeOnce.Do(func() { close(w.quit) })
+	<-w.done
+	return w.Err()
+}
+
+// Err returns the error which made writing fail, if any.
+func (w *Writer) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Dropped returns the number of records dropped because the writer could not
+// keep up.
+func (w *Writer) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Reader reads records from a capture file.
+type Reader struct {
+	s *rlp.Stream
+}
+
+// NewReader creates a reader and checks the file header.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(fileHeader))
+	if _, err := io.ReadFull(br, header); err != nil || !bytes.Equal(header, fileHeader) {
+		return nil, errBadHeader
+	}
+	return &Reader{s: rlp.NewStream(br, 0)}, nil
+}
+
+// Next returns the next record. It returns io.EOF at the end of the file.
+func (r *Reader) Next() (*Record, error) {
+	var dec rlpRecord
+	if err := r.s.Decode(&dec); err != nil {
+		return nil, err
+	}
+	return &Record{
+		Time:     time.Unix(0, int64(dec.Time)),
+		Peer:     dec.Peer,
+		Inbound:  dec.Inbound,
+		Protocol: dec.Protocol,
+		Version:  dec.Version,
+		Code:     dec.Code,
+		Payload:  dec.Payload,
+	}, nil
+}
+
+// ReadAll reads all remaining records.
+func (r *Reader) ReadAll() ([]*Record, error) {
+	var records []*Record
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+}
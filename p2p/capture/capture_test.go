@@ -0,0 +1,125 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package capture
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestRoundTrip(t *testing.T) {
+	records := []*Record{
+		{
+			Time:     time.Unix(1700000000, 123),
+			Peer:     enode.ID{1},
+			Inbound:  true,
+			Protocol: "eth",
+			Version:  68,
+			Code:     0x08,
+			Payload:  []byte{0xc1, 0x80},
+		},
+		{
+			Time:     time.Unix(1700000001, 0),
+			Peer:     enode.ID{2},
+			Protocol: "snap",
+			Version:  1,
+			Code:     0x00,
+			Payload:  []byte{},
+		},
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range records {
+		if err := w.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(records[0]); err != errClosed {
+		t.Fatalf("write after close: have %v, want %v", err, errClosed)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range records {
+		rec, err := r.Next()
+		if err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(rec, want) {
+			t.Fatalf("record %d mismatch:\nhave %v\nwant %v", i, rec, want)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+
+	// A truncated capture is reported.
+	r, _ = NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	if _, err := r.ReadAll(); err == nil || err == io.EOF {
+		t.Fatalf("expected error for truncated capture, got %v", err)
+	}
+}
+
+func TestBadHeader(t *testing.T) {
+	if _, err := NewReader(bytes.NewReader([]byte("something else"))); err != errBadHeader {
+		t.Fatalf("wrong error: %v", err)
+	}
+}
+
+// failWriter fails all writes after the file header.
+type failWriter struct{ header bool }
+
+var errFailWrite = errors.New("write failed")
+
+func (w *failWriter) Write(b []byte) (int, error) {
+	if !w.header {
+		w.header = true
+		return len(b), nil
+	}
+	return 0, errFailWrite
+}
+
+func TestWriteFailure(t *testing.T) {
+	w, err := NewWriter(new(failWriter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Records are buffered, so the failure only surfaces when flushing.
+	if err := w.Write(&Record{Time: time.Unix(0, 0), Payload: []byte{0x80}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != errFailWrite {
+		t.Fatalf("close error mismatch: have %v, want %v", err, errFailWrite)
+	}
+	if err := w.Write(&Record{}); err != errFailWrite {
+		t.Fatalf("write error mismatch: have %v, want %v", err, errFailWrite)
+	}
+}
@@ -63,14 +63,35 @@ type tcpDialer struct {
 	d *net.Dialer
 }
 
+// Dial connects to the IPv4 address of the node, and to its IPv6 address if the
+// node has no IPv4 address or it isn't reachable.
 func (t tcpDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
-	return t.d.DialContext(ctx, "tcp", nodeAddr(dest).String())
+	err := errNoIP
+	for _, addr := range nodeAddrs(dest) {
+		var fd net.Conn
+		if fd, err = t.d.DialContext(ctx, "tcp", addr.String()); err == nil || ctx.Err() != nil {
+			return fd, err
+		}
+	}
+	return nil, err
 }
 
 func nodeAddr(n *enode.Node) net.Addr {
 	return &net.TCPAddr{IP: n.IP(), Port: n.TCP()}
 }
 
+// nodeAddrs returns the TCP endpoints of a node, IPv4 first.
+func nodeAddrs(n *enode.Node) []*net.TCPAddr {
+	var addrs []*net.TCPAddr
+	if ip := n.IPv4(); ip != nil {
+		addrs = append(addrs, &net.TCPAddr{IP: ip, Port: n.TCP()})
+	}
+	if ip := n.IPv6(); ip != nil {
+		addrs = append(addrs, &net.TCPAddr{IP: ip, Port: n.TCP6()})
+	}
+	return addrs
+}
+
 // checkDial errors:
 var (
 	errSelf             = errors.New("is self")
@@ -79,6 +100,8 @@ var (
 	errRecentlyDialed   = errors.New("recently dialed")
 	errNetRestrict      = errors.New("not contained in netrestrict list")
 	errNoPort           = errors.New("node does not provide TCP port")
+	errNoIP             = errors.New("node does not provide IP address")
+	errBadReputation    = errors.New("node has bad reputation")
 )
 
 // dialer creates outbound connections and submits them into Server.
@@ -133,6 +156,7 @@ type dialConfig struct {
 	maxActiveDials int              // maximum number of active dials
 	netRestrict    *netutil.Netlist // IP netrestrict list, disabled if nil
 	resolver       nodeResolver
+	reputation     func(enode.ID) int64 // reputation score lookup, disabled if nil
 	dialer         NodeDialer
 	log            log.Logger
 	clock          mclock.Clock
@@ -241,7 +265,7 @@ loop:
 
 		select {
 		case node := <-nodesCh:
-			if err := d.checkDial(node); err != nil {
+			if err := d.checkDynDial(node); err != nil {
 				d.log.Trace("Discarding dial candidate", "id", node.ID(), "ip", node.IP(), "reason", err)
 			} else {
 				d.startDial(newDialTask(node, dynDialedConn))
@@ -396,6 +420,18 @@ func (d *dialScheduler) checkDial(n *enode.Node) error {
 	return nil
 }
 
+// checkDynDial returns an error if discovered node n should not be dialed. In
+// addition to the checks of all dials, nodes with a bad reputation are skipped.
+func (d *dialScheduler) checkDynDial(n *enode.Node) error {
+	if err := d.checkDial(n); err != nil {
+		return err
+	}
+	if d.reputation != nil && d.reputation(n.ID()) <= BanReputation {
+		return errBadReputation
+	}
+	return nil
+}
+
 // startStaticDials starts n static dial tasks.
 func (d *dialScheduler) startStaticDials(n int) (started int) {
 	for started = 0; started < n && len(d.staticPool) > 0; started++ {
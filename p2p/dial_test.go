@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/internal/testlog"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
 )
 
@@ -152,6 +153,33 @@ func TestDialSchedNetRestrict(t *testing.T) {
 	})
 }
 
+// This test checks that discovered nodes with a bad reputation are not dialed.
+func TestDialSchedBadReputation(t *testing.T) {
+	t.Parallel()
+
+	config := dialConfig{
+		maxActiveDials: 5,
+		maxDialPeers:   4,
+		reputation: func(id enode.ID) int64 {
+			if id == uintID(0x01) {
+				return BanReputation
+			}
+			return BanReputation + 1
+		},
+	}
+	runDialTest(t, config, []dialTestRound{
+		{
+			discovered: []*enode.Node{
+				newNode(uintID(0x01), "127.0.0.1:30303"), // not dialed because of bad reputation
+				newNode(uintID(0x02), "127.0.0.1:30303"),
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x02), "127.0.0.1:30303"),
+			},
+		},
+	})
+}
+
 // This test checks that static dials work and obey the limits.
 func TestDialSchedStaticDial(t *testing.T) {
 	t.Parallel()
@@ -670,3 +698,43 @@ func (t *dialTestResolver) Resolve(n *enode.Node) *enode.Node {
 	t.calls = append(t.calls, n.ID())
 	return t.answers[n.ID()]
 }
+
+// This test checks that dual-stack nodes are dialed on their IPv6 address if the
+// IPv4 address is unreachable.
+func TestTCPDialerDualStack(t *testing.T) {
+	ln, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skip("IPv6 not available:", err)
+	}
+	defer ln.Close()
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+
+	// Nothing listens on the IPv4 port.
+	ln4, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port4 := ln4.Addr().(*net.TCPAddr).Port
+	ln4.Close()
+
+	var r enr.Record
+	r.Set(enr.IPv4{127, 0, 0, 1})
+	r.Set(enr.TCP(port4))
+	r.Set(enr.IPv6(net.IPv6loopback))
+	r.Set(enr.TCP6(ln.Addr().(*net.TCPAddr).Port))
+	node := enode.SignNull(&r, enode.ID{1})
+
+	d := tcpDialer{&net.Dialer{Timeout: 5 * time.Second}}
+	conn, err := d.Dial(context.Background(), node)
+	if err != nil {
+		t.Fatal("dial failed:", err)
+	}
+	defer conn.Close()
+	if addr := conn.RemoteAddr().(*net.TCPAddr); !addr.IP.Equal(net.IPv6loopback) {
+		t.Fatalf("connected to %v, want IPv6 address", addr)
+	}
+}
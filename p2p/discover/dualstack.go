@@ -0,0 +1,169 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"net"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+)
+
+// DualStackConn combines an IPv4 and an IPv6 socket into a single UDPConn.
+// Packets are sent on the socket matching the address family of the destination.
+type DualStackConn struct {
+	conn4, conn6 *net.UDPConn
+
+	reads     chan dualStackRead
+	closing   chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+type dualStackRead struct {
+	data []byte
+	addr *net.UDPAddr
+	err  error
+}
+
+// NewDualStackConn creates a connection which reads from both sockets.
+func NewDualStackConn(conn4, conn6 *net.UDPConn) *DualStackConn {
+	c := &DualStackConn{
+		conn4:   conn4,
+		conn6:   conn6,
+		reads:   make(chan dualStackRead),
+		closing: make(chan struct{}),
+	}
+	c.wg.Add(2)
+	go c.readLoop(conn4)
+	go c.readLoop(conn6)
+	return c
+}
+
+func (c *DualStackConn) readLoop(conn *net.UDPConn) {
+	defer c.wg.Done()
+
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := conn.ReadFromUDP(buf)
+		r := dualStackRead{addr: addr, err: err}
+		if err == nil {
+			r.data = common.CopyBytes(buf[:n])
+		}
+		select {
+		case c.reads <- r:
+		case <-c.closing:
+			return
+		}
+		if err != nil && !netutil.IsTemporaryError(err) {
+			return
+		}
+	}
+}
+
+// ReadFromUDP implements UDPConn. It returns the next packet received on either socket.
+func (c *DualStackConn) ReadFromUDP(b []byte) (n int, addr *net.UDPAddr, err error) {
+	select {
+	case r := <-c.reads:
+		if r.err != nil {
+			return 0, nil, r.err
+		}
+		return copy(b, r.data), r.addr, nil
+	case <-c.closing:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+// WriteToUDP implements UDPConn.
+func (c *DualStackConn) WriteToUDP(b []byte, addr *net.UDPAddr) (n int, err error) {
+	if addr.IP.To4() != nil {
+		return c.conn4.WriteToUDP(b, addr)
+	}
+	return c.conn6.WriteToUDP(b, addr)
+}
+
+// LocalAddr implements UDPConn. Like for a dual-stack socket, the address is the
+// unspecified IPv6 address with the port of the IPv4 socket.
+func (c *DualStackConn) LocalAddr() net.Addr {
+	port := c.conn4.LocalAddr().(*net.UDPAddr).Port
+	return &net.UDPAddr{IP: net.IPv6unspecified, Port: port}
+}
+
+// LocalAddr4 returns the local address of the IPv4 socket.
+func (c *DualStackConn) LocalAddr4() *net.UDPAddr {
+	return c.conn4.LocalAddr().(*net.UDPAddr)
+}
+
+// LocalAddr6 returns the local address of the IPv6 socket.
+func (c *DualStackConn) LocalAddr6() *net.UDPAddr {
+	return c.conn6.LocalAddr().(*net.UDPAddr)
+}
+
+// Close closes both sockets.
+func (c *DualStackConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closing)
+		err = c.conn4.Close()
+		if err6 := c.conn6.Close(); err == nil {
+			err = err6
+		}
+		c.wg.Wait()
+	})
+	return err
+}
+
+// ipFamilies is the set of address families that can be reached from a socket.
+type ipFamilies struct {
+	ip4, ip6 bool
+}
+
+// connFamilies returns the address families that can be reached through c. A socket
+// bound to the unspecified IPv6 address is assumed to be a dual-stack socket.
+func connFamilies(c UDPConn) ipFamilies {
+	addr, ok := c.LocalAddr().(*net.UDPAddr)
+	switch {
+	case !ok || addr.IP == nil || addr.IP.Equal(net.IPv6unspecified):
+		return ipFamilies{ip4: true, ip6: true}
+	case addr.IP.To4() != nil:
+		return ipFamilies{ip4: true}
+	default:
+		return ipFamilies{ip6: true}
+	}
+}
+
+// familyOf returns the address family of ip.
+func familyOf(ip net.IP) ipFamilies {
+	if ip.To4() != nil {
+		return ipFamilies{ip4: true}
+	}
+	return ipFamilies{ip6: true}
+}
+
+// endpoint returns the UDP endpoint on which n is contacted. IPv4 is preferred if the
+// node has addresses of both families.
+func (f ipFamilies) endpoint(n *enode.Node) *net.UDPAddr {
+	if ip := n.IPv4(); ip != nil && f.ip4 {
+		return &net.UDPAddr{IP: ip, Port: n.UDP()}
+	}
+	if ip := n.IPv6(); ip != nil && f.ip6 {
+		return &net.UDPAddr{IP: ip, Port: n.UDP6()}
+	}
+	return &net.UDPAddr{IP: n.IP(), Port: n.UDP()}
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+func TestDualStackConn(t *testing.T) {
+	conn4, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn6, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.IPv6loopback})
+	if err != nil {
+		conn4.Close()
+		t.Skip("IPv6 not available:", err)
+	}
+	c := NewDualStackConn(conn4, conn6)
+	defer c.Close()
+
+	// Packets to both families are sent on the matching socket and received.
+	for _, network := range []string{"udp4", "udp6"} {
+		remote, err := net.ListenUDP(network, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer remote.Close()
+		var local *net.UDPAddr
+		if network == "udp4" {
+			local = c.LocalAddr4()
+		} else {
+			local = c.LocalAddr6()
+		}
+		if _, err := remote.WriteToUDP([]byte(network), local); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 100)
+		n, from, err := c.ReadFromUDP(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf[:n]) != network {
+			t.Fatalf("wrong packet %q", buf[:n])
+		}
+		if _, err := c.WriteToUDP([]byte("reply"), from); err != nil {
+			t.Fatalf("%s: write failed: %v", network, err)
+		}
+		remote.SetReadDeadline(time.Now().Add(time.Second))
+		if n, _, err := remote.ReadFromUDP(buf); err != nil || string(buf[:n]) != "reply" {
+			t.Fatalf("%s: reply not received: %v", network, err)
+		}
+	}
+
+	if fam := connFamilies(c); !fam.ip4 || !fam.ip6 {
+		t.Fatalf("wrong families of dual-stack conn: %+v", fam)
+	}
+	c.Close()
+	if _, _, err := c.ReadFromUDP(make([]byte, 10)); err == nil {
+		t.Fatal("read on closed conn succeeded")
+	}
+}
+
+func TestNodeEndpointFamily(t *testing.T) {
+	var r enr.Record
+	r.Set(enr.IPv4{1, 2, 3, 4})
+	r.Set(enr.IPv6(net.ParseIP("2001:db8::1")))
+	r.Set(enr.UDP(30303))
+	r.Set(enr.UDP6(30304))
+	n := enode.SignNull(&r, enode.ID{1})
+
+	tests := []struct {
+		fam  ipFamilies
+		want string
+	}{
+		{ipFamilies{ip4: true, ip6: true}, "1.2.3.4:30303"},
+		{ipFamilies{ip4: true}, "1.2.3.4:30303"},
+		{ipFamilies{ip6: true}, "[2001:db8::1]:30304"},
+	}
+	for _, test := range tests {
+		if addr := test.fam.endpoint(n); addr.String() != test.want {
+			t.Errorf("%+v: wrong endpoint %v, want %s", test.fam, addr, test.want)
+		}
+	}
+}
+
+// This test checks that v4 discovery works between an IPv6-only node and a dual-stack
+// node which prefers IPv4.
+func TestUDPv4_DualStack(t *testing.T) {
+	conn4, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := conn4.LocalAddr().(*net.UDPAddr).Port
+	conn6, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.IPv6loopback, Port: port})
+	if err != nil {
+		conn4.Close()
+		t.Skip("IPv6 not available:", err)
+	}
+	dual := listenTestV4(t, NewDualStackConn(conn4, conn6), net.IPv4(127, 0, 0, 1), net.IPv6loopback)
+	self := dual.Self()
+	if self.IPv4() == nil || self.IPv6() == nil {
+		t.Fatalf("dual-stack node record lacks an address: %v", self.Record())
+	}
+
+	only6, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.IPv6loopback})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node6 := listenTestV4(t, only6, net.IPv6loopback)
+
+	// The IPv6-only node reaches the dual-stack node on its IPv6 address,
+	// and gets replies from the IPv6 socket.
+	if err := node6.Ping(self); err != nil {
+		t.Fatal("ping from IPv6-only node failed:", err)
+	}
+	if _, err := node6.RequestENR(self); err != nil {
+		t.Fatal("ENR request from IPv6-only node failed:", err)
+	}
+	// The dual-stack node can reach it as well.
+	if err := dual.Ping(node6.Self()); err != nil {
+		t.Fatal("ping from dual-stack node failed:", err)
+	}
+}
+
+func listenTestV4(t *testing.T, conn UDPConn, ips ...net.IP) *UDPv4 {
+	t.Helper()
+
+	cfg := Config{PrivateKey: newkey(), Log: testlog.Logger(t, log.LvlTrace)}
+	db, _ := enode.OpenDB("")
+	ln := enode.NewLocalNode(db, cfg.PrivateKey)
+	for _, ip := range ips {
+		ln.SetStaticIP(ip)
+	}
+	ln.SetFallbackUDP(conn.LocalAddr().(*net.UDPAddr).Port)
+	udp, err := ListenV4(conn, ln, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		udp.Close()
+		db.Close()
+	})
+	return udp
+}
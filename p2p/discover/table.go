@@ -53,6 +53,7 @@ const (
 	// IP address limits.
 	bucketIPLimit, bucketSubnet = 2, 24 // at most 2 addresses from the same /24
 	tableIPLimit, tableSubnet   = 10, 24
+	bucketSubnet6, tableSubnet6 = 48, 48 // IPv6 networks are limited per /48
 
 	copyNodesInterval = 30 * time.Second
 	seedMinTableTime  = 5 * time.Minute
@@ -115,7 +116,7 @@ func newTable(t transport, db *enode.DB, cfg Config) (*Table, error) {
 		closeReq:   make(chan struct{}),
 		closed:     make(chan struct{}),
 		rand:       mrand.New(mrand.NewSource(0)),
-		ips:        netutil.DistinctNetSet{Subnet: tableSubnet, Limit: tableIPLimit},
+		ips:        netutil.DistinctNetSet{Subnet: tableSubnet, Limit: tableIPLimit, Subnet6: tableSubnet6},
 	}
 	if err := tab.setFallbackNodes(cfg.Bootnodes); err != nil {
 		return nil, err
@@ -123,7 +124,7 @@ func newTable(t transport, db *enode.DB, cfg Config) (*Table, error) {
 	for i := range tab.buckets {
 		tab.buckets[i] = &bucket{
 			index: i,
-			ips:   netutil.DistinctNetSet{Subnet: bucketSubnet, Limit: bucketIPLimit},
+			ips:   netutil.DistinctNetSet{Subnet: bucketSubnet, Limit: bucketIPLimit, Subnet6: bucketSubnet6},
 		}
 	}
 	tab.seedRand()
@@ -510,7 +511,7 @@ func (tab *Table) addSeenNode(n *node) {
 		tab.addReplacement(b, n)
 		return
 	}
-	if !tab.addIP(b, n.IP()) {
+	if !tab.addIP(b, n) {
 		// Can't add: IP limit reached.
 		return
 	}
@@ -554,7 +555,7 @@ func (tab *Table) addVerifiedNode(n *node) {
 		tab.addReplacement(b, n)
 		return
 	}
-	if !tab.addIP(b, n.IP()) {
+	if !tab.addIP(b, n) {
 		// Can't add: IP limit reached.
 		return
 	}
@@ -577,10 +578,36 @@ func (tab *Table) delete(node *node) {
 	tab.deleteInBucket(tab.bucket(node.ID()), node)
 }
 
-func (tab *Table) addIP(b *bucket, ip net.IP) bool {
-	if len(ip) == 0 {
+// addIP adds the IP addresses of a node to the table and bucket IP limits. The
+// addresses of dual-stack nodes count towards the limits of both address families.
+func (tab *Table) addIP(b *bucket, n *node) bool {
+	ip4, ip6 := n.IPv4(), n.IPv6()
+	if ip4 == nil && ip6 == nil {
 		return false // Nodes without IP cannot be added.
 	}
+	if ip4 != nil && !tab.addAddr(b, ip4) {
+		return false
+	}
+	if ip6 != nil && !tab.addAddr(b, ip6) {
+		if ip4 != nil {
+			tab.removeAddr(b, ip4)
+		}
+		return false
+	}
+	return true
+}
+
+// removeIP removes the IP addresses of a node from the table and bucket IP limits.
+func (tab *Table) removeIP(b *bucket, n *node) {
+	if ip4 := n.IPv4(); ip4 != nil {
+		tab.removeAddr(b, ip4)
+	}
+	if ip6 := n.IPv6(); ip6 != nil {
+		tab.removeAddr(b, ip6)
+	}
+}
+
+func (tab *Table) addAddr(b *bucket, ip net.IP) bool {
 	if netutil.IsLAN(ip) {
 		return true
 	}
@@ -596,7 +623,7 @@ func (tab *Table) addIP(b *bucket, ip net.IP) bool {
 	return true
 }
 
-func (tab *Table) removeIP(b *bucket, ip net.IP) {
+func (tab *Table) removeAddr(b *bucket, ip net.IP) {
 	if netutil.IsLAN(ip) {
 		return
 	}
@@ -610,13 +637,13 @@ func (tab *Table) addReplacement(b *bucket, n *node) {
 			return // already in list
 		}
 	}
-	if !tab.addIP(b, n.IP()) {
+	if !tab.addIP(b, n) {
 		return
 	}
 	var removed *node
 	b.replacements, removed = pushNode(b.replacements, n, maxReplacements)
 	if removed != nil {
-		tab.removeIP(b, removed.IP())
+		tab.removeIP(b, removed)
 	}
 }
 
@@ -636,7 +663,7 @@ func (tab *Table) replace(b *bucket, last *node) *node {
 	r := b.replacements[tab.rand.Intn(len(b.replacements))]
 	b.replacements = deleteNode(b.replacements, r)
 	b.entries[len(b.entries)-1] = r
-	tab.removeIP(b, last.IP())
+	tab.removeIP(b, last)
 	return r
 }
 
@@ -645,12 +672,13 @@ func (tab *Table) replace(b *bucket, last *node) *node {
 func (tab *Table) bumpInBucket(b *bucket, n *node) bool {
 	for i := range b.entries {
 		if b.entries[i].ID() == n.ID() {
-			if !n.IP().Equal(b.entries[i].IP()) {
+			old := b.entries[i]
+			if !n.IPv4().Equal(old.IPv4()) || !n.IPv6().Equal(old.IPv6()) {
 				// Endpoint has changed, ensure that the new IP fits into table limits.
-				tab.removeIP(b, b.entries[i].IP())
-				if !tab.addIP(b, n.IP()) {
+				tab.removeIP(b, old)
+				if !tab.addIP(b, n) {
 					// It doesn't, put the previous one back.
-					tab.addIP(b, b.entries[i].IP())
+					tab.addIP(b, old)
 					return false
 				}
 			}
@@ -670,7 +698,7 @@ func (tab *Table) deleteInBucket(b *bucket, n *node) {
 		return
 	}
 	b.entries = deleteNode(b.entries, n)
-	tab.removeIP(b, n.IP())
+	tab.removeIP(b, n)
 	if tab.nodeRemovedHook != nil {
 		tab.nodeRemovedHook(b, n)
 	}
@@ -174,15 +174,52 @@ func TestTable_BucketIPLimit(t *testing.T) {
 	checkIPLimitInvariant(t, tab)
 }
 
+// This checks that IPv6 addresses are limited per /48 and that dual-stack
+// nodes count towards the limits of both address families.
+func TestTable_IPLimitDualStack(t *testing.T) {
+	transport := newPingRecorder()
+	tab, db := newTestTable(transport)
+	defer db.Close()
+	defer tab.close()
+
+	d := 250
+	for i := 0; i < bucketIPLimit+1; i++ {
+		ip := net.ParseIP(fmt.Sprintf("2001:db8:1:%x::1", i))
+		tab.addSeenNode(nodeAtDistance(tab.self().ID(), d, ip))
+	}
+	if tab.len() != bucketIPLimit {
+		t.Fatalf("wrong number of IPv6 nodes in bucket: %d", tab.len())
+	}
+	checkIPLimitInvariant(t, tab)
+
+	// The IPv4 address of a dual-stack node is fine, but its IPv6 address is
+	// from the same /48.
+	var r enr.Record
+	r.Set(enr.IPv4{1, 2, 3, 4})
+	r.Set(enr.IPv6(net.ParseIP("2001:db8:1:ff::1")))
+	r.Set(enr.UDP(30303))
+	n := wrapNode(enode.SignNull(&r, idAtDistance(tab.self().ID(), d)))
+	tab.addSeenNode(n)
+	if contains(tab.bucket(n.ID()).entries, n.ID()) {
+		t.Fatal("dual-stack node exceeding the IPv6 limit was added")
+	}
+	checkIPLimitInvariant(t, tab)
+}
+
 // checkIPLimitInvariant checks that ip limit sets contain an entry for every
 // node in the table and no extra entries.
 func checkIPLimitInvariant(t *testing.T, tab *Table) {
 	t.Helper()
 
-	tabset := netutil.DistinctNetSet{Subnet: tableSubnet, Limit: tableIPLimit}
+	tabset := netutil.DistinctNetSet{Subnet: tableSubnet, Limit: tableIPLimit, Subnet6: tableSubnet6}
 	for _, b := range tab.buckets {
 		for _, n := range b.entries {
-			tabset.Add(n.IP())
+			if ip := n.IPv4(); ip != nil {
+				tabset.Add(ip)
+			}
+			if ip := n.IPv6(); ip != nil {
+				tabset.Add(ip)
+			}
 		}
 	}
 	if tabset.String() != tab.ips.String() {
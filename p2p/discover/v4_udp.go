@@ -66,6 +66,7 @@ const (
 // UDPv4 implements the v4 wire protocol.
 type UDPv4 struct {
 	conn        UDPConn
+	families    ipFamilies
 	log         log.Logger
 	netrestrict *netutil.Netlist
 	priv        *ecdsa.PrivateKey
@@ -131,6 +132,7 @@ func ListenV4(c UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv4, error) {
 	closeCtx, cancel := context.WithCancel(context.Background())
 	t := &UDPv4{
 		conn:            newMeteredConn(c),
+		families:        connFamilies(c),
 		priv:            cfg.PrivateKey,
 		netrestrict:     cfg.NetRestrict,
 		localNode:       ln,
@@ -214,7 +216,7 @@ func (t *UDPv4) Ping(n *enode.Node) error {
 
 // ping sends a ping message to the given node and waits for a reply.
 func (t *UDPv4) ping(n *enode.Node) (seq uint64, err error) {
-	rm := t.sendPing(n.ID(), &net.UDPAddr{IP: n.IP(), Port: n.UDP()}, nil)
+	rm := t.sendPing(n.ID(), t.families.endpoint(n), nil)
 	if err = <-rm.errc; err == nil {
 		seq = rm.reply.(*v4wire.Pong).ENRSeq
 	}
@@ -291,7 +293,7 @@ func (t *UDPv4) newLookup(ctx context.Context, targetKey encPubkey) *lookup {
 	target := enode.ID(crypto.Keccak256Hash(targetKey[:]))
 	ekey := v4wire.Pubkey(targetKey)
 	it := newLookup(ctx, t.tab, target, func(n *node) ([]*node, error) {
-		return t.findnode(n.ID(), n.addr(), ekey)
+		return t.findnode(n.ID(), t.families.endpoint(&n.Node), ekey)
 	})
 	return it
 }
@@ -336,7 +338,7 @@ func (t *UDPv4) findnode(toid enode.ID, toaddr *net.UDPAddr, target v4wire.Pubke
 
 // RequestENR sends ENRRequest to the given node and waits for a response.
 func (t *UDPv4) RequestENR(n *enode.Node) (*enode.Node, error) {
-	addr := &net.UDPAddr{IP: n.IP(), Port: n.UDP()}
+	addr := t.families.endpoint(n)
 	t.ensureBond(n.ID(), addr)
 
 	req := &v4wire.ENRRequest{
@@ -594,13 +596,18 @@ func (t *UDPv4) nodeFromRPC(sender *net.UDPAddr, rn v4wire.Node) (*node, error)
 	return n, err
 }
 
-func nodeToRPC(n *node) v4wire.Node {
+// nodeToRPC converts a node to its wire representation with the given endpoint.
+func nodeToRPC(n *node, addr *net.UDPAddr) v4wire.Node {
 	var key ecdsa.PublicKey
 	var ekey v4wire.Pubkey
 	if err := n.Load((*enode.Secp256k1)(&key)); err == nil {
 		ekey = v4wire.EncodePubkey(&key)
 	}
-	return v4wire.Node{ID: ekey, IP: n.IP(), UDP: uint16(n.UDP()), TCP: uint16(n.TCP())}
+	tcp := n.TCP()
+	if addr.IP.To4() == nil {
+		tcp = n.TCP6()
+	}
+	return v4wire.Node{ID: ekey, IP: addr.IP, UDP: uint16(addr.Port), TCP: uint16(tcp)}
 }
 
 // wrapPacket returns the handler functions applicable to a packet.
@@ -726,10 +733,15 @@ func (t *UDPv4) handleFindnode(h *packetHandlerV4, from *net.UDPAddr, fromID eno
 	// Send neighbors in chunks with at most maxNeighbors per packet
 	// to stay below the packet size limit.
 	p := v4wire.Neighbors{Expiration: uint64(time.Now().Add(expiration).Unix())}
-	var sent bool
+	// The nodes are sent with their endpoint in the address family of the requester.
+	var (
+		sent   bool
+		family = familyOf(from.IP)
+	)
 	for _, n := range closest {
-		if netutil.CheckRelayIP(from.IP, n.IP()) == nil {
-			p.Nodes = append(p.Nodes, nodeToRPC(n))
+		addr := family.endpoint(&n.Node)
+		if netutil.CheckRelayIP(from.IP, addr.IP) == nil {
+			p.Nodes = append(p.Nodes, nodeToRPC(n, addr))
 		}
 		if len(p.Nodes) == v4wire.MaxNeighbors {
 			t.send(from, fromID, &p)
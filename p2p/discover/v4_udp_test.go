@@ -340,7 +340,7 @@ func TestUDPv4_findnodeMultiReply(t *testing.T) {
 	}
 	rpclist := make([]v4wire.Node, len(list))
 	for i := range list {
-		rpclist[i] = nodeToRPC(list[i])
+		rpclist[i] = nodeToRPC(list[i], list[i].addr())
 	}
 	test.packetIn(nil, &v4wire.Neighbors{Expiration: futureExp, Nodes: rpclist[:2]})
 	test.packetIn(nil, &v4wire.Neighbors{Expiration: futureExp, Nodes: rpclist[2:]})
@@ -0,0 +1,611 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	topicAdLifetime    = 15 * time.Minute // lifetime of a topic advertisement
+	topicQueueLimit    = 100              // max number of advertisements per topic
+	topicTableLimit    = 10000            // max number of advertisements across all topics
+	topicTicketWindow  = 10 * time.Second // time a ticket stays usable after its wait time
+	topicMaxTicketWait = topicAdLifetime  // registrants give up on tickets with longer waits
+
+	topicQueueCountLimit  = 1000 // max number of topics with advertisements or reservations
+	topicNodeReserveLimit = 5    // max number of reservations held by a node across all topics
+	topicIPReserveLimit   = 10   // max number of reservations held by the nodes of a subnet
+
+	topicRegisterAttempts = 5                // max number of tickets followed per registrar
+	topicRegisterInterval = 10 * time.Minute // re-registration interval, below the ad lifetime
+	topicRegisterRetry    = 30 * time.Second // retry interval if no registration succeeded
+	topicQueryInterval    = 30 * time.Second // delay between topic search rounds
+)
+
+var errInvalidTicket = errors.New("invalid ticket")
+
+// TopicID identifies a topic nodes can advertise themselves for.
+type TopicID [32]byte
+
+// NewTopic returns the identifier of the topic with the given name.
+func NewTopic(name string) TopicID {
+	return TopicID(crypto.Keccak256Hash([]byte(name)))
+}
+
+// topicAd is an advertisement of a node for a topic.
+type topicAd struct {
+	node    *enode.Node
+	expires mclock.AbsTime
+}
+
+// topicReservation is a slot of a topic queue reserved for the holder of a ticket.
+type topicReservation struct {
+	node   enode.ID
+	ip     net.IP
+	issued mclock.AbsTime
+	wait   time.Duration
+}
+
+// end returns the time after which the ticket of the reservation is not usable
+// anymore, and the slot is released.
+func (r *topicReservation) end() mclock.AbsTime {
+	return r.issued.Add(r.wait + topicTicketWindow)
+}
+
+// topicQueue holds the advertisements of a single topic, ordered by expiry, and
+// the slots reserved for the holders of outstanding tickets.
+type topicQueue struct {
+	ads      []topicAd
+	reserved map[uint64]*topicReservation // ticket nonce -> reservation
+}
+
+// topicTicket is the content of a ticket handed out by a registrar. Tickets are
+// opaque to registrants and authenticated by the registrar.
+type topicTicket struct {
+	Topic  TopicID
+	Node   enode.ID
+	IP     net.IP
+	Nonce  uint64
+	Issued uint64 // mclock.AbsTime of issuance
+	Wait   uint64 // time.Duration the registrant has to wait
+}
+
+// topicTable is the registrar side of topic advertisement. It stores the nodes
+// registered for topics and hands out tickets when the topic queue or the table
+// is full. A ticket reserves a slot freed up at the end of its wait time.
+//
+// Reservations are limited per node and per subnet, so registrants can't claim
+// the slots of a topic queue in advance.
+//
+// The table is only accessed by the dispatch loop of UDPv5 and not thread-safe.
+type topicTable struct {
+	clock  mclock.Clock
+	key    []byte // ticket authentication key
+	nonce  uint64
+	queues map[TopicID]*topicQueue
+	count  int // number of advertisements across all topics
+
+	reservedNodes map[enode.ID]int       // number of reservations per node
+	reservedIPs   netutil.DistinctNetSet // IPs of the reservation holders
+}
+
+func newTopicTable(clock mclock.Clock) *topicTable {
+	key := make([]byte, 32)
+	crand.Read(key)
+	return &topicTable{
+		clock:         clock,
+		key:           key,
+		queues:        make(map[TopicID]*topicQueue),
+		reservedNodes: make(map[enode.ID]int),
+		reservedIPs:   netutil.DistinctNetSet{Subnet: tableSubnet, Limit: topicIPReserveLimit, Subnet6: tableSubnet6},
+	}
+}
+
+// register attempts to add an advertisement of the node for the topic. If there
+// is no space, a ticket is returned along with the time to wait before retrying
+// with it. Registrants presenting a ticket get the slot reserved for them.
+//
+// If no slot can be reserved, the registrant is asked to retry after the wait
+// time without a ticket.
+func (tt *topicTable) register(node *enode.Node, ip net.IP, topic TopicID, ticket []byte) ([]byte, time.Duration, bool) {
+	tt.expire()
+
+	now := tt.clock.Now()
+	queue := tt.queues[topic]
+	if queue == nil {
+		if len(tt.queues) >= topicQueueCountLimit {
+			return nil, topicAdLifetime, false
+		}
+		queue = &topicQueue{reserved: make(map[uint64]*topicReservation)}
+		tt.queues[topic] = queue
+	}
+	// Refresh the advertisement if the node is registered already
+	for i, ad := range queue.ads {
+		if ad.node.ID() == node.ID() {
+			queue.ads = append(queue.ads[:i], queue.ads[i+1:]...)
+			queue.ads = append(queue.ads, topicAd{node: node, expires: now.Add(topicAdLifetime)})
+			return nil, 0, true
+		}
+	}
+	// Redeem the ticket, claiming the slot reserved for it. Tickets which are not
+	// valid or not reserved anymore are ignored.
+	var redeemed bool
+	if len(ticket) > 0 {
+		if t, err := tt.decodeTicket(ticket); err == nil && t.Topic == topic && t.Node == node.ID() && t.IP.Equal(ip) {
+			if ready := mclock.AbsTime(t.Issued).Add(time.Duration(t.Wait)); now < ready {
+				// Too early, remind the registrant of the remaining wait
+				return ticket, time.Duration(ready - now), false
+			}
+			if _, redeemed = queue.reserved[t.Nonce]; redeemed {
+				tt.release(queue, t.Nonce)
+			}
+		}
+	}
+	space := len(queue.ads) < topicQueueLimit && tt.count < topicTableLimit
+	if !redeemed {
+		space = space && len(queue.ads)+len(queue.reserved) < topicQueueLimit
+	}
+	if space {
+		queue.ads = append(queue.ads, topicAd{node: node, expires: now.Add(topicAdLifetime)})
+		tt.count++
+		return nil, 0, true
+	}
+	// No space. Nodes asking again before their ticket is due get the ticket of
+	// the slot reserved for them already.
+	for nonce, r := range queue.reserved {
+		if r.node == node.ID() {
+			wait := time.Duration(r.issued.Add(r.wait) - now)
+			if wait < time.Second {
+				wait = time.Second
+			}
+			return tt.encodeTicket(&topicTicket{
+				Topic:  topic,
+				Node:   r.node,
+				IP:     r.ip,
+				Nonce:  nonce,
+				Issued: uint64(r.issued),
+				Wait:   uint64(r.wait),
+			}), wait, false
+		}
+	}
+	// Reserve the next slot freed up and hand out a ticket for it, unless the
+	// registrant holds too many reservations already.
+	if len(queue.reserved) >= topicQueueLimit || tt.reservedNodes[node.ID()] >= topicNodeReserveLimit {
+		return nil, topicAdLifetime, false
+	}
+	if !tt.reservedIPs.Add(ip) {
+		return nil, topicAdLifetime, false
+	}
+	wait := topicAdLifetime
+	if n := len(queue.reserved); n < len(queue.ads) {
+		wait = time.Duration(queue.ads[n].expires - now)
+	}
+	if tt.count >= topicTableLimit {
+		if oldest := tt.oldest(); time.Duration(oldest-now) > wait {
+			wait = time.Duration(oldest - now)
+		}
+	}
+	if wait < time.Second {
+		wait = time.Second
+	}
+	tt.nonce++
+	queue.reserved[tt.nonce] = &topicReservation{node: node.ID(), ip: ip, issued: now, wait: wait}
+	tt.reservedNodes[node.ID()]++
+
+	return tt.encodeTicket(&topicTicket{
+		Topic:  topic,
+		Node:   node.ID(),
+		IP:     ip,
+		Nonce:  tt.nonce,
+		Issued: uint64(now),
+		Wait:   uint64(wait),
+	}), wait, false
+}
+
+// release drops a reservation from the queue.
+func (tt *topicTable) release(queue *topicQueue, nonce uint64) {
+	r := queue.reserved[nonce]
+	delete(queue.reserved, nonce)
+
+	tt.reservedIPs.Remove(r.ip)
+	if tt.reservedNodes[r.node]--; tt.reservedNodes[r.node] <= 0 {
+		delete(tt.reservedNodes, r.node)
+	}
+}
+
+// nodes returns up to limit nodes registered for the topic, randomly chosen if
+// more are available.
+func (tt *topicTable) nodes(topic TopicID, limit int) []*enode.Node {
+	tt.expire()
+
+	queue := tt.queues[topic]
+	if queue == nil {
+		return nil
+	}
+	nodes := make([]*enode.Node, len(queue.ads))
+	for i, ad := range queue.ads {
+		nodes[i] = ad.node
+	}
+	if len(nodes) > limit {
+		rand.Shuffle(len(nodes), func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+		nodes = nodes[:limit]
+	}
+	return nodes
+}
+
+// expire drops all advertisements and reservations which exceeded their lifetime.
+func (tt *topicTable) expire() {
+	now := tt.clock.Now()
+	for topic, queue := range tt.queues {
+		n := 0
+		for n < len(queue.ads) && queue.ads[n].expires <= now {
+			n++
+		}
+		queue.ads = queue.ads[n:]
+		tt.count -= n
+
+		for nonce, r := range queue.reserved {
+			if r.end() <= now {
+				tt.release(queue, nonce)
+			}
+		}
+		if len(queue.ads) == 0 && len(queue.reserved) == 0 {
+			delete(tt.queues, topic)
+		}
+	}
+}
+
+// oldest returns the expiry time of the oldest advertisement in the table.
+func (tt *topicTable) oldest() mclock.AbsTime {
+	oldest := mclock.AbsTime(math.MaxInt64)
+	for _, queue := range tt.queues {
+		if len(queue.ads) > 0 && queue.ads[0].expires < oldest {
+			oldest = queue.ads[0].expires
+		}
+	}
+	return oldest
+}
+
+// encodeTicket serializes and authenticates a ticket.
+func (tt *topicTable) encodeTicket(t *topicTicket) []byte {
+	blob, _ := rlp.EncodeToBytes(t)
+	mac := hmac.New(sha256.New, tt.key)
+	mac.Write(blob)
+	return mac.Sum(blob)
+}
+
+// decodeTicket authenticates and deserializes a ticket.
+func (tt *topicTable) decodeTicket(ticket []byte) (*topicTicket, error) {
+	if len(ticket) <= sha256.Size {
+		return nil, errInvalidTicket
+	}
+	blob, sum := ticket[:len(ticket)-sha256.Size], ticket[len(ticket)-sha256.Size:]
+	mac := hmac.New(sha256.New, tt.key)
+	mac.Write(blob)
+	if !hmac.Equal(mac.Sum(nil), sum) {
+		return nil, errInvalidTicket
+	}
+	t := new(topicTicket)
+	if err := rlp.DecodeBytes(blob, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ticketWaitSeconds converts a ticket wait time into the wire representation,
+// rounding up to make sure registrants don't show up too early.
+func ticketWaitSeconds(wait time.Duration) uint {
+	return uint((wait + time.Second - 1) / time.Second)
+}
+
+// handleRegtopic admits the sender into the topic table or hands out a ticket.
+func (t *UDPv5) handleRegtopic(p *v5wire.Regtopic, fromID enode.ID, fromAddr *net.UDPAddr) {
+	if p.ENR == nil {
+		t.log.Debug("Invalid "+p.Name(), "id", fromID, "addr", fromAddr, "err", "missing record")
+		return
+	}
+	node, err := enode.New(t.validSchemes, p.ENR)
+	if err == nil && node.ID() != fromID {
+		err = errors.New("record of different node")
+	}
+	if err == nil && t.netrestrict != nil && !t.netrestrict.Contains(node.IP()) {
+		err = errors.New("not contained in netrestrict list")
+	}
+	if err != nil {
+		t.log.Debug("Invalid "+p.Name(), "id", fromID, "addr", fromAddr, "err", err)
+		return
+	}
+	ticket, wait, ok := t.topicTable.register(node, fromAddr.IP, TopicID(p.Topic), p.Ticket)
+	t.sendResponse(fromID, fromAddr, &v5wire.Ticket{
+		ReqID:    p.ReqID,
+		Ticket:   ticket,
+		WaitTime: ticketWaitSeconds(wait),
+	})
+	if ok {
+		t.sendResponse(fromID, fromAddr, &v5wire.Regconfirmation{ReqID: p.ReqID, Topic: p.Topic})
+	}
+}
+
+// handleTopicQuery returns the nodes registered for a topic to the requester.
+func (t *UDPv5) handleTopicQuery(p *v5wire.TopicQuery, fromID enode.ID, fromAddr *net.UDPAddr) {
+	var nodes []*enode.Node
+	for _, n := range t.topicTable.nodes(TopicID(p.Topic), findnodeResultLimit) {
+		if n.ID() != fromID && netutil.CheckRelayIP(fromAddr.IP, familyOf(fromAddr.IP).endpoint(n).IP) == nil {
+			nodes = append(nodes, n)
+		}
+	}
+	for _, resp := range packNodes(p.ReqID, nodes) {
+		t.sendResponse(fromID, fromAddr, resp)
+	}
+}
+
+// regtopic calls REGTOPIC on a node and waits for the ticket and the optional
+// confirmation. It returns the ticket and wait time if the registration has to
+// be retried later.
+func (t *UDPv5) regtopic(n *enode.Node, topic TopicID, ticket []byte) ([]byte, time.Duration, bool, error) {
+	req := &v5wire.Regtopic{Topic: topic, ENR: t.localNode.Node().Record(), Ticket: ticket}
+	resp := t.callToNode(n, v5wire.TicketMsg, req)
+	defer t.callDone(resp)
+
+	for {
+		select {
+		case p := <-resp.ch:
+			switch p := p.(type) {
+			case *v5wire.Ticket:
+				if p.WaitTime > 0 {
+					return p.Ticket, time.Duration(p.WaitTime) * time.Second, false, nil
+				}
+				// Registered, the confirmation follows
+			case *v5wire.Regconfirmation:
+				return nil, 0, p.Topic == topic, nil
+			}
+		case err := <-resp.err:
+			return nil, 0, false, err
+		}
+	}
+}
+
+// topicQuery calls TOPICQUERY on a node and waits for the registered nodes.
+func (t *UDPv5) topicQuery(n *enode.Node, topic TopicID) ([]*enode.Node, error) {
+	resp := t.callToNode(n, v5wire.NodesMsg, &v5wire.TopicQuery{Topic: topic})
+	return t.waitForNodes(resp, nil)
+}
+
+// RegisterTopic starts advertising the local node for the given topic. The node
+// is registered at the nodes closest to the topic identifier and re-registered
+// periodically, until StopRegisterTopic is called or the transport is closed.
+func (t *UDPv5) RegisterTopic(topic TopicID) {
+	t.topicMu.Lock()
+	defer t.topicMu.Unlock()
+
+	if _, ok := t.topicRegs[topic]; ok || t.closeCtx.Err() != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(t.closeCtx)
+	t.topicRegs[topic] = cancel
+
+	t.wg.Add(1)
+	go t.topicRegisterLoop(ctx, topic)
+}
+
+// StopRegisterTopic stops advertising the local node for the given topic. The
+// existing advertisements expire on their own.
+func (t *UDPv5) StopRegisterTopic(topic TopicID) {
+	t.topicMu.Lock()
+	defer t.topicMu.Unlock()
+
+	if cancel, ok := t.topicRegs[topic]; ok {
+		cancel()
+		delete(t.topicRegs, topic)
+	}
+}
+
+// topicRegisterLoop registers the local node for a topic at the nodes closest to
+// the topic, repeating the registration before the advertisements expire.
+func (t *UDPv5) topicRegisterLoop(ctx context.Context, topic TopicID) {
+	defer t.wg.Done()
+
+	for {
+		var (
+			start      = t.clock.Now()
+			registrars = t.newLookup(ctx, enode.ID(topic)).run()
+			registered atomic.Int32
+			pend       sync.WaitGroup
+		)
+		for _, n := range registrars {
+			pend.Add(1)
+			go func(n *enode.Node) {
+				defer pend.Done()
+				if t.registerAt(ctx, n, topic) {
+					registered.Add(1)
+				}
+			}(n)
+		}
+		pend.Wait()
+		t.log.Debug("Registered topic", "topic", hexutil.Bytes(topic[:]), "registrars", len(registrars), "registered", registered.Load())
+
+		interval := topicRegisterInterval
+		if registered.Load() == 0 {
+			interval = topicRegisterRetry
+		}
+		if wait := interval - time.Duration(t.clock.Now()-start); wait > 0 {
+			select {
+			case <-t.clock.After(wait):
+			case <-ctx.Done():
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// registerAt registers the local node for a topic at the given registrar. Tickets
+// handed out are waited for and redeemed, until admitted or giving up.
+func (t *UDPv5) registerAt(ctx context.Context, n *enode.Node, topic TopicID) bool {
+	var ticket []byte
+	for i := 0; i < topicRegisterAttempts; i++ {
+		next, wait, ok, err := t.regtopic(n, topic, ticket)
+		if err != nil || ok {
+			return ok
+		}
+		if wait > topicMaxTicketWait {
+			return false
+		}
+		ticket = next
+
+		select {
+		case <-t.clock.After(wait):
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return false
+}
+
+// TopicNodes returns an iterator over the nodes registered for the given topic.
+// The iterator repeatedly queries the nodes closest to the topic identifier and
+// returns every registered node once.
+func (t *UDPv5) TopicNodes(topic TopicID) enode.Iterator {
+	ctx, cancel := context.WithCancel(t.closeCtx)
+	return &topicIterator{
+		t:      t,
+		topic:  topic,
+		ctx:    ctx,
+		cancel: cancel,
+		seen:   make(map[enode.ID]struct{}),
+	}
+}
+
+// topicIterator performs lookups towards a topic, sending TOPICQUERY to all nodes
+// encountered along the way, and iterates over the registered nodes found.
+type topicIterator struct {
+	t      *UDPv5
+	topic  TopicID
+	ctx    context.Context
+	cancel context.CancelFunc
+	lookup *lookup
+	rounds int
+
+	lock  sync.Mutex
+	found []*enode.Node // results of topic queries not yet consumed
+
+	seen   map[enode.ID]struct{}
+	buffer []*enode.Node
+}
+
+// Node returns the current node.
+func (it *topicIterator) Node() *enode.Node {
+	if len(it.buffer) == 0 {
+		return nil
+	}
+	return it.buffer[0]
+}
+
+// Next moves to the next node.
+func (it *topicIterator) Next() bool {
+	// Consume next node in buffer.
+	if len(it.buffer) > 0 {
+		it.buffer = it.buffer[1:]
+	}
+	// Advance the lookups to refill the buffer.
+	for len(it.buffer) == 0 {
+		if it.ctx.Err() != nil {
+			it.lookup = nil
+			it.buffer = nil
+			return false
+		}
+		if it.collect() {
+			break
+		}
+		if it.lookup == nil {
+			// Rate limit the search rounds, registrations don't change quickly.
+			if it.rounds > 0 {
+				select {
+				case <-it.t.clock.After(topicQueryInterval):
+				case <-it.ctx.Done():
+					continue
+				}
+			}
+			it.lookup = it.newLookup()
+			it.rounds++
+			continue
+		}
+		if !it.lookup.advance() {
+			it.lookup = nil
+		}
+	}
+	return true
+}
+
+// Close ends the iterator.
+func (it *topicIterator) Close() {
+	it.cancel()
+}
+
+// collect moves the unseen topic query results into the buffer, reporting
+// whether any new node was found.
+func (it *topicIterator) collect() bool {
+	it.lock.Lock()
+	found := it.found
+	it.found = nil
+	it.lock.Unlock()
+
+	self := it.t.Self().ID()
+	for _, n := range found {
+		if _, ok := it.seen[n.ID()]; ok || n.ID() == self {
+			continue
+		}
+		it.seen[n.ID()] = struct{}{}
+		it.buffer = append(it.buffer, n)
+	}
+	return len(it.buffer) > 0
+}
+
+// newLookup creates a lookup towards the topic, querying every node asked for
+// its topic registrations too.
+func (it *topicIterator) newLookup() *lookup {
+	target := enode.ID(it.topic)
+	return newLookup(it.ctx, it.t.tab, target, func(n *node) ([]*node, error) {
+		if nodes, err := it.t.topicQuery(unwrapNode(n), it.topic); err == nil && len(nodes) > 0 {
+			it.lock.Lock()
+			it.found = append(it.found, nodes...)
+			it.lock.Unlock()
+		}
+		return it.t.lookupWorker(n, target)
+	})
+}
@@ -0,0 +1,292 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// This test checks that the topic table hands out tickets when full and admits
+// their holders once the reserved slots are freed up.
+func TestTopicTable_tickets(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		tab   = newTopicTable(clock)
+		topic = NewTopic("test")
+		ip    = net.IP{10, 0, 0, 1}
+	)
+	newNode := func() *enode.Node { return unwrapNode(nodeAtDistance(enode.ID{}, 255, ip)) }
+	// Fill up the topic queue, one registration per second.
+	for i := 0; i < topicQueueLimit; i++ {
+		if _, _, ok := tab.register(newNode(), ip, topic, nil); !ok {
+			t.Fatalf("registration %d rejected", i)
+		}
+		clock.Run(time.Second)
+	}
+	// Further registrants are handed tickets for the slots freed up next.
+	var (
+		first  = newNode()
+		second = newNode()
+		other  = newNode()
+	)
+	ticket1, wait1, ok := tab.register(first, ip, topic, nil)
+	if ok {
+		t.Fatal("registration admitted into full queue")
+	}
+	if want := topicAdLifetime - topicQueueLimit*time.Second; wait1 != want {
+		t.Fatalf("wrong wait time: got %v, want %v", wait1, want)
+	}
+	ticket2, wait2, _ := tab.register(second, ip, topic, nil)
+	if wait2 != wait1+time.Second {
+		t.Fatalf("wrong wait time for second ticket: got %v, want %v", wait2, wait1+time.Second)
+	}
+	// Tickets can't be redeemed early, or by other nodes.
+	clock.Run(wait1 / 2)
+	if _, wait, ok := tab.register(first, ip, topic, ticket1); ok || wait != wait1-wait1/2 {
+		t.Fatalf("early ticket accepted: ok %v, remaining wait %v", ok, wait)
+	}
+	clock.Run(wait1 - wait1/2)
+	if _, _, ok := tab.register(other, ip, topic, ticket1); ok {
+		t.Fatal("ticket of other node accepted")
+	}
+	// The freed slot is reserved for the ticket holder.
+	if _, _, ok := tab.register(newNode(), ip, topic, nil); ok {
+		t.Fatal("registration without ticket took reserved slot")
+	}
+	if _, _, ok := tab.register(first, ip, topic, ticket1); !ok {
+		t.Fatal("valid ticket rejected")
+	}
+	// Forged tickets are ignored, genuine ones admitted once the slot is freed.
+	clock.Run(time.Second)
+	forged := append([]byte{}, ticket2...)
+	forged[0] ^= 0xff
+	if _, _, ok := tab.register(second, ip, topic, forged); ok {
+		t.Fatal("forged ticket accepted")
+	}
+	if _, _, ok := tab.register(second, ip, topic, ticket2); !ok {
+		t.Fatal("valid ticket rejected")
+	}
+	// All advertisements expire eventually.
+	clock.Run(topicAdLifetime)
+	if nodes := tab.nodes(topic, topicQueueLimit); len(nodes) != 0 {
+		t.Fatalf("%d advertisements left after expiry", len(nodes))
+	}
+	if tab.count != 0 {
+		t.Fatalf("wrong advertisement count after expiry: %d", tab.count)
+	}
+}
+
+// This test checks that reservations are limited per node and subnet, and
+// released once their tickets expire.
+func TestTopicTable_reservationLimits(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		tab   = newTopicTable(clock)
+		ip    = net.IP{10, 0, 0, 1}
+	)
+	newNode := func(ip net.IP) *enode.Node { return unwrapNode(nodeAtDistance(enode.ID{}, 255, ip)) }
+	fill := func(topic TopicID) {
+		for i := 0; i < topicQueueLimit; i++ {
+			if _, _, ok := tab.register(newNode(net.IP{10, 1, byte(i), 1}), net.IP{10, 1, byte(i), 1}, topic, nil); !ok {
+				t.Fatalf("registration %d rejected", i)
+			}
+		}
+	}
+	topic := NewTopic("test")
+	fill(topic)
+
+	// Repeated requests of a node don't reserve further slots.
+	node := newNode(ip)
+	ticket1, _, _ := tab.register(node, ip, topic, nil)
+	ticket2, _, _ := tab.register(node, ip, topic, nil)
+	if ticket1 == nil || !bytes.Equal(ticket1, ticket2) {
+		t.Fatal("repeated request handed out another ticket")
+	}
+	if n := len(tab.queues[topic].reserved); n != 1 {
+		t.Fatalf("wrong number of reservations: %d", n)
+	}
+	// Nodes of the same subnet can't hold more than the allowed reservations.
+	for i := 1; i < topicIPReserveLimit; i++ {
+		if ticket, _, _ := tab.register(newNode(ip), ip, topic, nil); ticket == nil {
+			t.Fatalf("reservation %d rejected", i)
+		}
+	}
+	if ticket, wait, ok := tab.register(newNode(ip), ip, topic, nil); ticket != nil || ok || wait == 0 {
+		t.Fatal("reservation beyond subnet limit accepted")
+	}
+	// Nodes can't hold more than the allowed reservations across topics.
+	other := newNode(net.IP{10, 2, 0, 1})
+	for i := 0; i < topicNodeReserveLimit; i++ {
+		topic := NewTopic(fmt.Sprintf("topic-%d", i))
+		fill(topic)
+		if ticket, _, _ := tab.register(other, other.IP(), topic, nil); ticket == nil {
+			t.Fatalf("reservation %d rejected", i)
+		}
+	}
+	fill(NewTopic("last"))
+	if ticket, _, ok := tab.register(other, other.IP(), NewTopic("last"), nil); ticket != nil || ok {
+		t.Fatal("reservation beyond node limit accepted")
+	}
+	// Reservations are released once their tickets expire.
+	clock.Run(topicAdLifetime + topicTicketWindow)
+	tab.expire()
+	if len(tab.reservedNodes) != 0 || tab.reservedIPs.Len() != 0 {
+		t.Fatalf("reservations left after expiry: %d nodes, %d IPs", len(tab.reservedNodes), tab.reservedIPs.Len())
+	}
+}
+
+// This test checks that the number of topic queues is limited.
+func TestTopicTable_queueLimit(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		tab   = newTopicTable(clock)
+		ip    = net.IP{10, 0, 0, 1}
+		node  = unwrapNode(nodeAtDistance(enode.ID{}, 255, ip))
+	)
+	for i := 0; i < topicQueueCountLimit; i++ {
+		if _, _, ok := tab.register(node, ip, NewTopic(fmt.Sprint(i)), nil); !ok {
+			t.Fatalf("registration %d rejected", i)
+		}
+	}
+	if ticket, wait, ok := tab.register(node, ip, NewTopic("new"), nil); ticket != nil || ok || wait == 0 {
+		t.Fatal("registration for new topic accepted into full table")
+	}
+	if len(tab.queues) != topicQueueCountLimit {
+		t.Fatalf("wrong number of queues: %d", len(tab.queues))
+	}
+	// Queues are dropped once their advertisements expire.
+	clock.Run(topicAdLifetime)
+	if _, _, ok := tab.register(node, ip, NewTopic("new"), nil); !ok {
+		t.Fatal("registration for new topic rejected after expiry")
+	}
+}
+
+// This test checks that incoming REGTOPIC and TOPICQUERY calls are handled correctly.
+func TestUDPv5_topicHandling(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	topic := NewTopic("test")
+	remote := test.getNode(test.remotekey, test.remoteaddr).Node()
+
+	// Registering with a record of another node fails.
+	other := test.getNode(newkey(), &net.UDPAddr{IP: net.IP{10, 0, 1, 100}, Port: 30303}).Node()
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte{0}, Topic: topic, ENR: other.Record()})
+
+	// A valid registration is admitted.
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte{1}, Topic: topic, ENR: remote.Record()})
+	test.waitPacketOut(func(p *v5wire.Ticket, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if p.WaitTime != 0 {
+			t.Errorf("wrong wait time: %d", p.WaitTime)
+		}
+	})
+	test.waitPacketOut(func(p *v5wire.Regconfirmation, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if p.Topic != topic {
+			t.Errorf("wrong topic confirmed: %x", p.Topic)
+		}
+	})
+	// Other nodes can query the registration.
+	querykey, queryaddr := newkey(), &net.UDPAddr{IP: net.IP{10, 0, 1, 101}, Port: 30303}
+	test.packetInFrom(querykey, queryaddr, &v5wire.TopicQuery{ReqID: []byte{2}, Topic: topic})
+	test.expectNodes([]byte{2}, 1, []*enode.Node{remote})
+
+	test.packetInFrom(querykey, queryaddr, &v5wire.TopicQuery{ReqID: []byte{3}, Topic: NewTopic("other")})
+	test.expectNodes([]byte{3}, 1, nil)
+}
+
+// This test checks that outgoing REGTOPIC calls follow tickets.
+func TestUDPv5_regtopicCall(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	var (
+		topic  = NewTopic("test")
+		remote = test.getNode(test.remotekey, test.remoteaddr).Node()
+		done   = make(chan bool, 1)
+	)
+	go func() {
+		done <- test.udp.registerAt(context.Background(), remote, topic)
+	}()
+	// The first attempt is answered with a short wait.
+	test.waitPacketOut(func(p *v5wire.Regtopic, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if p.Topic != topic {
+			t.Errorf("wrong topic: %x", p.Topic)
+		}
+		if len(p.Ticket) != 0 {
+			t.Errorf("ticket sent on first attempt")
+		}
+		test.packetIn(&v5wire.Ticket{ReqID: p.ReqID, Ticket: []byte("ticket"), WaitTime: 1})
+	})
+	// The retry must present the ticket, which gets it admitted.
+	test.waitPacketOut(func(p *v5wire.Regtopic, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if string(p.Ticket) != "ticket" {
+			t.Errorf("wrong ticket: %q", p.Ticket)
+		}
+		test.packetIn(&v5wire.Ticket{ReqID: p.ReqID})
+		test.packetIn(&v5wire.Regconfirmation{ReqID: p.ReqID, Topic: topic})
+	})
+	if ok := <-done; !ok {
+		t.Fatal("registration failed")
+	}
+}
+
+// Real sockets, real crypto: this test checks that registered nodes can be found.
+func TestUDPv5_topicE2E(t *testing.T) {
+	t.Parallel()
+
+	const N = 5
+	var nodes []*UDPv5
+	for i := 0; i < N; i++ {
+		var cfg Config
+		if len(nodes) > 0 {
+			bn := nodes[0].Self()
+			cfg.Bootnodes = []*enode.Node{bn}
+		}
+		node := startLocalhostV5(t, cfg)
+		nodes = append(nodes, node)
+		defer node.Close()
+	}
+	topic := NewTopic("test")
+	for _, registrar := range nodes[:2] {
+		if !nodes[2].registerAt(context.Background(), registrar.Self(), topic) {
+			t.Fatalf("registration at %v failed", registrar.Self().ID())
+		}
+	}
+	it := nodes[N-1].TopicNodes(topic)
+	defer it.Close()
+
+	timer := time.AfterFunc(10*time.Second, it.Close)
+	defer timer.Stop()
+
+	if !it.Next() {
+		t.Fatal("no node found for topic")
+	}
+	if it.Node().ID() != nodes[2].Self().ID() {
+		t.Fatalf("wrong node found: %v", it.Node().ID())
+	}
+}
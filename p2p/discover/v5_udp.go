@@ -61,6 +61,7 @@ type codecV5 interface {
 type UDPv5 struct {
 	// static fields
 	conn         UDPConn
+	families     ipFamilies
 	tab          *Table
 	netrestrict  *netutil.Netlist
 	priv         *ecdsa.PrivateKey
@@ -76,6 +77,11 @@ type UDPv5 struct {
 	// talkreq handler registry
 	talk *talkSystem
 
+	// topic advertisement state
+	topicTable *topicTable // accessed by dispatch only
+	topicMu    sync.Mutex
+	topicRegs  map[TopicID]context.CancelFunc
+
 	// channels into dispatch
 	packetInCh    chan ReadPacket
 	readNextCh    chan struct{}
@@ -149,6 +155,7 @@ func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 	t := &UDPv5{
 		// static fields
 		conn:         newMeteredConn(conn),
+		families:     connFamilies(conn),
 		localNode:    ln,
 		db:           ln.Database(),
 		netrestrict:  cfg.NetRestrict,
@@ -169,6 +176,9 @@ func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 		activeCallByNode: make(map[enode.ID]*callV5),
 		activeCallByAuth: make(map[v5wire.Nonce]*callV5),
 		callQueue:        make(map[enode.ID][]*callV5),
+		// topic advertisement
+		topicTable: newTopicTable(cfg.Clock),
+		topicRegs:  make(map[TopicID]context.CancelFunc),
 		// shutdown
 		closeCtx:       closeCtx,
 		cancelCloseCtx: cancelCloseCtx,
@@ -426,13 +436,14 @@ func (t *UDPv5) verifyResponseNode(c *callV5, r *enr.Record, distances []uint, s
 	if err != nil {
 		return nil, err
 	}
-	if err := netutil.CheckRelayIP(c.addr.IP, node.IP()); err != nil {
+	addr := t.families.endpoint(node)
+	if err := netutil.CheckRelayIP(c.addr.IP, addr.IP); err != nil {
 		return nil, err
 	}
-	if t.netrestrict != nil && !t.netrestrict.Contains(node.IP()) {
+	if t.netrestrict != nil && !t.netrestrict.Contains(addr.IP) {
 		return nil, errors.New("not contained in netrestrict list")
 	}
-	if node.UDP() <= 1024 {
+	if addr.Port <= 1024 {
 		return nil, errLowPort
 	}
 	if distances != nil {
@@ -460,7 +471,7 @@ func containsUint(x uint, xs []uint) bool {
 // callToNode sends the given call and sets up a handler for response packets (of message
 // type responseType). Responses are dispatched to the call's response channel.
 func (t *UDPv5) callToNode(n *enode.Node, responseType byte, req v5wire.Packet) *callV5 {
-	addr := &net.UDPAddr{IP: n.IP(), Port: n.UDP()}
+	addr := t.families.endpoint(n)
 	c := &callV5{id: n.ID(), addr: addr, node: n}
 	t.initCall(c, responseType, req)
 	return c
@@ -730,7 +741,7 @@ func (t *UDPv5) handleCallResponse(fromID enode.ID, fromAddr *net.UDPAddr, p v5w
 		t.log.Debug(fmt.Sprintf("%s from wrong endpoint", p.Name()), "id", fromID, "addr", fromAddr)
 		return false
 	}
-	if p.Kind() != ac.responseType {
+	if !acceptsResponse(ac.responseType, p.Kind()) {
 		t.log.Debug(fmt.Sprintf("Wrong discv5 response type %s", p.Name()), "id", fromID, "addr", fromAddr)
 		return false
 	}
@@ -739,6 +750,16 @@ func (t *UDPv5) handleCallResponse(fromID enode.ID, fromAddr *net.UDPAddr, p v5w
 	return true
 }
 
+// acceptsResponse reports whether a response of the given kind answers a call
+// expecting responseType. REGTOPIC is answered by TICKET, optionally followed
+// by REGCONFIRMATION.
+func acceptsResponse(responseType, kind byte) bool {
+	if responseType == v5wire.TicketMsg && kind == v5wire.RegconfirmationMsg {
+		return true
+	}
+	return kind == responseType
+}
+
 // getNode looks for a node record in table and database.
 func (t *UDPv5) getNode(id enode.ID) *enode.Node {
 	if n := t.tab.getNode(id); n != nil {
@@ -771,6 +792,14 @@ func (t *UDPv5) handle(p v5wire.Packet, fromID enode.ID, fromAddr *net.UDPAddr)
 		t.talk.handleRequest(fromID, fromAddr, p)
 	case *v5wire.TalkResponse:
 		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.Regtopic:
+		t.handleRegtopic(p, fromID, fromAddr)
+	case *v5wire.Ticket:
+		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.Regconfirmation:
+		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.TopicQuery:
+		t.handleTopicQuery(p, fromID, fromAddr)
 	}
 }
 
@@ -874,7 +903,7 @@ func (t *UDPv5) collectTableNodes(rip net.IP, distances []uint, limit int) []*en
 		// Apply some pre-checks to avoid sending invalid nodes.
 		for _, n := range bn {
 			// TODO livenessChecks > 1
-			if netutil.CheckRelayIP(rip, n.IP()) != nil {
+			if netutil.CheckRelayIP(rip, familyOf(rip).endpoint(n).IP) != nil {
 				continue
 			}
 			nodes = append(nodes, n)
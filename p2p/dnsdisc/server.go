@@ -0,0 +1,382 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package dnsdisc
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	maxUDPSize     = 512 // response size limit for queries without EDNS0
+	maxTXTString   = 255 // maximum length of a single TXT character-string
+	tcpIdleTimeout = 10 * time.Second
+)
+
+// ServerConfig holds configuration options for the server.
+type ServerConfig struct {
+	RootTTL time.Duration // TTL of tree root records (default 30min)
+	NodeTTL time.Duration // TTL of all other tree records (default 4 weeks)
+	Logger  log.Logger    // destination of server log messages (defaults to root logger)
+}
+
+func (cfg ServerConfig) withDefaults() ServerConfig {
+	const (
+		defaultRootTTL = 30 * time.Minute
+		defaultNodeTTL = 4 * 7 * 24 * time.Hour
+	)
+	if cfg.RootTTL == 0 {
+		cfg.RootTTL = defaultRootTTL
+	}
+	if cfg.NodeTTL == 0 {
+		cfg.NodeTTL = defaultNodeTTL
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = log.Root()
+	}
+	return cfg
+}
+
+// Server is a minimal authoritative DNS server for node trees. It answers queries
+// for the TXT records of the trees it publishes over UDP and TCP. Queries for names
+// outside of the published domains are refused.
+type Server struct {
+	cfg ServerConfig
+
+	mu    sync.RWMutex
+	trees map[string]*Tree             // published trees by domain
+	zones map[string]map[string]string // TXT records by domain and lowercase name
+
+	lock    sync.Mutex // protects the fields below
+	udp     net.PacketConn
+	tcp     net.Listener
+	conns   map[net.Conn]struct{} // open TCP connections
+	closing bool
+
+	wg sync.WaitGroup
+}
+
+// NewServer creates a server. Call Start to begin serving queries.
+func NewServer(cfg ServerConfig) *Server {
+	return &Server{
+		cfg:   cfg.withDefaults(),
+		trees: make(map[string]*Tree),
+		zones: make(map[string]map[string]string),
+		conns: make(map[net.Conn]struct{}),
+	}
+}
+
+// SetTree publishes the records of t under the given domain, replacing the tree
+// previously published for the domain. Only records which differ from the previous
+// tree are updated. SetTree returns the number of records set and removed.
+func (s *Server) SetTree(domain string, t *Tree) (set, removed int) {
+	domain = canonicalName(domain)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes, del := t.Diff(s.trees[domain], domain)
+	zone := make(map[string]string, len(s.zones[domain])+len(changes))
+	for name, value := range s.zones[domain] {
+		zone[name] = value
+	}
+	for name, value := range changes {
+		zone[strings.ToLower(name)] = value
+	}
+	for _, name := range del {
+		delete(zone, strings.ToLower(name))
+	}
+	s.trees[domain] = t
+	s.zones[domain] = zone
+	logger := s.cfg.Logger.Debug
+	if len(changes) > 0 || len(del) > 0 {
+		logger = s.cfg.Logger.Info
+	}
+	logger("Updated DNS tree", "domain", domain, "seq", t.Seq(), "set", len(changes), "removed", len(del), "records", len(zone))
+	return len(changes), len(del)
+}
+
+// Start listens for queries on the given UDP address and on the TCP port with the
+// same number.
+func (s *Server) Start(addr string) error {
+	udp, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+	if err != nil {
+		udp.Close()
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.udp != nil || s.closing {
+		udp.Close()
+		tcp.Close()
+		return errors.New("server already started")
+	}
+	s.udp, s.tcp = udp, tcp
+	s.wg.Add(2)
+	go s.serveUDP(udp)
+	go s.serveTCP(tcp)
+	s.cfg.Logger.Info("DNS server started", "addr", udp.LocalAddr())
+	return nil
+}
+
+// Addr returns the listening address of the server, or nil if it isn't started.
+func (s *Server) Addr() net.Addr {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.udp == nil {
+		return nil
+	}
+	return s.udp.LocalAddr()
+}
+
+// Close stops the server, waiting for the queries in progress to be answered.
+func (s *Server) Close() {
+	s.lock.Lock()
+	s.closing = true
+	if s.udp != nil {
+		s.udp.Close()
+		s.tcp.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Server) serveUDP(udp net.PacketConn) {
+	defer s.wg.Done()
+
+	buf := make([]byte, 65535)
+	for {
+		n, from, err := udp.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		} else if err != nil {
+			s.cfg.Logger.Debug("DNS read error", "err", err)
+			continue
+		}
+		if resp := s.handle(buf[:n], false); resp != nil {
+			udp.WriteTo(resp, from)
+		}
+	}
+}
+
+func (s *Server) serveTCP(tcp net.Listener) {
+	defer s.wg.Done()
+
+	for {
+		conn, err := tcp.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		} else if err != nil {
+			s.cfg.Logger.Debug("DNS accept error", "err", err)
+			continue
+		}
+		s.lock.Lock()
+		if s.closing {
+			s.lock.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.lock.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// serveConn answers the queries of a TCP connection. Messages on TCP are prefixed
+// by their length as a 16-bit big endian integer.
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, conn)
+		s.lock.Unlock()
+		conn.Close()
+		s.wg.Done()
+	}()
+
+	var size [2]byte
+	for {
+		conn.SetDeadline(time.Now().Add(tcpIdleTimeout))
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint16(size[:]))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+		resp := s.handle(req, true)
+		if resp == nil {
+			return
+		}
+		out := binary.BigEndian.AppendUint16(make([]byte, 0, 2+len(resp)), uint16(len(resp)))
+		if _, err := conn.Write(append(out, resp...)); err != nil {
+			return
+		}
+	}
+}
+
+// handle answers a single query. It returns nil if the message should be ignored.
+func (s *Server) handle(req []byte, tcp bool) []byte {
+	var p dnsmessage.Parser
+	h, err := p.Start(req)
+	if err != nil || h.Response {
+		return nil
+	}
+	resp := dnsmessage.Header{ID: h.ID, Response: true, OpCode: h.OpCode, RecursionDesired: h.RecursionDesired}
+	q, err := p.Question()
+	if err != nil {
+		resp.RCode = dnsmessage.RCodeFormatError
+		return buildResponse(resp, nil, nil)
+	}
+	if h.OpCode != 0 {
+		resp.RCode = dnsmessage.RCodeNotImplemented
+		return buildResponse(resp, &q, nil)
+	}
+
+	// Find the record.
+	value, ttl, authoritative, found := s.lookup(canonicalName(q.Name.String()))
+	switch {
+	case !authoritative || (q.Class != dnsmessage.ClassINET && q.Class != dnsmessage.ClassANY):
+		resp.RCode = dnsmessage.RCodeRefused
+		return buildResponse(resp, &q, nil)
+	case !found:
+		resp.Authoritative = true
+		resp.RCode = dnsmessage.RCodeNameError
+		return buildResponse(resp, &q, nil)
+	}
+	resp.Authoritative = true
+	var answer *dnsmessage.Resource
+	if q.Type == dnsmessage.TypeTXT || q.Type == dnsmessage.TypeALL {
+		answer = &dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: ttl},
+			Body:   &dnsmessage.TXTResource{TXT: splitTXTString(value)},
+		}
+	}
+
+	// Check the response fits into the size limit of the client. If not, the
+	// answer is dropped and the client retries over TCP.
+	msg := buildResponse(resp, &q, answer)
+	if !tcp && len(msg) > requestUDPSize(&p) {
+		resp.Truncated = true
+		msg = buildResponse(resp, &q, nil)
+	}
+	return msg
+}
+
+// lookup finds the TXT record of the given name.
+func (s *Server) lookup(name string) (value string, ttl uint32, authoritative, found bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for domain, zone := range s.zones {
+		if name != domain && !strings.HasSuffix(name, "."+domain) {
+			continue
+		}
+		value, found = zone[name]
+		ttl = uint32(s.cfg.NodeTTL / time.Second)
+		if name == domain {
+			ttl = uint32(s.cfg.RootTTL / time.Second)
+		}
+		return value, ttl, true, found
+	}
+	return "", 0, false, false
+}
+
+// requestUDPSize returns the maximum response size announced by the client in its
+// EDNS0 OPT record. The parser must be positioned after the first question.
+func requestUDPSize(p *dnsmessage.Parser) int {
+	if p.SkipAllQuestions() != nil || p.SkipAllAnswers() != nil || p.SkipAllAuthorities() != nil {
+		return maxUDPSize
+	}
+	for {
+		h, err := p.AdditionalHeader()
+		if err != nil {
+			return maxUDPSize
+		}
+		if h.Type == dnsmessage.TypeOPT {
+			// The class field of the OPT record holds the UDP payload size.
+			if size := int(h.Class); size > maxUDPSize {
+				return size
+			}
+			return maxUDPSize
+		}
+		if p.SkipAdditional() != nil {
+			return maxUDPSize
+		}
+	}
+}
+
+func buildResponse(h dnsmessage.Header, q *dnsmessage.Question, answer *dnsmessage.Resource) []byte {
+	b := dnsmessage.NewBuilder(make([]byte, 0, maxUDPSize), h)
+	b.EnableCompression()
+	if q != nil {
+		if err := b.StartQuestions(); err != nil {
+			return nil
+		}
+		if err := b.Question(*q); err != nil {
+			return nil
+		}
+	}
+	if answer != nil {
+		if err := b.StartAnswers(); err != nil {
+			return nil
+		}
+		if err := b.TXTResource(answer.Header, *answer.Body.(*dnsmessage.TXTResource)); err != nil {
+			return nil
+		}
+	}
+	msg, err := b.Finish()
+	if err != nil {
+		return nil
+	}
+	return msg
+}
+
+// splitTXTString splits a record value into character-strings of the maximum
+// length allowed in TXT records. Resolvers concatenate them when reading the record.
+func splitTXTString(value string) []string {
+	var parts []string
+	for len(value) > maxTXTString {
+		parts = append(parts, value[:maxTXTString])
+		value = value[maxTXTString:]
+	}
+	return append(parts, value)
+}
+
+// canonicalName converts a domain name to lowercase and removes the trailing dot.
+func canonicalName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package dnsdisc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func startTestServer(t *testing.T) *Server {
+	t.Helper()
+	srv := NewServer(ServerConfig{Logger: testlog.Logger(t, log.LvlTrace)})
+	if err := srv.Start("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// serverResolver returns a resolver which sends all queries to srv.
+func serverResolver(srv *Server) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, srv.Addr().String())
+		},
+	}
+}
+
+func TestServerLookup(t *testing.T) {
+	srv := startTestServer(t)
+	tree, _ := makeTestTree("nodes.example.org", testNodes(testKeys(30)), nil)
+	srv.SetTree("nodes.example.org", tree)
+
+	r := serverResolver(srv)
+	for name, value := range tree.ToTXT("nodes.example.org") {
+		// Names are case insensitive.
+		txt, err := r.LookupTXT(context.Background(), strings.ToLower(name))
+		if err != nil {
+			t.Fatalf("lookup %s: %v", name, err)
+		}
+		if !reflect.DeepEqual(txt, []string{value}) {
+			t.Fatalf("wrong TXT record for %s: %q", name, txt)
+		}
+	}
+
+	// Unknown names in the domain don't exist.
+	_, err := r.LookupTXT(context.Background(), "unknown.nodes.example.org")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Fatalf("wrong error for unknown name: %v", err)
+	}
+}
+
+func TestServerRefused(t *testing.T) {
+	srv := startTestServer(t)
+	tree, _ := makeTestTree("nodes.example.org", nil, nil)
+	srv.SetTree("nodes.example.org", tree)
+
+	resp := srv.handle(makeQuery(t, "example.org.", dnsmessage.TypeTXT), false)
+	var p dnsmessage.Parser
+	h, err := p.Start(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.RCode != dnsmessage.RCodeRefused || h.Authoritative {
+		t.Fatalf("wrong response header %v", h)
+	}
+}
+
+func TestServerTruncate(t *testing.T) {
+	srv := startTestServer(t)
+	// A link to a tree with a long domain name gives an oversized record.
+	link := "enrtree://AM5FCQLWIZX2QFPNJAP7VUERCCRNGRHWZG3YYHIUV7BVDQ5FDPRT2@" + strings.Repeat(strings.Repeat("a", 60)+".", 8) + "org"
+	tree, _ := makeTestTree("n", nil, []string{link})
+	srv.SetTree("n", tree)
+
+	name := tree.Links()[0]
+	for sd, value := range tree.ToTXT("n") {
+		if value == name {
+			name = sd
+		}
+	}
+	resp := srv.handle(makeQuery(t, name+".", dnsmessage.TypeTXT), false)
+	var p dnsmessage.Parser
+	h, err := p.Start(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.Truncated {
+		t.Fatal("oversized UDP response not truncated")
+	}
+	// The resolver falls back to TCP.
+	txt, err := serverResolver(srv).LookupTXT(context.Background(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(txt, "") != link {
+		t.Fatalf("wrong TXT record %q", txt)
+	}
+}
+
+func TestServerSetTreeDiff(t *testing.T) {
+	srv := startTestServer(t)
+	keys := testKeys(20)
+	nodes := testNodes(keys)
+	tree1, _ := makeTestTree("n", nodes, nil)
+	if set, removed := srv.SetTree("n", tree1); set != len(tree1.ToTXT("n")) || removed != 0 {
+		t.Fatalf("wrong initial update: set %d, removed %d", set, removed)
+	}
+
+	// Republishing the same tree doesn't change anything.
+	if set, removed := srv.SetTree("n", tree1); set != 0 || removed != 0 {
+		t.Fatalf("wrong update for same tree: set %d, removed %d", set, removed)
+	}
+
+	// Dropping a node removes its record and updates the branches above it.
+	tree2, _ := makeTestTree("n", nodes[1:], nil)
+	set, removed := srv.SetTree("n", tree2)
+	if removed == 0 || set == 0 || set >= len(tree2.ToTXT("n")) {
+		t.Fatalf("wrong update after removing node: set %d, removed %d", set, removed)
+	}
+	if _, _, _, found := srv.lookup(canonicalName(subdomain(&enrEntry{nodes[0]}) + ".n")); found {
+		t.Fatal("removed node still served")
+	}
+}
+
+func TestServerCloseNotStarted(t *testing.T) {
+	srv := NewServer(ServerConfig{})
+	if addr := srv.Addr(); addr != nil {
+		t.Fatalf("unstarted server has address %v", addr)
+	}
+	srv.Close()
+	if err := srv.Start("127.0.0.1:0"); err == nil {
+		t.Fatal("closed server started")
+	}
+}
+
+func TestServerCloseConns(t *testing.T) {
+	srv := startTestServer(t)
+	conn, err := net.Dial("tcp", srv.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// Wait for the server to pick up the connection.
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		srv.lock.Lock()
+		n := len(srv.conns)
+		srv.lock.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("connection not accepted")
+		}
+	}
+
+	// Idle connections are closed by the server on shutdown.
+	srv.Close()
+	conn.SetReadDeadline(time.Now().Add(tcpIdleTimeout / 2))
+	if _, err := conn.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Fatalf("wrong read error after close: %v", err)
+	}
+}
+
+// This test syncs a tree through the DNS server.
+func TestClientSyncTreeServer(t *testing.T) {
+	srv := startTestServer(t)
+	nodes := testNodes(testKeys(20))
+	tree, url := makeTestTree("nodes.example.org", nodes, nil)
+	srv.SetTree("nodes.example.org", tree)
+
+	c := NewClient(Config{Resolver: serverResolver(srv), RateLimit: 1000, Logger: testlog.Logger(t, log.LvlTrace)})
+	stree, err := c.SyncTree(url)
+	if err != nil {
+		t.Fatal("sync error:", err)
+	}
+	if !reflect.DeepEqual(sortByID(stree.Nodes()), sortByID(nodes)) {
+		t.Error("wrong nodes in synced tree")
+	}
+}
+
+func makeQuery(t *testing.T, name string, qtype dnsmessage.Type) []byte {
+	t.Helper()
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: 1})
+	b.StartQuestions()
+	b.Question(dnsmessage.Question{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET})
+	msg, err := b.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
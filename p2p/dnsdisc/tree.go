@@ -91,6 +91,29 @@ func (t *Tree) ToTXT(domain string) map[string]string {
 	return records
 }
 
+// Diff returns the TXT records which must be created or updated, and the names of
+// records which must be deleted, to turn the records of prev into those of t. Tree
+// entries are content-addressed, so subtrees which are unchanged between the two
+// trees do not appear in the result. If prev is nil, all records of t are returned.
+func (t *Tree) Diff(prev *Tree, domain string) (set map[string]string, del []string) {
+	var old map[string]string
+	if prev != nil {
+		old = prev.ToTXT(domain)
+	}
+	set = make(map[string]string)
+	for name, value := range t.ToTXT(domain) {
+		if oldValue, ok := old[name]; !ok || oldValue != value {
+			set[name] = value
+		}
+		delete(old, name)
+	}
+	for name := range old {
+		del = append(del, name)
+	}
+	slices.Sort(del)
+	return set, del
+}
+
 // Links returns all links contained in the tree.
 func (t *Tree) Links() []string {
 	var links []string
@@ -149,3 +149,43 @@ func TestMakeTree(t *testing.T) {
 		t.Fatal("too few TXT records in output")
 	}
 }
+
+func TestTreeDiff(t *testing.T) {
+	keys := testKeys(50)
+	nodes := testNodes(keys)
+	tree1, _ := makeTestTree("n", nodes, nil)
+
+	set, del := tree1.Diff(nil, "n")
+	if !reflect.DeepEqual(set, tree1.ToTXT("n")) || len(del) != 0 {
+		t.Fatal("diff against nil tree doesn't contain all records")
+	}
+	if set, del := tree1.Diff(tree1, "n"); len(set) != 0 || len(del) != 0 {
+		t.Fatalf("diff of same tree not empty: %v %v", set, del)
+	}
+
+	// Updating some nodes changes their records and the branches above them, but
+	// leaves the other leaves in place.
+	updateSomeNodes(keys, nodes)
+	tree2, _ := makeTestTree("n", nodes, nil)
+	set, del = tree2.Diff(tree1, "n")
+	txt1, txt2 := tree1.ToTXT("n"), tree2.ToTXT("n")
+	for name, value := range set {
+		if txt2[name] != value {
+			t.Errorf("wrong value for %s in diff", name)
+		}
+	}
+	for _, name := range del {
+		if _, ok := txt2[name]; ok {
+			t.Errorf("deleted name %s exists in new tree", name)
+		}
+		if _, ok := txt1[name]; !ok {
+			t.Errorf("deleted name %s doesn't exist in old tree", name)
+		}
+	}
+	if _, ok := set["n"]; !ok {
+		t.Error("root not updated")
+	}
+	if len(set) >= len(txt2) {
+		t.Errorf("diff contains all %d records", len(set))
+	}
+}
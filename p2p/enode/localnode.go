@@ -192,6 +192,16 @@ func (ln *LocalNode) SetStaticIP(ip net.IP) {
 	ln.updateEndpoints()
 }
 
+// ClearStaticIP removes the static IP of the address family of the given IP,
+// enabling endpoint prediction again.
+func (ln *LocalNode) ClearStaticIP(ip net.IP) {
+	ln.mu.Lock()
+	defer ln.mu.Unlock()
+
+	ln.endpointForIP(ip).staticIP = nil
+	ln.updateEndpoints()
+}
+
 // SetFallbackIP sets the last-resort IP address. This address is used
 // if no endpoint prediction can be made and no static IP is set.
 func (ln *LocalNode) SetFallbackIP(ip net.IP) {
@@ -126,4 +126,10 @@ func TestLocalNodeEndpoint(t *testing.T) {
 	assert.Equal(t, staticIP, ln.Node().IP())
 	assert.Equal(t, fallback.Port, ln.Node().UDP())
 	assert.Equal(t, initialSeq+3, ln.Node().Seq())
+
+	// Clearing the static IP enables prediction again.
+	ln.ClearStaticIP(staticIP)
+	assert.Equal(t, predicted.IP, ln.Node().IP())
+	assert.Equal(t, predicted.Port, ln.Node().UDP())
+	assert.Equal(t, initialSeq+4, ln.Node().Seq())
 }
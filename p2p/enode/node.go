@@ -114,6 +114,24 @@ func (n *Node) IP() net.IP {
 	return nil
 }
 
+// IPv4 returns the IPv4 address of the node, or nil if it has none.
+func (n *Node) IPv4() net.IP {
+	var ip enr.IPv4
+	if n.Load(&ip) != nil {
+		return nil
+	}
+	return net.IP(ip)
+}
+
+// IPv6 returns the IPv6 address of the node, or nil if it has none.
+func (n *Node) IPv6() net.IP {
+	var ip enr.IPv6
+	if n.Load(&ip) != nil {
+		return nil
+	}
+	return net.IP(ip)
+}
+
 // UDP returns the UDP port of the node.
 func (n *Node) UDP() int {
 	var port enr.UDP
@@ -121,6 +139,16 @@ func (n *Node) UDP() int {
 	return int(port)
 }
 
+// UDP6 returns the UDP port of the node on its IPv6 address. Like the node record
+// specification says, this is the same as the UDP port unless a different one is set.
+func (n *Node) UDP6() int {
+	var port enr.UDP6
+	if n.Load(&port) != nil {
+		return n.UDP()
+	}
+	return int(port)
+}
+
 // TCP returns the TCP port of the node.
 func (n *Node) TCP() int {
 	var port enr.TCP
@@ -128,6 +156,23 @@ func (n *Node) TCP() int {
 	return int(port)
 }
 
+// TCP6 returns the TCP port of the node on its IPv6 address. Like the node record
+// specification says, this is the same as the TCP port unless a different one is set.
+func (n *Node) TCP6() int {
+	var port enr.TCP6
+	if n.Load(&port) != nil {
+		return n.TCP()
+	}
+	return int(port)
+}
+
+// QUIC returns the QUIC port of the node.
+func (n *Node) QUIC() int {
+	var port enr.QUIC
+	n.Load(&port)
+	return int(port)
+}
+
 // Pubkey returns the secp256k1 public key of the node, if present.
 func (n *Node) Pubkey() *ecdsa.PublicKey {
 	var key ecdsa.PublicKey
@@ -21,6 +21,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"net"
 	"testing"
 	"testing/quick"
 
@@ -64,6 +65,22 @@ func TestPythonInterop(t *testing.T) {
 	}
 }
 
+func TestNodeDualStack(t *testing.T) {
+	var r enr.Record
+	r.Set(enr.IPv4{127, 0, 0, 1})
+	r.Set(enr.IPv6(net.ParseIP("2001:db8::1")))
+	r.Set(enr.UDP(30303))
+	r.Set(enr.TCP(30303))
+	r.Set(enr.TCP6(30304))
+	n := SignNull(&r, ID{})
+
+	assert.Equal(t, net.IP{127, 0, 0, 1}, n.IP(), "IP prefers IPv4")
+	assert.Equal(t, net.IP{127, 0, 0, 1}, n.IPv4())
+	assert.Equal(t, net.ParseIP("2001:db8::1"), n.IPv6())
+	assert.Equal(t, 30303, n.UDP6(), "udp6 defaults to udp")
+	assert.Equal(t, 30304, n.TCP6())
+}
+
 func TestHexID(t *testing.T) {
 	ref := ID{0, 0, 0, 0, 0, 0, 0, 128, 106, 217, 182, 31, 165, 174, 1, 67, 7, 235, 220, 150, 66, 83, 173, 205, 159, 44, 10, 57, 42, 161, 26, 188}
 	id1 := HexID("0x00000000000000806ad9b61fa5ae014307ebdc964253adcd9f2c0a392aa11abc")
@@ -37,11 +37,12 @@ import (
 
 // Keys in the node database.
 const (
-	dbVersionKey   = "version" // Version of the database to flush if changes
-	dbNodePrefix   = "n:"      // Identifier to prefix node entries with
-	dbLocalPrefix  = "local:"
-	dbDiscoverRoot = "v4"
-	dbDiscv5Root   = "v5"
+	dbVersionKey       = "version" // Version of the database to flush if changes
+	dbNodePrefix       = "n:"      // Identifier to prefix node entries with
+	dbLocalPrefix      = "local:"
+	dbReputationPrefix = "rep:"
+	dbDiscoverRoot     = "v4"
+	dbDiscv5Root       = "v5"
 
 	// These fields are stored per ID and IP, the full key is "n:<ID>:v4:<IP>:findfail".
 	// Use nodeItemKey to create those keys.
@@ -53,12 +54,19 @@ const (
 	// Local information is keyed by ID only, the full key is "local:<ID>:seq".
 	// Use localItemKey to create those keys.
 	dbLocalSeq = "seq"
+
+	// Reputation information is keyed by ID only and kept apart from the node
+	// entries, so it survives the expiration of discovery data. The full key is
+	// "rep:<ID>:score". Use reputationItemKey to create those keys.
+	dbReputationScore   = "score"
+	dbReputationUpdated = "updated"
 )
 
 const (
-	dbNodeExpiration = 24 * time.Hour // Time after which an unseen node should be dropped.
-	dbCleanupCycle   = time.Hour      // Time period for running the expiration task.
-	dbVersion        = 9
+	dbNodeExpiration       = 24 * time.Hour     // Time after which an unseen node should be dropped.
+	dbReputationExpiration = 7 * 24 * time.Hour // Time after which an unchanged reputation should be dropped.
+	dbCleanupCycle         = time.Hour          // Time period for running the expiration task.
+	dbVersion              = 9
 )
 
 var (
@@ -195,6 +203,21 @@ func localItemKey(id ID, field string) []byte {
 	return key
 }
 
+// reputationItemKey returns the key of a node reputation item.
+func reputationItemKey(id ID, field string) []byte {
+	key := append([]byte(dbReputationPrefix), id[:]...)
+	key = append(key, ':')
+	key = append(key, field...)
+	return key
+}
+
+// splitReputationItemKey returns the components of a key created by reputationItemKey.
+func splitReputationItemKey(key []byte) (id ID, field string) {
+	item := key[len(dbReputationPrefix):]
+	copy(id[:], item[:len(id)])
+	return id, string(item[len(id)+1:])
+}
+
 // fetchInt64 retrieves an integer associated with a particular key.
 func (db *DB) fetchInt64(key []byte) int64 {
 	blob, err := db.lvl.Get(key, nil)
@@ -315,6 +338,7 @@ func (db *DB) expirer() {
 		select {
 		case <-tick.C:
 			db.expireNodes()
+			db.expireReputations()
 		case <-db.quit:
 			return
 		}
@@ -360,6 +384,24 @@ func (db *DB) expireNodes() {
 	}
 }
 
+// expireReputations deletes the reputation of all nodes which has not been updated
+// for some time.
+func (db *DB) expireReputations() {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbReputationPrefix)), nil)
+	defer it.Release()
+
+	threshold := time.Now().Add(-dbReputationExpiration).Unix()
+	for it.Next() {
+		id, field := splitReputationItemKey(it.Key())
+		if field != dbReputationUpdated {
+			continue
+		}
+		if updated, _ := binary.Varint(it.Value()); updated < threshold {
+			db.DeleteReputation(id)
+		}
+	}
+}
+
 // LastPingReceived retrieves the time of the last ping packet received from
 // a remote node.
 func (db *DB) LastPingReceived(id ID, ip net.IP) time.Time {
@@ -427,6 +469,41 @@ func (db *DB) UpdateFindFailsV5(id ID, ip net.IP, fails int) error {
 	return db.storeInt64(v5Key(id, ip, dbNodeFindFails), int64(fails))
 }
 
+// Reputation retrieves the reputation score of a node and the time it was last
+// updated. Unknown nodes have a zero score.
+func (db *DB) Reputation(id ID) (int64, time.Time) {
+	score := db.fetchInt64(reputationItemKey(id, dbReputationScore))
+	updated := db.fetchInt64(reputationItemKey(id, dbReputationUpdated))
+	return score, time.Unix(updated, 0)
+}
+
+// UpdateReputation stores the reputation score of a node.
+func (db *DB) UpdateReputation(id ID, score int64, updated time.Time) error {
+	if err := db.storeInt64(reputationItemKey(id, dbReputationScore), score); err != nil {
+		return err
+	}
+	return db.storeInt64(reputationItemKey(id, dbReputationUpdated), updated.Unix())
+}
+
+// DeleteReputation deletes the reputation of a node.
+func (db *DB) DeleteReputation(id ID) {
+	deleteRange(db.lvl, reputationItemKey(id, ""))
+}
+
+// Reputations retrieves the IDs of all nodes with a stored reputation.
+func (db *DB) Reputations() []ID {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbReputationPrefix)), nil)
+	defer it.Release()
+
+	var ids []ID
+	for it.Next() {
+		if id, field := splitReputationItemKey(it.Key()); field == dbReputationScore {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // localSeq retrieves the local record sequence counter, defaulting to the current
 // timestamp if no previous exists. This ensures that wiping all data associated
 // with a node (apart from its key) will not generate already used sequence nums.
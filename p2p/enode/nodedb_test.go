@@ -467,3 +467,41 @@ func TestDBExpireV5(t *testing.T) {
 	db.UpdateFindFailsV5(ID{}, ip, 4)
 	db.expireNodes()
 }
+
+// This test checks that node reputations are stored apart from the node entries
+// and expire once they haven't been updated for a while.
+func TestDBReputation(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+
+	var (
+		fresh = ID{1}
+		stale = ID{2}
+		now   = time.Now()
+	)
+	if score, _ := db.Reputation(fresh); score != 0 {
+		t.Fatalf("unknown node has non-zero reputation %d", score)
+	}
+	db.UpdateReputation(fresh, -42, now)
+	db.UpdateReputation(stale, 10, now.Add(-dbReputationExpiration-time.Hour))
+
+	if score, updated := db.Reputation(fresh); score != -42 || updated.Unix() != now.Unix() {
+		t.Fatalf("wrong reputation: score %d, updated %v", score, updated)
+	}
+	if ids := db.Reputations(); len(ids) != 2 {
+		t.Fatalf("wrong number of reputations: %v", ids)
+	}
+	// Expiring the nodes leaves reputations intact.
+	db.expireNodes()
+	if score, _ := db.Reputation(stale); score != 10 {
+		t.Fatalf("reputation removed with node expiration")
+	}
+	db.expireReputations()
+	if ids := db.Reputations(); len(ids) != 1 || ids[0] != fresh {
+		t.Fatalf("wrong reputations after expiration: %v", ids)
+	}
+	db.DeleteReputation(fresh)
+	if score, _ := db.Reputation(fresh); score != 0 {
+		t.Fatalf("reputation present after deletion: %d", score)
+	}
+}
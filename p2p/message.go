@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/p2p/capture"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -323,3 +324,93 @@ func (ev *msgEventer) Close() error {
 	}
 	return nil
 }
+
+// msgCapturer wraps a MsgReadWriter and records all messages sent or received
+// into a capture file.
+type msgCapturer struct {
+	MsgReadWriter
+
+	w       *capture.Writer
+	peerID  enode.ID
+	proto   string
+	version uint
+}
+
+// newMsgCapturer returns a msgCapturer which records messages of the given
+// protocol into w.
+func newMsgCapturer(rw MsgReadWriter, w *capture.Writer, peerID enode.ID, proto string, version uint) *msgCapturer {
+	return &msgCapturer{
+		MsgReadWriter: rw,
+		w:             w,
+		peerID:        peerID,
+		proto:         proto,
+		version:       version,
+	}
+}
+
+// ReadMsg reads a message from the underlying MsgReadWriter and records it.
+func (c *msgCapturer) ReadMsg() (Msg, error) {
+	msg, err := c.MsgReadWriter.ReadMsg()
+	if err != nil {
+		return msg, err
+	}
+	t := msg.ReceivedAt
+	if t.IsZero() {
+		t = time.Now()
+	}
+	msg, payload, err := bufferPayload(msg)
+	if err != nil {
+		return msg, err
+	}
+	c.record(msg.Code, payload, true, t)
+	return msg, nil
+}
+
+// WriteMsg writes a message to the underlying MsgReadWriter and records it if
+// it was sent successfully.
+func (c *msgCapturer) WriteMsg(msg Msg) error {
+	msg, payload, err := bufferPayload(msg)
+	if err != nil {
+		return err
+	}
+	if err := c.MsgReadWriter.WriteMsg(msg); err != nil {
+		return err
+	}
+	c.record(msg.Code, payload, false, time.Now())
+	return nil
+}
+
+// bufferPayload reads the message payload, returning it along with the message
+// with a fresh payload reader.
+func bufferPayload(msg Msg) (Msg, []byte, error) {
+	payload := make([]byte, msg.Size)
+	if _, err := io.ReadFull(msg.Payload, payload); err != nil {
+		return msg, nil, err
+	}
+	msg.Payload = bytes.NewReader(payload)
+	return msg, payload, nil
+}
+
+// record queues a message for writing to the capture file.
+func (c *msgCapturer) record(code uint64, payload []byte, inbound bool, t time.Time) {
+	// Failed writes are not fatal for the connection. The writer remembers
+	// the error, it is reported when the server stops.
+	c.w.Write(&capture.Record{
+		Time:     t,
+		Peer:     c.peerID,
+		Inbound:  inbound,
+		Protocol: c.proto,
+		Version:  c.version,
+		Code:     code,
+		Payload:  payload,
+	})
+}
+
+// Close closes the underlying MsgReadWriter if it implements the io.Closer
+// interface
+func (c *msgCapturer) Close() error {
+	if v, ok := c.MsgReadWriter.(io.Closer); ok {
+		return v.Close()
+	}
+	return nil
+}
@@ -23,6 +23,10 @@ import (
 	"runtime"
 	"testing"
 	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/capture"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func ExampleMsgPipe() {
@@ -139,3 +143,65 @@ func TestEOFSignal(t *testing.T) {
 	default:
 	}
 }
+
+func TestMsgCapturer(t *testing.T) {
+	var (
+		buf      bytes.Buffer
+		id       = enode.ID{1}
+		rw1, rw2 = MsgPipe()
+	)
+	defer rw1.Close()
+	w, err := capture.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newMsgCapturer(rw1, w, id, "test", 2)
+
+	// Messages passing the capturer must still be delivered in full.
+	go func() {
+		SendItems(c, 3, "out")
+		SendItems(rw2, 4, "in")
+	}()
+	if err := ExpectMsg(rw2, 3, []string{"out"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExpectMsg(c, 4, []string{"in"}); err != nil {
+		t.Fatal(err)
+	}
+	// Messages failing to be sent must not be recorded.
+	rw2.Close()
+	if err := SendItems(c, 5, "failed"); err == nil {
+		t.Fatal("sending on closed pipe succeeded")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := capture.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("wrong number of records: %d", len(records))
+	}
+	for i, want := range []struct {
+		inbound bool
+		code    uint64
+		payload []interface{}
+	}{
+		{false, 3, []interface{}{"out"}},
+		{true, 4, []interface{}{"in"}},
+	} {
+		rec := records[i]
+		enc, _ := rlp.EncodeToBytes(want.payload)
+		if rec.Inbound != want.inbound || rec.Code != want.code || !bytes.Equal(rec.Payload, enc) {
+			t.Errorf("record %d mismatch: %v", i, rec)
+		}
+		if rec.Peer != id || rec.Protocol != "test" || rec.Version != 2 {
+			t.Errorf("record %d has wrong origin: %v", i, rec)
+		}
+	}
+}
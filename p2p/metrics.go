@@ -112,6 +112,10 @@ func newMeteredConn(conn net.Conn) net.Conn {
 	if !metrics.Enabled {
 		return conn
 	}
+	// QUIC connections are not read through the net.Conn interface.
+	if _, ok := conn.(*quicConn); ok {
+		return conn
+	}
 	return &meteredConn{Conn: conn}
 }
 
@@ -59,6 +59,9 @@ type Interface interface {
 //	"upnp"               uses the Universal Plug and Play protocol
 //	"pmp"                uses NAT-PMP with an auto-detected gateway address
 //	"pmp:192.168.0.1"    uses NAT-PMP with the given gateway address
+//	"pcp"                uses PCP with an auto-detected gateway address
+//	"pcp:192.168.0.1"    uses PCP with the given gateway address
+//	"pcp:2001:db8::1"    uses PCP with the given IPv6 gateway, opening firewall pinholes
 func Parse(spec string) (Interface, error) {
 	var (
 		parts = strings.SplitN(spec, ":", 2)
@@ -85,6 +88,8 @@ func Parse(spec string) (Interface, error) {
 		return UPnP(), nil
 	case "pmp", "natpmp", "nat-pmp":
 		return PMP(ip), nil
+	case "pcp":
+		return PCP(ip), nil
 	default:
 		return nil, fmt.Errorf("unknown mechanism %q", parts[0])
 	}
@@ -146,10 +151,11 @@ func (ExtIP) DeleteMapping(string, int, int) error
 func Any() Interface {
 	// TODO: attempt to discover whether the local machine has an
 	// Internet-class address. Return ExtIP in this case.
-	return startautodisc("UPnP or NAT-PMP", func() Interface {
-		found := make(chan Interface, 2)
+	return startautodisc("UPnP, NAT-PMP or PCP", func() Interface {
+		found := make(chan Interface, 3)
 		go func() { found <- discoverUPnP() }()
 		go func() { found <- discoverPMP() }()
+		go func() { found <- discoverPCP() }()
 		for i := 0; i < cap(found); i++ {
 			if c := <-found; c != nil {
 				return c
@@ -175,6 +181,18 @@ func PMP(gateway net.IP) Interface {
 	return startautodisc("NAT-PMP", discoverPMP)
 }
 
+// PCP returns a port mapper that uses the Port Control Protocol. The
+// provided gateway address should be the IP of your router. If the gateway
+// address is nil, PCP will attempt to auto-discover an IPv4 router.
+//
+// When an IPv6 gateway is given, mappings open pinholes in its firewall.
+func PCP(gateway net.IP) Interface {
+	if gateway != nil {
+		return newPCP(gateway)
+	}
+	return startautodisc("PCP", discoverPCP)
+}
+
 // autodisc represents a port mapping mechanism that is still being
 // auto-discovered. Calls to the Interface methods on this type will
 // wait until the discovery is done and then call the method on the
@@ -0,0 +1,324 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package nat
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// This file implements the MAP opcode of the Port Control Protocol, RFC 6887.
+// PCP is the successor of NAT-PMP and also supported by carrier-grade NATs
+// and IPv6 firewalls. For IPv6, a mapping opens a pinhole in the firewall
+// and the external address is the global address of the local machine.
+
+const (
+	pcpPort    = 5351
+	pcpVersion = 2
+
+	pcpOpMap       = 1
+	pcpResponseBit = 0x80
+
+	pcpHeaderSize  = 24
+	pcpMapBodySize = 36
+	pcpPacketSize  = pcpHeaderSize + pcpMapBodySize
+
+	pcpProtoTCP = 6
+	pcpProtoUDP = 17
+
+	// Requests are retransmitted with exponential backoff, see RFC 6887 section 8.1.1.
+	pcpInitialTimeout = 250 * time.Millisecond
+	pcpMaxAttempts    = 6
+
+	// pcpProbePort and pcpProbeLifetime configure the temporary mapping
+	// which is created to learn the external address.
+	pcpProbePort     = 9 // discard
+	pcpProbeLifetime = 2 * time.Minute
+)
+
+var (
+	errPCPTimeout    = errors.New("PCP request timed out")
+	errPCPOnlyNATPMP = errors.New("gateway only supports NAT-PMP")
+	errPCPProtocol   = errors.New("unknown protocol")
+	errPCPMismatch   = errors.New("response does not match request")
+)
+
+// pcpResultError is a non-success result code returned by a PCP server.
+type pcpResultError uint8
+
+var pcpResultNames = [...]string{
+	1:  "unsupported version",
+	2:  "not authorized",
+	3:  "malformed request",
+	4:  "unsupported opcode",
+	5:  "unsupported option",
+	6:  "malformed option",
+	7:  "network failure",
+	8:  "no resources",
+	9:  "unsupported protocol",
+	10: "user exceeded quota",
+	11: "cannot provide external",
+	12: "address mismatch",
+	13: "excessive remote peers",
+}
+
+func (e pcpResultError) Error() string {
+	if int(e) < len(pcpResultNames) && pcpResultNames[e] != "" {
+		return "PCP error: " + pcpResultNames[e]
+	}
+	return fmt.Sprintf("PCP error: result code %d", uint8(e))
+}
+
+// pcp implements the Interface for a PCP server.
+type pcp struct {
+	gw   net.IP
+	port int // server port, pcpPort unless testing
+
+	mu     sync.Mutex
+	nonces map[pcpMappingKey][12]byte
+}
+
+// pcpMappingKey identifies a mapping. The server requires refreshes and
+// deletions of a mapping to carry the nonce which was used to create it.
+type pcpMappingKey struct {
+	proto   uint8
+	intport uint16
+}
+
+type pcpMapResponse struct {
+	lifetime time.Duration
+	extport  uint16
+	extIP    net.IP
+}
+
+func newPCP(gw net.IP) *pcp {
+	return &pcp{gw: gw, port: pcpPort, nonces: make(map[pcpMappingKey][12]byte)}
+}
+
+func (n *pcp) String() string {
+	return fmt.Sprintf("PCP(%v)", n.gw)
+}
+
+// ExternalIP returns the external address of the gateway. PCP has no request for
+// querying it directly, so a short-lived mapping is created and deleted again.
+func (n *pcp) ExternalIP() (net.IP, error) {
+	key := pcpMappingKey{pcpProtoUDP, pcpProbePort}
+	nonce := n.nonce(key)
+	defer n.forget(key)
+
+	res, err := n.request(pcpProtoUDP, pcpProbePort, 0, pcpProbeLifetime, nonce)
+	if err != nil {
+		return nil, err
+	}
+	n.request(pcpProtoUDP, pcpProbePort, res.extport, 0, nonce)
+	return res.extIP, nil
+}
+
+func (n *pcp) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (uint16, error) {
+	if lifetime <= 0 {
+		return 0, fmt.Errorf("lifetime must not be <= 0")
+	}
+	proto, err := pcpProtocol(protocol)
+	if err != nil {
+		return 0, err
+	}
+	key := pcpMappingKey{proto, uint16(intport)}
+	res, err := n.request(proto, uint16(intport), uint16(extport), lifetime, n.nonce(key))
+	if err != nil {
+		return 0, err
+	}
+	// Like NAT-PMP, the server may assign a different external port.
+	return res.extport, nil
+}
+
+func (n *pcp) DeleteMapping(protocol string, extport, intport int) error {
+	proto, err := pcpProtocol(protocol)
+	if err != nil {
+		return err
+	}
+	// A mapping is deleted by requesting a lifetime of zero.
+	key := pcpMappingKey{proto, uint16(intport)}
+	defer n.forget(key)
+	_, err = n.request(proto, uint16(intport), uint16(extport), 0, n.nonce(key))
+	return err
+}
+
+// nonce returns the nonce of a mapping, creating it if necessary.
+func (n *pcp) nonce(key pcpMappingKey) [12]byte {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	nonce, ok := n.nonces[key]
+	if !ok {
+		rand.Read(nonce[:])
+		n.nonces[key] = nonce
+	}
+	return nonce
+}
+
+func (n *pcp) forget(key pcpMappingKey) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	delete(n.nonces, key)
+}
+
+func pcpProtocol(protocol string) (uint8, error) {
+	switch strings.ToUpper(protocol) {
+	case "TCP":
+		return pcpProtoTCP, nil
+	case "UDP":
+		return pcpProtoUDP, nil
+	default:
+		return 0, errPCPProtocol
+	}
+}
+
+// request sends a MAP request to the server and waits for the response.
+func (n *pcp) request(proto uint8, intport, extport uint16, lifetime time.Duration, nonce [12]byte) (*pcpMapResponse, error) {
+	conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: n.gw, Port: n.port})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// The client address in the request must be the source address of the
+	// packet, otherwise the server answers with an address mismatch error.
+	local := conn.LocalAddr().(*net.UDPAddr).IP
+	req := encodePCPMapRequest(local, proto, intport, extport, lifetime, nonce)
+
+	buf := make([]byte, 1100) // maximum PCP message size
+	timeout := pcpInitialTimeout
+	for attempt := 0; attempt < pcpMaxAttempts; attempt++ {
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+		conn.SetReadDeadline(time.Now().Add(timeout))
+		for {
+			nbytes, err := conn.Read(buf)
+			if err != nil {
+				if ne, ok := err.(net.Error); ok && ne.Timeout() {
+					break
+				}
+				return nil, err
+			}
+			res, err := decodePCPMapResponse(buf[:nbytes], proto, intport, nonce)
+			if err == errPCPMismatch {
+				continue // stale or unrelated response
+			} else if err != nil {
+				return nil, err
+			}
+			return res, nil
+		}
+		timeout *= 2
+	}
+	return nil, errPCPTimeout
+}
+
+// encodePCPMapRequest creates a MAP request packet.
+func encodePCPMapRequest(client net.IP, proto uint8, intport, extport uint16, lifetime time.Duration, nonce [12]byte) []byte {
+	req := make([]byte, pcpPacketSize)
+	req[0] = pcpVersion
+	req[1] = pcpOpMap
+	binary.BigEndian.PutUint32(req[4:], uint32(lifetime/time.Second))
+	copy(req[8:24], client.To16())
+
+	body := req[pcpHeaderSize:]
+	copy(body[0:12], nonce[:])
+	body[12] = proto
+	binary.BigEndian.PutUint16(body[16:], intport)
+	binary.BigEndian.PutUint16(body[18:], extport)
+	// The suggested external address is left unspecified, which is all zeros
+	// for IPv6 and the IPv4-mapped zero address for IPv4.
+	if client.To4() != nil {
+		copy(body[20:36], net.IPv4zero.To16())
+	}
+	return req
+}
+
+// decodePCPMapResponse parses a response to a MAP request.
+func decodePCPMapResponse(buf []byte, proto uint8, intport uint16, nonce [12]byte) (*pcpMapResponse, error) {
+	if len(buf) >= 4 && buf[0] == 0 {
+		// NAT-PMP servers answer with their own version number.
+		return nil, errPCPOnlyNATPMP
+	}
+	if len(buf) < pcpHeaderSize || buf[0] != pcpVersion || buf[1] != pcpResponseBit|pcpOpMap {
+		return nil, errPCPMismatch
+	}
+	if result := buf[3]; result != 0 {
+		// Error responses may not include the opcode-specific body.
+		if len(buf) >= pcpPacketSize && !bytes.Equal(buf[pcpHeaderSize:pcpHeaderSize+12], nonce[:]) {
+			return nil, errPCPMismatch
+		}
+		return nil, pcpResultError(result)
+	}
+	if len(buf) < pcpPacketSize {
+		return nil, errPCPMismatch
+	}
+	body := buf[pcpHeaderSize:]
+	if !bytes.Equal(body[0:12], nonce[:]) || body[12] != proto || binary.BigEndian.Uint16(body[16:]) != intport {
+		return nil, errPCPMismatch
+	}
+	res := &pcpMapResponse{
+		lifetime: time.Duration(binary.BigEndian.Uint32(buf[4:])) * time.Second,
+		extport:  binary.BigEndian.Uint16(body[18:]),
+		extIP:    make(net.IP, 16),
+	}
+	copy(res.extIP, body[20:36])
+	if ip4 := res.extIP.To4(); ip4 != nil {
+		res.extIP = ip4
+	}
+	return res, nil
+}
+
+func discoverPCP() Interface {
+	// run external address lookups on all potential gateways
+	gws := potentialGateways()
+	found := make(chan *pcp, len(gws))
+	for i := range gws {
+		gw := gws[i]
+		go func() {
+			c := newPCP(gw)
+			if _, err := c.ExternalIP(); err != nil {
+				found <- nil
+			} else {
+				found <- c
+			}
+		}()
+	}
+	// return the one that responds first.
+	timeout := time.NewTimer(1 * time.Second)
+	defer timeout.Stop()
+	for range gws {
+		select {
+		case c := <-found:
+			if c != nil {
+				return c
+			}
+		case <-timeout.C:
+			return nil
+		}
+	}
+	return nil
+}
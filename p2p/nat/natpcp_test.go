@@ -0,0 +1,182 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package nat
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePCP is a minimal PCP server which maps ports one higher than requested.
+type fakePCP struct {
+	conn  *net.UDPConn
+	extIP net.IP
+
+	mu       sync.Mutex
+	mappings map[pcpMappingKey][12]byte
+}
+
+func startFakePCP(t *testing.T, listenIP, extIP net.IP) *fakePCP {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: listenIP})
+	if err != nil {
+		t.Skip("can't listen:", err)
+	}
+	s := &fakePCP{conn: conn, extIP: extIP, mappings: make(map[pcpMappingKey][12]byte)}
+	t.Cleanup(func() { conn.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakePCP) client() *pcp {
+	addr := s.conn.LocalAddr().(*net.UDPAddr)
+	c := newPCP(addr.IP)
+	c.port = addr.Port
+	return c
+}
+
+func (s *fakePCP) serve() {
+	buf := make([]byte, 1100)
+	for {
+		n, from, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if n != pcpPacketSize || buf[0] != pcpVersion || buf[1] != pcpOpMap {
+			continue
+		}
+		res := make([]byte, pcpPacketSize)
+		copy(res[pcpHeaderSize:], buf[pcpHeaderSize:n])
+		res[0] = pcpVersion
+		res[1] = pcpResponseBit | pcpOpMap
+		res[3] = s.handle(buf[:n], res, from)
+		s.conn.WriteToUDP(res, from)
+	}
+}
+
+func (s *fakePCP) handle(req, res []byte, from *net.UDPAddr) uint8 {
+	if !net.IP(req[8:24]).Equal(from.IP) {
+		return 12 // address mismatch
+	}
+	var (
+		body     = req[pcpHeaderSize:]
+		lifetime = binary.BigEndian.Uint32(req[4:])
+		nonce    [12]byte
+		key      = pcpMappingKey{body[12], binary.BigEndian.Uint16(body[16:])}
+	)
+	copy(nonce[:], body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.mappings[key]; ok && existing != nonce {
+		return 2 // not authorized
+	}
+	if lifetime == 0 {
+		delete(s.mappings, key)
+		return 0
+	}
+	s.mappings[key] = nonce
+	copy(res[4:], req[4:8])
+	binary.BigEndian.PutUint16(res[pcpHeaderSize+18:], key.intport+1)
+	copy(res[pcpHeaderSize+20:], s.extIP.To16())
+	return 0
+}
+
+func (s *fakePCP) mapped(proto uint8, intport uint16) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.mappings[pcpMappingKey{proto, intport}]
+	return ok
+}
+
+func TestPCP(t *testing.T) {
+	t.Run("IPv4", func(t *testing.T) {
+		testPCP(t, net.IP{127, 0, 0, 1}, net.IP{203, 0, 113, 7})
+	})
+	t.Run("IPv6", func(t *testing.T) {
+		testPCP(t, net.IPv6loopback, net.ParseIP("2001:db8::7"))
+	})
+}
+
+func testPCP(t *testing.T, listenIP, extIP net.IP) {
+	var (
+		server = startFakePCP(t, listenIP, extIP)
+		c      = server.client()
+	)
+	ip, err := c.ExternalIP()
+	if err != nil {
+		t.Fatal("ExternalIP failed:", err)
+	}
+	if !ip.Equal(extIP) {
+		t.Fatalf("wrong external IP %v, want %v", ip, extIP)
+	}
+	if server.mapped(pcpProtoUDP, pcpProbePort) {
+		t.Fatal("probe mapping not deleted")
+	}
+
+	// Add a mapping and refresh it.
+	for i := 0; i < 2; i++ {
+		port, err := c.AddMapping("tcp", 30303, 30303, "test", time.Minute)
+		if err != nil {
+			t.Fatalf("AddMapping %d failed: %v", i, err)
+		}
+		if port != 30304 {
+			t.Fatalf("wrong external port %d", port)
+		}
+	}
+	if !server.mapped(pcpProtoTCP, 30303) {
+		t.Fatal("mapping not created")
+	}
+
+	// Another client can't take over the mapping.
+	other := server.client()
+	if _, err := other.AddMapping("tcp", 30303, 30303, "test", time.Minute); err != pcpResultError(2) {
+		t.Fatalf("wrong error for foreign mapping: %v", err)
+	}
+
+	if err := c.DeleteMapping("TCP", 30304, 30303); err != nil {
+		t.Fatal("DeleteMapping failed:", err)
+	}
+	if server.mapped(pcpProtoTCP, 30303) {
+		t.Fatal("mapping not deleted")
+	}
+}
+
+func TestPCPOnlyNATPMP(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IP{127, 0, 0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	go func() {
+		buf := make([]byte, 1100)
+		_, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		// NAT-PMP answers with version 0 and 'unsupported version'.
+		conn.WriteToUDP([]byte{0, 0x80 | buf[1], 0, 1, 0, 0, 0, 0}, from)
+	}()
+
+	c := newPCP(net.IP{127, 0, 0, 1})
+	c.port = conn.LocalAddr().(*net.UDPAddr).Port
+	if _, err := c.ExternalIP(); err != errPCPOnlyNATPMP {
+		t.Fatalf("wrong error: %v", err)
+	}
+}
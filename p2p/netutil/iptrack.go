@@ -77,7 +77,9 @@ func (it *IPTracker) PredictFullConeNAT() bool {
 func (it *IPTracker) PredictEndpoint() string {
 	it.gcStatements(it.clock.Now())
 
-	// The current strategy is simple: find the endpoint with most statements.
+	// The endpoint is chosen by majority vote: it must be the one stated by more
+	// than half of the hosts. When statements are split, e.g. because a symmetric
+	// NAT assigns a different port for every destination, there is no prediction.
 	counts := make(map[string]int, len(it.statements))
 	maxcount, max := 0, ""
 	for _, s := range it.statements {
@@ -87,6 +89,9 @@ func (it *IPTracker) PredictEndpoint() string {
 			maxcount, max = c, s.endpoint
 		}
 	}
+	if maxcount*2 <= len(it.statements) {
+		return ""
+	}
 	return max
 }
 
@@ -58,6 +58,18 @@ func TestIPTracker(t *testing.T) {
 			{opStatement, 10100, "127.0.0.1", "127.0.0.2"},
 			{opPredict, 10200, "127.0.0.1", ""},
 		},
+		"majority": {
+			{opStatement, 0, "127.0.0.1", "127.0.0.2"},
+			{opStatement, 0, "127.0.0.1", "127.0.0.3"},
+			{opStatement, 0, "127.0.0.1", "127.0.0.4"},
+			{opStatement, 0, "127.0.0.5", "127.0.0.6"},
+			{opStatement, 0, "127.0.0.5", "127.0.0.7"},
+			{opPredict, 0, "127.0.0.1", ""},
+			{opStatement, 0, "127.0.0.5", "127.0.0.8"},
+			{opPredict, 0, "", ""}, // no majority
+			{opStatement, 0, "127.0.0.5", "127.0.0.2"},
+			{opPredict, 0, "127.0.0.5", ""},
+		},
 		"fullcone": {
 			{opContact, 0, "", "127.0.0.2"},
 			{opStatement, 10, "127.0.0.1", "127.0.0.2"},
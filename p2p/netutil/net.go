@@ -217,10 +217,16 @@ func sameNet(bits uint, ip, other net.IP) bool {
 
 // DistinctNetSet tracks IPs, ensuring that at most N of them
 // fall into the same network range.
+//
+// Since IPv6 networks are allocated in much larger blocks than IPv4 networks,
+// the range and limit can be configured separately for IPv6 addresses.
 type DistinctNetSet struct {
 	Subnet uint // number of common prefix bits
 	Limit  uint // maximum number of IPs in each subnet
 
+	Subnet6 uint // number of common prefix bits of IPv6 addresses, Subnet if zero
+	Limit6  uint // maximum number of IPv6 addresses in each subnet, Limit if zero
+
 	members map[string]uint
 	buf     net.IP
 }
@@ -230,7 +236,7 @@ type DistinctNetSet struct {
 func (s *DistinctNetSet) Add(ip net.IP) bool {
 	key := s.key(ip)
 	n := s.members[string(key)]
-	if n < s.Limit {
+	if n < s.limit(key) {
 		s.members[string(key)] = n + 1
 		return true
 	}
@@ -281,6 +287,9 @@ func (s *DistinctNetSet) key(ip net.IP) net.IP {
 		typ, ip = '4', ip4
 	}
 	bits := s.Subnet
+	if typ == '6' && s.Subnet6 != 0 {
+		bits = s.Subnet6
+	}
 	if bits > uint(len(ip)*8) {
 		bits = uint(len(ip) * 8)
 	}
@@ -295,6 +304,14 @@ func (s *DistinctNetSet) key(ip net.IP) net.IP {
 	return buf
 }
 
+// limit returns the maximum number of IPs in the subnet of the given key.
+func (s *DistinctNetSet) limit(key net.IP) uint {
+	if key[0] == '6' && s.Limit6 != 0 {
+		return s.Limit6
+	}
+	return s.Limit
+}
+
 // String implements fmt.Stringer
 func (s DistinctNetSet) String() string {
 	var buf bytes.Buffer
@@ -243,6 +243,31 @@ func TestDistinctNetSet(t *testing.T) {
 	}
 }
 
+func TestDistinctNetSetIPv6(t *testing.T) {
+	set := DistinctNetSet{Subnet: 24, Limit: 1, Subnet6: 48, Limit6: 2}
+	ops := []struct {
+		add   string
+		fails bool
+	}{
+		{add: "1.2.3.4"},
+		{add: "1.2.3.5", fails: true},
+		{add: "2001:db8:1:1::1"},
+		{add: "2001:db8:1:2::1"},
+		{add: "2001:db8:1:3::1", fails: true},
+		{add: "2001:db8:2::1"},
+		// IPv6 addresses don't count towards the IPv4 limit.
+		{add: "1.2.4.1"},
+	}
+	for _, op := range ops {
+		if ok := set.Add(parseIP(op.add)); ok != !op.fails {
+			t.Errorf("Add(%s) == %t, want %t", op.add, ok, !op.fails)
+		}
+	}
+	if set.Len() != 5 {
+		t.Errorf("wrong set length %d, want 5", set.Len())
+	}
+}
+
 func TestDistinctNetSetAddRemove(t *testing.T) {
 	cfg := &quick.Config{}
 	fn := func(ips []net.IP) bool {
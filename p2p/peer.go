@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/capture"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -118,6 +119,12 @@ type Peer struct {
 	// events receives message send / receive events if set
 	events   *event.Feed
 	testPipe *MsgPipeRW // for testing
+
+	// reputation tracks the score of the peer if set
+	reputation *reputationStore
+
+	// capture records all subprotocol messages if set
+	capture *capture.Writer
 }
 
 // NewPeer returns a peer for testing purposes.
@@ -240,6 +247,29 @@ func newPeer(log log.Logger, conn *conn, protocols []Protocol) *Peer {
 	return p
 }
 
+// Score returns the reputation score of the remote node.
+func (p *Peer) Score() int64 {
+	if p.reputation == nil {
+		return 0
+	}
+	return p.reputation.score(p.ID())
+}
+
+// Report adjusts the reputation of the remote node according to an observed
+// event. Peers whose score drops to the ban threshold are disconnected unless
+// they are trusted.
+func (p *Peer) Report(ev ReputationEvent) {
+	if p.reputation == nil {
+		return
+	}
+	score := p.reputation.report(p.ID(), ev)
+	p.log.Trace("Adjusted peer reputation", "event", ev, "score", score)
+	if score <= BanReputation && !p.rw.is(trustedConn) {
+		p.log.Debug("Dropping peer with bad reputation", "score", score)
+		p.Disconnect(DiscUselessPeer)
+	}
+}
+
 func (p *Peer) Log() log.Logger {
 	return p.log
 }
@@ -281,6 +311,11 @@ loop:
 			break loop
 		case err = <-p.protoErr:
 			reason = discReasonForError(err)
+			if (reason == DiscProtocolError || reason == DiscSubprotocolError) && p.reputation != nil {
+				// Only record the violation, the peer is being dropped anyway.
+				// Report would block on Disconnect, which waits for this loop.
+				p.reputation.report(p.ID(), ProtocolViolation)
+			}
 			break loop
 		case err = <-p.disc:
 			reason = discReasonForError(err)
@@ -417,6 +452,9 @@ func (p *Peer) startProtocols(writeStart <-chan struct{}, writeErr chan<- error)
 		proto.wstart = writeStart
 		proto.werr = writeErr
 		var rw MsgReadWriter = proto
+		if p.capture != nil {
+			rw = newMsgCapturer(rw, p.capture, p.ID(), proto.Name, proto.Version)
+		}
 		if p.events != nil {
 			rw = newMsgEventer(rw, p.events, p.ID(), proto.Name, p.Info().Network.RemoteAddress, p.Info().Network.LocalAddress)
 		}
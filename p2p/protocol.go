@@ -60,6 +60,11 @@ type Protocol struct {
 	// attempts to create connections to them.
 	DialCandidates enode.Iterator
 
+	// DiscoveryTopic, if set, is the discovery v5 topic the local node is advertised
+	// under for this protocol. Nodes registered for the topic are used as additional
+	// dial candidates. The topic is ignored if discovery v5 is not running.
+	DiscoveryTopic string
+
 	// Attributes contains protocol specific information for the node record.
 	Attributes []enr.Entry
 }
@@ -0,0 +1,479 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/quic-go/quic-go"
+)
+
+// The QUIC transport is an experimental alternative to RLPx over TCP. Peers are
+// authenticated by the TLS handshake of the QUIC connection, using self-signed
+// certificates bound to the node key. After the devp2p handshake, which is run
+// on the first stream of the connection, every matched subprotocol is sent on a
+// unidirectional stream of its own, so a slow protocol doesn't hold up the others.
+//
+// Messages are framed as the uvarint encoded message code and size, followed by
+// the payload.
+
+const (
+	// quicALPN is the application protocol negotiated in the TLS handshake.
+	quicALPN = "devp2p"
+
+	// quicStreamVersion is written as the first byte of the control stream by
+	// the dialing side, announcing the stream to the listener.
+	quicStreamVersion = 1
+
+	// quicMaxMsgSize is the maximum size of a message payload.
+	quicMaxMsgSize = 16 * 1024 * 1024
+
+	// quicIdleTimeout is the time after which connections without any traffic
+	// are closed. Keep-alive packets are sent at half this interval.
+	quicIdleTimeout = 30 * time.Second
+
+	// quicDiscCodeBase is added to disconnect reasons when they are sent as the
+	// application error code of the connection close.
+	quicDiscCodeBase = 0x100
+)
+
+var (
+	errQUICConnIO      = errors.New("QUIC connections are read and written through streams")
+	errQUICNoIdentity  = errors.New("certificate does not contain node identity")
+	errQUICWrongKey    = errors.New("remote node key does not match dial destination")
+	errQUICMsgTooLarge = errors.New("message too large")
+
+	// quicIdentityOID identifies the certificate extension holding the signature
+	// of the node key over the certificate public key.
+	quicIdentityOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 37476, 9000, 1, 1}
+
+	// quicIdentityPrefix is prepended to the certificate public key when it is
+	// signed by the node key.
+	quicIdentityPrefix = []byte("devp2p-quic-tls:")
+)
+
+// newQUICConfig returns the QUIC connection parameters of devp2p connections.
+func newQUICConfig() *quic.Config {
+	return &quic.Config{
+		HandshakeIdleTimeout: handshakeTimeout,
+		MaxIdleTimeout:       quicIdleTimeout,
+		KeepAlivePeriod:      quicIdleTimeout / 2,
+	}
+}
+
+// newQUICCertificate creates a self-signed TLS certificate with an ephemeral
+// key. The certificate key is signed by the node key, binding the two together.
+func newQUICCertificate(prv *ecdsa.PrivateKey) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	spki, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	sig, err := crypto.Sign(quicIdentityHash(spki), prv)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(100 * 365 * 24 * time.Hour),
+		ExtraExtensions: []pkix.Extension{{Id: quicIdentityOID, Value: sig}},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{cert}, PrivateKey: key}, nil
+}
+
+// quicIdentityHash returns the hash signed by the node key.
+func quicIdentityHash(spki []byte) []byte {
+	return crypto.Keccak256(quicIdentityPrefix, spki)
+}
+
+// quicPeerKey extracts the node key from the certificate presented by a peer.
+func quicPeerKey(raw []byte) (*ecdsa.PublicKey, error) {
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		return nil, err
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(quicIdentityOID) {
+			return crypto.SigToPub(quicIdentityHash(cert.RawSubjectPublicKeyInfo), ext.Value)
+		}
+	}
+	return nil, errQUICNoIdentity
+}
+
+// newQUICTLSConfig creates the TLS configuration of a QUIC connection. The
+// certificates are not verified against any authority, instead the peer is
+// required to present a certificate signed by its node key. If dialDest is
+// non-nil, the node key must match it.
+func newQUICTLSConfig(cert tls.Certificate, dialDest *ecdsa.PublicKey) *tls.Config {
+	return &tls.Config{
+		Certificates:       []tls.Certificate{cert},
+		NextProtos:         []string{quicALPN},
+		MinVersion:         tls.VersionTLS13,
+		ClientAuth:         tls.RequireAnyClientCert,
+		InsecureSkipVerify: true, // certificates are checked against the node key below
+		VerifyPeerCertificate: func(certs [][]byte, _ [][]*x509.Certificate) error {
+			if len(certs) == 0 {
+				return errQUICNoIdentity
+			}
+			key, err := quicPeerKey(certs[0])
+			if err != nil {
+				return err
+			}
+			if dialDest != nil && !key.Equal(dialDest) {
+				return errQUICWrongKey
+			}
+			return nil
+		},
+	}
+}
+
+// quicConn passes a QUIC connection through the connection setup of the server.
+// All data is exchanged on the streams of the connection by quicTransport, the
+// net.Conn read and write methods can't be used.
+type quicConn struct {
+	quic.Connection
+}
+
+func (c *quicConn) Read([]byte) (int, error)         { return 0, errQUICConnIO }
+func (c *quicConn) Write([]byte) (int, error)        { return 0, errQUICConnIO }
+func (c *quicConn) Close() error                     { return c.CloseWithError(0, "") }
+func (c *quicConn) SetDeadline(time.Time) error      { return nil }
+func (c *quicConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *quicConn) SetWriteDeadline(time.Time) error { return nil }
+
+// quicDialer implements NodeDialer, connecting over QUIC to nodes which announce
+// a QUIC port in their record, and falling back to the wrapped dialer for all
+// other nodes or if the QUIC connection can't be established.
+type quicDialer struct {
+	socket   *quic.Transport
+	cert     tls.Certificate
+	fallback NodeDialer
+	log      log.Logger
+}
+
+func (d *quicDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	if port := dest.QUIC(); port != 0 && dest.IP() != nil {
+		conn, err := d.dialQUIC(ctx, dest, port)
+		if err == nil {
+			return conn, nil
+		}
+		d.log.Trace("QUIC dial failed, falling back to TCP", "id", dest.ID(), "port", port, "err", err)
+	}
+	return d.fallback.Dial(ctx, dest)
+}
+
+func (d *quicDialer) dialQUIC(ctx context.Context, dest *enode.Node, port int) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, handshakeTimeout)
+	defer cancel()
+
+	addr := &net.UDPAddr{IP: dest.IP(), Port: port}
+	conn, err := d.socket.Dial(ctx, addr, newQUICTLSConfig(d.cert, dest.Pubkey()), newQUICConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &quicConn{conn}, nil
+}
+
+// quicTransport is the transport of QUIC connections.
+type quicTransport struct {
+	conn      quic.Connection
+	dialDest  *ecdsa.PublicKey
+	protocols []Protocol
+
+	in      chan Msg      // messages received on all streams
+	errc    chan error    // first read error of any stream
+	closing chan struct{} // closed when the transport is closed
+	once    sync.Once
+
+	smu     sync.Mutex
+	offsets []uint64           // message code offsets of the matched protocols
+	streams []*quicWriteStream // write streams: control stream, then one per matched protocol
+}
+
+// quicWriteStream is a lazily opened outgoing stream.
+type quicWriteStream struct {
+	mu     sync.Mutex
+	stream quic.SendStream
+	buf    bytes.Buffer
+}
+
+func newQUICTransport(conn *quicConn, dialDest *ecdsa.PublicKey, protocols []Protocol) transport {
+	return &quicTransport{
+		conn:      conn.Connection,
+		dialDest:  dialDest,
+		protocols: protocols,
+		in:        make(chan Msg),
+		errc:      make(chan error, 1),
+		closing:   make(chan struct{}),
+	}
+}
+
+// doEncHandshake returns the node key authenticated by the TLS handshake and
+// sets up the control stream.
+func (t *quicTransport) doEncHandshake(prv *ecdsa.PrivateKey) (*ecdsa.PublicKey, error) {
+	certs := t.conn.ConnectionState().TLS.PeerCertificates
+	if len(certs) == 0 {
+		return nil, errQUICNoIdentity
+	}
+	remote, err := quicPeerKey(certs[0].Raw)
+	if err != nil {
+		return nil, err
+	}
+	if t.dialDest != nil && !remote.Equal(t.dialDest) {
+		return nil, errQUICWrongKey
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+	defer cancel()
+
+	var ctrl quic.Stream
+	if t.dialDest != nil {
+		// The dialer opens the control stream. The stream only becomes visible
+		// to the listener when data is sent, so announce it right away.
+		if ctrl, err = t.conn.OpenStreamSync(ctx); err != nil {
+			return nil, err
+		}
+		ctrl.SetWriteDeadline(time.Now().Add(handshakeTimeout))
+		if _, err = ctrl.Write([]byte{quicStreamVersion}); err != nil {
+			return nil, err
+		}
+	} else {
+		if ctrl, err = t.conn.AcceptStream(ctx); err != nil {
+			return nil, err
+		}
+		ctrl.SetReadDeadline(time.Now().Add(handshakeTimeout))
+		var version [1]byte
+		if _, err = io.ReadFull(ctrl, version[:]); err != nil {
+			return nil, err
+		}
+		if version[0] != quicStreamVersion {
+			return nil, fmt.Errorf("unsupported QUIC stream version %d", version[0])
+		}
+		ctrl.SetReadDeadline(time.Time{})
+	}
+	t.streams = []*quicWriteStream{{stream: ctrl}}
+
+	go t.readLoop(ctrl)
+	go t.acceptLoop()
+	return remote, nil
+}
+
+func (t *quicTransport) doProtoHandshake(our *protoHandshake) (their *protoHandshake, err error) {
+	// The handshake is bounded by closing the connection if it takes too long.
+	timer := time.AfterFunc(handshakeTimeout, func() {
+		t.conn.CloseWithError(0, "handshake timeout")
+	})
+	defer timer.Stop()
+
+	werr := make(chan error, 1)
+	go func() { werr <- Send(t, handshakeMsg, our) }()
+	if their, err = readProtocolHandshake(t); err != nil {
+		<-werr // make sure the write terminates too
+		return nil, err
+	}
+	if err := <-werr; err != nil {
+		return nil, fmt.Errorf("write error: %v", err)
+	}
+	// Assign the protocol streams. Both ends match the protocols in the same
+	// way, but streams are only used for sending, so it doesn't matter if
+	// they disagree.
+	matched := matchProtocols(t.protocols, their.Caps, nil)
+	t.smu.Lock()
+	for _, proto := range matched {
+		t.offsets = append(t.offsets, proto.offset)
+	}
+	sort.Slice(t.offsets, func(i, j int) bool { return t.offsets[i] < t.offsets[j] })
+	for range t.offsets {
+		t.streams = append(t.streams, new(quicWriteStream))
+	}
+	t.smu.Unlock()
+	return their, nil
+}
+
+// acceptLoop accepts the protocol streams opened by the remote end.
+func (t *quicTransport) acceptLoop() {
+	for {
+		stream, err := t.conn.AcceptUniStream(context.Background())
+		if err != nil {
+			t.readFailed(err)
+			return
+		}
+		go t.readLoop(stream)
+	}
+}
+
+// readLoop reads messages from a stream until it fails.
+func (t *quicTransport) readLoop(stream quic.ReceiveStream) {
+	r := bufio.NewReader(stream)
+	for {
+		msg, err := readQUICMsg(r)
+		if err != nil {
+			t.readFailed(err)
+			return
+		}
+		select {
+		case t.in <- msg:
+		case <-t.closing:
+			return
+		}
+	}
+}
+
+// readFailed records a read error, translating disconnects of the remote end.
+func (t *quicTransport) readFailed(err error) {
+	var appErr *quic.ApplicationError
+	if errors.As(err, &appErr) && appErr.Remote && appErr.ErrorCode >= quicDiscCodeBase {
+		err = DiscReason(appErr.ErrorCode - quicDiscCodeBase)
+	}
+	select {
+	case t.errc <- err:
+	default:
+	}
+}
+
+func readQUICMsg(r *bufio.Reader) (Msg, error) {
+	code, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Msg{}, err
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Msg{}, err
+	}
+	if size > quicMaxMsgSize {
+		return Msg{}, errQUICMsgTooLarge
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return Msg{}, err
+	}
+	return Msg{
+		ReceivedAt: time.Now(),
+		Code:       code,
+		Size:       uint32(size),
+		meterSize:  uint32(size),
+		Payload:    bytes.NewReader(data),
+	}, nil
+}
+
+func (t *quicTransport) ReadMsg() (Msg, error) {
+	select {
+	case msg := <-t.in:
+		return msg, nil
+	case err := <-t.errc:
+		// Keep the error around for subsequent reads.
+		t.readFailed(err)
+		return Msg{}, err
+	case <-t.closing:
+		return Msg{}, io.EOF
+	}
+}
+
+func (t *quicTransport) WriteMsg(msg Msg) error {
+	if msg.Size > quicMaxMsgSize {
+		return errQUICMsgTooLarge
+	}
+	ws := t.writeStream(msg.Code)
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.stream == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), frameWriteTimeout)
+		stream, err := t.conn.OpenUniStreamSync(ctx)
+		cancel()
+		if err != nil {
+			return err
+		}
+		ws.stream = stream
+	}
+	ws.buf.Reset()
+	var hdr [2 * binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(hdr[:], msg.Code)
+	n += binary.PutUvarint(hdr[n:], uint64(msg.Size))
+	ws.buf.Write(hdr[:n])
+	if _, err := io.CopyN(&ws.buf, msg.Payload, int64(msg.Size)); err != nil {
+		return err
+	}
+	ws.stream.SetWriteDeadline(time.Now().Add(frameWriteTimeout))
+	if _, err := ws.stream.Write(ws.buf.Bytes()); err != nil {
+		return err
+	}
+
+	// Set metrics.
+	msg.meterSize = uint32(ws.buf.Len())
+	if metrics.Enabled && msg.meterCap.Name != "" { // don't meter non-subprotocol messages
+		m := fmt.Sprintf("%s/%s/%d/%#02x", egressMeterName, msg.meterCap.Name, msg.meterCap.Version, msg.meterCode)
+		metrics.GetOrRegisterMeter(m, nil).Mark(int64(msg.meterSize))
+		metrics.GetOrRegisterMeter(m+"/packets", nil).Mark(1)
+	}
+	return nil
+}
+
+// writeStream returns the stream carrying messages with the given code. Base
+// protocol messages are sent on the control stream.
+func (t *quicTransport) writeStream(code uint64) *quicWriteStream {
+	t.smu.Lock()
+	defer t.smu.Unlock()
+
+	index := sort.Search(len(t.offsets), func(i int) bool { return t.offsets[i] > code })
+	return t.streams[index]
+}
+
+func (t *quicTransport) close(err error) {
+	t.once.Do(func() {
+		close(t.closing)
+
+		// Tell the remote end why we're disconnecting. The reason is sent as
+		// the error code of the connection close, as any pending stream data
+		// is discarded.
+		if r, ok := err.(DiscReason); ok && r != DiscNetworkError {
+			t.conn.CloseWithError(quic.ApplicationErrorCode(quicDiscCodeBase+uint64(r)), r.String())
+		} else {
+			t.conn.CloseWithError(0, "")
+		}
+	})
+}
@@ -0,0 +1,304 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/quic-go/quic-go"
+)
+
+func newQUICTestSocket(t *testing.T) *quic.Transport {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IP{127, 0, 0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := &quic.Transport{Conn: conn}
+	t.Cleanup(func() {
+		socket.Close()
+		conn.Close()
+	})
+	return socket
+}
+
+// quicPipe establishes a QUIC connection between two keys, returning the
+// transports of the dialer and the listener.
+func quicPipe(t *testing.T, dialKey, listenKey *ecdsa.PrivateKey, dialDest *ecdsa.PublicKey, protocols []Protocol) (transport, transport, error) {
+	dialCert, err := newQUICCertificate(dialKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listenCert, err := newQUICCertificate(listenKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dialSocket, listenSocket := newQUICTestSocket(t), newQUICTestSocket(t)
+	ln, err := listenSocket.Listen(newQUICTLSConfig(listenCert, nil), newQUICConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	accepted := make(chan quic.Connection, 1)
+	go func() {
+		conn, _ := ln.Accept(ctx)
+		accepted <- conn
+	}()
+	conn, err := dialSocket.Dial(ctx, listenSocket.Conn.LocalAddr(), newQUICTLSConfig(dialCert, dialDest), newQUICConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+	lconn := <-accepted
+	if lconn == nil {
+		return nil, nil, errors.New("no connection accepted")
+	}
+	return newQUICTransport(&quicConn{conn}, dialDest, protocols), newQUICTransport(&quicConn{lconn}, nil, protocols), nil
+}
+
+func TestQUICTransportHandshake(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dialKey, listenKey = newkey(), newkey()
+		protocols          = []Protocol{{Name: "a", Version: 1, Length: 2}, {Name: "b", Version: 1, Length: 3}}
+	)
+	dialer, listener, err := quicPipe(t, dialKey, listenKey, &listenKey.PublicKey, protocols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dialer.close(nil)
+	defer listener.close(nil)
+
+	// Both ends learn the node key of the other end.
+	type result struct {
+		key *ecdsa.PublicKey
+		hs  *protoHandshake
+		err error
+	}
+	run := func(tr transport, key *ecdsa.PrivateKey, res chan<- result) {
+		remote, err := tr.doEncHandshake(key)
+		if err != nil {
+			res <- result{err: err}
+			return
+		}
+		pub := crypto.FromECDSAPub(&key.PublicKey)[1:]
+		hs := &protoHandshake{Version: baseProtocolVersion, ID: pub}
+		for _, p := range protocols {
+			hs.Caps = append(hs.Caps, p.cap())
+		}
+		their, err := tr.doProtoHandshake(hs)
+		res <- result{remote, their, err}
+	}
+	dres, lres := make(chan result, 1), make(chan result, 1)
+	go run(dialer, dialKey, dres)
+	go run(listener, listenKey, lres)
+	d, l := <-dres, <-lres
+	if d.err != nil || l.err != nil {
+		t.Fatalf("handshake failed: dialer %v, listener %v", d.err, l.err)
+	}
+	if !d.key.Equal(&listenKey.PublicKey) || !l.key.Equal(&dialKey.PublicKey) {
+		t.Fatal("wrong remote keys after handshake")
+	}
+	if len(d.hs.Caps) != 2 || len(l.hs.Caps) != 2 {
+		t.Fatalf("wrong caps in handshake: %v %v", d.hs.Caps, l.hs.Caps)
+	}
+
+	// Messages of the base protocol and both subprotocols go through.
+	codes := []uint64{pingMsg, baseProtocolLength, baseProtocolLength + 1, baseProtocolLength + 2, baseProtocolLength + 4}
+	for _, code := range codes {
+		if err := Send(dialer, code, []uint{uint(code)}); err != nil {
+			t.Fatalf("send %d failed: %v", code, err)
+		}
+	}
+	// Messages of a single stream are delivered in order, there is no order
+	// across streams.
+	received := make(map[uint64]bool)
+	for range codes {
+		msg, err := listener.ReadMsg()
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		var content []uint
+		if err := msg.Decode(&content); err != nil || len(content) != 1 || uint64(content[0]) != msg.Code {
+			t.Fatalf("wrong content of message %d: %v %v", msg.Code, content, err)
+		}
+		received[msg.Code] = true
+	}
+	if len(received) != len(codes) {
+		t.Fatalf("wrong messages received: %v", received)
+	}
+
+	// Disconnect reasons are passed on.
+	dialer.close(DiscTooManyPeers)
+	if _, err := listener.ReadMsg(); err != DiscTooManyPeers {
+		t.Fatalf("wrong read error after disconnect: %v", err)
+	}
+}
+
+func TestQUICTransportWrongKey(t *testing.T) {
+	t.Parallel()
+
+	dialKey, listenKey := newkey(), newkey()
+	if _, _, err := quicPipe(t, dialKey, listenKey, &newkey().PublicKey, nil); err == nil {
+		t.Fatal("connection to wrong node key succeeded")
+	}
+}
+
+func TestServerQUIC(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan string, 2)
+	newServer := func(quic bool) *Server {
+		srv := &Server{
+			Config: Config{
+				PrivateKey:  newkey(),
+				MaxPeers:    10,
+				NoDiscovery: true,
+				Logger:      testlog.Logger(t, log.LvlTrace),
+				ListenAddr:  "127.0.0.1:0",
+				Protocols: []Protocol{{
+					Name:    "test",
+					Version: 1,
+					Length:  1,
+					Run: func(p *Peer, rw MsgReadWriter) error {
+						if p.Inbound() {
+							msg, err := rw.ReadMsg()
+							if err != nil {
+								return err
+							}
+							var s string
+							msg.Decode(&s)
+							received <- s
+						} else if err := Send(rw, 0, "hello"); err != nil {
+							return err
+						}
+						<-p.closed
+						return nil
+					},
+				}},
+			},
+		}
+		if quic {
+			srv.QUICListenAddr = "127.0.0.1:0"
+		}
+		if err := srv.Start(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(srv.Stop)
+		return srv
+	}
+	connect := func(from, to *Server) *Peer {
+		events := make(chan *PeerEvent, 1)
+		sub := from.SubscribeEvents(events)
+		defer sub.Unsubscribe()
+
+		from.AddPeer(to.Self())
+		timeout := time.After(10 * time.Second)
+		for {
+			select {
+			case ev := <-events:
+				if ev.Type != PeerEventTypeAdd || ev.Peer != to.Self().ID() {
+					continue
+				}
+				for _, p := range from.Peers() {
+					if p.ID() == ev.Peer {
+						return p
+					}
+				}
+			case <-timeout:
+				t.Fatal("peer not connected")
+			}
+		}
+	}
+	dialer := newServer(true)
+
+	// Nodes announcing QUIC support are connected over QUIC.
+	qnode := newServer(true)
+	if qnode.Self().QUIC() == 0 {
+		t.Fatal("QUIC port missing from local node record")
+	}
+	p := connect(dialer, qnode)
+	if _, ok := p.rw.fd.(*quicConn); !ok {
+		t.Fatalf("peer connected over %T, want QUIC", p.rw.fd)
+	}
+	if s := <-received; s != "hello" {
+		t.Fatalf("wrong message received: %q", s)
+	}
+
+	// Other nodes are connected over TCP.
+	tnode := newServer(false)
+	p = connect(dialer, tnode)
+	if _, ok := p.rw.fd.(*quicConn); ok {
+		t.Fatal("peer without QUIC support connected over QUIC")
+	}
+	if s := <-received; s != "hello" {
+		t.Fatalf("wrong message received: %q", s)
+	}
+}
+
+// This test checks that dials fall back to TCP if the QUIC connection fails.
+func TestQUICDialerFallback(t *testing.T) {
+	t.Parallel()
+
+	cert, err := newQUICCertificate(newkey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fallback := new(quicFallbackRecorder)
+	d := &quicDialer{socket: newQUICTestSocket(t), cert: cert, fallback: fallback, log: testlog.Logger(t, log.LvlTrace)}
+
+	// Nothing listens on the announced QUIC port.
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IP{127, 0, 0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	conn.Close()
+
+	var r enr.Record
+	r.Set(enr.IP(net.IP{127, 0, 0, 1}))
+	r.Set(enr.QUIC(port))
+	r.Set(enr.TCP(30303))
+	node := enode.SignNull(&r, enode.ID{1})
+	if _, err := d.Dial(context.Background(), node); err != errQUICFallbackTest {
+		t.Fatalf("unexpected dial result: %v", err)
+	}
+	if fallback.dials != 1 {
+		t.Fatalf("fallback dialer not used")
+	}
+}
+
+var errQUICFallbackTest = errors.New("fallback dial")
+
+type quicFallbackRecorder struct{ dials int }
+
+func (d *quicFallbackRecorder) Dial(context.Context, *enode.Node) (net.Conn, error) {
+	d.dials++
+	return nil, errQUICFallbackTest
+}
@@ -0,0 +1,250 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+const (
+	// MaxReputation and MinReputation bound the reputation score of peers.
+	MaxReputation = 100
+	MinReputation = -100
+
+	// BanReputation is the score at or below which nodes are neither dialed
+	// nor accepted as inbound peers.
+	BanReputation = -50
+
+	// reputationHalfLife is the time it takes for a score to decay halfway
+	// back to zero, so peers can eventually redeem themselves.
+	reputationHalfLife = 6 * time.Hour
+
+	// reputationFlushInterval is the interval at which changed scores are
+	// written to the node database.
+	reputationFlushInterval = 30 * time.Second
+)
+
+// ReputationEvent is an observation about the behaviour of a peer, reported by
+// the protocol handlers to adjust its reputation.
+type ReputationEvent int
+
+const (
+	UsefulResponse    ReputationEvent = iota // peer delivered the requested data
+	UselessResponse                          // peer delivered empty or unusable data
+	RequestTimeout                           // peer failed to respond in time
+	InvalidBlock                             // peer sent an invalid block or chain
+	TxSpam                                   // peer sent transactions rejected by the pool
+	ProtocolViolation                        // peer was dropped for a protocol error
+)
+
+// reputationDeltas are the score adjustments of the reputation events.
+var reputationDeltas = [...]int64{
+	UsefulResponse:    1,
+	UselessResponse:   -2,
+	RequestTimeout:    -5,
+	InvalidBlock:      -50,
+	TxSpam:            -10,
+	ProtocolViolation: -20,
+}
+
+func (ev ReputationEvent) String() string {
+	switch ev {
+	case UsefulResponse:
+		return "useful response"
+	case UselessResponse:
+		return "useless response"
+	case RequestTimeout:
+		return "request timeout"
+	case InvalidBlock:
+		return "invalid block"
+	case TxSpam:
+		return "transaction spam"
+	case ProtocolViolation:
+		return "protocol violation"
+	default:
+		return "unknown event"
+	}
+}
+
+// reputationStore tracks the reputation scores of nodes. Scores are kept in
+// memory and periodically flushed to the node database, so they are retained
+// across reconnects and restarts without hitting the disk on every report.
+type reputationStore struct {
+	db  *enode.DB
+	now func() time.Time
+
+	entries map[enode.ID]*reputationEntry
+	mu      sync.Mutex
+}
+
+// reputationEntry is the score of a node as of its last update.
+type reputationEntry struct {
+	score   int64
+	updated time.Time
+	dirty   bool // score changed since the last flush
+}
+
+func newReputationStore(db *enode.DB) *reputationStore {
+	r := &reputationStore{
+		db:      db,
+		now:     time.Now,
+		entries: make(map[enode.ID]*reputationEntry),
+	}
+	for _, id := range db.Reputations() {
+		if score, updated := db.Reputation(id); score != 0 {
+			r.entries[id] = &reputationEntry{score: score, updated: updated}
+		}
+	}
+	return r
+}
+
+// score returns the current reputation score of a node.
+func (r *reputationStore) score(id enode.ID) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.load(id)
+}
+
+// load retrieves the score of a node, decayed to the current time.
+func (r *reputationStore) load(id enode.ID) int64 {
+	e := r.entries[id]
+	if e == nil {
+		return 0
+	}
+	return r.decay(e)
+}
+
+// decay returns the score of an entry decayed to the current time.
+func (r *reputationStore) decay(e *reputationEntry) int64 {
+	elapsed := r.now().Sub(e.updated)
+	if elapsed <= 0 {
+		return e.score
+	}
+	return int64(math.Round(float64(e.score) * math.Exp2(-float64(elapsed)/float64(reputationHalfLife))))
+}
+
+// report adjusts the score of a node according to the event.
+func (r *reputationStore) report(id enode.ID, ev ReputationEvent) int64 {
+	if int(ev) < 0 || int(ev) >= len(reputationDeltas) {
+		return r.score(id)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	score := r.load(id) + reputationDeltas[ev]
+	return r.store(id, score)
+}
+
+// set overrides the score of a node.
+func (r *reputationStore) set(id enode.ID, score int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.store(id, score)
+}
+
+func (r *reputationStore) store(id enode.ID, score int64) int64 {
+	if score > MaxReputation {
+		score = MaxReputation
+	}
+	if score < MinReputation {
+		score = MinReputation
+	}
+	// Zero scores are kept until the next flush, to delete them from the database.
+	r.entries[id] = &reputationEntry{score: score, updated: r.now(), dirty: true}
+	return score
+}
+
+// flush writes the scores changed since the last flush to the database, and
+// drops the scores which decayed to zero from memory.
+func (r *reputationStore) flush() {
+	type update struct {
+		id enode.ID
+		reputationEntry
+	}
+	var updates []update
+
+	r.mu.Lock()
+	for id, e := range r.entries {
+		if e.dirty {
+			updates = append(updates, update{id, *e})
+			e.dirty = false
+		}
+		if r.decay(e) == 0 {
+			delete(r.entries, id)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, u := range updates {
+		if u.score == 0 {
+			r.db.DeleteReputation(u.id)
+		} else if err := r.db.UpdateReputation(u.id, u.score, u.updated); err != nil {
+			log.Warn("Failed to store peer reputation", "id", u.id, "err", err)
+		}
+	}
+}
+
+// banned reports whether a node's score is too low to connect to it.
+func (r *reputationStore) banned(id enode.ID) bool {
+	return r != nil && r.score(id) <= BanReputation
+}
+
+// all returns the current scores of all nodes with a stored reputation.
+func (r *reputationStore) all() map[enode.ID]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scores := make(map[enode.ID]int64)
+	for id := range r.entries {
+		if score := r.load(id); score != 0 {
+			scores[id] = score
+		}
+	}
+	return scores
+}
+
+// PeerScore returns the reputation score of a node.
+func (srv *Server) PeerScore(id enode.ID) int64 {
+	if srv.reputation == nil {
+		return 0
+	}
+	return srv.reputation.score(id)
+}
+
+// PeerScores returns the reputation scores of all nodes with a non-zero score.
+func (srv *Server) PeerScores() map[enode.ID]int64 {
+	if srv.reputation == nil {
+		return nil
+	}
+	return srv.reputation.all()
+}
+
+// SetPeerScore overrides the reputation score of a node, returning the
+// score after clamping it to the valid range.
+func (srv *Server) SetPeerScore(id enode.ID, score int64) int64 {
+	if srv.reputation == nil {
+		return 0
+	}
+	return srv.reputation.set(id, score)
+}
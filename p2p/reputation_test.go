@@ -0,0 +1,192 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+func TestReputationStore(t *testing.T) {
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+
+	var (
+		now = time.Unix(1700000000, 0)
+		rep = newReputationStore(db)
+		id  = enode.ID{1}
+	)
+	rep.now = func() time.Time { return now }
+
+	// Events adjust the score within the bounds.
+	rep.report(id, UsefulResponse)
+	rep.report(id, UsefulResponse)
+	if score := rep.score(id); score != 2 {
+		t.Fatalf("wrong score after useful responses: %d", score)
+	}
+	rep.report(id, RequestTimeout)
+	if score := rep.score(id); score != -3 {
+		t.Fatalf("wrong score after timeout: %d", score)
+	}
+	for i := 0; i < 5; i++ {
+		rep.report(id, InvalidBlock)
+	}
+	if score := rep.score(id); score != MinReputation {
+		t.Fatalf("score not clamped: %d", score)
+	}
+	if !rep.banned(id) {
+		t.Fatal("node with minimal score not banned")
+	}
+	// Scores decay towards zero over time.
+	now = now.Add(2 * reputationHalfLife)
+	if score := rep.score(id); score != MinReputation/4 {
+		t.Fatalf("wrong score after two half-lives: %d", score)
+	}
+	if rep.banned(id) {
+		t.Fatal("node still banned after decay")
+	}
+	// Scores can be overridden, and are persisted in the node database.
+	if score := rep.set(id, 1000); score != MaxReputation {
+		t.Fatalf("set score not clamped: %d", score)
+	}
+	if score, _ := db.Reputation(id); score != 0 {
+		t.Fatalf("score persisted before flush: %d", score)
+	}
+	rep.flush()
+	reopened := newReputationStore(db)
+	reopened.now = rep.now
+	if score := reopened.score(id); score != MaxReputation {
+		t.Fatalf("score not persisted: %d", score)
+	}
+	if scores := rep.all(); len(scores) != 1 || scores[id] != MaxReputation {
+		t.Fatalf("wrong scores: %v", scores)
+	}
+	rep.set(id, 0)
+	if scores := rep.all(); len(scores) != 0 {
+		t.Fatalf("zero score retained: %v", scores)
+	}
+	rep.flush()
+	if ids := db.Reputations(); len(ids) != 0 {
+		t.Fatalf("zero score not deleted: %v", ids)
+	}
+}
+
+// This test checks that inbound connections of nodes with a bad reputation are
+// rejected, unless the node is trusted.
+func TestServerBadReputation(t *testing.T) {
+	remote := newkey()
+	srv := &Server{
+		Config: Config{
+			PrivateKey:  newkey(),
+			MaxPeers:    10,
+			NoDial:      true,
+			NoDiscovery: true,
+			Logger:      testlog.Logger(t, log.LvlTrace),
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start: %v", err)
+	}
+	defer srv.Stop()
+
+	id := enode.PubkeyToIDV4(&remote.PublicKey)
+	newconn := func() *conn {
+		fd, _ := net.Pipe()
+		tx := newTestTransport(&remote.PublicKey, fd, nil)
+		node := enode.SignNull(new(enr.Record), id)
+		return &conn{fd: fd, transport: tx, flags: inboundConn, node: node, cont: make(chan error)}
+	}
+	if err := srv.checkpoint(newconn(), srv.checkpointPostHandshake); err != nil {
+		t.Fatalf("unexpected error before ban: %v", err)
+	}
+	if score := srv.SetPeerScore(id, BanReputation); score != BanReputation {
+		t.Fatalf("wrong score set: %d", score)
+	}
+	if err := srv.checkpoint(newconn(), srv.checkpointPostHandshake); err != DiscUselessPeer {
+		t.Fatalf("wrong error for banned node: %v", err)
+	}
+	srv.AddTrustedPeer(newNode(id, ""))
+	if err := srv.checkpoint(newconn(), srv.checkpointPostHandshake); err != nil {
+		t.Fatalf("unexpected error for trusted node: %v", err)
+	}
+}
+
+// This test checks that peers are disconnected once their reputation drops to
+// the ban threshold.
+func TestPeerReportDisconnect(t *testing.T) {
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+
+	closer, _, p, errc := testPeer(nil)
+	defer closer()
+
+	p.reputation = newReputationStore(db)
+	p.Report(InvalidBlock)
+	if score := p.Score(); score != BanReputation {
+		t.Fatalf("wrong score: %d", score)
+	}
+	select {
+	case err := <-errc:
+		if err != DiscUselessPeer {
+			t.Fatalf("wrong disconnect reason: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("peer not disconnected")
+	}
+}
+
+// This test checks that a protocol error dropping the score of a peer to the
+// ban threshold doesn't block the peer from shutting down.
+func TestPeerProtocolErrorBan(t *testing.T) {
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+
+	fail := make(chan struct{})
+	proto := Protocol{
+		Name:   "a",
+		Length: 1,
+		Run: func(peer *Peer, rw MsgReadWriter) error {
+			<-fail
+			return errors.New("invalid message")
+		},
+	}
+	closer, _, p, errc := testPeer([]Protocol{proto})
+	defer closer()
+
+	p.reputation = newReputationStore(db)
+	p.reputation.set(p.ID(), BanReputation+5)
+	close(fail)
+
+	select {
+	case err := <-errc:
+		if discReasonForError(err) != DiscSubprotocolError {
+			t.Fatalf("wrong disconnect reason: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("peer run loop blocked")
+	}
+	if score := p.Score(); score != BanReputation+5+reputationDeltas[ProtocolViolation] {
+		t.Fatalf("wrong score: %d", score)
+	}
+}
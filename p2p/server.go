@@ -19,11 +19,14 @@ package p2p
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
+	"crypto/tls"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -33,11 +36,13 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/capture"
 	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/quic-go/quic-go"
 	"golang.org/x/exp/slices"
 )
 
@@ -146,6 +151,13 @@ type Config struct {
 	// for TCP and DiscAddr for the UDP discovery protocol.
 	DiscAddr string
 
+	// If QUICListenAddr is set to a non-nil value, the server additionally
+	// accepts connections over the experimental QUIC transport on the given
+	// UDP address. Nodes announcing QUIC support are then dialed over QUIC,
+	// falling back to TCP if the connection can't be established. The QUIC
+	// port is not mapped by the NAT port mapper.
+	QUICListenAddr string `toml:",omitempty"`
+
 	// If set to a non-nil value, the given NAT port mapper
 	// is used to make the listening port available to the
 	// Internet.
@@ -162,6 +174,11 @@ type Config struct {
 	// whenever a message is sent to or received from a peer
 	EnableMsgEvents bool
 
+	// If CaptureFile is set, all subprotocol messages exchanged with peers are
+	// recorded into the given file. The capture can be replayed with the devp2p
+	// tool. Note that capture files grow quickly on busy nodes.
+	CaptureFile string `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 
@@ -184,16 +201,26 @@ type Server struct {
 
 	listener     net.Listener
 	ourHandshake *protoHandshake
-	loopWG       sync.WaitGroup // loop, listenLoop
+	loopWG       sync.WaitGroup // loop, listenLoop, quicListenLoop
 	peerFeed     event.Feed
 	log          log.Logger
 
-	nodedb    *enode.DB
-	localnode *enode.LocalNode
-	ntab      *discover.UDPv4
-	DiscV5    *discover.UDPv5
-	discmix   *enode.FairMix
-	dialsched *dialScheduler
+	nodedb     *enode.DB
+	reputation *reputationStore
+	localnode  *enode.LocalNode
+	ntab       *discover.UDPv4
+	DiscV5     *discover.UDPv5
+	discmix    *enode.FairMix
+	dialsched  *dialScheduler
+
+	// Message capture, if enabled.
+	captureFile *os.File
+	capture     *capture.Writer
+
+	// QUIC transport, if enabled.
+	quicSocket   *quic.Transport
+	quicListener *quic.Listener
+	quicCert     tls.Certificate
 
 	// This is read by the NAT port mapping loop.
 	portMappingRegister chan *portMapping
@@ -413,15 +440,47 @@ func (srv *Server) Stop() {
 		// this unblocks listener Accept
 		srv.listener.Close()
 	}
+	if srv.quicListener != nil {
+		srv.quicListener.Close()
+	}
 	close(srv.quit)
 	srv.lock.Unlock()
 	srv.loopWG.Wait()
+	if srv.quicSocket != nil {
+		srv.quicSocket.Close()
+		srv.quicSocket.Conn.Close()
+	}
+	if srv.captureFile != nil {
+		if err := srv.capture.Close(); err != nil {
+			srv.log.Error("Message capture failed", "file", srv.CaptureFile, "err", err)
+		}
+		if dropped := srv.capture.Dropped(); dropped > 0 {
+			srv.log.Warn("Message capture incomplete", "file", srv.CaptureFile, "dropped", dropped)
+		}
+		srv.captureFile.Close()
+	}
+}
+
+// setupCapture creates the message capture file.
+func (srv *Server) setupCapture() error {
+	f, err := os.Create(srv.CaptureFile)
+	if err != nil {
+		return err
+	}
+	w, err := capture.NewWriter(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	srv.captureFile, srv.capture = f, w
+	srv.log.Warn("Recording peer messages", "file", srv.CaptureFile)
+	return nil
 }
 
 // sharedUDPConn implements a shared connection. Write sends messages to the underlying connection while read returns
 // messages that were found unprocessable and sent to the unhandled channel by the primary listener.
 type sharedUDPConn struct {
-	*net.UDPConn
+	discover.UDPConn
 	unhandled chan discover.ReadPacket
 }
 
@@ -469,7 +528,7 @@ func (srv *Server) Start() (err error) {
 		return errors.New("Server.PrivateKey must be set to a non-nil key")
 	}
 	if srv.newTransport == nil {
-		srv.newTransport = newRLPX
+		srv.newTransport = srv.newConnTransport
 	}
 	if srv.listenFunc == nil {
 		srv.listenFunc = net.Listen
@@ -486,6 +545,11 @@ func (srv *Server) Start() (err error) {
 	if err := srv.setupLocalNode(); err != nil {
 		return err
 	}
+	if srv.CaptureFile != "" {
+		if err := srv.setupCapture(); err != nil {
+			return err
+		}
+	}
 	srv.setupPortMapping()
 
 	if srv.ListenAddr != "" {
@@ -493,6 +557,11 @@ func (srv *Server) Start() (err error) {
 			return err
 		}
 	}
+	if srv.QUICListenAddr != "" {
+		if err := srv.setupQUICListening(); err != nil {
+			return err
+		}
+	}
 	if err := srv.setupDiscovery(); err != nil {
 		return err
 	}
@@ -518,6 +587,7 @@ func (srv *Server) setupLocalNode() error {
 		return err
 	}
 	srv.nodedb = db
+	srv.reputation = newReputationStore(db)
 	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey)
 	srv.localnode.SetFallbackIP(net.IP{127, 0, 0, 1})
 	// TODO: check conflicts
@@ -589,6 +659,18 @@ func (srv *Server) setupDiscovery() error {
 			added[proto.Name] = true
 		}
 	}
+	// Advertise the protocol-specific topics and dial the nodes found through them.
+	if srv.DiscV5 != nil {
+		topics := make(map[string]bool)
+		for _, proto := range srv.Protocols {
+			if proto.DiscoveryTopic != "" && !topics[proto.DiscoveryTopic] {
+				topic := discover.NewTopic(proto.DiscoveryTopic)
+				srv.DiscV5.RegisterTopic(topic)
+				srv.discmix.AddSource(srv.DiscV5.TopicNodes(topic))
+				topics[proto.DiscoveryTopic] = true
+			}
+		}
+	}
 	return nil
 }
 
@@ -605,9 +687,15 @@ func (srv *Server) setupDialScheduler() {
 	if srv.ntab != nil {
 		config.resolver = srv.ntab
 	}
+	if srv.reputation != nil {
+		config.reputation = srv.reputation.score
+	}
 	if config.dialer == nil {
 		config.dialer = tcpDialer{&net.Dialer{Timeout: defaultDialTimeout}}
 	}
+	if srv.quicSocket != nil {
+		config.dialer = &quicDialer{socket: srv.quicSocket, cert: srv.quicCert, fallback: config.dialer, log: srv.log}
+	}
 	srv.dialsched = newDialScheduler(config, srv.discmix, srv.SetupConn)
 	for _, n := range srv.StaticNodes {
 		srv.dialsched.addStatic(n)
@@ -660,7 +748,35 @@ func (srv *Server) setupListening() error {
 	return nil
 }
 
-func (srv *Server) setupUDPListening() (*net.UDPConn, error) {
+func (srv *Server) setupQUICListening() error {
+	addr, err := net.ResolveUDPAddr("udp", srv.QUICListenAddr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return err
+	}
+	if srv.quicCert, err = newQUICCertificate(srv.PrivateKey); err != nil {
+		conn.Close()
+		return err
+	}
+	srv.quicSocket = &quic.Transport{Conn: conn}
+	srv.quicListener, err = srv.quicSocket.Listen(newQUICTLSConfig(srv.quicCert, nil), newQUICConfig())
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	laddr := conn.LocalAddr().(*net.UDPAddr)
+	srv.QUICListenAddr = laddr.String()
+	srv.localnode.Set(enr.QUIC(laddr.Port))
+
+	srv.loopWG.Add(1)
+	go srv.quicListenLoop()
+	return nil
+}
+
+func (srv *Server) setupUDPListening() (discover.UDPConn, error) {
 	listenAddr := srv.ListenAddr
 
 	// Use an alternate listening address for UDP if
@@ -672,7 +788,13 @@ func (srv *Server) setupUDPListening() (*net.UDPConn, error) {
 	if err != nil {
 		return nil, err
 	}
-	conn, err := net.ListenUDP("udp", addr)
+	var conn discover.UDPConn
+	if addr.IP == nil {
+		// No address given, listen on both IPv4 and IPv6.
+		conn, err = srv.listenUDPDualStack(addr.Port)
+	} else {
+		conn, err = net.ListenUDP("udp", addr)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -690,6 +812,22 @@ func (srv *Server) setupUDPListening() (*net.UDPConn, error) {
 	return conn, nil
 }
 
+// listenUDPDualStack opens separate IPv4 and IPv6 discovery sockets on the same port.
+// If no IPv6 socket can be opened, discovery runs on IPv4 only.
+func (srv *Server) listenUDPDualStack(port int) (discover.UDPConn, error) {
+	conn4, err := net.ListenUDP("udp4", &net.UDPAddr{Port: port})
+	if err != nil {
+		return nil, err
+	}
+	port = conn4.LocalAddr().(*net.UDPAddr).Port
+	conn6, err := net.ListenUDP("udp6", &net.UDPAddr{Port: port})
+	if err != nil {
+		srv.log.Debug("IPv6 discovery disabled", "err", err)
+		return conn4, nil
+	}
+	return discover.NewDualStackConn(conn4, conn6), nil
+}
+
 // doPeerOp runs fn on the main loop.
 func (srv *Server) doPeerOp(fn peerOpFunc) {
 	select {
@@ -704,6 +842,7 @@ func (srv *Server) run() {
 	srv.log.Info("Started P2P networking", "self", srv.localnode.Node().URLv4())
 	defer srv.loopWG.Done()
 	defer srv.nodedb.Close()
+	defer srv.reputation.flush()
 	defer srv.discmix.Close()
 	defer srv.dialsched.stop()
 
@@ -711,7 +850,9 @@ func (srv *Server) run() {
 		peers        = make(map[enode.ID]*Peer)
 		inboundCount = 0
 		trusted      = make(map[enode.ID]bool, len(srv.TrustedNodes))
+		flush        = time.NewTicker(reputationFlushInterval)
 	)
+	defer flush.Stop()
 	// Put trusted nodes into a map to speed up checks.
 	// Trusted peers are loaded on startup or added via AddTrustedPeer RPC.
 	for _, n := range srv.TrustedNodes {
@@ -748,6 +889,10 @@ running:
 			op(peers)
 			srv.peerOpDone <- struct{}{}
 
+		case <-flush.C:
+			// Persist the reputation scores changed since the last flush.
+			srv.reputation.flush()
+
 		case c := <-srv.checkpointPostHandshake:
 			// A connection has passed the encryption handshake so
 			// the remote identity is known (but hasn't been verified yet).
@@ -824,6 +969,8 @@ func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount in
 		return DiscAlreadyConnected
 	case c.node.ID() == srv.localnode.ID():
 		return DiscSelf
+	case !c.is(trustedConn) && c.is(inboundConn) && srv.reputation.banned(c.node.ID()):
+		return DiscUselessPeer
 	default:
 		return nil
 	}
@@ -908,6 +1055,56 @@ func (srv *Server) listenLoop() {
 	}
 }
 
+// quicListenLoop runs in its own goroutine and accepts inbound QUIC connections.
+func (srv *Server) quicListenLoop() {
+	srv.log.Debug("QUIC listener up", "addr", srv.quicListener.Addr())
+
+	// The slots channel limits accepts of new connections.
+	tokens := defaultMaxPendingPeers
+	if srv.MaxPendingPeers > 0 {
+		tokens = srv.MaxPendingPeers
+	}
+	slots := make(chan struct{}, tokens)
+	for i := 0; i < tokens; i++ {
+		slots <- struct{}{}
+	}
+
+	// Wait for slots to be returned on exit. This ensures all connection goroutines
+	// are down before quicListenLoop returns.
+	defer srv.loopWG.Done()
+	defer func() {
+		for i := 0; i < cap(slots); i++ {
+			<-slots
+		}
+	}()
+
+	for {
+		// Wait for a free slot before accepting.
+		<-slots
+
+		qc, err := srv.quicListener.Accept(context.Background())
+		if err != nil {
+			srv.log.Debug("QUIC accept error", "err", err)
+			slots <- struct{}{}
+			return
+		}
+		fd := &quicConn{qc}
+		remoteIP := netutil.AddrIP(fd.RemoteAddr())
+		if err := srv.checkInboundConn(remoteIP); err != nil {
+			srv.log.Debug("Rejected inbound QUIC connection", "addr", fd.RemoteAddr(), "err", err)
+			fd.Close()
+			slots <- struct{}{}
+			continue
+		}
+		serveMeter.Mark(1)
+		srv.log.Trace("Accepted QUIC connection", "addr", fd.RemoteAddr())
+		go func() {
+			srv.SetupConn(fd, inboundConn, nil)
+			slots <- struct{}{}
+		}()
+	}
+}
+
 func (srv *Server) checkInboundConn(remoteIP net.IP) error {
 	if remoteIP == nil {
 		return nil
@@ -926,6 +1123,15 @@ func (srv *Server) checkInboundConn(remoteIP net.IP) error {
 	return nil
 }
 
+// newConnTransport creates the transport of a connection, running QUIC
+// connections over their streams and all others over RLPx.
+func (srv *Server) newConnTransport(fd net.Conn, dialDest *ecdsa.PublicKey) transport {
+	if qc, ok := fd.(*quicConn); ok {
+		return newQUICTransport(qc, dialDest, srv.Protocols)
+	}
+	return newRLPX(fd, dialDest)
+}
+
 // SetupConn runs the handshakes and attempts to add the connection
 // as a peer. It returns when the connection has been added as a peer
 // or the handshakes have failed.
@@ -1027,6 +1233,8 @@ func (srv *Server) checkpoint(c *conn, stage chan<- *conn) error {
 
 func (srv *Server) launchPeer(c *conn) *Peer {
 	p := newPeer(srv.log, c, srv.Protocols)
+	p.reputation = srv.reputation
+	p.capture = srv.capture
 	if srv.EnableMsgEvents {
 		// If message events are enabled, pass the peerFeed
 		// to the peer.
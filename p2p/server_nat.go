@@ -24,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/nat"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
 )
 
 const (
@@ -71,6 +72,26 @@ func (srv *Server) setupPortMapping() {
 	}
 }
 
+// sharedAddressSpace is the range used by carrier-grade NAT, RFC 6598.
+var sharedAddressSpace = net.IPNet{IP: net.IP{100, 64, 0, 0}, Mask: net.CIDRMask(10, 32)}
+
+// setNATExternalIP applies the external IP reported by the NAT device. When the
+// device itself is behind another NAT, e.g. the carrier-grade NAT of an ISP, the
+// reported address isn't reachable from the Internet. It is only used as a
+// fallback then, so the endpoint predicted from discovery pongs takes precedence.
+func (srv *Server) setNATExternalIP(ip net.IP) {
+	if ip == nil {
+		return
+	}
+	if netutil.IsLAN(ip) || sharedAddressSpace.Contains(ip) {
+		srv.log.Info("NAT device is behind another NAT, predicting endpoint from discovery", "ip", ip, "interface", srv.NAT)
+		srv.localnode.ClearStaticIP(ip)
+		srv.localnode.SetFallbackIP(ip)
+		return
+	}
+	srv.localnode.SetStaticIP(ip)
+}
+
 func (srv *Server) consumePortMappingRequests() {
 	defer srv.loopWG.Done()
 	for {
@@ -125,13 +146,13 @@ func (srv *Server) portMappingLoop() {
 			if err != nil {
 				log.Debug("Couldn't get external IP", "err", err, "interface", srv.NAT)
 			} else if !ip.Equal(lastExtIP) {
-				log.Debug("External IP changed", "ip", extip, "interface", srv.NAT)
+				log.Debug("External IP changed", "ip", ip, "interface", srv.NAT)
 			} else {
-				return
+				continue
 			}
 			// Here, we either failed to get the external IP, or it has changed.
 			lastExtIP = ip
-			srv.localnode.SetStaticIP(ip)
+			srv.setNATExternalIP(ip)
 			// Ensure port mappings are refreshed in case we have moved to a new network.
 			for _, m := range mappings {
 				m.nextTime = srv.clock.Now()
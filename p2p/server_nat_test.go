@@ -75,7 +75,53 @@ func TestServerPortMapping(t *testing.T) {
 	}
 }
 
+// This test checks that the external IP of a NAT device behind carrier-grade NAT
+// doesn't override the endpoint predicted from discovery.
+func TestServerPortMappingCGNAT(t *testing.T) {
+	clock := new(mclock.Simulated)
+	mockNAT := &mockNAT{mappedPort: 30000, extIP: net.IP{100, 64, 1, 2}}
+	srv := Server{
+		Config: Config{
+			PrivateKey: newkey(),
+			NoDial:     true,
+			ListenAddr: ":0",
+			NAT:        mockNAT,
+			Logger:     testlog.Logger(t, log.LvlTrace),
+			clock:      clock,
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	deadline := clock.Now().Add(portMapRefreshInterval)
+	for clock.Now() < deadline && mockNAT.mapRequests.Load() < 2 {
+		time.Sleep(10 * time.Millisecond)
+		clock.Run(1 * time.Second)
+	}
+	ln := srv.LocalNode()
+	if ip := ln.Node().IP(); !ip.Equal(mockNAT.extIP) {
+		t.Fatal("fallback IP not set in ENR:", ip)
+	}
+
+	// Statements from discovery take precedence.
+	predicted := &net.UDPAddr{IP: net.IP{198, 51, 100, 7}, Port: 31000}
+	for i := 0; i < 10; i++ {
+		from := &net.UDPAddr{IP: net.IP{1, 2, 3, byte(i)}, Port: 30303}
+		ln.UDPEndpointStatement(from, predicted)
+	}
+	enr := ln.Node()
+	if !enr.IP().Equal(predicted.IP) {
+		t.Error("wrong IP in ENR:", enr.IP())
+	}
+	if enr.UDP() != predicted.Port {
+		t.Error("wrong UDP port in ENR:", enr.UDP())
+	}
+}
+
 type mockNAT struct {
+	extIP         net.IP
 	mappedPort    uint16
 	mapRequests   atomic.Int32
 	unmapRequests atomic.Int32
@@ -94,6 +140,9 @@ func (m *mockNAT) DeleteMapping(protocol string, extport, intport int) error {
 
 func (m *mockNAT) ExternalIP() (net.IP, error) {
 	m.ipRequests.Add(1)
+	if m.extIP != nil {
+		return m.extIP, nil
+	}
 	return net.ParseIP("192.0.2.0"), nil
 }
 
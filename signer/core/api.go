@@ -44,9 +44,9 @@ const (
 	// numberOfAccountsToDerive For hardware wallets, the number of accounts to derive
 	numberOfAccountsToDerive = 10
 	// ExternalAPIVersion -- see extapi_changelog.md
-	ExternalAPIVersion = "6.1.0"
+	ExternalAPIVersion = "6.2.0"
 	// InternalAPIVersion -- see intapi_changelog.md
-	InternalAPIVersion = "7.0.1"
+	InternalAPIVersion = "7.2.0"
 )
 
 // ExternalAPI defines the external API through which signing requests are made.
@@ -243,6 +243,9 @@ type (
 		Callinfo    []apitypes.ValidationInfo `json:"call_info"`
 		Hash        hexutil.Bytes             `json:"hash"`
 		Meta        Metadata                  `json:"meta"`
+
+		// TypedData is the decoded typed data of well-known domains and types
+		TypedData *apitypes.TypedDataSummary `json:"typed_data,omitempty"`
 	}
 	SignDataResponse struct {
 		Approved bool `json:"approved"`
@@ -495,6 +498,14 @@ func logDiff(original *SignTxRequest, new *SignTxResponse) bool {
 		log.Info("maxFeePerGas changed by UI", "was", a, "is", b)
 		modified = true
 	}
+	if a, b := original.Transaction.BlobFeeCap, new.Transaction.BlobFeeCap; intPtrModified(a, b) {
+		log.Info("maxFeePerBlobGas changed by UI", "was", a, "is", b)
+		modified = true
+	}
+	if h0, h1 := original.Transaction.BlobHashes, new.Transaction.BlobHashes; !reflect.DeepEqual(h0, h1) {
+		log.Info("blobVersionedHashes changed by UI", "was", h0, "is", h1)
+		modified = true
+	}
 	if v0, v1 := big.Int(original.Transaction.Value), big.Int(new.Transaction.Value); v0.Cmp(&v1) != 0 {
 		modified = true
 		log.Info("Value changed by UI", "was", v0, "is", v1)
@@ -546,6 +557,10 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxA
 		err    error
 		result SignTxResponse
 	)
+	// Verify the blobs of blob transactions before anything is shown to the user
+	if err := args.ValidateTxSidecar(); err != nil {
+		return nil, err
+	}
 	msgs, err := api.validator.ValidateTransaction(methodSelector, &args)
 	if err != nil {
 		return nil, err
@@ -590,7 +605,10 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxA
 		return nil, err
 	}
 	// Convert fields into a real transaction
-	var unsignedTx = result.Transaction.ToTransaction()
+	unsignedTx, err := result.Transaction.ToTransaction()
+	if err != nil {
+		return nil, err
+	}
 	// Get the password for the transaction
 	pw, err := api.lookupOrQueryPassword(acc.Address, "Account password",
 		fmt.Sprintf("Please enter the password for account %s", acc.Address.String()))
@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/signer/core"
@@ -318,3 +319,58 @@ func TestSignTx(t *testing.T) {
 		t.Error("Expected tx to be modified by UI")
 	}
 }
+
+func TestSignBlobTx(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	control.approveCh <- "A"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := common.NewMixedcaseAddress(list[0])
+	to := common.NewMixedcaseAddress(common.HexToAddress("0x1337"))
+	tx := apitypes.SendTxArgs{
+		From:                 from,
+		To:                   &to,
+		Gas:                  21000,
+		MaxFeePerGas:         (*hexutil.Big)(big.NewInt(2000000000)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(1000000000)),
+		BlobFeeCap:           (*hexutil.Big)(big.NewInt(1)),
+		Blobs:                []kzg4844.Blob{{}},
+	}
+	// Sidecars not matching the versioned hashes are rejected before approval
+	bad := tx
+	bad.BlobHashes = []common.Hash{{0x01}}
+	if _, err := api.SignTransaction(context.Background(), bad, nil); err == nil {
+		t.Fatal("signed blob transaction with mismatching hashes")
+	}
+	// Valid sidecars are signed along with the transaction
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	res, err := api.SignTransaction(context.Background(), tx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(res.Raw); err != nil {
+		t.Fatal(err)
+	}
+	if signed.Type() != types.BlobTxType {
+		t.Fatalf("wrong transaction type: have %d, want %d", signed.Type(), types.BlobTxType)
+	}
+	sidecar := signed.BlobTxSidecar()
+	if sidecar == nil || len(sidecar.Blobs) != 1 {
+		t.Fatal("signed transaction misses the blob sidecar")
+	}
+	if hashes := sidecar.BlobHashes(); len(signed.BlobHashes()) != 1 || signed.BlobHashes()[0] != hashes[0] {
+		t.Fatalf("wrong blob hashes: have %v, want %v", signed.BlobHashes(), hashes)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1337)), signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender != from.Address() {
+		t.Fatalf("wrong sender: have %v, want %v", sender, from.Address())
+	}
+}
@@ -0,0 +1,498 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package apitypes
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Permit2Address is the address of the canonical Permit2 contract, which is the
+// same on all chains.
+var Permit2Address = common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+
+// unlimitedAmount is the smallest amount considered an unlimited approval, the
+// maximum amount of Permit2 allowances.
+var unlimitedAmount = new(big.Int).Sub(new(big.Int).Lsh(common.Big1, 160), common.Big1)
+
+// Kinds of well-known typed data.
+const (
+	KindERC2612Permit   = "ERC-2612 permit"
+	KindDaiPermit       = "DAI permit"
+	KindPermit2Allow    = "Permit2 allowance"
+	KindPermit2Transfer = "Permit2 transfer"
+	KindSeaportOrder    = "Seaport order"
+)
+
+// TypedDataItem is an asset in decoded typed data, e.g. a token allowance or an
+// item of a marketplace order.
+type TypedDataItem struct {
+	Token      common.Address  `json:"token"`                // Token contract, zero for ether
+	Type       string          `json:"type,omitempty"`       // Token standard, if given
+	Identifier *hexutil.Big    `json:"identifier,omitempty"` // Token id of non-fungible tokens
+	Amount     *hexutil.Big    `json:"amount,omitempty"`     // Amount of tokens, nil if unlimited without amount
+	Unlimited  bool            `json:"unlimited"`            // Whether the amount is practically unlimited
+	Expiration *hexutil.Big    `json:"expiration,omitempty"` // Unix time a Permit2 allowance expires
+	Recipient  *common.Address `json:"recipient,omitempty"`  // Recipient of order considerations
+}
+
+// TypedDataSummary is the decoded meaning of typed data of a well-known domain
+// and primary type, e.g. a token approval, for showing it to the user and for
+// rules to reference.
+type TypedDataSummary struct {
+	Kind           string          `json:"kind"`                     // One of the Kind constants
+	Contract       common.Address  `json:"contract"`                 // Verifying contract of the domain
+	Owner          *common.Address `json:"owner,omitempty"`          // Account granting the approval or making the order
+	Spender        *common.Address `json:"spender,omitempty"`        // Account allowed to spend the items
+	Deadline       *hexutil.Big    `json:"deadline,omitempty"`       // Unix time the signature expires
+	Items          []TypedDataItem `json:"items"`                    // Assets approved, transferred or offered
+	Considerations []TypedDataItem `json:"considerations,omitempty"` // Assets asked for in return by an order
+	Unlimited      bool            `json:"unlimited"`                // Whether any item is an unlimited approval
+}
+
+// Summarize decodes typed data of well-known domains and primary types, i.e.
+// token permits, Permit2 approvals and transfers, and Seaport orders. It returns
+// nil if the typed data is not known, and an error if it is known but malformed.
+func (typedData *TypedData) Summarize() (*TypedDataSummary, error) {
+	var (
+		summary *TypedDataSummary
+		err     error
+		msg     = message(typedData.Message)
+		primary = typedData.PrimaryType
+	)
+	switch {
+	case typedData.Domain.Name == "Permit2":
+		switch {
+		case primary == "PermitSingle" || primary == "PermitBatch":
+			summary, err = summarizePermit2Allowance(msg)
+		case strings.HasPrefix(primary, "PermitTransferFrom") || strings.HasPrefix(primary, "PermitBatchTransferFrom") ||
+			strings.HasPrefix(primary, "PermitWitnessTransferFrom") || strings.HasPrefix(primary, "PermitBatchWitnessTransferFrom"):
+			summary, err = summarizePermit2Transfer(msg)
+		}
+	case typedData.Domain.Name == "Seaport" && primary == "OrderComponents":
+		summary, err = summarizeSeaportOrder(msg)
+	case primary == "Permit" && typedData.hasFields("Permit", "owner", "spender", "value", "deadline"):
+		summary, err = summarizeERC2612Permit(msg)
+	case primary == "Permit" && typedData.hasFields("Permit", "holder", "spender", "allowed", "expiry"):
+		summary, err = summarizeDaiPermit(msg)
+	}
+	if summary == nil || err != nil {
+		return nil, err
+	}
+	summary.Contract = common.HexToAddress(typedData.Domain.VerifyingContract)
+	for _, item := range summary.Items {
+		summary.Unlimited = summary.Unlimited || item.Unlimited
+	}
+	// Single token permits approve the verifying contract
+	if summary.Kind == KindERC2612Permit || summary.Kind == KindDaiPermit {
+		summary.Items[0].Token = summary.Contract
+	}
+	return summary, nil
+}
+
+// hasFields returns whether a type has all the named fields.
+func (typedData *TypedData) hasFields(typ string, names ...string) bool {
+	fields := make(map[string]bool)
+	for _, field := range typedData.Types[typ] {
+		fields[field.Name] = true
+	}
+	for _, name := range names {
+		if !fields[name] {
+			return false
+		}
+	}
+	return true
+}
+
+func summarizeERC2612Permit(msg message) (*TypedDataSummary, error) {
+	owner, err := msg.address("owner")
+	if err != nil {
+		return nil, err
+	}
+	spender, err := msg.address("spender")
+	if err != nil {
+		return nil, err
+	}
+	value, err := msg.integer("value")
+	if err != nil {
+		return nil, err
+	}
+	deadline, err := msg.integer("deadline")
+	if err != nil {
+		return nil, err
+	}
+	return &TypedDataSummary{
+		Kind:     KindERC2612Permit,
+		Owner:    &owner,
+		Spender:  &spender,
+		Deadline: (*hexutil.Big)(deadline),
+		Items:    []TypedDataItem{{Amount: (*hexutil.Big)(value), Unlimited: value.Cmp(unlimitedAmount) >= 0}},
+	}, nil
+}
+
+func summarizeDaiPermit(msg message) (*TypedDataSummary, error) {
+	holder, err := msg.address("holder")
+	if err != nil {
+		return nil, err
+	}
+	spender, err := msg.address("spender")
+	if err != nil {
+		return nil, err
+	}
+	allowed, ok := msg["allowed"].(bool)
+	if !ok {
+		return nil, fmt.Errorf("invalid field allowed: %v", msg["allowed"])
+	}
+	expiry, err := msg.integer("expiry")
+	if err != nil {
+		return nil, err
+	}
+	// DAI permits either allow spending everything, or revoke the allowance
+	item := TypedDataItem{Amount: (*hexutil.Big)(new(big.Int)), Unlimited: allowed}
+	if allowed {
+		item.Amount = nil
+	}
+	summary := &TypedDataSummary{
+		Kind:    KindDaiPermit,
+		Owner:   &holder,
+		Spender: &spender,
+		Items:   []TypedDataItem{item},
+	}
+	// An expiry of zero means the permit never expires
+	if expiry.Sign() != 0 {
+		summary.Deadline = (*hexutil.Big)(expiry)
+	}
+	return summary, nil
+}
+
+func summarizePermit2Allowance(msg message) (*TypedDataSummary, error) {
+	spender, err := msg.address("spender")
+	if err != nil {
+		return nil, err
+	}
+	deadline, err := msg.integer("sigDeadline")
+	if err != nil {
+		return nil, err
+	}
+	details, err := msg.structs("details")
+	if err != nil {
+		return nil, err
+	}
+	summary := &TypedDataSummary{
+		Kind:     KindPermit2Allow,
+		Spender:  &spender,
+		Deadline: (*hexutil.Big)(deadline),
+		Items:    []TypedDataItem{},
+	}
+	for _, detail := range details {
+		token, err := detail.address("token")
+		if err != nil {
+			return nil, err
+		}
+		amount, err := detail.integer("amount")
+		if err != nil {
+			return nil, err
+		}
+		expiration, err := detail.integer("expiration")
+		if err != nil {
+			return nil, err
+		}
+		summary.Items = append(summary.Items, TypedDataItem{
+			Token:      token,
+			Amount:     (*hexutil.Big)(amount),
+			Unlimited:  amount.Cmp(unlimitedAmount) >= 0,
+			Expiration: (*hexutil.Big)(expiration),
+		})
+	}
+	return summary, nil
+}
+
+func summarizePermit2Transfer(msg message) (*TypedDataSummary, error) {
+	spender, err := msg.address("spender")
+	if err != nil {
+		return nil, err
+	}
+	deadline, err := msg.integer("deadline")
+	if err != nil {
+		return nil, err
+	}
+	permitted, err := msg.structs("permitted")
+	if err != nil {
+		return nil, err
+	}
+	summary := &TypedDataSummary{
+		Kind:     KindPermit2Transfer,
+		Spender:  &spender,
+		Deadline: (*hexutil.Big)(deadline),
+		Items:    []TypedDataItem{},
+	}
+	for _, permit := range permitted {
+		token, err := permit.address("token")
+		if err != nil {
+			return nil, err
+		}
+		amount, err := permit.integer("amount")
+		if err != nil {
+			return nil, err
+		}
+		summary.Items = append(summary.Items, TypedDataItem{
+			Token:     token,
+			Amount:    (*hexutil.Big)(amount),
+			Unlimited: amount.Cmp(unlimitedAmount) >= 0,
+		})
+	}
+	return summary, nil
+}
+
+// seaportItemTypes are the names of the Seaport item types.
+var seaportItemTypes = []string{"native", "ERC20", "ERC721", "ERC1155", "ERC721 with criteria", "ERC1155 with criteria"}
+
+func summarizeSeaportOrder(msg message) (*TypedDataSummary, error) {
+	offerer, err := msg.address("offerer")
+	if err != nil {
+		return nil, err
+	}
+	endTime, err := msg.integer("endTime")
+	if err != nil {
+		return nil, err
+	}
+	summary := &TypedDataSummary{
+		Kind:     KindSeaportOrder,
+		Owner:    &offerer,
+		Deadline: (*hexutil.Big)(endTime),
+		Items:    []TypedDataItem{},
+	}
+	// Amounts may change over the lifetime of an order, show the worst case for
+	// the offerer: the most offered and the least asked for in return
+	offer, err := msg.structs("offer")
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range offer {
+		item, start, end, err := seaportItem(o)
+		if err != nil {
+			return nil, err
+		}
+		if start.Cmp(end) > 0 {
+			end = start
+		}
+		item.Amount = (*hexutil.Big)(end)
+		summary.Items = append(summary.Items, item)
+	}
+	consideration, err := msg.structs("consideration")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range consideration {
+		item, start, end, err := seaportItem(c)
+		if err != nil {
+			return nil, err
+		}
+		if start.Cmp(end) < 0 {
+			end = start
+		}
+		item.Amount = (*hexutil.Big)(end)
+
+		recipient, err := c.address("recipient")
+		if err != nil {
+			return nil, err
+		}
+		item.Recipient = &recipient
+		summary.Considerations = append(summary.Considerations, item)
+	}
+	return summary, nil
+}
+
+// seaportItem decodes an offer or consideration item of a Seaport order,
+// returning its start and end amounts.
+func seaportItem(msg message) (TypedDataItem, *big.Int, *big.Int, error) {
+	var item TypedDataItem
+
+	typ, err := msg.integer("itemType")
+	if err != nil {
+		return item, nil, nil, err
+	}
+	if !typ.IsInt64() || typ.Int64() >= int64(len(seaportItemTypes)) {
+		return item, nil, nil, fmt.Errorf("invalid item type %v", typ)
+	}
+	item.Type = seaportItemTypes[typ.Int64()]
+
+	if item.Token, err = msg.address("token"); err != nil {
+		return item, nil, nil, err
+	}
+	id, err := msg.integer("identifierOrCriteria")
+	if err != nil {
+		return item, nil, nil, err
+	}
+	if typ.Int64() >= 2 {
+		item.Identifier = (*hexutil.Big)(id)
+	}
+	start, err := msg.integer("startAmount")
+	if err != nil {
+		return item, nil, nil, err
+	}
+	end, err := msg.integer("endAmount")
+	if err != nil {
+		return item, nil, nil, err
+	}
+	return item, start, end, nil
+}
+
+// Validate adds warnings about dangerous typed data to msgs: unlimited
+// approvals, Permit2 requests to other contracts than Permit2, and orders
+// offering assets for nothing in return.
+func (s *TypedDataSummary) Validate(msgs *ValidationMessages) {
+	for _, item := range s.Items {
+		if item.Unlimited {
+			msgs.Warn(fmt.Sprintf("Unlimited approval of token %v to %v", item.Token, s.Spender))
+		}
+	}
+	if (s.Kind == KindPermit2Allow || s.Kind == KindPermit2Transfer) && s.Contract != Permit2Address {
+		msgs.Warn(fmt.Sprintf("Permit2 domain with verifying contract %v, not the Permit2 contract %v", s.Contract, Permit2Address))
+	}
+	if s.Kind == KindSeaportOrder && len(s.Items) > 0 {
+		var received bool
+		for _, item := range s.Considerations {
+			if *item.Recipient == *s.Owner && item.Amount.ToInt().Sign() > 0 {
+				received = true
+			}
+		}
+		if !received {
+			msgs.Warn("Order offers assets without anything in return to the offerer")
+		}
+	}
+	msgs.Info(fmt.Sprintf("Decoded %s", s.Kind))
+}
+
+// String returns a human-readable rendering of the summary.
+func (s *TypedDataSummary) String() string {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "%s (contract %v)\n", s.Kind, s.Contract)
+	if s.Owner != nil {
+		fmt.Fprintf(&out, "  Owner:    %v\n", *s.Owner)
+	}
+	if s.Spender != nil {
+		fmt.Fprintf(&out, "  Spender:  %v\n", *s.Spender)
+	}
+	fmt.Fprintf(&out, "  Deadline: %s\n", formatTimestamp(s.Deadline))
+
+	title := "Approves"
+	switch s.Kind {
+	case KindPermit2Transfer:
+		title = "Transfers"
+	case KindSeaportOrder:
+		title = "Offers"
+	}
+	fmt.Fprintf(&out, "  %s:\n", title)
+	for _, item := range s.Items {
+		fmt.Fprintf(&out, "    %s\n", item.String())
+	}
+	if s.Kind == KindSeaportOrder {
+		fmt.Fprintf(&out, "  Asks for:\n")
+		for _, item := range s.Considerations {
+			fmt.Fprintf(&out, "    %s\n", item.String())
+		}
+	}
+	return out.String()
+}
+
+// String returns a human-readable rendering of the item.
+func (item TypedDataItem) String() string {
+	var out strings.Builder
+
+	switch {
+	case item.Unlimited:
+		out.WriteString("UNLIMITED")
+	case item.Amount != nil:
+		out.WriteString(item.Amount.ToInt().String())
+	}
+	switch {
+	case item.Type == "native":
+		out.WriteString(" wei")
+	case item.Type != "":
+		fmt.Fprintf(&out, " of %s token %v", item.Type, item.Token)
+	default:
+		fmt.Fprintf(&out, " of token %v", item.Token)
+	}
+	if item.Identifier != nil {
+		fmt.Fprintf(&out, " #%v", item.Identifier.ToInt())
+	}
+	if item.Expiration != nil {
+		fmt.Fprintf(&out, ", expires %s", formatTimestamp(item.Expiration))
+	}
+	if item.Recipient != nil {
+		fmt.Fprintf(&out, " to %v", *item.Recipient)
+	}
+	return out.String()
+}
+
+// formatTimestamp renders a unix timestamp of typed data, which may be too far
+// in the future to be meant as a date.
+func formatTimestamp(ts *hexutil.Big) string {
+	if ts == nil {
+		return "never"
+	}
+	t := ts.ToInt()
+	if !t.IsInt64() || t.Int64() > 253402300799 { // After the year 9999
+		return fmt.Sprintf("never (%v)", t)
+	}
+	return time.Unix(t.Int64(), 0).UTC().Format(time.RFC3339)
+}
+
+// message is a struct value of typed data.
+type message map[string]interface{}
+
+func (msg message) address(field string) (common.Address, error) {
+	value, ok := msg[field].(string)
+	if !ok || !common.IsHexAddress(value) {
+		return common.Address{}, fmt.Errorf("invalid address field %s: %v", field, msg[field])
+	}
+	return common.HexToAddress(value), nil
+}
+
+func (msg message) integer(field string) (*big.Int, error) {
+	value, err := parseInteger("uint256", msg[field])
+	if err != nil {
+		return nil, fmt.Errorf("invalid integer field %s: %v", field, err)
+	}
+	return value, nil
+}
+
+// structs returns a struct or array of structs field as a list.
+func (msg message) structs(field string) ([]message, error) {
+	switch value := msg[field].(type) {
+	case map[string]interface{}:
+		return []message{value}, nil
+	case []interface{}:
+		list := make([]message, 0, len(value))
+		for _, elem := range value {
+			m, ok := elem.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid struct in field %s: %v", field, elem)
+			}
+			list = append(list, m)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("invalid struct field %s: %v", field, msg[field])
+}
@@ -0,0 +1,303 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package apitypes
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const erc2612Permit = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Permit": [
+      {"name": "owner", "type": "address"},
+      {"name": "spender", "type": "address"},
+      {"name": "value", "type": "uint256"},
+      {"name": "nonce", "type": "uint256"},
+      {"name": "deadline", "type": "uint256"}
+    ]
+  },
+  "primaryType": "Permit",
+  "domain": {"name": "USD Coin", "version": "2", "chainId": 1, "verifyingContract": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+  "message": {
+    "owner": "0x0000000000000000000000000000000000001337",
+    "spender": "0x0000000000000000000000000000000000000bad",
+    "value": "%VALUE%",
+    "nonce": 0,
+    "deadline": "1700000000"
+  }
+}`
+
+const daiPermit = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Permit": [
+      {"name": "holder", "type": "address"},
+      {"name": "spender", "type": "address"},
+      {"name": "nonce", "type": "uint256"},
+      {"name": "expiry", "type": "uint256"},
+      {"name": "allowed", "type": "bool"}
+    ]
+  },
+  "primaryType": "Permit",
+  "domain": {"name": "Dai Stablecoin", "version": "1", "chainId": 1, "verifyingContract": "0x6b175474e89094c44da98b954eedeac495271d0f"},
+  "message": {
+    "holder": "0x0000000000000000000000000000000000001337",
+    "spender": "0x0000000000000000000000000000000000000bad",
+    "nonce": 0,
+    "expiry": 0,
+    "allowed": true
+  }
+}`
+
+const permit2Batch = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "PermitBatch": [
+      {"name": "details", "type": "PermitDetails[]"},
+      {"name": "spender", "type": "address"},
+      {"name": "sigDeadline", "type": "uint256"}
+    ],
+    "PermitDetails": [
+      {"name": "token", "type": "address"},
+      {"name": "amount", "type": "uint160"},
+      {"name": "expiration", "type": "uint48"},
+      {"name": "nonce", "type": "uint48"}
+    ]
+  },
+  "primaryType": "PermitBatch",
+  "domain": {"name": "Permit2", "chainId": 1, "verifyingContract": "%CONTRACT%"},
+  "message": {
+    "details": [
+      {"token": "0x0000000000000000000000000000000000000001", "amount": "1000", "expiration": "1700000000", "nonce": 0},
+      {"token": "0x0000000000000000000000000000000000000002", "amount": "0xffffffffffffffffffffffffffffffffffffffff", "expiration": "0xffffffffffff", "nonce": 0}
+    ],
+    "spender": "0x0000000000000000000000000000000000000bad",
+    "sigDeadline": "1700000000"
+  }
+}`
+
+const seaportOrder = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "OrderComponents": [
+      {"name": "offerer", "type": "address"},
+      {"name": "zone", "type": "address"},
+      {"name": "offer", "type": "OfferItem[]"},
+      {"name": "consideration", "type": "ConsiderationItem[]"},
+      {"name": "orderType", "type": "uint8"},
+      {"name": "startTime", "type": "uint256"},
+      {"name": "endTime", "type": "uint256"},
+      {"name": "zoneHash", "type": "bytes32"},
+      {"name": "salt", "type": "uint256"},
+      {"name": "conduitKey", "type": "bytes32"},
+      {"name": "counter", "type": "uint256"}
+    ],
+    "OfferItem": [
+      {"name": "itemType", "type": "uint8"},
+      {"name": "token", "type": "address"},
+      {"name": "identifierOrCriteria", "type": "uint256"},
+      {"name": "startAmount", "type": "uint256"},
+      {"name": "endAmount", "type": "uint256"}
+    ],
+    "ConsiderationItem": [
+      {"name": "itemType", "type": "uint8"},
+      {"name": "token", "type": "address"},
+      {"name": "identifierOrCriteria", "type": "uint256"},
+      {"name": "startAmount", "type": "uint256"},
+      {"name": "endAmount", "type": "uint256"},
+      {"name": "recipient", "type": "address"}
+    ]
+  },
+  "primaryType": "OrderComponents",
+  "domain": {"name": "Seaport", "version": "1.5", "chainId": 1, "verifyingContract": "0x00000000000000adc04c56bf30ac9d3c0aaf14dc"},
+  "message": {
+    "offerer": "0x0000000000000000000000000000000000001337",
+    "zone": "0x0000000000000000000000000000000000000000",
+    "offer": [
+      {"itemType": 2, "token": "0x0000000000000000000000000000000000000a11", "identifierOrCriteria": "42", "startAmount": "1", "endAmount": "1"}
+    ],
+    "consideration": [
+      {"itemType": 0, "token": "0x0000000000000000000000000000000000000000", "identifierOrCriteria": "0", "startAmount": "2000000000000000000", "endAmount": "1000000000000000000", "recipient": "%RECIPIENT%"}
+    ],
+    "orderType": 0,
+    "startTime": "1690000000",
+    "endTime": "1700000000",
+    "zoneHash": "0x0000000000000000000000000000000000000000000000000000000000000000",
+    "salt": "1",
+    "conduitKey": "0x0000000000000000000000000000000000000000000000000000000000000000",
+    "counter": "0"
+  }
+}`
+
+func parseTypedData(t *testing.T, data string, replacements ...string) *TypedData {
+	t.Helper()
+
+	var td TypedData
+	if err := json.Unmarshal([]byte(strings.NewReplacer(replacements...).Replace(data)), &td); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := TypedDataAndHash(td); err != nil {
+		t.Fatal(err)
+	}
+	return &td
+}
+
+func summarize(t *testing.T, td *TypedData) (*TypedDataSummary, []ValidationInfo) {
+	t.Helper()
+
+	summary, err := td.Summarize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary == nil {
+		t.Fatal("typed data not decoded")
+	}
+	var msgs ValidationMessages
+	summary.Validate(&msgs)
+	return summary, msgs.Messages
+}
+
+func countWarnings(msgs []ValidationInfo) int {
+	var n int
+	for _, msg := range msgs {
+		if msg.Typ == WARN {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSummarizeERC2612Permit(t *testing.T) {
+	usdc := common.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+
+	summary, msgs := summarize(t, parseTypedData(t, erc2612Permit, "%VALUE%", "1000000"))
+	if summary.Kind != KindERC2612Permit || summary.Contract != usdc || *summary.Spender != common.HexToAddress("0xbad") {
+		t.Fatalf("wrong summary: %+v", summary)
+	}
+	if len(summary.Items) != 1 || summary.Items[0].Token != usdc || summary.Items[0].Amount.ToInt().Int64() != 1000000 {
+		t.Fatalf("wrong items: %+v", summary.Items)
+	}
+	if summary.Unlimited || countWarnings(msgs) != 0 {
+		t.Fatalf("limited permit flagged: %v", msgs)
+	}
+	if have := summary.String(); !strings.Contains(have, "Deadline: 2023-11-14T22:13:20Z") || !strings.Contains(have, "1000000 of token "+usdc.Hex()) {
+		t.Errorf("wrong rendering:\n%s", have)
+	}
+
+	maxUint256 := "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+	summary, msgs = summarize(t, parseTypedData(t, erc2612Permit, "%VALUE%", maxUint256))
+	if !summary.Unlimited || countWarnings(msgs) != 1 {
+		t.Fatalf("unlimited permit not flagged: %v", msgs)
+	}
+	if have := summary.String(); !strings.Contains(have, "UNLIMITED of token") {
+		t.Errorf("wrong rendering:\n%s", have)
+	}
+}
+
+func TestSummarizeDaiPermit(t *testing.T) {
+	summary, msgs := summarize(t, parseTypedData(t, daiPermit))
+	if summary.Kind != KindDaiPermit || !summary.Unlimited || summary.Deadline != nil {
+		t.Fatalf("wrong summary: %+v", summary)
+	}
+	if countWarnings(msgs) != 1 {
+		t.Fatalf("unlimited permit not flagged: %v", msgs)
+	}
+	if have := summary.String(); !strings.Contains(have, "Deadline: never") {
+		t.Errorf("wrong rendering:\n%s", have)
+	}
+}
+
+func TestSummarizePermit2(t *testing.T) {
+	summary, msgs := summarize(t, parseTypedData(t, permit2Batch, "%CONTRACT%", Permit2Address.Hex()))
+	if summary.Kind != KindPermit2Allow || !summary.Unlimited || len(summary.Items) != 2 {
+		t.Fatalf("wrong summary: %+v", summary)
+	}
+	if summary.Items[0].Unlimited || !summary.Items[1].Unlimited {
+		t.Fatalf("wrong items: %+v", summary.Items)
+	}
+	if countWarnings(msgs) != 1 {
+		t.Fatalf("wrong warnings: %v", msgs)
+	}
+	if have := summary.String(); !strings.Contains(have, "expires never (281474976710655)") {
+		t.Errorf("wrong rendering:\n%s", have)
+	}
+	// Permit2 permits for other contracts are likely phishing
+	_, msgs = summarize(t, parseTypedData(t, permit2Batch, "%CONTRACT%", "0x0000000000000000000000000000000000000bad"))
+	if countWarnings(msgs) != 2 {
+		t.Fatalf("impostor Permit2 not flagged: %v", msgs)
+	}
+}
+
+func TestSummarizeSeaportOrder(t *testing.T) {
+	summary, msgs := summarize(t, parseTypedData(t, seaportOrder, "%RECIPIENT%", "0x0000000000000000000000000000000000001337"))
+	if summary.Kind != KindSeaportOrder || *summary.Owner != common.HexToAddress("0x1337") {
+		t.Fatalf("wrong summary: %+v", summary)
+	}
+	if item := summary.Items[0]; item.Type != "ERC721" || item.Identifier.ToInt().Int64() != 42 {
+		t.Fatalf("wrong offer: %+v", item)
+	}
+	// The worst case price for the offerer is the lower one
+	if item := summary.Considerations[0]; item.Amount.ToInt().String() != "1000000000000000000" {
+		t.Fatalf("wrong consideration: %+v", item)
+	}
+	if countWarnings(msgs) != 0 {
+		t.Fatalf("sale flagged: %v", msgs)
+	}
+	// Orders paying someone else are giveaways
+	_, msgs = summarize(t, parseTypedData(t, seaportOrder, "%RECIPIENT%", "0x0000000000000000000000000000000000000bad"))
+	if countWarnings(msgs) != 1 {
+		t.Fatalf("giveaway not flagged: %v", msgs)
+	}
+}
+
+func TestSummarizeUnknown(t *testing.T) {
+	// Typed data of unknown types is not decoded
+	td := parseTypedData(t, erc2612Permit, "%VALUE%", "1", `"Permit"`, `"Mail"`)
+	if summary, err := td.Summarize(); summary != nil || err != nil {
+		t.Fatalf("unknown typed data decoded: %v %v", summary, err)
+	}
+	// Malformed typed data of known types is an error
+	td = parseTypedData(t, erc2612Permit, "%VALUE%", "1")
+	td.Message["spender"] = "0xbad"
+	if _, err := td.Summarize(); err == nil {
+		t.Fatal("malformed typed data decoded")
+	}
+}
@@ -34,6 +34,8 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
 )
 
 var typedDataReferenceTypeRegexp = regexp.MustCompile(`^[A-Za-z](\w*)(\[\])?$`)
@@ -98,6 +100,16 @@ type SendTxArgs struct {
 	// For non-legacy transactions
 	AccessList *types.AccessList `json:"accessList,omitempty"`
 	ChainID    *hexutil.Big      `json:"chainId,omitempty"`
+
+	// For blob transactions
+	BlobFeeCap *hexutil.Big  `json:"maxFeePerBlobGas,omitempty"`
+	BlobHashes []common.Hash `json:"blobVersionedHashes,omitempty"`
+
+	// For blob transactions carrying their sidecar. The commitments and proofs
+	// are computed from the blobs if omitted.
+	Blobs       []kzg4844.Blob       `json:"blobs,omitempty"`
+	Commitments []kzg4844.Commitment `json:"commitments,omitempty"`
+	Proofs      []kzg4844.Proof      `json:"proofs,omitempty"`
 }
 
 func (args SendTxArgs) String() string {
@@ -108,8 +120,92 @@ func (args SendTxArgs) String() string {
 	return err.Error()
 }
 
+// IsBlobTx returns whether the arguments describe a blob transaction.
+func (args *SendTxArgs) IsBlobTx() bool {
+	return args.BlobFeeCap != nil || args.BlobHashes != nil || args.Blobs != nil
+}
+
+// ValidateTxSidecar checks the blobs of a blob transaction against their
+// commitments, proofs and versioned hashes. Missing commitments, proofs and
+// hashes are computed from the blobs.
+func (args *SendTxArgs) ValidateTxSidecar() error {
+	// No blobs, nothing to validate
+	if args.Blobs == nil {
+		return nil
+	}
+	n := len(args.Blobs)
+	if n == 0 {
+		return errors.New("blob transaction sidecar without blobs")
+	}
+	// Commitments and proofs are either both given or both computed
+	switch {
+	case args.Commitments == nil && args.Proofs != nil:
+		return errors.New("blob proofs provided without commitments")
+	case args.Commitments != nil && args.Proofs == nil:
+		return errors.New("blob commitments provided without proofs")
+	}
+	if args.Commitments != nil && len(args.Commitments) != n {
+		return fmt.Errorf("number of blobs and commitments mismatch (have=%d, want=%d)", len(args.Commitments), n)
+	}
+	if args.Proofs != nil && len(args.Proofs) != n {
+		return fmt.Errorf("number of blobs and proofs mismatch (have=%d, want=%d)", len(args.Proofs), n)
+	}
+	if args.BlobHashes != nil && len(args.BlobHashes) != n {
+		return fmt.Errorf("number of blobs and hashes mismatch (have=%d, want=%d)", len(args.BlobHashes), n)
+	}
+	if args.Commitments == nil {
+		commitments := make([]kzg4844.Commitment, n)
+		proofs := make([]kzg4844.Proof, n)
+		for i, blob := range args.Blobs {
+			c, err := kzg4844.BlobToCommitment(blob)
+			if err != nil {
+				return fmt.Errorf("blobs[%d]: error computing commitment: %v", i, err)
+			}
+			p, err := kzg4844.ComputeBlobProof(blob, c)
+			if err != nil {
+				return fmt.Errorf("blobs[%d]: error computing proof: %v", i, err)
+			}
+			commitments[i], proofs[i] = c, p
+		}
+		args.Commitments, args.Proofs = commitments, proofs
+	} else {
+		for i, blob := range args.Blobs {
+			if err := kzg4844.VerifyBlobProof(blob, args.Commitments[i], args.Proofs[i]); err != nil {
+				return fmt.Errorf("blobs[%d]: failed to verify blob proof: %v", i, err)
+			}
+		}
+	}
+	sidecar := types.BlobTxSidecar{Blobs: args.Blobs, Commitments: args.Commitments, Proofs: args.Proofs}
+	hashes := sidecar.BlobHashes()
+	if args.BlobHashes == nil {
+		args.BlobHashes = hashes
+		return nil
+	}
+	for i, hash := range hashes {
+		if args.BlobHashes[i] != hash {
+			return fmt.Errorf("blobVersionedHashes[%d]: hash mismatch (have=%v, want=%v)", i, args.BlobHashes[i], hash)
+		}
+	}
+	return nil
+}
+
+// toUint256 converts an optional big integer argument to a 256-bit integer.
+func toUint256(field string, v *hexutil.Big) (*uint256.Int, error) {
+	if v == nil {
+		return new(uint256.Int), nil
+	}
+	if v.ToInt().Sign() < 0 {
+		return nil, fmt.Errorf("%s is negative", field)
+	}
+	u, overflow := uint256.FromBig(v.ToInt())
+	if overflow {
+		return nil, fmt.Errorf("%s exceeds 256 bits", field)
+	}
+	return u, nil
+}
+
 // ToTransaction converts the arguments to a transaction.
-func (args *SendTxArgs) ToTransaction() *types.Transaction {
+func (args *SendTxArgs) ToTransaction() (*types.Transaction, error) {
 	// Add the To-field, if specified
 	var to *common.Address
 	if args.To != nil {
@@ -126,6 +222,48 @@ func (args *SendTxArgs) ToTransaction() *types.Transaction {
 
 	var data types.TxData
 	switch {
+	case args.IsBlobTx():
+		if to == nil {
+			return nil, errors.New("blob transaction without recipient")
+		}
+		if err := args.ValidateTxSidecar(); err != nil {
+			return nil, err
+		}
+		tx := &types.BlobTx{
+			To:         *to,
+			Nonce:      uint64(args.Nonce),
+			Gas:        uint64(args.Gas),
+			Data:       input,
+			BlobHashes: args.BlobHashes,
+		}
+		if args.AccessList != nil {
+			tx.AccessList = *args.AccessList
+		}
+		for _, field := range []struct {
+			name string
+			arg  *hexutil.Big
+			val  **uint256.Int
+		}{
+			{"chainId", args.ChainID, &tx.ChainID},
+			{"maxPriorityFeePerGas", args.MaxPriorityFeePerGas, &tx.GasTipCap},
+			{"maxFeePerGas", args.MaxFeePerGas, &tx.GasFeeCap},
+			{"value", &args.Value, &tx.Value},
+			{"maxFeePerBlobGas", args.BlobFeeCap, &tx.BlobFeeCap},
+		} {
+			v, err := toUint256(field.name, field.arg)
+			if err != nil {
+				return nil, err
+			}
+			*field.val = v
+		}
+		if args.Blobs != nil {
+			tx.Sidecar = &types.BlobTxSidecar{
+				Blobs:       args.Blobs,
+				Commitments: args.Commitments,
+				Proofs:      args.Proofs,
+			}
+		}
+		data = tx
 	case args.MaxFeePerGas != nil:
 		al := types.AccessList{}
 		if args.AccessList != nil {
@@ -163,7 +301,7 @@ func (args *SendTxArgs) ToTransaction() *types.Transaction {
 			Data:     input,
 		}
 	}
-	return types.NewTx(data)
+	return types.NewTx(data), nil
 }
 
 type SigFormat struct {
@@ -16,7 +16,17 @@
 
 package apitypes
 
-import "testing"
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
 
 func TestIsPrimitive(t *testing.T) {
 	// Expected positives
@@ -38,3 +48,140 @@ func TestIsPrimitive(t *testing.T) {
 		}
 	}
 }
+
+var (
+	emptyBlob          = kzg4844.Blob{}
+	emptyBlobCommit, _ = kzg4844.BlobToCommitment(emptyBlob)
+	emptyBlobProof, _  = kzg4844.ComputeBlobProof(emptyBlob, emptyBlobCommit)
+	emptyBlobHash      = (&types.BlobTxSidecar{Blobs: []kzg4844.Blob{emptyBlob}, Commitments: []kzg4844.Commitment{emptyBlobCommit}}).BlobHashes()[0]
+)
+
+func blobTxArgs() *SendTxArgs {
+	to := common.NewMixedcaseAddress(common.HexToAddress("0x1337"))
+	return &SendTxArgs{
+		From:                 common.NewMixedcaseAddress(common.HexToAddress("0x1")),
+		To:                   &to,
+		Gas:                  21000,
+		MaxFeePerGas:         (*hexutil.Big)(big.NewInt(100)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(2)),
+		BlobFeeCap:           (*hexutil.Big)(big.NewInt(7)),
+		ChainID:              (*hexutil.Big)(big.NewInt(1337)),
+	}
+}
+
+func TestBlobTxSidecarValidation(t *testing.T) {
+	var otherHash = common.Hash{0x01, 0x02}
+
+	tests := []struct {
+		name   string
+		modify func(args *SendTxArgs)
+		err    string
+	}{
+		{
+			name:   "blobs only",
+			modify: func(args *SendTxArgs) { args.Blobs = []kzg4844.Blob{emptyBlob} },
+		},
+		{
+			name: "full sidecar",
+			modify: func(args *SendTxArgs) {
+				args.Blobs = []kzg4844.Blob{emptyBlob}
+				args.Commitments = []kzg4844.Commitment{emptyBlobCommit}
+				args.Proofs = []kzg4844.Proof{emptyBlobProof}
+				args.BlobHashes = []common.Hash{emptyBlobHash}
+			},
+		},
+		{
+			name: "commitments without proofs",
+			modify: func(args *SendTxArgs) {
+				args.Blobs = []kzg4844.Blob{emptyBlob}
+				args.Commitments = []kzg4844.Commitment{emptyBlobCommit}
+			},
+			err: "blob commitments provided without proofs",
+		},
+		{
+			name: "too few proofs",
+			modify: func(args *SendTxArgs) {
+				args.Blobs = []kzg4844.Blob{emptyBlob, emptyBlob}
+				args.Commitments = []kzg4844.Commitment{emptyBlobCommit, emptyBlobCommit}
+				args.Proofs = []kzg4844.Proof{emptyBlobProof}
+			},
+			err: "number of blobs and proofs mismatch",
+		},
+		{
+			name: "invalid proof",
+			modify: func(args *SendTxArgs) {
+				args.Blobs = []kzg4844.Blob{emptyBlob}
+				args.Commitments = []kzg4844.Commitment{emptyBlobCommit}
+				args.Proofs = []kzg4844.Proof{{0x01}}
+			},
+			err: "failed to verify blob proof",
+		},
+		{
+			name: "wrong hash",
+			modify: func(args *SendTxArgs) {
+				args.Blobs = []kzg4844.Blob{emptyBlob}
+				args.BlobHashes = []common.Hash{otherHash}
+			},
+			err: "blobVersionedHashes[0]: hash mismatch",
+		},
+	}
+	for _, tt := range tests {
+		args := blobTxArgs()
+		tt.modify(args)
+
+		err := args.ValidateTxSidecar()
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: wrong error: have %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: validation failed: %v", tt.name, err)
+			continue
+		}
+		if len(args.BlobHashes) != 1 || args.BlobHashes[0] != emptyBlobHash {
+			t.Errorf("%s: wrong blob hashes: %v", tt.name, args.BlobHashes)
+		}
+		if len(args.Commitments) != 1 || args.Commitments[0] != emptyBlobCommit || len(args.Proofs) != 1 {
+			t.Errorf("%s: wrong commitments or proofs", tt.name)
+		}
+	}
+}
+
+func TestBlobTxToTransaction(t *testing.T) {
+	// Blob transactions can be described by their hashes alone
+	args := blobTxArgs()
+	args.BlobHashes = []common.Hash{emptyBlobHash}
+
+	tx, err := args.ToTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type() != types.BlobTxType {
+		t.Fatalf("wrong transaction type: have %d, want %d", tx.Type(), types.BlobTxType)
+	}
+	if tx.BlobGasFeeCap().Uint64() != 7 || len(tx.BlobHashes()) != 1 || tx.BlobTxSidecar() != nil {
+		t.Fatalf("wrong blob fields: %v", tx)
+	}
+	// Sidecars given as JSON are carried by the transaction
+	blob, _ := json.Marshal(emptyBlob)
+	args = blobTxArgs()
+	if err := json.Unmarshal([]byte(`{"maxFeePerBlobGas":"0x7","blobs":[`+string(blob)+`]}`), args); err != nil {
+		t.Fatal(err)
+	}
+	if tx, err = args.ToTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	if sidecar := tx.BlobTxSidecar(); sidecar == nil || sidecar.Commitments[0] != emptyBlobCommit {
+		t.Fatal("transaction misses the blob sidecar")
+	}
+	if tx.BlobHashes()[0] != emptyBlobHash {
+		t.Fatalf("wrong blob hash: have %v, want %v", tx.BlobHashes()[0], emptyBlobHash)
+	}
+	// Blob transactions can't create contracts
+	args.To = nil
+	if _, err := args.ToTransaction(); err == nil {
+		t.Fatal("converted blob transaction without recipient")
+	}
+}
@@ -112,13 +112,23 @@ func (l *AuditLogger) Version(ctx context.Context) (string, error) {
 	return data, err
 }
 
-func NewAuditLogger(path string, api ExternalAPI) (*AuditLogger, error) {
-	l := log.New("api", "signer")
+// NewAuditLogger wraps api, recording its requests and responses in the given
+// audit log.
+func NewAuditLogger(audit log.Logger, api ExternalAPI) *AuditLogger {
+	return &AuditLogger{audit.New("api", "signer"), api}
+}
+
+// NewAuditLog creates a logger appending to the audit log at path. The file is
+// opened once, all parts of the signer leaving an audit trail, e.g. the external
+// API and the votes of approvers, should log to children of the returned logger
+// to keep their records in order.
+func NewAuditLog(path string) (log.Logger, error) {
+	l := log.New()
 	handler, err := log.FileHandler(path, log.LogfmtFormat())
 	if err != nil {
 		return nil, err
 	}
 	l.SetHandler(handler)
 	l.Info("Configured", "audit log", path)
-	return &AuditLogger{l, api}, nil
+	return l, nil
 }
@@ -120,6 +120,9 @@ func (ui *CommandlineUI) ApproveTx(request *SignTxRequest) (SignTxResponse, erro
 	if request.Transaction.MaxFeePerGas != nil {
 		fmt.Printf("maxFeePerGas:          %v wei\n", request.Transaction.MaxFeePerGas.ToInt())
 		fmt.Printf("maxPriorityFeePerGas:  %v wei\n", request.Transaction.MaxPriorityFeePerGas.ToInt())
+		if request.Transaction.BlobFeeCap != nil {
+			fmt.Printf("maxFeePerBlobGas:      %v wei\n", request.Transaction.BlobFeeCap.ToInt())
+		}
 	} else {
 		fmt.Printf("gasprice: %v wei\n", request.Transaction.GasPrice.ToInt())
 	}
@@ -136,6 +139,16 @@ func (ui *CommandlineUI) ApproveTx(request *SignTxRequest) (SignTxResponse, erro
 			}
 		}
 	}
+	if hashes := request.Transaction.BlobHashes; hashes != nil {
+		if request.Transaction.Blobs != nil {
+			fmt.Printf("Blobs (sidecar of %d blobs verified)\n", len(request.Transaction.Blobs))
+		} else {
+			fmt.Printf("Blobs (no sidecar)\n")
+		}
+		for i, hash := range hashes {
+			fmt.Printf(" %d. %v\n", i, hash)
+		}
+	}
 	if request.Transaction.Data != nil {
 		d := *request.Transaction.Data
 		if len(d) > 0 {
@@ -172,6 +185,9 @@ func (ui *CommandlineUI) ApproveSignData(request *SignDataRequest) (SignDataResp
 		}
 		fmt.Println()
 	}
+	if request.TypedData != nil {
+		fmt.Printf("Decoded typed data:\n%v\n", request.TypedData)
+	}
 	fmt.Printf("messages:\n")
 	for _, nvt := range request.Messages {
 		fmt.Printf("\u00a0\u00a0%v\n", strings.TrimSpace(nvt.Pprint(1)))
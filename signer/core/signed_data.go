@@ -240,7 +240,7 @@ func (api *SignerAPI) signTypedData(ctx context.Context, addr common.MixedcaseAd
 	req.Address = addr
 	req.Meta = MetadataFromContext(ctx)
 	if validationMessages != nil {
-		req.Callinfo = validationMessages.Messages
+		req.Callinfo = append(validationMessages.Messages, req.Callinfo...)
 	}
 	signature, err := api.sign(req, true)
 	if err != nil {
@@ -282,11 +282,22 @@ func typedDataRequest(data any) (*SignDataRequest, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Decode well-known typed data, e.g. token approvals, and warn about the
+	// dangerous ones
+	var msgs apitypes.ValidationMessages
+	summary, err := typedData.Summarize()
+	if err != nil {
+		msgs.Warn(fmt.Sprintf("Failed to decode %s typed data: %v", typedData.PrimaryType, err))
+	} else if summary != nil {
+		summary.Validate(&msgs)
+	}
 	return &SignDataRequest{
 		ContentType: apitypes.DataTyped.Mime,
 		Rawdata:     []byte(rawData),
 		Messages:    messages,
-		Hash:        sighash}, nil
+		Callinfo:    msgs.Messages,
+		Hash:        sighash,
+		TypedData:   summary}, nil
 }
 
 // EcRecover recovers the address associated with the given sig.
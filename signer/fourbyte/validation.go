@@ -23,6 +23,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
@@ -45,6 +46,13 @@ func (db *Database) ValidateTransaction(selector *string, tx *apitypes.SendTxArg
 	if tx.Data != nil {
 		data = *tx.Data
 	}
+	// Blob transactions can't create contracts, validate their fields first
+	if tx.IsBlobTx() {
+		if tx.To == nil {
+			return nil, errors.New("blob transaction can't create a contract")
+		}
+		validateBlobFields(tx, messages)
+	}
 	// Contract creation doesn't validate call data, handle first
 	if tx.To == nil {
 		// Contract creation should contain sufficient data to deploy a contract. A
@@ -88,6 +96,34 @@ func (db *Database) ValidateTransaction(selector *string, tx *apitypes.SendTxArg
 	return messages, nil
 }
 
+// validateBlobFields checks the blob specific fields of a blob transaction.
+// Blob sidecars are verified against the versioned hashes before validation.
+func validateBlobFields(tx *apitypes.SendTxArgs, messages *apitypes.ValidationMessages) {
+	if tx.GasPrice != nil {
+		messages.Crit("Blob transaction with 'gasPrice' specified, use 'maxFeePerGas' instead.")
+	}
+	if tx.BlobFeeCap == nil {
+		messages.Crit("Blob transaction without 'maxFeePerBlobGas' specified.")
+	}
+	if len(tx.BlobHashes) == 0 {
+		messages.Crit("Blob transaction without blobs")
+		return
+	}
+	if maxBlobs := params.MaxBlobGasPerBlock / params.BlobTxBlobGasPerBlob; len(tx.BlobHashes) > maxBlobs {
+		messages.Crit(fmt.Sprintf("Blob transaction carries %d blobs, more than fit in a block (%d)", len(tx.BlobHashes), maxBlobs))
+	}
+	for i, hash := range tx.BlobHashes {
+		if hash[0] != params.BlobTxHashVersion {
+			messages.Crit(fmt.Sprintf("Blob versioned hash %d has unsupported version %#x", i, hash[0]))
+		}
+	}
+	if tx.Blobs == nil {
+		messages.Info(fmt.Sprintf("Transaction commits to %d blobs, which are not included", len(tx.BlobHashes)))
+	} else {
+		messages.Info(fmt.Sprintf("Transaction carries %d blobs, verified against their versioned hashes", len(tx.Blobs)))
+	}
+}
+
 // ValidateCallData checks if the ABI call-data + method selector (if given) can
 // be parsed and seems to match.
 func (db *Database) ValidateCallData(selector *string, data []byte, messages *apitypes.ValidationMessages) {
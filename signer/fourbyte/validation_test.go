@@ -134,3 +134,64 @@ func TestTransactionValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestBlobTransactionValidation(t *testing.T) {
+	db := newEmpty()
+	to, _ := mixAddr("0x000000000000000000000000000000000000dEaD")
+	from, _ := mixAddr("0x000000000000000000000000000000000000dEaD")
+	blobArgs := func() *apitypes.SendTxArgs {
+		return &apitypes.SendTxArgs{
+			From:                 *from,
+			To:                   to,
+			MaxFeePerGas:         (*hexutil.Big)(big.NewInt(100)),
+			MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(2)),
+			BlobFeeCap:           (*hexutil.Big)(big.NewInt(7)),
+			BlobHashes:           []common.Hash{{0x01}},
+		}
+	}
+	tests := []struct {
+		modify      func(tx *apitypes.SendTxArgs)
+		expectErr   bool
+		numMessages int
+	}{
+		// Valid blob transaction without sidecar, noted as info
+		{modify: func(tx *apitypes.SendTxArgs) {}, numMessages: 1},
+		// Blob transactions can't create contracts
+		{modify: func(tx *apitypes.SendTxArgs) { tx.To = nil }, expectErr: true},
+		// Missing blob fee cap
+		{modify: func(tx *apitypes.SendTxArgs) { tx.BlobFeeCap = nil }, numMessages: 2},
+		// Legacy gas price instead of dynamic fees
+		{modify: func(tx *apitypes.SendTxArgs) {
+			tx.GasPrice, tx.MaxFeePerGas, tx.MaxPriorityFeePerGas = (*hexutil.Big)(big.NewInt(100)), nil, nil
+		}, numMessages: 2},
+		// No blobs at all
+		{modify: func(tx *apitypes.SendTxArgs) { tx.BlobHashes = []common.Hash{} }, numMessages: 1},
+		// Unknown hash version
+		{modify: func(tx *apitypes.SendTxArgs) { tx.BlobHashes = []common.Hash{{0x02}} }, numMessages: 2},
+		// More blobs than fit in a block
+		{modify: func(tx *apitypes.SendTxArgs) {
+			tx.BlobHashes = make([]common.Hash, 7)
+			for i := range tx.BlobHashes {
+				tx.BlobHashes[i][0] = 0x01
+			}
+		}, numMessages: 2},
+	}
+	for i, test := range tests {
+		tx := blobArgs()
+		test.modify(tx)
+
+		msgs, err := db.ValidateTransaction(nil, tx)
+		if err == nil && test.expectErr {
+			t.Errorf("Test %d, expected error", i)
+		}
+		if err != nil && !test.expectErr {
+			t.Errorf("Test %d, unexpected error: %v", i, err)
+		}
+		if err == nil && len(msgs.Messages) != test.numMessages {
+			for _, msg := range msgs.Messages {
+				t.Logf("* %s: %s", msg.Typ, msg.Message)
+			}
+			t.Errorf("Test %d, expected %d messages, got %d", i, test.numMessages, len(msgs.Messages))
+		}
+	}
+}
@@ -0,0 +1,394 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ethereum/go-ethereum/signer/storage"
+)
+
+const (
+	// dailyWindow is the time window of daily value limits.
+	dailyWindow = 24 * time.Hour
+
+	// reservationTimeout is the time after which values approved by rules with
+	// daily limits are released again if the transaction was not signed.
+	reservationTimeout = 10 * time.Minute
+)
+
+// Decision is the outcome of evaluating a request against a policy.
+type Decision struct {
+	Action  Action   // Outcome of the request, empty if no rule matched
+	Rule    string   // Name of the matching rule
+	Reasons []string // Reasons the rules before the matching one didn't match
+}
+
+// Engine evaluates requests against a policy, keeping track of the values
+// approved by rules with daily limits in a storage.
+type Engine struct {
+	policy   *Policy
+	storage  storage.Storage
+	now      func() time.Time
+	reserved map[reservationKey]*reservation // Approved values awaiting the signing of their transaction
+
+	lock sync.Mutex // Serializes evaluations, so daily limits aren't exceeded by concurrent requests
+}
+
+// reservationKey identifies the transaction of a reservation.
+type reservationKey struct {
+	from  common.Address
+	nonce uint64
+}
+
+// reservation is a value approved by a rule with a daily limit, whose
+// transaction has not been signed yet.
+type reservation struct {
+	rule  *TxRule
+	spent spending
+}
+
+// NewEngine creates an engine evaluating requests against the policy.
+func NewEngine(policy *Policy, db storage.Storage) *Engine {
+	return &Engine{
+		policy:   policy,
+		storage:  db,
+		now:      time.Now,
+		reserved: make(map[reservationKey]*reservation),
+	}
+}
+
+// EvaluateTx checks a transaction signing request against the rules of the
+// policy. If a rule with a daily limit approves the request, its value is
+// reserved until ConfirmTx reports the transaction signed, when it is recorded
+// in the storage. Reservations of transactions not signed in time are released.
+func (e *Engine) EvaluateTx(req *core.SignTxRequest) Decision {
+	return e.EvaluateTxAt(req, e.now())
+}
+
+// EvaluateTxAt is like EvaluateTx, but evaluates the request as if it was made
+// at the given time, for replaying requests.
+func (e *Engine) EvaluateTxAt(req *core.SignTxRequest, now time.Time) Decision {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.release(now)
+
+	var reasons []string
+	for _, rule := range e.policy.Transactions {
+		if reason := e.match(rule, req, now); reason != "" {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", rule.Name, reason))
+			continue
+		}
+		if rule.Action == Approve && rule.DailyValue != nil {
+			e.reserve(rule, req, now)
+		}
+		return Decision{Action: rule.Action, Rule: rule.Name, Reasons: reasons}
+	}
+	return Decision{Reasons: reasons}
+}
+
+// match checks whether a rule matches a request, returning the reason if not.
+func (e *Engine) match(rule *TxRule, req *core.SignTxRequest, now time.Time) string {
+	tx := &req.Transaction
+
+	// Check the time of the request
+	if len(rule.Days) > 0 && !containsDay(rule.Days, now.UTC().Weekday()) {
+		return fmt.Sprintf("not allowed on %v", now.UTC().Weekday())
+	}
+	if rule.Hours != nil && !rule.Hours.contains(now) {
+		return fmt.Sprintf("only allowed %v UTC", rule.Hours)
+	}
+	// Check the parties of the transaction
+	if len(rule.From) > 0 && !containsAddress(rule.From, tx.From.Address()) {
+		return fmt.Sprintf("sender %v not allowed", tx.From.Address())
+	}
+	switch {
+	case tx.To == nil && !rule.Create:
+		return "contract creation not allowed"
+	case tx.To != nil && len(rule.To) > 0 && !containsAddress(rule.To, tx.To.Address()):
+		return fmt.Sprintf("recipient %v not allowed", tx.To.Address())
+	}
+	if !rule.AllowWarnings {
+		for _, msg := range req.Callinfo {
+			if msg.Typ == apitypes.WARN || msg.Typ == apitypes.CRIT {
+				return fmt.Sprintf("validation %s: %s", msg.Typ, msg.Message)
+			}
+		}
+	}
+	// Check the invoked method, contract creations carry code instead
+	if tx.To != nil {
+		var data []byte
+		if tx.Input != nil {
+			data = *tx.Input
+		} else if tx.Data != nil {
+			data = *tx.Data
+		}
+		switch {
+		case len(data) == 0:
+		case len(rule.selectors) == 0:
+			return "call data not allowed"
+		case len(data) < 4:
+			return "call data without method selector"
+		default:
+			if _, ok := rule.selectors[[4]byte(data[:4])]; !ok {
+				return fmt.Sprintf("method %#x not allowed", data[:4])
+			}
+		}
+	}
+	// Check the value and fees of the transaction
+	value := tx.Value.ToInt()
+	if rule.MaxValue != nil && value.Cmp(rule.MaxValue.ToInt()) > 0 {
+		return fmt.Sprintf("value %v above limit %v", value, rule.MaxValue)
+	}
+	if rule.MaxGas != 0 && uint64(tx.Gas) > rule.MaxGas {
+		return fmt.Sprintf("gas %d above limit %d", tx.Gas, rule.MaxGas)
+	}
+	if rule.MaxGasPrice != nil {
+		price := tx.GasPrice
+		if price == nil {
+			price = tx.MaxFeePerGas
+		}
+		if price == nil {
+			return "no gas price"
+		}
+		if price.ToInt().Cmp(rule.MaxGasPrice.ToInt()) > 0 {
+			return fmt.Sprintf("gas price %v above limit %v", price.ToInt(), rule.MaxGasPrice)
+		}
+	}
+	if tx.IsBlobTx() {
+		switch {
+		case rule.MaxBlobGasPrice == nil:
+			return "blob transactions not allowed"
+		case tx.BlobFeeCap == nil:
+			return "no blob gas price"
+		case tx.BlobFeeCap.ToInt().Cmp(rule.MaxBlobGasPrice.ToInt()) > 0:
+			return fmt.Sprintf("blob gas price %v above limit %v", tx.BlobFeeCap.ToInt(), rule.MaxBlobGasPrice)
+		}
+	}
+	if rule.DailyValue != nil {
+		spent := new(big.Int).Add(e.spent(rule, now), value)
+		if spent.Cmp(rule.DailyValue.ToInt()) > 0 {
+			return fmt.Sprintf("daily value %v above limit %v", spent, rule.DailyValue)
+		}
+	}
+	return ""
+}
+
+// spending is a value approved by a rule with a daily limit.
+type spending struct {
+	Time  int64        `json:"time"`
+	Value *hexutil.Big `json:"value"`
+}
+
+// storageKey returns the key of the approved values of a rule in the storage.
+func storageKey(rule *TxRule) string {
+	return "policy:" + rule.Name
+}
+
+// spendings returns the values approved by a rule in the last 24 hours.
+func (e *Engine) spendings(rule *TxRule, now time.Time) []spending {
+	blob, err := e.storage.Get(storageKey(rule))
+	if err != nil {
+		return nil
+	}
+	var all []spending
+	if err := json.Unmarshal([]byte(blob), &all); err != nil {
+		// Corrupt state must not lift the limit, count it as exhausted
+		log.Error("Invalid policy state, daily limit exhausted", "rule", rule.Name, "err", err)
+		return []spending{{Time: now.Unix(), Value: (*hexutil.Big)(rule.DailyValue.ToInt())}}
+	}
+	recent := all[:0]
+	for _, s := range all {
+		if now.Sub(time.Unix(s.Time, 0)) < dailyWindow && s.Value != nil {
+			recent = append(recent, s)
+		}
+	}
+	return recent
+}
+
+// spent returns the sum of the values approved by a rule in the last 24 hours,
+// including the ones reserved for transactions not signed yet.
+func (e *Engine) spent(rule *TxRule, now time.Time) *big.Int {
+	sum := new(big.Int)
+	for _, s := range e.spendings(rule, now) {
+		sum.Add(sum, s.Value.ToInt())
+	}
+	for _, r := range e.reserved {
+		if r.rule == rule {
+			sum.Add(sum, r.spent.Value.ToInt())
+		}
+	}
+	return sum
+}
+
+// release drops the reservations of transactions not signed in time.
+func (e *Engine) release(now time.Time) {
+	for key, r := range e.reserved {
+		if now.Sub(time.Unix(r.spent.Time, 0)) >= reservationTimeout {
+			delete(e.reserved, key)
+		}
+	}
+}
+
+// reserve sets aside a value approved by a rule until its transaction is signed.
+// A previous reservation of the same transaction is replaced.
+func (e *Engine) reserve(rule *TxRule, req *core.SignTxRequest, now time.Time) {
+	key := reservationKey{from: req.Transaction.From.Address(), nonce: uint64(req.Transaction.Nonce)}
+	e.reserved[key] = &reservation{
+		rule:  rule,
+		spent: spending{Time: now.Unix(), Value: (*hexutil.Big)(new(big.Int).Set(req.Transaction.Value.ToInt()))},
+	}
+}
+
+// ConfirmTx reports that the transaction with the given sender and nonce was
+// signed, recording the value reserved for it in the storage. Transactions not
+// approved by a rule with a daily limit are ignored.
+func (e *Engine) ConfirmTx(from common.Address, nonce uint64) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	key := reservationKey{from: from, nonce: nonce}
+	r := e.reserved[key]
+	if r == nil {
+		return
+	}
+	delete(e.reserved, key)
+	e.spend(r.rule, r.spent, time.Unix(r.spent.Time, 0))
+}
+
+// spend records a value approved by a rule.
+func (e *Engine) spend(rule *TxRule, s spending, now time.Time) {
+	all := append(e.spendings(rule, now), s)
+	blob, err := json.Marshal(all)
+	if err != nil {
+		log.Error("Failed to encode policy state", "rule", rule.Name, "err", err)
+		return
+	}
+	// The storage doesn't report write failures, check the outcome instead
+	key := storageKey(rule)
+	e.storage.Put(key, string(blob))
+	if stored, err := e.storage.Get(key); err != nil || stored != string(blob) {
+		log.Error("Failed to store policy state, daily limit may be lost on restart", "rule", rule.Name, "err", err)
+	}
+}
+
+func containsAddress(list []common.Address, addr common.Address) bool {
+	for _, a := range list {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func containsDay(list []Weekday, day time.Weekday) bool {
+	for _, d := range list {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// policyUI provides an implementation of UIClientAPI that evaluates requests
+// against a policy, passing the ones not matched on to the next handler.
+type policyUI struct {
+	next   core.UIClientAPI // The next handler, for requests not matched by the policy
+	engine *Engine
+}
+
+// NewPolicyEvaluator creates a UI evaluating requests with the engine before
+// passing them on to next.
+func NewPolicyEvaluator(next core.UIClientAPI, engine *Engine) *policyUI {
+	return &policyUI{next: next, engine: engine}
+}
+
+func (p *policyUI) RegisterUIServer(api *core.UIServerAPI) {
+	p.next.RegisterUIServer(api)
+}
+
+func (p *policyUI) ApproveTx(request *core.SignTxRequest) (core.SignTxResponse, error) {
+	decision := p.engine.EvaluateTx(request)
+	switch decision.Action {
+	case Approve:
+		log.Info("Transaction approved by policy", "rule", decision.Rule)
+		return core.SignTxResponse{Transaction: request.Transaction, Approved: true}, nil
+	case Reject:
+		log.Info("Transaction rejected by policy", "rule", decision.Rule)
+		return core.SignTxResponse{Approved: false}, nil
+	}
+	log.Debug("Transaction not matched by policy, going to manual", "reasons", decision.Reasons)
+	return p.next.ApproveTx(request)
+}
+
+func (p *policyUI) ApproveSignData(request *core.SignDataRequest) (core.SignDataResponse, error) {
+	return p.next.ApproveSignData(request)
+}
+
+func (p *policyUI) ApproveListing(request *core.ListRequest) (core.ListResponse, error) {
+	switch p.engine.policy.Listing {
+	case Approve:
+		log.Info("Listing approved by policy")
+		return core.ListResponse{Accounts: request.Accounts}, nil
+	case Reject:
+		log.Info("Listing rejected by policy")
+		return core.ListResponse{}, nil
+	}
+	return p.next.ApproveListing(request)
+}
+
+func (p *policyUI) ApproveNewAccount(request *core.NewAccountRequest) (core.NewAccountResponse, error) {
+	// This cannot be handled by a policy, requires setting a password
+	return p.next.ApproveNewAccount(request)
+}
+
+func (p *policyUI) ShowError(message string) {
+	p.next.ShowError(message)
+}
+
+func (p *policyUI) ShowInfo(message string) {
+	p.next.ShowInfo(message)
+}
+
+func (p *policyUI) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	if tx.Tx != nil {
+		if from, err := types.Sender(types.LatestSignerForChainID(tx.Tx.ChainId()), tx.Tx); err == nil {
+			p.engine.ConfirmTx(from, tx.Tx.Nonce())
+		}
+	}
+	p.next.OnApprovedTx(tx)
+}
+
+func (p *policyUI) OnSignerStartup(info core.StartupInfo) {
+	p.next.OnSignerStartup(info)
+}
+
+func (p *policyUI) OnInputRequired(info core.UserInputRequest) (core.UserInputResponse, error) {
+	return p.next.OnInputRequired(info)
+}
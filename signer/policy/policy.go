@@ -0,0 +1,306 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package policy implements declarative policies for approving signing requests.
+//
+// A policy is a YAML (or JSON) document listing transaction rules. Requests are
+// checked against the rules in order, and the first rule matching a request
+// approves or rejects it. Requests matched by no rule are passed on to the next
+// handler, i.e. the JavaScript rules or the user.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"gopkg.in/yaml.v3"
+)
+
+// Action is the outcome of a matching rule.
+type Action string
+
+const (
+	Approve Action = "approve"
+	Reject  Action = "reject"
+)
+
+// Policy is a declarative signing policy.
+type Policy struct {
+	Listing      Action    `yaml:"listing"`      // Outcome of account listing requests, if any
+	Transactions []*TxRule `yaml:"transactions"` // Rules for transaction signing requests
+}
+
+// TxRule matches transaction signing requests. All conditions given in a rule
+// must hold for the rule to match, omitted conditions always hold.
+type TxRule struct {
+	Name   string `yaml:"name"`   // Unique name of the rule, keying its state
+	Action Action `yaml:"action"` // Outcome of matching requests, approve if omitted
+
+	From   []common.Address `yaml:"from"`   // Allowed senders
+	To     []common.Address `yaml:"to"`     // Allowed recipients
+	Create bool             `yaml:"create"` // Whether contract creations match
+
+	// Methods lists the allowed method signatures or 4 byte selectors. Only
+	// plain transfers without call data match rules without methods.
+	Methods []string `yaml:"methods"`
+
+	// AllowWarnings makes the rule match requests the validator warned about,
+	// e.g. because the call data didn't match the method signature in the
+	// 4byte database.
+	AllowWarnings bool `yaml:"allowWarnings"`
+
+	MaxValue        *Amount `yaml:"maxValue"`        // Maximum value of a transaction
+	DailyValue      *Amount `yaml:"dailyValue"`      // Maximum value of matching transactions in 24 hours
+	MaxGas          uint64  `yaml:"maxGas"`          // Maximum gas limit of a transaction
+	MaxGasPrice     *Amount `yaml:"maxGasPrice"`     // Maximum gasPrice or maxFeePerGas of a transaction
+	MaxBlobGasPrice *Amount `yaml:"maxBlobGasPrice"` // Maximum maxFeePerBlobGas of a blob transaction
+
+	Hours *Hours    `yaml:"hours"` // Time of day requests are matched, in UTC
+	Days  []Weekday `yaml:"days"`  // Days of the week requests are matched, in UTC
+
+	selectors map[[4]byte]string // Parsed method selectors
+}
+
+// Load reads a policy from a file.
+func Load(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates a policy. Unknown fields are rejected, to avoid
+// misspelled conditions silently widening a rule.
+func Parse(data []byte) (*Policy, error) {
+	var policy Policy
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&policy); err != nil {
+		return nil, err
+	}
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// validate checks the policy for errors, and fills the defaults of rules.
+func (p *Policy) validate() error {
+	switch p.Listing {
+	case "", Approve, Reject:
+	default:
+		return fmt.Errorf("invalid listing action %q", p.Listing)
+	}
+	names := make(map[string]bool)
+	for i, rule := range p.Transactions {
+		if rule == nil {
+			return fmt.Errorf("transaction rule %d is empty", i)
+		}
+		if rule.Name == "" {
+			return fmt.Errorf("transaction rule %d has no name", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate transaction rule %q", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("transaction rule %q: %v", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+// validate checks a rule for errors, and fills its defaults.
+func (r *TxRule) validate() error {
+	switch r.Action {
+	case "":
+		r.Action = Approve
+	case Approve, Reject:
+	default:
+		return fmt.Errorf("invalid action %q", r.Action)
+	}
+	if r.Create && len(r.To) > 0 {
+		return errors.New("contract creations can't match a recipient list")
+	}
+	r.selectors = make(map[[4]byte]string)
+	for _, method := range r.Methods {
+		selector, err := parseMethod(method)
+		if err != nil {
+			return err
+		}
+		r.selectors[selector] = method
+	}
+	return nil
+}
+
+// parseMethod parses a method signature or 4 byte selector.
+func parseMethod(method string) ([4]byte, error) {
+	var selector [4]byte
+	if strings.HasPrefix(method, "0x") {
+		blob, err := hexutil.Decode(method)
+		if err != nil || len(blob) != 4 {
+			return selector, fmt.Errorf("invalid method selector %q", method)
+		}
+		copy(selector[:], blob)
+		return selector, nil
+	}
+	open := strings.IndexByte(method, '(')
+	if open <= 0 || !strings.HasSuffix(method, ")") || strings.ContainsAny(method, " \t") {
+		return selector, fmt.Errorf("invalid method signature %q, want e.g. \"transfer(address,uint256)\"", method)
+	}
+	copy(selector[:], crypto.Keccak256([]byte(method)))
+	return selector, nil
+}
+
+// Amount is an amount of wei, given either as an integer or with one of the
+// units wei, gwei and ether, e.g. "1.5 ether".
+type Amount big.Int
+
+// units are the denominations amounts can be given in.
+var units = map[string]*big.Int{
+	"wei":   big.NewInt(params.Wei),
+	"gwei":  big.NewInt(params.GWei),
+	"ether": big.NewInt(params.Ether),
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (a *Amount) UnmarshalText(input []byte) error {
+	text := strings.TrimSpace(string(input))
+	if strings.HasPrefix(text, "0x") {
+		v, err := hexutil.DecodeBig(text)
+		if err != nil {
+			return fmt.Errorf("invalid amount %q: %v", text, err)
+		}
+		*a = Amount(*v)
+		return nil
+	}
+	number, unit := text, "wei"
+	if i := strings.IndexAny(text, " \t"); i >= 0 {
+		number, unit = text[:i], strings.ToLower(strings.TrimSpace(text[i:]))
+	}
+	multiplier, ok := units[unit]
+	if !ok {
+		return fmt.Errorf("invalid amount %q: unknown unit %q", text, unit)
+	}
+	v, ok := new(big.Rat).SetString(number)
+	if !ok || v.Sign() < 0 {
+		return fmt.Errorf("invalid amount %q", text)
+	}
+	v.Mul(v, new(big.Rat).SetInt(multiplier))
+	if !v.IsInt() {
+		return fmt.Errorf("invalid amount %q: fractional wei", text)
+	}
+	*a = Amount(*v.Num())
+	return nil
+}
+
+// ToInt returns the amount in wei.
+func (a *Amount) ToInt() *big.Int {
+	return (*big.Int)(a)
+}
+
+// String implements fmt.Stringer.
+func (a *Amount) String() string {
+	return a.ToInt().String()
+}
+
+// Hours is a range of the time of day, e.g. "09:00-17:30". Ranges ending
+// before their start wrap around midnight.
+type Hours struct {
+	Start time.Duration // Start of the range, since midnight
+	End   time.Duration // End of the range, since midnight, exclusive
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (h *Hours) UnmarshalText(input []byte) error {
+	start, end, ok := strings.Cut(strings.TrimSpace(string(input)), "-")
+	if !ok {
+		return fmt.Errorf("invalid hours %q, want e.g. \"09:00-17:00\"", input)
+	}
+	var err error
+	if h.Start, err = parseClock(start); err != nil {
+		return err
+	}
+	if h.End, err = parseClock(end); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseClock parses a time of day, returning the duration since midnight.
+func parseClock(clock string) (time.Duration, error) {
+	clock = strings.TrimSpace(clock)
+	if clock == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", clock)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// contains returns whether the range contains the time of day of t.
+func (h *Hours) contains(t time.Time) bool {
+	t = t.UTC()
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if h.Start <= h.End {
+		return clock >= h.Start && clock < h.End
+	}
+	return clock >= h.Start || clock < h.End
+}
+
+// String implements fmt.Stringer.
+func (h *Hours) String() string {
+	format := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return format(h.Start) + "-" + format(h.End)
+}
+
+// Weekday is a day of the week, given by its English name or its three letter
+// abbreviation.
+type Weekday time.Weekday
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Weekday) UnmarshalText(input []byte) error {
+	name := strings.ToLower(strings.TrimSpace(string(input)))
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			*d = Weekday(day)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid weekday %q", input)
+}
+
+// String implements fmt.Stringer.
+func (d Weekday) String() string {
+	return time.Weekday(d).String()
+}
@@ -0,0 +1,412 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package policy
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ethereum/go-ethereum/signer/storage"
+)
+
+const testPolicy = `
+listing: approve
+transactions:
+  - name: blocked
+    action: reject
+    to: ["0x000000000000000000000000000000000000dead"]
+
+  - name: batcher
+    from: ["0x0000000000000000000000000000000000001337"]
+    to: ["0x00000000000000000000000000000000000b10b5"]
+    methods: ["submit(bytes)", "0xa9059cbb"]
+    maxValue: 1 ether
+    dailyValue: 2.5 ether
+    maxGasPrice: 100 gwei
+    maxBlobGasPrice: 10 gwei
+
+  - name: office
+    to: ["0x0000000000000000000000000000000000000042"]
+    maxValue: 0.1 ether
+    hours: "09:00-17:00"
+    days: [mon, tue, wednesday, thu, fri]
+`
+
+var (
+	sender     = common.HexToAddress("0x1337")
+	batchInbox = common.HexToAddress("0xb10b5")
+	office     = common.HexToAddress("0x42")
+	blackhole  = common.HexToAddress("0xdead")
+
+	// Tuesday morning
+	testTime = time.Date(2023, 10, 17, 10, 0, 0, 0, time.UTC)
+)
+
+func testEngine(t *testing.T) *Engine {
+	t.Helper()
+	policy, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := NewEngine(policy, storage.NewEphemeralStorage())
+	engine.now = func() time.Time { return testTime }
+	return engine
+}
+
+func ether(v float64) hexutil.Big {
+	wei, _ := new(big.Float).Mul(big.NewFloat(v), big.NewFloat(1e18)).Int(nil)
+	return hexutil.Big(*wei)
+}
+
+func txRequest(from, to common.Address, value hexutil.Big, data string) *core.SignTxRequest {
+	mixedTo := common.NewMixedcaseAddress(to)
+	req := &core.SignTxRequest{
+		Transaction: apitypes.SendTxArgs{
+			From:     common.NewMixedcaseAddress(from),
+			To:       &mixedTo,
+			Gas:      50000,
+			GasPrice: (*hexutil.Big)(big.NewInt(50e9)),
+			Value:    value,
+		},
+	}
+	if data != "" {
+		input := hexutil.Bytes(common.FromHex(data))
+		req.Transaction.Input = &input
+	}
+	return req
+}
+
+func TestParse(t *testing.T) {
+	engine := testEngine(t)
+	rule := engine.policy.Transactions[1]
+
+	if rule.Action != Approve {
+		t.Errorf("default action mismatch: have %q, want %q", rule.Action, Approve)
+	}
+	if want := new(big.Int).Mul(big.NewInt(25), big.NewInt(1e17)); rule.DailyValue.ToInt().Cmp(want) != 0 {
+		t.Errorf("daily value mismatch: have %v, want %v", rule.DailyValue, want)
+	}
+	if want := big.NewInt(100e9); rule.MaxGasPrice.ToInt().Cmp(want) != 0 {
+		t.Errorf("gas price mismatch: have %v, want %v", rule.MaxGasPrice, want)
+	}
+	if len(rule.selectors) != 2 {
+		t.Errorf("selector count mismatch: have %d, want 2", len(rule.selectors))
+	}
+	hours := engine.policy.Transactions[2].Hours
+	if hours.Start != 9*time.Hour || hours.End != 17*time.Hour {
+		t.Errorf("hours mismatch: have %v", hours)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		policy string
+		err    string
+	}{
+		{`listing: maybe`, `invalid listing action`},
+		{`transactions: [{maxGas: 21000}]`, `has no name`},
+		{`transactions: [{name: a}, {name: a}]`, `duplicate transaction rule`},
+		{`transactions: [{name: a, action: allow}]`, `invalid action`},
+		{`transactions: [{name: a, maxvalue: 1 ether}]`, `field maxvalue not found`},
+		{`transactions: [{name: a, maxValue: 1 finney}]`, `unknown unit`},
+		{`transactions: [{name: a, maxValue: 0.5 wei}]`, `fractional wei`},
+		{`transactions: [{name: a, methods: [transfer]}]`, `invalid method signature`},
+		{`transactions: [{name: a, methods: ["0xa9059c"]}]`, `invalid method selector`},
+		{`transactions: [{name: a, hours: "9-17"}]`, `invalid time of day`},
+		{`transactions: [{name: a, days: [caturday]}]`, `invalid weekday`},
+		{`transactions: [{name: a, create: true, to: ["0x0000000000000000000000000000000000000001"]}]`, `contract creations`},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.policy))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("policy %q: error mismatch: have %v, want %q", tt.policy, err, tt.err)
+		}
+	}
+	// Policies can be written in JSON too
+	if _, err := Parse([]byte(`{"transactions": [{"name": "a", "maxValue": "0x10"}]}`)); err != nil {
+		t.Errorf("failed to parse JSON policy: %v", err)
+	}
+}
+
+func TestEvaluateTx(t *testing.T) {
+	blobTx := txRequest(sender, batchInbox, ether(0), "")
+	blobTx.Transaction.GasPrice = nil
+	blobTx.Transaction.MaxFeePerGas = (*hexutil.Big)(big.NewInt(50e9))
+	blobTx.Transaction.BlobFeeCap = (*hexutil.Big)(big.NewInt(1e9))
+	blobTx.Transaction.BlobHashes = []common.Hash{{0x01}}
+
+	expensiveBlobTx := *blobTx
+	expensiveBlobTx.Transaction.BlobFeeCap = (*hexutil.Big)(big.NewInt(20e9))
+
+	warned := txRequest(sender, batchInbox, ether(0), "0xa9059cbb")
+	warned.Callinfo = []apitypes.ValidationInfo{{Typ: apitypes.WARN, Message: "Transaction data is not valid ABI"}}
+
+	expensive := txRequest(sender, batchInbox, ether(0), "")
+	expensive.Transaction.GasPrice = (*hexutil.Big)(big.NewInt(200e9))
+
+	creation := txRequest(sender, batchInbox, ether(0), "0x6000")
+	creation.Transaction.To = nil
+
+	tests := []struct {
+		name   string
+		req    *core.SignTxRequest
+		action Action
+		rule   string
+		reason string
+	}{
+		{"blocked recipient", txRequest(sender, blackhole, ether(0), ""), Reject, "blocked", ""},
+		{"plain transfer", txRequest(sender, batchInbox, ether(0.5), ""), Approve, "batcher", ""},
+		{"allowed signature", txRequest(sender, batchInbox, ether(0), hexutil.Encode(crypto.Keccak256([]byte("submit(bytes)"))[:4])), Approve, "batcher", ""},
+		{"allowed selector", txRequest(sender, batchInbox, ether(0), "0xa9059cbb"), Approve, "batcher", ""},
+		{"disallowed method", txRequest(sender, batchInbox, ether(0), "0x095ea7b3"), "", "", "batcher: method 0x095ea7b3 not allowed"},
+		{"call data without methods", txRequest(sender, office, ether(0), "0xa9059cbb"), "", "", "office: call data not allowed"},
+		{"validator warning", warned, "", "", "batcher: validation WARNING"},
+		{"wrong sender", txRequest(common.HexToAddress("0x01"), batchInbox, ether(0), ""), "", "", "sender"},
+		{"value above limit", txRequest(sender, batchInbox, ether(1.5), ""), "", "", "batcher: value"},
+		{"gas price above limit", expensive, "", "", "batcher: gas price"},
+		{"blob transaction", blobTx, Approve, "batcher", ""},
+		{"blob gas price above limit", &expensiveBlobTx, "", "", "batcher: blob gas price"},
+		{"blob transaction without cap", func() *core.SignTxRequest {
+			req := *blobTx
+			req.Transaction.To = func() *common.MixedcaseAddress { a := common.NewMixedcaseAddress(office); return &a }()
+			return &req
+		}(), "", "", "office: blob transactions not allowed"},
+		{"contract creation", creation, "", "", "contract creation not allowed"},
+		{"office hours", txRequest(sender, office, ether(0.1), ""), Approve, "office", ""},
+	}
+	for _, tt := range tests {
+		engine := testEngine(t)
+		decision := engine.EvaluateTx(tt.req)
+		if decision.Action != tt.action || decision.Rule != tt.rule {
+			t.Errorf("%s: decision mismatch: have %q by %q, want %q by %q (reasons %v)", tt.name, decision.Action, decision.Rule, tt.action, tt.rule, decision.Reasons)
+		}
+		if tt.reason != "" && !strings.Contains(strings.Join(decision.Reasons, "\n"), tt.reason) {
+			t.Errorf("%s: reason %q missing from %v", tt.name, tt.reason, decision.Reasons)
+		}
+	}
+}
+
+func TestTimeWindow(t *testing.T) {
+	engine := testEngine(t)
+	req := txRequest(sender, office, ether(0.1), "")
+
+	tests := []struct {
+		time     time.Time
+		approved bool
+	}{
+		{time.Date(2023, 10, 17, 9, 0, 0, 0, time.UTC), true},
+		{time.Date(2023, 10, 17, 16, 59, 59, 0, time.UTC), true},
+		{time.Date(2023, 10, 17, 17, 0, 0, 0, time.UTC), false},
+		{time.Date(2023, 10, 17, 8, 59, 0, 0, time.UTC), false},
+		{time.Date(2023, 10, 21, 12, 0, 0, 0, time.UTC), false},                      // Saturday
+		{time.Date(2023, 10, 20, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600)), true}, // Friday 10:00 UTC
+	}
+	for _, tt := range tests {
+		engine.now = func() time.Time { return tt.time }
+		if approved := engine.EvaluateTx(req).Action == Approve; approved != tt.approved {
+			t.Errorf("%v: approval mismatch: have %v, want %v", tt.time, approved, tt.approved)
+		}
+	}
+	// Ranges can wrap around midnight
+	night := &Hours{Start: 22 * time.Hour, End: 6 * time.Hour}
+	for hour, want := range map[int]bool{23: true, 2: true, 6: false, 12: false, 22: true} {
+		if have := night.contains(time.Date(2023, 10, 17, hour, 0, 0, 0, time.UTC)); have != want {
+			t.Errorf("night hours at %d:00: have %v, want %v", hour, have, want)
+		}
+	}
+}
+
+func TestDailyValue(t *testing.T) {
+	var (
+		db      = storage.NewEphemeralStorage()
+		now     = testTime
+		policy  = testEngine(t).policy
+		engine  = NewEngine(policy, db)
+		nonce   uint64
+		approve = func(value float64) bool {
+			req := txRequest(sender, batchInbox, ether(value), "")
+			req.Transaction.Nonce = hexutil.Uint64(nonce)
+			nonce++
+			return engine.EvaluateTx(req).Action == Approve
+		}
+	)
+	engine.now = func() time.Time { return now }
+
+	if !approve(1) || !approve(1) {
+		t.Fatal("transactions within daily limit not approved")
+	}
+	if approve(1) {
+		t.Fatal("transaction exceeding daily limit approved")
+	}
+	if !approve(0.5) {
+		t.Fatal("transaction reaching daily limit not approved")
+	}
+	for n := uint64(0); n < nonce; n++ {
+		engine.ConfirmTx(sender, n)
+	}
+	// The limit is kept in the storage
+	reloaded := NewEngine(policy, db)
+	reloaded.now = engine.now
+	if reloaded.EvaluateTx(txRequest(sender, batchInbox, ether(0.1), "")).Action == Approve {
+		t.Fatal("daily limit lost on reload")
+	}
+	// Values drop out of the limit after 24 hours
+	now = now.Add(dailyWindow)
+	if !approve(1) {
+		t.Fatal("transaction not approved after the window passed")
+	}
+	// Corrupt state exhausts the limit
+	db.Put(storageKey(policy.Transactions[1]), "garbage")
+	if approve(0.1) {
+		t.Fatal("transaction approved with corrupt state")
+	}
+}
+
+// Tests that values approved by rules with daily limits are only recorded once
+// their transaction is signed, and released if it isn't.
+func TestDailyValueReservations(t *testing.T) {
+	var (
+		db      = storage.NewEphemeralStorage()
+		now     = testTime
+		engine  = NewEngine(testEngine(t).policy, db)
+		rule    = engine.policy.Transactions[1]
+		approve = func(value float64, nonce uint64) bool {
+			req := txRequest(sender, batchInbox, ether(value), "")
+			req.Transaction.Nonce = hexutil.Uint64(nonce)
+			return engine.EvaluateTx(req).Action == Approve
+		}
+	)
+	engine.now = func() time.Time { return now }
+
+	// Reserved values count against the limit, without being recorded
+	if !approve(1, 0) || !approve(1, 1) {
+		t.Fatal("transactions within daily limit not approved")
+	}
+	if approve(1, 2) {
+		t.Fatal("transaction exceeding daily limit approved")
+	}
+	if _, err := db.Get(storageKey(rule)); err == nil {
+		t.Fatal("unsigned transactions recorded")
+	}
+	// Signed transactions are recorded, the others released after a while
+	engine.ConfirmTx(sender, 1)
+	engine.ConfirmTx(sender, 2)
+
+	now = now.Add(reservationTimeout)
+	if !approve(1, 2) {
+		t.Fatal("transaction not approved after reservation expired")
+	}
+	engine.ConfirmTx(sender, 0)
+	if spent, want := engine.spent(rule, now), ether(2); spent.Cmp(want.ToInt()) != 0 {
+		t.Fatalf("spent value mismatch: have %v, want %v", spent, want.ToInt())
+	}
+	if recorded := engine.spendings(rule, now); len(recorded) != 1 {
+		t.Fatalf("recorded values mismatch: have %d, want 1", len(recorded))
+	}
+}
+
+// recordingUI records the requests passed on by the policy.
+type recordingUI struct {
+	core.UIClientAPI
+	calls []string
+}
+
+func (ui *recordingUI) ApproveTx(request *core.SignTxRequest) (core.SignTxResponse, error) {
+	ui.calls = append(ui.calls, "ApproveTx")
+	return core.SignTxResponse{}, core.ErrRequestDenied
+}
+
+func (ui *recordingUI) ApproveListing(request *core.ListRequest) (core.ListResponse, error) {
+	ui.calls = append(ui.calls, "ApproveListing")
+	return core.ListResponse{}, core.ErrRequestDenied
+}
+
+func (ui *recordingUI) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	ui.calls = append(ui.calls, "OnApprovedTx")
+}
+
+func TestPolicyUI(t *testing.T) {
+	next := new(recordingUI)
+	ui := NewPolicyEvaluator(next, testEngine(t))
+
+	resp, err := ui.ApproveTx(txRequest(sender, batchInbox, ether(0.5), ""))
+	if err != nil || !resp.Approved {
+		t.Fatalf("transaction not approved: %v", err)
+	}
+	if resp, err = ui.ApproveTx(txRequest(sender, blackhole, ether(0), "")); err != nil || resp.Approved {
+		t.Fatalf("transaction not rejected: %v", err)
+	}
+	if _, err = ui.ApproveTx(txRequest(sender, common.HexToAddress("0x01"), ether(0), "")); err != core.ErrRequestDenied {
+		t.Fatalf("unmatched transaction not passed on: %v", err)
+	}
+	accs := []accounts.Account{{Address: sender}}
+	if resp, err := ui.ApproveListing(&core.ListRequest{Accounts: accs}); err != nil || len(resp.Accounts) != 1 {
+		t.Fatalf("listing not approved: %v", err)
+	}
+	ui.OnApprovedTx(ethapi.SignTransactionResult{})
+
+	if have, want := strings.Join(next.calls, ","), "ApproveTx,OnApprovedTx"; have != want {
+		t.Fatalf("passed on calls mismatch: have %s, want %s", have, want)
+	}
+}
+
+// Tests that values approved by the policy are recorded when the UI is notified
+// about the signed transaction.
+func TestPolicyUIConfirm(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	policy, err := Parse([]byte(`
+transactions:
+  - name: limited
+    from: ["` + crypto.PubkeyToAddress(key.PublicKey).Hex() + `"]
+    dailyValue: 1 ether
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		engine = NewEngine(policy, storage.NewEphemeralStorage())
+		ui     = NewPolicyEvaluator(new(recordingUI), engine)
+		req    = txRequest(crypto.PubkeyToAddress(key.PublicKey), batchInbox, ether(1), "")
+	)
+	req.Transaction.Nonce = 5
+	if resp, err := ui.ApproveTx(req); err != nil || !resp.Approved {
+		t.Fatalf("transaction not approved: %v", err)
+	}
+	tx, err := req.Transaction.ToTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(big.NewInt(1)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui.OnApprovedTx(ethapi.SignTransactionResult{Tx: signed})
+
+	if recorded := engine.spendings(policy.Transactions[0], engine.now()); len(recorded) != 1 {
+		t.Fatalf("recorded values mismatch: have %d, want 1", len(recorded))
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package quorum
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	errUnknownRequest = errors.New("unknown or decided request")
+	errAlreadyVoted   = errors.New("approver already voted")
+	errNotApprover    = errors.New("signer is not an approver")
+)
+
+// PendingRequest is a signing request awaiting approvals, as listed by the
+// approval API.
+type PendingRequest struct {
+	ID         string           `json:"id"`
+	Kind       string           `json:"kind"`
+	Digest     common.Hash      `json:"digest"`
+	Request    interface{}      `json:"request"`
+	Approvals  []common.Address `json:"approvals"`
+	Rejections []common.Address `json:"rejections"`
+	Threshold  int              `json:"threshold"`
+	Expires    time.Time        `json:"expires"`
+}
+
+// VoteMessage returns the message an approver signs to vote on a request. The
+// message is signed like with personal_sign, so approvers can use any wallet.
+func VoteMessage(id string, digest common.Hash, approve bool) []byte {
+	verb := "Reject"
+	if approve {
+		verb = "Approve"
+	}
+	return []byte(fmt.Sprintf("%s signing request %s with digest %v", verb, id, digest))
+}
+
+// ApprovalAPI is the API approvers use to vote on pending requests. It is
+// served separately from the external API, under the "approval" namespace.
+type ApprovalAPI struct {
+	quorum *QuorumUI
+}
+
+// NewApprovalAPI creates the approval API of a quorum.
+func NewApprovalAPI(quorum *QuorumUI) *ApprovalAPI {
+	return &ApprovalAPI{quorum}
+}
+
+// Pending lists the requests awaiting approvals.
+func (api *ApprovalAPI) Pending() []PendingRequest {
+	q := api.quorum
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	list := make([]PendingRequest, 0, len(q.pending))
+	for _, r := range q.pending {
+		if r.status != Pending {
+			continue
+		}
+		list = append(list, PendingRequest{
+			ID:         r.id,
+			Kind:       r.kind,
+			Digest:     r.digest,
+			Request:    r.content,
+			Approvals:  addresses(r.approvals),
+			Rejections: addresses(r.rejections),
+			Threshold:  q.config.Threshold,
+			Expires:    r.expires,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Expires.Before(list[j].Expires)
+	})
+	return list
+}
+
+// Approve votes for a pending request. The signature is made over
+// VoteMessage(id, digest, true) by one of the approvers. It returns the status
+// of the request after the vote.
+func (api *ApprovalAPI) Approve(id string, signature hexutil.Bytes) (Status, error) {
+	return api.vote(id, signature, true)
+}
+
+// Reject votes against a pending request. The signature is made over
+// VoteMessage(id, digest, false) by one of the approvers. It returns the status
+// of the request after the vote.
+func (api *ApprovalAPI) Reject(id string, signature hexutil.Bytes) (Status, error) {
+	return api.vote(id, signature, false)
+}
+
+// vote authenticates the approver of a vote, and records it.
+func (api *ApprovalAPI) vote(id string, signature hexutil.Bytes, approve bool) (Status, error) {
+	q := api.quorum
+
+	q.lock.Lock()
+	r, ok := q.pending[id]
+	q.lock.Unlock()
+	if !ok {
+		return "", errUnknownRequest
+	}
+	approver, err := recoverSigner(VoteMessage(id, r.digest, approve), signature)
+	if err != nil {
+		return "", err
+	}
+	if !q.isApprover(approver) {
+		q.audit.Warn("ApprovalVoteDenied", "id", id, "signer", approver, "approve", approve)
+		return "", errNotApprover
+	}
+	return q.vote(id, approver, approve)
+}
+
+// recoverSigner returns the address which signed a message with personal_sign.
+func recoverSigner(msg []byte, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature must be %d bytes long", crypto.SignatureLength)
+	}
+	sig := common.CopyBytes(signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27 // Transform yellow paper V from 27/28 to 0/1
+	}
+	pubkey, err := crypto.SigToPub(accounts.TextHash(msg), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
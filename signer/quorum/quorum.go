@@ -0,0 +1,291 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package quorum implements threshold approval of signing requests.
+//
+// Instead of a single user approving each request, a request stays pending until
+// enough of a set of approvers confirm it over the approval API, each signing
+// the request digest with their own key. A request is rejected once enough
+// approvers reject it to make the threshold unreachable, or when it times out.
+package quorum
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Status is the state of a pending request.
+type Status string
+
+const (
+	Pending  Status = "pending"
+	Approved Status = "approved"
+	Rejected Status = "rejected"
+	Expired  Status = "expired"
+)
+
+// Config is the configuration of the approval quorum.
+type Config struct {
+	Approvers []common.Address // Addresses of the keys allowed to approve requests
+	Threshold int              // Number of approvals required for a request
+	Timeout   time.Duration    // Time after which pending requests are rejected
+	ChainID   *big.Int         // Chain id transactions are signed for
+}
+
+// validate checks the configuration for errors.
+func (c *Config) validate() error {
+	if len(c.Approvers) == 0 {
+		return errors.New("no approvers")
+	}
+	seen := make(map[common.Address]bool)
+	for _, addr := range c.Approvers {
+		if seen[addr] {
+			return fmt.Errorf("duplicate approver %v", addr)
+		}
+		seen[addr] = true
+	}
+	if c.Threshold < 1 || c.Threshold > len(c.Approvers) {
+		return fmt.Errorf("invalid threshold %d of %d approvers", c.Threshold, len(c.Approvers))
+	}
+	if c.Timeout <= 0 {
+		return errors.New("no approval timeout")
+	}
+	if c.ChainID == nil {
+		return errors.New("no chain id")
+	}
+	return nil
+}
+
+// request is a signing request awaiting approvals.
+type request struct {
+	id      string
+	kind    string      // Type of the request, "transaction" or "data"
+	digest  common.Hash // Hash to be signed, which approvers sign off
+	content interface{} // Request shown to the approvers
+	expires time.Time
+
+	approvals  map[common.Address]bool
+	rejections map[common.Address]bool
+	status     Status
+	done       chan struct{} // Closed when the request is decided
+}
+
+// QuorumUI provides an implementation of UIClientAPI that requires signing
+// requests to be approved by a quorum of approvers. Other requests are passed
+// on to the next handler.
+type QuorumUI struct {
+	next   core.UIClientAPI
+	config Config
+	audit  log.Logger // Logger recording the votes and decisions
+
+	pending map[string]*request
+	lock    sync.Mutex
+}
+
+// New creates a UI requiring threshold approvals of signing requests, passing
+// other requests on to next.
+func New(next core.UIClientAPI, config Config, audit log.Logger) (*QuorumUI, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &QuorumUI{
+		next:    next,
+		config:  config,
+		audit:   audit,
+		pending: make(map[string]*request),
+	}, nil
+}
+
+// TxDigest returns the hash approvers sign off for a transaction request. It
+// is the hash the sender signs, i.e. the transaction hash sans signature.
+func TxDigest(args *apitypes.SendTxArgs, chainID *big.Int) (common.Hash, error) {
+	tx, err := args.ToTransaction()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return types.LatestSignerForChainID(chainID).Hash(tx), nil
+}
+
+func (q *QuorumUI) ApproveTx(req *core.SignTxRequest) (core.SignTxResponse, error) {
+	digest, err := TxDigest(&req.Transaction, q.config.ChainID)
+	if err != nil {
+		return core.SignTxResponse{Approved: false}, err
+	}
+	if q.await("transaction", digest, req) != Approved {
+		return core.SignTxResponse{Approved: false}, nil
+	}
+	// Return the request unmodified, it's what the approvers signed off
+	return core.SignTxResponse{Transaction: req.Transaction, Approved: true}, nil
+}
+
+func (q *QuorumUI) ApproveSignData(req *core.SignDataRequest) (core.SignDataResponse, error) {
+	if len(req.Hash) != common.HashLength {
+		return core.SignDataResponse{Approved: false}, fmt.Errorf("invalid data hash length %d", len(req.Hash))
+	}
+	return core.SignDataResponse{Approved: q.await("data", common.BytesToHash(req.Hash), req) == Approved}, nil
+}
+
+// await registers a request as pending and waits until it is decided.
+func (q *QuorumUI) await(kind string, digest common.Hash, content interface{}) Status {
+	r := &request{
+		id:         newID(),
+		kind:       kind,
+		digest:     digest,
+		content:    content,
+		expires:    time.Now().Add(q.config.Timeout),
+		approvals:  make(map[common.Address]bool),
+		rejections: make(map[common.Address]bool),
+		status:     Pending,
+		done:       make(chan struct{}),
+	}
+	q.lock.Lock()
+	q.pending[r.id] = r
+	q.lock.Unlock()
+
+	q.audit.Info("AwaitApproval", "id", r.id, "kind", kind, "digest", digest,
+		"threshold", q.config.Threshold, "approvers", len(q.config.Approvers), "expires", r.expires)
+	q.next.ShowInfo(fmt.Sprintf("Request %s (%s %v) awaiting %d of %d approvals",
+		r.id, kind, digest, q.config.Threshold, len(q.config.Approvers)))
+
+	timeout := time.NewTimer(q.config.Timeout)
+	defer timeout.Stop()
+
+	select {
+	case <-r.done:
+	case <-timeout.C:
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if r.status == Pending {
+		r.status = Expired
+	}
+	delete(q.pending, r.id)
+
+	q.audit.Info("ApprovalDecided", "id", r.id, "kind", kind, "digest", digest, "status", r.status,
+		"approvals", addresses(r.approvals), "rejections", addresses(r.rejections))
+	return r.status
+}
+
+// vote records the vote of an approver on a pending request, and decides the
+// request if the vote reaches the threshold or makes it unreachable.
+func (q *QuorumUI) vote(id string, approver common.Address, approve bool) (Status, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	r, ok := q.pending[id]
+	if !ok || r.status != Pending {
+		return "", errUnknownRequest
+	}
+	if r.approvals[approver] || r.rejections[approver] {
+		return r.status, errAlreadyVoted
+	}
+	if approve {
+		r.approvals[approver] = true
+	} else {
+		r.rejections[approver] = true
+	}
+	q.audit.Info("ApprovalVote", "id", id, "digest", r.digest, "approver", approver, "approve", approve)
+
+	switch {
+	case len(r.approvals) >= q.config.Threshold:
+		r.status = Approved
+	case len(r.rejections) > len(q.config.Approvers)-q.config.Threshold:
+		r.status = Rejected
+	}
+	if r.status != Pending {
+		close(r.done)
+	}
+	return r.status, nil
+}
+
+// isApprover returns whether an address belongs to the approvers.
+func (q *QuorumUI) isApprover(addr common.Address) bool {
+	for _, approver := range q.config.Approvers {
+		if approver == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *QuorumUI) RegisterUIServer(api *core.UIServerAPI) {
+	q.next.RegisterUIServer(api)
+}
+
+func (q *QuorumUI) ApproveListing(request *core.ListRequest) (core.ListResponse, error) {
+	return q.next.ApproveListing(request)
+}
+
+func (q *QuorumUI) ApproveNewAccount(request *core.NewAccountRequest) (core.NewAccountResponse, error) {
+	return q.next.ApproveNewAccount(request)
+}
+
+func (q *QuorumUI) ShowError(message string) {
+	q.next.ShowError(message)
+}
+
+func (q *QuorumUI) ShowInfo(message string) {
+	q.next.ShowInfo(message)
+}
+
+func (q *QuorumUI) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	q.audit.Info("ApprovedTxSigned", "hash", tx.Tx.Hash())
+	q.next.OnApprovedTx(tx)
+}
+
+func (q *QuorumUI) OnSignerStartup(info core.StartupInfo) {
+	q.next.OnSignerStartup(info)
+}
+
+func (q *QuorumUI) OnInputRequired(info core.UserInputRequest) (core.UserInputResponse, error) {
+	return q.next.OnInputRequired(info)
+}
+
+// newID returns a random request id, so approvals can't be replayed on
+// requests made later or to another signer.
+func newID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hexutil.Encode(id)
+}
+
+// addresses returns the addresses of a set in order.
+func addresses(set map[common.Address]bool) []common.Address {
+	list := make([]common.Address, 0, len(set))
+	for addr := range set {
+		list = append(list, addr)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Cmp(list[j]) < 0
+	})
+	return list
+}
@@ -0,0 +1,273 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package quorum
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// testUI is the UI behind the quorum, announcing requests awaiting approvals.
+type testUI struct {
+	infoCh chan string
+}
+
+func (ui *testUI) RegisterUIServer(api *core.UIServerAPI) {}
+func (ui *testUI) ApproveTx(request *core.SignTxRequest) (core.SignTxResponse, error) {
+	panic("transaction passed on by quorum")
+}
+func (ui *testUI) ApproveSignData(request *core.SignDataRequest) (core.SignDataResponse, error) {
+	panic("data passed on by quorum")
+}
+func (ui *testUI) ApproveListing(request *core.ListRequest) (core.ListResponse, error) {
+	return core.ListResponse{Accounts: request.Accounts}, nil
+}
+func (ui *testUI) ApproveNewAccount(request *core.NewAccountRequest) (core.NewAccountResponse, error) {
+	return core.NewAccountResponse{Approved: false}, nil
+}
+func (ui *testUI) ShowError(message string)                     {}
+func (ui *testUI) ShowInfo(message string)                      { ui.infoCh <- message }
+func (ui *testUI) OnApprovedTx(tx ethapi.SignTransactionResult) {}
+func (ui *testUI) OnSignerStartup(info core.StartupInfo)        {}
+func (ui *testUI) OnInputRequired(info core.UserInputRequest) (core.UserInputResponse, error) {
+	return core.UserInputResponse{}, nil
+}
+
+type testQuorum struct {
+	ui   *QuorumUI
+	api  *ApprovalAPI
+	keys []*ecdsa.PrivateKey
+	info chan string
+}
+
+func newTestQuorum(t *testing.T, approvers, threshold int, timeout time.Duration) *testQuorum {
+	t.Helper()
+
+	tq := &testQuorum{info: make(chan string, 1)}
+	var addrs []common.Address
+	for i := 0; i < approvers; i++ {
+		key, _ := crypto.GenerateKey()
+		tq.keys = append(tq.keys, key)
+		addrs = append(addrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	ui, err := New(&testUI{infoCh: tq.info}, Config{
+		Approvers: addrs,
+		Threshold: threshold,
+		Timeout:   timeout,
+		ChainID:   big.NewInt(1),
+	}, log.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tq.ui, tq.api = ui, NewApprovalAPI(ui)
+	return tq
+}
+
+// submit sends a transaction request to the quorum, returning the channel of
+// its response and the pending request.
+func (tq *testQuorum) submit(t *testing.T) (chan core.SignTxResponse, PendingRequest) {
+	t.Helper()
+
+	to := common.NewMixedcaseAddress(common.HexToAddress("0x1337"))
+	req := &core.SignTxRequest{
+		Transaction: apitypes.SendTxArgs{
+			From:     common.NewMixedcaseAddress(common.HexToAddress("0x42")),
+			To:       &to,
+			Gas:      21000,
+			GasPrice: (*hexutil.Big)(big.NewInt(1_000_000_000)),
+			Value:    hexutil.Big(*big.NewInt(1)),
+		},
+	}
+	resCh := make(chan core.SignTxResponse, 1)
+	go func() {
+		res, err := tq.ui.ApproveTx(req)
+		if err != nil {
+			t.Error(err)
+		}
+		resCh <- res
+	}()
+	<-tq.info
+
+	pending := tq.api.Pending()
+	if len(pending) != 1 {
+		t.Fatalf("pending requests: have %d, want 1", len(pending))
+	}
+	// Approvers should sign off what the sender signs
+	tx, _ := req.Transaction.ToTransaction()
+	if have, want := pending[0].Digest, types.LatestSignerForChainID(big.NewInt(1)).Hash(tx); have != want {
+		t.Fatalf("digest mismatch: have %v, want %v", have, want)
+	}
+	return resCh, pending[0]
+}
+
+func voteSig(t *testing.T, key *ecdsa.PrivateKey, req PendingRequest, approve bool) hexutil.Bytes {
+	t.Helper()
+	sig, err := crypto.Sign(accounts.TextHash(VoteMessage(req.ID, req.Digest, approve)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig
+}
+
+func TestQuorumApprove(t *testing.T) {
+	tq := newTestQuorum(t, 3, 2, time.Minute)
+	resCh, req := tq.submit(t)
+
+	status, err := tq.api.Approve(req.ID, voteSig(t, tq.keys[0], req, true))
+	if err != nil || status != Pending {
+		t.Fatalf("first approval: have %v %v, want %v", status, err, Pending)
+	}
+	if _, err := tq.api.Approve(req.ID, voteSig(t, tq.keys[0], req, true)); err != errAlreadyVoted {
+		t.Fatalf("repeated approval: have %v, want %v", err, errAlreadyVoted)
+	}
+	if _, err := tq.api.Reject(req.ID, voteSig(t, tq.keys[0], req, false)); err != errAlreadyVoted {
+		t.Fatalf("changed vote: have %v, want %v", err, errAlreadyVoted)
+	}
+	select {
+	case <-resCh:
+		t.Fatal("request decided before quorum")
+	case <-time.After(50 * time.Millisecond):
+	}
+	status, err = tq.api.Approve(req.ID, voteSig(t, tq.keys[2], req, true))
+	if err != nil || status != Approved {
+		t.Fatalf("second approval: have %v %v, want %v", status, err, Approved)
+	}
+	if res := <-resCh; !res.Approved {
+		t.Fatal("request not approved")
+	}
+	if pending := tq.api.Pending(); len(pending) != 0 {
+		t.Fatalf("decided request still pending: %v", pending)
+	}
+	if _, err := tq.api.Approve(req.ID, voteSig(t, tq.keys[1], req, true)); err != errUnknownRequest {
+		t.Fatalf("approval of decided request: have %v, want %v", err, errUnknownRequest)
+	}
+}
+
+func TestQuorumReject(t *testing.T) {
+	tq := newTestQuorum(t, 3, 2, time.Minute)
+	resCh, req := tq.submit(t)
+
+	// A single rejection leaves the threshold reachable
+	if status, err := tq.api.Reject(req.ID, voteSig(t, tq.keys[0], req, false)); err != nil || status != Pending {
+		t.Fatalf("first rejection: have %v %v, want %v", status, err, Pending)
+	}
+	if status, err := tq.api.Reject(req.ID, voteSig(t, tq.keys[1], req, false)); err != nil || status != Rejected {
+		t.Fatalf("second rejection: have %v %v, want %v", status, err, Rejected)
+	}
+	if res := <-resCh; res.Approved {
+		t.Fatal("rejected request approved")
+	}
+}
+
+func TestQuorumAuthentication(t *testing.T) {
+	tq := newTestQuorum(t, 2, 1, time.Minute)
+	resCh, req := tq.submit(t)
+
+	outsider, _ := crypto.GenerateKey()
+	if _, err := tq.api.Approve(req.ID, voteSig(t, outsider, req, true)); err != errNotApprover {
+		t.Fatalf("outsider approval: have %v, want %v", err, errNotApprover)
+	}
+	// A rejection must not count as approval
+	if _, err := tq.api.Approve(req.ID, voteSig(t, tq.keys[0], req, false)); err != errNotApprover {
+		t.Fatalf("approval with rejection signature: have %v, want %v", err, errNotApprover)
+	}
+	// Neither must an approval of another request
+	other := req
+	other.ID = newID()
+	if _, err := tq.api.Approve(req.ID, voteSig(t, tq.keys[0], other, true)); err != errNotApprover {
+		t.Fatalf("approval of another request: have %v, want %v", err, errNotApprover)
+	}
+	if _, err := tq.api.Approve(req.ID, hexutil.Bytes{1, 2, 3}); err == nil {
+		t.Fatal("malformed signature accepted")
+	}
+	if status, err := tq.api.Approve(req.ID, voteSig(t, tq.keys[1], req, true)); err != nil || status != Approved {
+		t.Fatalf("approval: have %v %v, want %v", status, err, Approved)
+	}
+	if res := <-resCh; !res.Approved {
+		t.Fatal("request not approved")
+	}
+}
+
+func TestQuorumTimeout(t *testing.T) {
+	tq := newTestQuorum(t, 2, 2, 100*time.Millisecond)
+	resCh, req := tq.submit(t)
+
+	if _, err := tq.api.Approve(req.ID, voteSig(t, tq.keys[0], req, true)); err != nil {
+		t.Fatal(err)
+	}
+	if res := <-resCh; res.Approved {
+		t.Fatal("expired request approved")
+	}
+	if _, err := tq.api.Approve(req.ID, voteSig(t, tq.keys[1], req, true)); err != errUnknownRequest {
+		t.Fatalf("approval of expired request: have %v, want %v", err, errUnknownRequest)
+	}
+}
+
+func TestQuorumSignData(t *testing.T) {
+	tq := newTestQuorum(t, 1, 1, time.Minute)
+
+	hash := crypto.Keccak256([]byte("hello"))
+	resCh := make(chan bool, 1)
+	go func() {
+		res, err := tq.ui.ApproveSignData(&core.SignDataRequest{Hash: hash})
+		if err != nil {
+			t.Error(err)
+		}
+		resCh <- res.Approved
+	}()
+	<-tq.info
+
+	req := tq.api.Pending()[0]
+	if req.Kind != "data" || req.Digest != common.BytesToHash(hash) {
+		t.Fatalf("unexpected request: %v %v", req.Kind, req.Digest)
+	}
+	if _, err := tq.api.Approve(req.ID, voteSig(t, tq.keys[0], req, true)); err != nil {
+		t.Fatal(err)
+	}
+	if !<-resCh {
+		t.Fatal("request not approved")
+	}
+}
+
+func TestQuorumConfig(t *testing.T) {
+	addrs := []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02")}
+	for i, config := range []Config{
+		{Approvers: nil, Threshold: 1, Timeout: time.Minute, ChainID: common.Big1},
+		{Approvers: addrs, Threshold: 0, Timeout: time.Minute, ChainID: common.Big1},
+		{Approvers: addrs, Threshold: 3, Timeout: time.Minute, ChainID: common.Big1},
+		{Approvers: []common.Address{addrs[0], addrs[0]}, Threshold: 1, Timeout: time.Minute, ChainID: common.Big1},
+		{Approvers: addrs, Threshold: 1, ChainID: common.Big1},
+		{Approvers: addrs, Threshold: 1, Timeout: time.Minute},
+	} {
+		if _, err := New(&testUI{}, config, log.New()); err == nil {
+			t.Errorf("test %d: invalid config accepted", i)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -194,6 +195,51 @@ func TestSignTxRequest(t *testing.T) {
 	}
 }
 
+// Tests that rules can inspect the blob fields of blob transactions.
+func TestSignBlobTxRequest(t *testing.T) {
+	js := `
+	function ApproveTx(r){
+		var tx = r.transaction;
+		if(!tx.blobVersionedHashes || tx.blobVersionedHashes.length > 2){ return "Reject" }
+		if(new BigNumber(tx.maxFeePerBlobGas.slice(2), 16).gt(new BigNumber("1e9"))){ return "Reject" }
+		return "Approve"
+	}`
+
+	r, err := initRuleEngine(js)
+	if err != nil {
+		t.Fatalf("Couldn't create evaluator %v", err)
+	}
+	from, _ := mixAddr("0000000000000000000000000000000000001337")
+	to, _ := mixAddr("000000000000000000000000000000000000dead")
+
+	tests := []struct {
+		blobFeeCap int64
+		blobs      int
+		approved   bool
+	}{
+		{blobFeeCap: 1000, blobs: 1, approved: true},
+		{blobFeeCap: 1000, blobs: 3, approved: false},
+		{blobFeeCap: 2000000000, blobs: 1, approved: false},
+	}
+	for i, tt := range tests {
+		resp, err := r.ApproveTx(&core.SignTxRequest{
+			Transaction: apitypes.SendTxArgs{
+				From:       *from,
+				To:         to,
+				BlobFeeCap: (*hexutil.Big)(big.NewInt(tt.blobFeeCap)),
+				BlobHashes: make([]common.Hash, tt.blobs),
+			},
+			Meta: core.Metadata{Remote: "remoteip", Local: "localip", Scheme: "inproc"},
+		})
+		if err != nil {
+			t.Errorf("test %d: unexpected error %v", i, err)
+		}
+		if resp.Approved != tt.approved {
+			t.Errorf("test %d: approval mismatch: have %v, want %v", i, resp.Approved, tt.approved)
+		}
+	}
+}
+
 type dummyUI struct {
 	calls []string
 }
@@ -616,3 +662,40 @@ function ApproveSignData(r){
 		t.Fatalf("Expected approved")
 	}
 }
+
+func TestSignTypedDataSummary(t *testing.T) {
+	js := `function ApproveSignData(r){
+    if (r.typed_data && r.typed_data.kind == "ERC-2612 permit") {
+        if (r.typed_data.unlimited) {
+            return "Reject"
+        }
+        if (r.typed_data.spender == "0x0000000000000000000000000000000000000042") {
+            return "Approve"
+        }
+    }
+    // Otherwise goes to manual processing
+}`
+	r, err := initRuleEngine(js)
+	if err != nil {
+		t.Fatalf("Couldn't create evaluator %v", err)
+	}
+	request := func(spender common.Address, amount *big.Int, unlimited bool) *core.SignDataRequest {
+		return &core.SignDataRequest{
+			ContentType: apitypes.DataTyped.Mime,
+			TypedData: &apitypes.TypedDataSummary{
+				Kind:      apitypes.KindERC2612Permit,
+				Spender:   &spender,
+				Items:     []apitypes.TypedDataItem{{Amount: (*hexutil.Big)(amount), Unlimited: unlimited}},
+				Unlimited: unlimited,
+			},
+		}
+	}
+	resp, err := r.ApproveSignData(request(common.HexToAddress("0x42"), big.NewInt(1000), false))
+	if err != nil || !resp.Approved {
+		t.Errorf("expected limited permit to be approved: %v", err)
+	}
+	resp, err = r.ApproveSignData(request(common.HexToAddress("0x42"), abi.MaxUint256, true))
+	if err != nil || resp.Approved {
+		t.Errorf("expected unlimited permit to be rejected: %v", err)
+	}
+}
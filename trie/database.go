@@ -262,6 +262,21 @@ func (db *Database) Recover(target common.Hash) error {
 	return pdb.Recover(target, &trieLoader{db: db})
 }
 
+// HistoricReader returns a reader for accessing all trie nodes of a historic
+// state below the persistent one, reconstructed in memory from the state
+// histories. The reader becomes unusable once the persistent state moves
+// forward. It's only supported by path-based database and will return an
+// error for others.
+func (db *Database) HistoricReader(root common.Hash) (Reader, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, errors.New("not supported")
+	}
+	return pdb.HistoricReader(root, func(reader pathdb.NodeReader) triestate.TrieLoader {
+		return &readerLoader{reader: reader}
+	})
+}
+
 // Recoverable returns the indicator if the specified state is enabled to be
 // recovered. It's only supported by path-based database and will return an
 // error for others.
@@ -17,10 +17,20 @@
 package trie
 
 import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie/triedb/hashdb"
 	"github.com/ethereum/go-ethereum/trie/triedb/pathdb"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/trie/triestate"
 )
 
 // newTestDatabase initializes the trie database with specified scheme.
@@ -38,3 +48,87 @@ func newTestDatabase(diskdb ethdb.Database, scheme string) *Database {
 	}
 	return NewDatabase(diskdb, config)
 }
+
+// Tests that historic states below the persistent one can be read from the
+// path-based database without reverting the persistent state.
+func TestHistoricTrie(t *testing.T) {
+	disk, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	db := NewDatabase(disk, &Config{PathDB: &pathdb.Config{}})
+	defer db.Close()
+
+	var (
+		addrs    = []common.Address{{0x1}, {0x2}, {0x3}}
+		accounts = make(map[common.Address]*types.StateAccount)
+		parent   = types.EmptyRootHash
+		roots    []common.Hash
+		states   []map[common.Hash][]byte
+	)
+	for block := uint64(1); block <= 8; block++ {
+		tr, err := New(TrieID(parent), db)
+		if err != nil {
+			t.Fatalf("Failed to open trie %d: %v", block, err)
+		}
+		origins := make(map[common.Address][]byte)
+		for i, addr := range addrs {
+			// Introduce the accounts one by one and modify them afterwards
+			if uint64(i) > block {
+				continue
+			}
+			if prev := accounts[addr]; prev != nil {
+				origins[addr] = types.SlimAccountRLP(*prev)
+			} else {
+				origins[addr] = nil
+			}
+			acc := &types.StateAccount{
+				Nonce:    block,
+				Balance:  big.NewInt(int64(i)),
+				Root:     types.EmptyRootHash,
+				CodeHash: types.EmptyCodeHash.Bytes(),
+			}
+			blob, _ := rlp.EncodeToBytes(acc)
+			tr.MustUpdate(crypto.Keccak256(addr.Bytes()), blob)
+			accounts[addr] = acc
+		}
+		root, nodes, _ := tr.Commit(false)
+		if err := db.Update(root, parent, block, trienode.NewWithNodeSet(nodes), triestate.New(origins, nil, nil)); err != nil {
+			t.Fatalf("Failed to update state %d: %v", block, err)
+		}
+		state := make(map[common.Hash][]byte)
+		for addr, acc := range accounts {
+			blob, _ := rlp.EncodeToBytes(acc)
+			state[crypto.Keccak256Hash(addr.Bytes())] = blob
+		}
+		roots, states, parent = append(roots, root), append(states, state), root
+	}
+	// Flush everything into the persistent state, making the states historic
+	if err := db.Commit(parent, false); err != nil {
+		t.Fatalf("Failed to commit state: %v", err)
+	}
+	if _, err := New(TrieID(roots[0]), db); err == nil {
+		t.Fatal("Historic state available without reconstruction")
+	}
+	for i := len(roots) - 2; i >= 0; i-- {
+		tr, err := NewHistoric(TrieID(roots[i]), db)
+		if err != nil {
+			t.Fatalf("Failed to open historic trie %d: %v", i, err)
+		}
+		found := make(map[common.Hash][]byte)
+		it := NewIterator(tr.MustNodeIterator(nil))
+		for it.Next() {
+			found[common.BytesToHash(it.Key)] = common.CopyBytes(it.Value)
+		}
+		if it.Err != nil {
+			t.Fatalf("Failed to iterate historic trie %d: %v", i, it.Err)
+		}
+		if !reflect.DeepEqual(found, states[i]) {
+			t.Fatalf("Historic state %d mismatch: have %v, want %v", i, found, states[i])
+		}
+	}
+	// The persistent state should remain untouched
+	if _, err := New(TrieID(parent), db); err != nil {
+		t.Fatalf("Persistent state unavailable: %v", err)
+	}
+}
@@ -84,6 +84,23 @@ func New(id *ID, db *Database) (*Trie, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newWithReader(id, reader)
+}
+
+// NewHistoric creates the trie instance with provided trie id, resolving the
+// nodes from a historic state below the persistent one. It's only supported
+// by the path-based scheme, see Database.HistoricReader for more details.
+func NewHistoric(id *ID, db *Database) (*Trie, error) {
+	reader, err := db.HistoricReader(id.StateRoot)
+	if err != nil {
+		return nil, &MissingNodeError{Owner: id.Owner, NodeHash: id.StateRoot, err: err}
+	}
+	return newWithReader(id, &trieReader{owner: id.Owner, reader: reader})
+}
+
+// newWithReader creates the trie instance with provided trie id and the reader
+// for resolving the trie nodes.
+func newWithReader(id *ID, reader *trieReader) (*Trie, error) {
 	trie := &Trie{
 		owner:  id.Owner,
 		reader: reader,
@@ -99,3 +99,19 @@ func (l *trieLoader) OpenTrie(root common.Hash) (triestate.Trie, error) {
 func (l *trieLoader) OpenStorageTrie(stateRoot common.Hash, addrHash, root common.Hash) (triestate.Trie, error) {
 	return New(StorageTrieID(stateRoot, addrHash, root), l.db)
 }
+
+// readerLoader implements triestate.TrieLoader for constructing tries on top
+// of a fixed node reader, regardless of the state availability in the database.
+type readerLoader struct {
+	reader Reader
+}
+
+// OpenTrie opens the main account trie.
+func (l *readerLoader) OpenTrie(root common.Hash) (triestate.Trie, error) {
+	return newWithReader(TrieID(root), &trieReader{reader: l.reader})
+}
+
+// OpenStorageTrie opens the storage trie of an account.
+func (l *readerLoader) OpenStorageTrie(stateRoot common.Hash, addrHash, root common.Hash) (triestate.Trie, error) {
+	return newWithReader(StorageTrieID(stateRoot, addrHash, root), &trieReader{owner: addrHash, reader: l.reader})
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/trie/testutil"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/trie/triestate"
+	"golang.org/x/time/rate"
 )
 
 func updateTrie(addrHash common.Hash, root common.Hash, dirties, cleans map[common.Hash][]byte) (common.Hash, *trienode.NodeSet) {
@@ -315,7 +316,11 @@ func (t *tester) verifyState(root common.Hash) error {
 	if err != nil {
 		return err
 	}
-	_, err = reader.Node(common.Hash{}, nil, root)
+	return t.verifyReader(reader, root)
+}
+
+func (t *tester) verifyReader(reader NodeReader, root common.Hash) error {
+	_, err := reader.Node(common.Hash{}, nil, root)
 	if err != nil {
 		return errors.New("root node is not available")
 	}
@@ -404,6 +409,72 @@ func TestDatabaseRollback(t *testing.T) {
 	}
 }
 
+func TestDatabaseHistoricReader(t *testing.T) {
+	tester := newTester(t, 0)
+	defer tester.release()
+
+	loader := func(reader NodeReader) triestate.TrieLoader {
+		return &historicLoader{tester: tester}
+	}
+	// Historic states below the disk layer should be readable without
+	// touching the persistent state
+	bottom := tester.bottomIndex()
+	for _, i := range []int{bottom - 1, bottom - maxHistoricDepth/2, bottom - maxHistoricDepth} {
+		reader, err := tester.db.HistoricReader(tester.roots[i], loader)
+		if err != nil {
+			t.Fatalf("Failed to reconstruct historic state %d: %v", i, err)
+		}
+		if err := tester.verifyReader(reader, tester.roots[i]); err != nil {
+			t.Fatalf("Invalid historic state %d: %v", i, err)
+		}
+	}
+	if index := tester.bottomIndex(); index != bottom {
+		t.Fatalf("Disk layer mutated, want %d, got %d", bottom, index)
+	}
+	if err := tester.verifyState(tester.roots[bottom]); err != nil {
+		t.Fatalf("Invalid disk state: %v", err)
+	}
+	// The disk layer and the layers above are not historic
+	for _, i := range []int{bottom, bottom + 1} {
+		if _, err := tester.db.HistoricReader(tester.roots[i], loader); err == nil {
+			t.Fatalf("Non-historic state %d reconstructed", i)
+		}
+	}
+	if _, err := tester.db.HistoricReader(common.Hash{0x1}, loader); err == nil {
+		t.Fatal("Unknown state reconstructed")
+	}
+	// States too far below the disk layer are not reconstructed
+	if _, err := tester.db.HistoricReader(tester.roots[bottom-maxHistoricDepth-1], loader); !errors.Is(err, errHistoricTooDeep) {
+		t.Fatalf("Unexpected error for deep state, want %v, got %v", errHistoricTooDeep, err)
+	}
+	// Reconstructions beyond the allowed rate are rejected, cached states are
+	// still served
+	tester.db.rebuilds.limiter = rate.NewLimiter(0, 2)
+	if _, err := tester.db.HistoricReader(tester.roots[bottom-2], loader); err != nil {
+		t.Fatalf("Failed to reconstruct historic state: %v", err)
+	}
+	if _, err := tester.db.HistoricReader(tester.roots[bottom-3], loader); !errors.Is(err, errHistoricThrottled) {
+		t.Fatalf("Unexpected error for throttled state, want %v, got %v", errHistoricThrottled, err)
+	}
+	if _, err := tester.db.HistoricReader(tester.roots[bottom-2], loader); err != nil {
+		t.Fatalf("Failed to read cached historic state: %v", err)
+	}
+}
+
+// historicLoader is a trie loader opening the tries of any state known to
+// the tester.
+type historicLoader struct {
+	tester *tester
+}
+
+func (l *historicLoader) OpenTrie(root common.Hash) (triestate.Trie, error) {
+	return newTestHasher(common.Hash{}, root, l.tester.snapAccounts[root])
+}
+
+func (l *historicLoader) OpenStorageTrie(stateRoot common.Hash, addrHash, root common.Hash) (triestate.Trie, error) {
+	return newTestHasher(addrHash, root, l.tester.snapStorages[stateRoot][addrHash])
+}
+
 func TestDatabaseRecoverable(t *testing.T) {
 	var (
 		tester = newTester(t, 0)
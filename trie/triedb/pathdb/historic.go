@@ -0,0 +1,239 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/trie/triestate"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// maxHistoricDepth is the maximum number of state histories that are
+	// reverted in memory to reconstruct a historic state below the disk layer.
+	maxHistoricDepth = 32
+
+	// maxHistoricSize is the maximum memory allowance (in bytes) of the trie
+	// nodes reverted to reconstruct a single historic state.
+	maxHistoricSize = 16 * 1024 * 1024
+
+	// historicCacheSize is the number of reconstructed historic states that are
+	// kept around to serve subsequent reads.
+	historicCacheSize = 4
+
+	// historicRebuildRate is the number of state histories per second which
+	// may be reverted on average, so remote requests cycling through historic
+	// roots can't keep the node busy reconstructing states.
+	historicRebuildRate = maxHistoricDepth
+
+	// historicRebuildBurst is the number of state histories which may be
+	// reverted in a burst.
+	historicRebuildBurst = 4 * maxHistoricDepth
+)
+
+var (
+	// errHistoricTooDeep is returned if a historic state is requested which is
+	// too far below the disk layer to be reconstructed in memory.
+	errHistoricTooDeep = errors.New("historic state too deep")
+
+	// errHistoricTooLarge is returned if the trie nodes reverted to reconstruct
+	// a historic state exceed the memory allowance.
+	errHistoricTooLarge = errors.New("historic state too large")
+
+	// errHistoricThrottled is returned if reconstructing a historic state would
+	// exceed the allowed rate of reverted state histories.
+	errHistoricThrottled = errors.New("historic state reconstruction throttled")
+)
+
+// historicBuilder throttles the reconstruction of historic states, allowing a
+// single one at a time and limiting the rate of the reverted state histories.
+type historicBuilder struct {
+	limiter *rate.Limiter // Rate limiter of the reverted state histories
+	lock    sync.Mutex    // Held during a reconstruction
+}
+
+// newHistoricBuilder creates a throttler of historic state reconstructions.
+func newHistoricBuilder() *historicBuilder {
+	return &historicBuilder{
+		limiter: rate.NewLimiter(historicRebuildRate, historicRebuildBurst),
+	}
+}
+
+// NodeReader wraps the Node method of a backing trie store.
+type NodeReader interface {
+	// Node retrieves the trie node blob with the provided trie identifier, node
+	// path and the corresponding node hash.
+	Node(owner common.Hash, path []byte, hash common.Hash) ([]byte, error)
+}
+
+// LoaderFunc constructs a trie loader opening tries through the given reader.
+type LoaderFunc func(reader NodeReader) triestate.TrieLoader
+
+// historicLayer is a read-only view of a state below the disk layer. It's made
+// up of the disk layer and the set of trie nodes reverted by applying the state
+// histories between the two states in memory, leaving the disk untouched.
+//
+// The view is only valid as long as the disk layer it was assembled on is not
+// stale. Afterwards all reads fail and the view needs to be rebuilt.
+type historicLayer struct {
+	root  common.Hash                               // Root hash of the historic state
+	id    uint64                                    // State id of the historic state
+	disk  *diskLayer                                // Disk layer the view is based on
+	nodes map[common.Hash]map[string]*trienode.Node // Trie nodes differing from the disk layer
+	size  uint64                                    // Approximate memory size of the reverted nodes
+}
+
+// Node implements NodeReader, retrieving the trie node from the set of reverted
+// nodes first and falling back to the disk layer otherwise.
+func (hl *historicLayer) Node(owner common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	if subset, ok := hl.nodes[owner]; ok {
+		if n, ok := subset[string(path)]; ok {
+			if n.Hash != hash {
+				return nil, newUnexpectedNodeError("historic", hash, n.Hash, owner, path, n.Blob)
+			}
+			return n.Blob, nil
+		}
+	}
+	return hl.disk.Node(owner, path, hash)
+}
+
+// stale reports whether the disk layer backing the view was invalidated.
+func (hl *historicLayer) stale() bool {
+	hl.disk.lock.RLock()
+	defer hl.disk.lock.RUnlock()
+
+	return hl.disk.stale
+}
+
+// merge applies a set of reverted trie nodes on top of the view, moving it one
+// state further into the past.
+func (hl *historicLayer) merge(root common.Hash, nodes map[common.Hash]map[string]*trienode.Node) {
+	for owner, subset := range nodes {
+		current, ok := hl.nodes[owner]
+		if !ok {
+			current = make(map[string]*trienode.Node, len(subset))
+			hl.nodes[owner] = current
+		}
+		for path, n := range subset {
+			if prev, ok := current[path]; ok {
+				hl.size -= uint64(len(prev.Blob))
+			} else {
+				hl.size += uint64(len(path) + common.HashLength)
+			}
+			hl.size += uint64(len(n.Blob))
+			current[path] = n
+		}
+	}
+	hl.root = root
+	hl.id--
+}
+
+// HistoricReader returns a read-only reader of a historic state below the disk
+// layer, reconstructed in memory by reverting the state histories on top of the
+// current disk state. The given loader is used to open the intermediate tries
+// while the histories are applied.
+//
+// Reconstructions are bounded in depth and memory, serialized and rate limited,
+// so they can be safely triggered by remote requests.
+//
+// The returned reader becomes unusable once the disk layer moves forward, after
+// which a new one needs to be requested.
+func (db *Database) HistoricReader(root common.Hash, loader LoaderFunc) (NodeReader, error) {
+	root = types.TrieRootHash(root)
+	if hl, ok := db.historics.Get(root); ok && !hl.stale() {
+		return hl, nil
+	}
+	// Reconstruct a single state at a time, it might be requested concurrently.
+	db.rebuilds.lock.Lock()
+	defer db.rebuilds.lock.Unlock()
+
+	if hl, ok := db.historics.Get(root); ok && !hl.stale() {
+		return hl, nil
+	}
+	// Hold the lock to prevent the disk layer from moving forward while the
+	// state histories on top of it are reverted.
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.freezer == nil {
+		return nil, errors.New("historic state is non-supported")
+	}
+	if db.waitSync {
+		return nil, errDatabaseWaitSync
+	}
+	id := rawdb.ReadStateID(db.diskdb, root)
+	if id == nil {
+		return nil, fmt.Errorf("state %#x is not available", root)
+	}
+	dl := db.tree.bottom()
+	if *id >= dl.stateID() {
+		return nil, fmt.Errorf("state %#x is not historic", root)
+	}
+	if dl.stateID()-*id > maxHistoricDepth {
+		return nil, fmt.Errorf("%w: %d states below disk", errHistoricTooDeep, dl.stateID()-*id)
+	}
+	if !db.rebuilds.limiter.AllowN(time.Now(), int(dl.stateID()-*id)) {
+		return nil, errHistoricThrottled
+	}
+	// Revert the state histories one by one, opening the tries of each state
+	// through the view assembled so far.
+	var (
+		start = time.Now()
+		hl    = &historicLayer{
+			root:  dl.rootHash(),
+			id:    dl.stateID(),
+			disk:  dl,
+			nodes: make(map[common.Hash]map[string]*trienode.Node),
+		}
+	)
+	for hl.id > *id {
+		h, err := readHistory(db.freezer, hl.id)
+		if err != nil {
+			return nil, err
+		}
+		if h.meta.root != hl.root {
+			return nil, errUnexpectedHistory
+		}
+		if len(h.meta.incomplete) > 0 {
+			return nil, errors.New("incomplete state history")
+		}
+		nodes, err := triestate.Apply(h.meta.parent, h.meta.root, h.accounts, h.storages, loader(hl))
+		if err != nil {
+			return nil, err
+		}
+		hl.merge(h.meta.parent, nodes)
+		if hl.size > maxHistoricSize {
+			return nil, fmt.Errorf("%w: %d bytes reverted", errHistoricTooLarge, hl.size)
+		}
+	}
+	if hl.root != root {
+		return nil, fmt.Errorf("%w: want %#x, got %#x", errUnexpectedHistory, root, hl.root)
+	}
+	db.historics.Add(root, hl)
+
+	log.Debug("Reconstructed historic state", "root", root, "id", hl.id, "disk", dl.stateID(), "size", common.StorageSize(hl.size), "elapsed", common.PrettyDuration(time.Since(start)))
+	return hl, nil
+}